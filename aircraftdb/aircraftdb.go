@@ -0,0 +1,154 @@
+// Package aircraftdb looks aircraft up by ICAO address in downloaded
+// databases (the ones dbupdate keeps fresh): their registration and type,
+// for the countries where the address alone doesn't give them away.
+//
+// A database is a text file with one aircraft a line: the address, the
+// registration and the ICAO type code, split on commas or semicolons.
+// Anything after those is ignored, as are blank lines and ones starting
+// with #, so tar1090-db's aircraft.csv reads as it comes, gzipped or not.
+package aircraftdb
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Entry is what a database says about one aircraft. Either can be empty.
+type Entry struct {
+	Registration string
+	Type         string // ICAO type designator, like A388 or B738
+}
+
+// DB is the aircraft from one or more database files, later files
+// winning. It's safe to look up in while it's being reloaded.
+type DB struct {
+	paths []string
+
+	mu       sync.RWMutex
+	aircraft map[string]Entry // By upper-case ICAO address
+	err      error            // From the last load, if a file wouldn't read
+}
+
+// Open loads the databases at paths. One that's missing (not downloaded
+// yet, say) is left out, as is one that won't read, which says why in
+// Err, rather than failing the lot.
+func Open(paths ...string) *DB {
+	d := &DB{paths: paths}
+	d.Reload()
+	return d
+}
+
+// Reload reads the files again, for when one's been updated
+func (d *DB) Reload() {
+	aircraft := make(map[string]Entry)
+	var errs []string
+	for _, path := range d.paths {
+		if err := load(path, aircraft); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.aircraft = aircraft
+	d.err = nil
+	if len(errs) > 0 {
+		d.err = fmt.Errorf("aircraftdb: %s", strings.Join(errs, "; "))
+	}
+}
+
+// Lookup returns what the databases say about the aircraft with ICAO
+// address hex. A nil DB has nothing in it.
+func (d *DB) Lookup(hex string) (Entry, bool) {
+	if d == nil {
+		return Entry{}, false
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	e, ok := d.aircraft[strings.ToUpper(hex)]
+	return e, ok
+}
+
+// Len is how many aircraft the databases have between them
+func (d *DB) Len() int {
+	if d == nil {
+		return 0
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.aircraft)
+}
+
+// Err is why a file didn't load last time, or nil if they all did
+func (d *DB) Err() error {
+	if d == nil {
+		return nil
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.err
+}
+
+// load adds the aircraft in the file at path to aircraft
+func load(path string, aircraft map[string]Entry) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hex, e, ok := parseLine(line)
+		if ok {
+			aircraft[hex] = e
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// parseLine reads one aircraft's line, reporting false for one without a
+// six-digit address (a header, say) or with nothing to say about it
+func parseLine(line string) (string, Entry, bool) {
+	sep := ","
+	if strings.Contains(line, ";") {
+		sep = ";"
+	}
+	fields := strings.Split(line, sep)
+	hex := strings.ToUpper(strings.TrimSpace(fields[0]))
+	if len(hex) != 6 || strings.Trim(hex, "0123456789ABCDEF") != "" {
+		return "", Entry{}, false
+	}
+	var e Entry
+	if len(fields) > 1 {
+		e.Registration = strings.TrimSpace(fields[1])
+	}
+	if len(fields) > 2 {
+		e.Type = strings.ToUpper(strings.TrimSpace(fields[2]))
+	}
+	return hex, e, e != Entry{}
+}
@@ -0,0 +1,206 @@
+// Package announce speaks events out loud through an external text-to-speech
+// command (espeak, say, piper...), so the tracker can be followed without
+// watching it.
+package announce
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"termtrack/geo"
+	"termtrack/sbs"
+	"termtrack/watch"
+)
+
+// DefaultRange is how close, in nautical miles, an aircraft has to come
+// before we announce it
+const DefaultRange = 10.0
+
+// queueSize is how many announcements can wait to be spoken. Beyond that
+// they're dropped: by the time they'd be read out they'd be stale.
+const queueSize = 4
+
+// Announcer watches the aircraft for events and speaks them
+type Announcer struct {
+	command []string // The text goes on the end as one more argument
+	radius  float64
+
+	inRange   map[string]bool        // ICAOs inside the radius
+	anomalies map[string]sbs.Anomaly // What we've already said is suspect
+	circling  map[string]bool
+	emergency map[string]string // What each aircraft's squawk says is wrong
+	started   bool              // The first check only learns what's there
+	muted     bool
+	watch.Watch
+
+	queue chan string
+
+	mu  sync.Mutex
+	err error // From the last time the command ran
+}
+
+// New starts an announcer that speaks with command, split on spaces, e.g.
+// "espeak -s 150". Aircraft are announced when they come within radius
+// nautical miles of the reference point.
+func New(command string, radius float64) (*Announcer, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("announce: no command given")
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return nil, fmt.Errorf("announce: %w", err)
+	}
+
+	a := &Announcer{
+		command:   args,
+		radius:    radius,
+		inRange:   make(map[string]bool),
+		anomalies: make(map[string]sbs.Anomaly),
+		circling:  make(map[string]bool),
+		emergency: make(map[string]string),
+		queue:     make(chan string, queueSize),
+	}
+	go a.speak()
+	return a, nil
+}
+
+// Close stops speaking once whatever's being said has finished
+func (a *Announcer) Close() {
+	close(a.queue)
+}
+
+// SetMuted stops (or starts again) announcing. Events still get tracked
+// while muted, so unmuting doesn't bring a backlog of old news.
+func (a *Announcer) SetMuted(muted bool) {
+	a.muted = muted
+}
+
+// Muted reports whether announcements are off
+func (a *Announcer) Muted() bool {
+	return a.muted
+}
+
+// Err is why the speech command last failed, or nil if it worked
+func (a *Announcer) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// Check looks for events worth announcing (at most every watch.Interval).
+// Distances and directions are from the reference point.
+func (a *Announcer) Check(all map[string]*sbs.Aircraft, refLat, refLon float64) {
+	now := time.Now()
+	if !a.Due(now) {
+		return
+	}
+
+	for icao, ac := range all {
+		// --- Emergencies ---
+		// Said with or without a position; it's what matters most
+		what := ac.SquawkEmergency()
+		if what != "" && what != a.emergency[icao] {
+			text := fmt.Sprintf("Emergency, %s, %s", what, spokenName(ac))
+			if ac.Lat != 0 || ac.Lon != 0 {
+				text += ", " + spokenPlace(refLat, refLon, ac.Lat, ac.Lon)
+			}
+			a.say(text)
+		}
+		if what != "" {
+			a.emergency[icao] = what
+		} else {
+			delete(a.emergency, icao)
+		}
+
+		if ac.Lat == 0 && ac.Lon == 0 {
+			continue // No position yet
+		}
+		dist := geo.Distance(refLat, refLon, ac.Lat, ac.Lon)
+		where := fmt.Sprintf("%s, %s", spokenName(ac), spokenPlace(refLat, refLon, ac.Lat, ac.Lon))
+
+		// --- Suspect data ---
+		if fresh := ac.Anomalies &^ a.anomalies[icao]; fresh != 0 {
+			a.say(fmt.Sprintf("Suspect data, %s, %s", where, fresh))
+		}
+		a.anomalies[icao] = ac.Anomalies
+
+		// --- Circling ---
+		circling := ac.Circling(now)
+		if circling && !a.circling[icao] {
+			a.say(fmt.Sprintf("Circling, %s", where))
+		}
+		a.circling[icao] = circling
+
+		// --- Range ---
+		in := dist <= a.radius && !a.Quiet(ac, now)
+		if in && !a.inRange[icao] {
+			a.say(fmt.Sprintf("Inbound, %s", where))
+		}
+		a.inRange[icao] = in
+	}
+
+	// Forget the ones the store has dropped
+	for icao := range a.inRange {
+		if _, ok := all[icao]; !ok {
+			delete(a.inRange, icao)
+			delete(a.anomalies, icao)
+			delete(a.circling, icao)
+		}
+	}
+	for icao := range a.emergency {
+		if _, ok := all[icao]; !ok {
+			delete(a.emergency, icao)
+		}
+	}
+	a.started = true
+}
+
+// Alert speaks a rule the aircraft has just started matching
+func (a *Announcer) Alert(rule string, ac *sbs.Aircraft, refLat, refLon float64) {
+	a.say(fmt.Sprintf("Alert, %s, %s, %s", rule, spokenName(ac), spokenPlace(refLat, refLon, ac.Lat, ac.Lon)))
+}
+
+// say queues text to be spoken, dropping it if we're muted, still
+// learning what's about, or too far behind
+func (a *Announcer) say(text string) {
+	if a.muted || !a.started {
+		return
+	}
+	select {
+	case a.queue <- text:
+	default:
+	}
+}
+
+// speak runs the command for each announcement in turn, so they never
+// talk over each other
+func (a *Announcer) speak() {
+	for text := range a.queue {
+		args := append(a.command[1:len(a.command):len(a.command)], text)
+		out, err := exec.Command(a.command[0], args...).CombinedOutput()
+		if err != nil && len(out) > 0 {
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		}
+		a.mu.Lock()
+		a.err = err
+		a.mu.Unlock()
+	}
+}
+
+// spokenPlace is where an aircraft is from the reference point, like
+// "15 miles north-west"
+func spokenPlace(refLat, refLon, lat, lon float64) string {
+	dist := geo.Distance(refLat, refLon, lat, lon)
+	dir := geo.CompassPoint(geo.Bearing(refLat, refLon, lat, lon))
+	if dist < 1 {
+		return "overhead"
+	}
+	if dist < 1.5 {
+		return "1 mile " + dir
+	}
+	return fmt.Sprintf("%.0f miles %s", dist, dir)
+}
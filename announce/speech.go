@@ -0,0 +1,59 @@
+package announce
+
+import (
+	"strings"
+
+	"termtrack/icao"
+	"termtrack/sbs"
+)
+
+// telephony is how controllers say the busier airlines' ICAO designators,
+// so DAL123 comes out as "Delta 1 2 3" rather than "dal one hundred and
+// twenty-three"
+var telephony = map[string]string{
+	"AAL": "American",
+	"ACA": "Air Canada",
+	"AFR": "Air France",
+	"ASA": "Alaska",
+	"BAW": "Speedbird",
+	"DAL": "Delta",
+	"DLH": "Lufthansa",
+	"EIN": "Shamrock",
+	"EZY": "Easy",
+	"FDX": "FedEx",
+	"FFT": "Frontier Flight",
+	"JBU": "JetBlue",
+	"KLM": "K L M",
+	"NKS": "Spirit Wings",
+	"RPA": "Brickyard",
+	"RYR": "Ryanair",
+	"SKW": "SkyWest",
+	"SWA": "Southwest",
+	"UAE": "Emirates",
+	"UAL": "United",
+	"UPS": "U P S",
+	"VIR": "Virgin",
+	"WJA": "WestJet",
+}
+
+// spokenName is how we say an aircraft: an airline's callsign the way a
+// controller would, anything else spelt out, and the hex when there's
+// nothing better
+func spokenName(ac *sbs.Aircraft) string {
+	call := ac.Callsign
+	if call == "" || icao.BlockedCallsign(call) {
+		return "hex " + spell(ac.ICAO)
+	}
+	if len(call) > 3 {
+		if airline, ok := telephony[call[:3]]; ok {
+			return airline + " " + spell(call[3:])
+		}
+	}
+	return spell(call)
+}
+
+// spell separates the characters, so speech engines read out each letter
+// and digit instead of guessing at a word or a number
+func spell(s string) string {
+	return strings.Join(strings.Split(s, ""), " ")
+}
@@ -0,0 +1,266 @@
+// Package api is TermTrack's gRPC streaming API. The generated code comes
+// from termtrack.proto; regenerate it with go generate after editing it.
+package api
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative termtrack.proto
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"termtrack/sbs"
+)
+
+// defaultInterval is how often StreamAircraft sends when the client doesn't say
+const defaultInterval = time.Second
+
+// minInterval stops clients from asking for a snapshot every microsecond
+const minInterval = 100 * time.Millisecond
+
+// emergencyEvery is how often the store is checked for aircraft starting
+// to squawk an emergency
+const emergencyEvery = time.Second
+
+// alertQueue is how many alerts can wait for a slow StreamAlerts client.
+// Beyond that they're dropped for it, rather than hold up the others.
+const alertQueue = 64
+
+// Server implements the TermTrack service on top of an aircraft store.
+// Alerts reach StreamAlerts clients through Raise; the server raises the
+// emergencies itself, TUI or not.
+type Server struct {
+	UnimplementedTermTrackServer
+	store *sbs.Store
+
+	mu       sync.Mutex
+	watchers map[chan *Alert]struct{} // One per StreamAlerts client
+
+	done     chan struct{} // Closed on shutdown, ending every stream
+	stopOnce sync.Once
+}
+
+// NewServer creates a server that streams from store
+func NewServer(store *sbs.Store) *Server {
+	return &Server{store: store, watchers: make(map[chan *Alert]struct{}), done: make(chan struct{})}
+}
+
+// stop ends every open stream
+func (s *Server) stop() {
+	s.stopOnce.Do(func() { close(s.done) })
+}
+
+// StreamAircraft sends snapshots of the store until the client hangs up
+func (s *Server) StreamAircraft(req *StreamAircraftRequest, stream grpc.ServerStreamingServer[Snapshot]) error {
+	interval := time.Duration(req.GetIntervalMs()) * time.Millisecond
+	if interval == 0 {
+		interval = defaultInterval
+	}
+	interval = max(interval, minInterval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := stream.Send(s.snapshot()); err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.done:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// StreamAlerts sends every alert raised from when the client connects
+// until it hangs up
+func (s *Server) StreamAlerts(_ *StreamAlertsRequest, stream grpc.ServerStreamingServer[Alert]) error {
+	alerts := make(chan *Alert, alertQueue)
+	s.mu.Lock()
+	s.watchers[alerts] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers, alerts)
+		s.mu.Unlock()
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.done:
+			return nil
+		case a := <-alerts:
+			if err := stream.Send(a); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Raise sends an alert about the aircraft icao to every StreamAlerts
+// client. kind is what sort it is, see Alert.
+func (s *Server) Raise(at time.Time, icao, kind, message string) {
+	a := &Alert{Time: timestamppb.New(at), Icao: icao, Kind: kind, Message: message}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for alerts := range s.watchers {
+		select {
+		case alerts <- a:
+		default: // Too far behind; it misses this one
+		}
+	}
+}
+
+// watchEmergencies raises an "emergency" alert whenever an aircraft
+// starts squawking an emergency code (or sets the emergency flag), until
+// shutdown
+func (s *Server) watchEmergencies() {
+	ticker := time.NewTicker(emergencyEvery)
+	defer ticker.Stop()
+
+	var version uint64
+	emergency := make(map[string]string) // What's wrong, by ICAO
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+		diff := s.store.Changes(version)
+		version = diff.Version
+		if diff.Full {
+			clear(emergency) // Those gone since are missing from Removed
+		}
+		for _, icao := range diff.Removed {
+			delete(emergency, icao)
+		}
+		for icao, ac := range diff.Changed {
+			what := ac.SquawkEmergency()
+			if what != "" && what != emergency[icao] {
+				name := cmp.Or(ac.Callsign, icao)
+				if ac.Squawk != "" {
+					name += " squawking " + ac.Squawk
+				}
+				s.Raise(ac.LastSeen, icao, "emergency", fmt.Sprintf("Emergency: %s, %s", name, what))
+			}
+			if what != "" {
+				emergency[icao] = what
+			} else {
+				delete(emergency, icao)
+			}
+		}
+	}
+}
+
+// snapshot converts the store's current state, ordered by ICAO
+func (s *Server) snapshot() *Snapshot {
+	now := time.Now()
+	all := s.store.Snapshot()
+
+	icaos := make([]string, 0, len(all))
+	for icao := range all {
+		icaos = append(icaos, icao)
+	}
+	sort.Strings(icaos)
+
+	snap := &Snapshot{Time: timestamppb.New(now)}
+	for _, icao := range icaos {
+		snap.Aircraft = append(snap.Aircraft, convert(all[icao], now))
+	}
+	return snap
+}
+
+// GetAircraft looks up one aircraft, without copying the rest of the store
+func (s *Server) GetAircraft(_ context.Context, req *GetAircraftRequest) (*Aircraft, error) {
+	ac, ok := s.store.Get(strings.ToUpper(req.GetIcao()))
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no aircraft %q", req.GetIcao())
+	}
+	return convert(ac, time.Now()), nil
+}
+
+// convert turns one of the store's aircraft into the API's
+func convert(ac *sbs.Aircraft, now time.Time) *Aircraft {
+	out := &Aircraft{
+		Icao:               ac.ICAO,
+		Callsign:           ac.Callsign,
+		GroundSpeedKt:      ac.Speed,
+		TrackDeg:           ac.Track,
+		SpeedTrendKtPerMin: ac.SpeedTrend,
+		LastSeen:           timestamppb.New(ac.LastSeen),
+		Receivers:          ac.HeardBy(now),
+		Squawk:             ac.Squawk,
+		Emergency:          ac.SquawkEmergency(),
+	}
+	if ac.Lat != 0 || ac.Lon != 0 {
+		out.Position = &Position{Lat: ac.Lat, Lon: ac.Lon}
+	}
+	if ac.Has&sbs.HasAltitude != 0 {
+		out.AltitudeFt = proto.Int32(int32(ac.Altitude))
+	}
+	if ac.Has&sbs.HasVertRate != 0 {
+		out.VerticalRateFpm = proto.Int32(int32(ac.VertRate))
+	}
+	return out
+}
+
+// shutdownTimeout is how long Shutdown waits for clients before cutting them off
+const shutdownTimeout = 2 * time.Second
+
+// Listener is a running gRPC server
+type Listener struct {
+	srv  *Server
+	grpc *grpc.Server
+}
+
+// ListenAndServe starts a gRPC server for store on addr in the background
+func ListenAndServe(addr string, store *sbs.Store) (*Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("grpc listen: %w", err)
+	}
+
+	l := &Listener{srv: NewServer(store), grpc: grpc.NewServer()}
+	RegisterTermTrackServer(l.grpc, l.srv)
+	go l.grpc.Serve(ln)
+	go l.srv.watchEmergencies()
+	return l, nil
+}
+
+// Raise sends an alert to every StreamAlerts client, see Server.Raise
+func (l *Listener) Raise(at time.Time, icao, kind, message string) {
+	l.srv.Raise(at, icao, kind, message)
+}
+
+// Shutdown ends the open streams, lets in-flight sends finish and closes
+// the listener. Clients that won't let go are cut off after shutdownTimeout.
+func (l *Listener) Shutdown() {
+	l.srv.stop()
+
+	done := make(chan struct{})
+	go func() {
+		l.grpc.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		l.grpc.Stop()
+	}
+}
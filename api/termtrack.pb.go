@@ -0,0 +1,562 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: termtrack.proto
+
+package api
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StreamAircraftRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	IntervalMs    uint32                 `protobuf:"varint,1,opt,name=interval_ms,json=intervalMs,proto3" json:"interval_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamAircraftRequest) Reset() {
+	*x = StreamAircraftRequest{}
+	mi := &file_termtrack_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamAircraftRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamAircraftRequest) ProtoMessage() {}
+
+func (x *StreamAircraftRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_termtrack_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamAircraftRequest.ProtoReflect.Descriptor instead.
+func (*StreamAircraftRequest) Descriptor() ([]byte, []int) {
+	return file_termtrack_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StreamAircraftRequest) GetIntervalMs() uint32 {
+	if x != nil {
+		return x.IntervalMs
+	}
+	return 0
+}
+
+type StreamAlertsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamAlertsRequest) Reset() {
+	*x = StreamAlertsRequest{}
+	mi := &file_termtrack_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamAlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamAlertsRequest) ProtoMessage() {}
+
+func (x *StreamAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_termtrack_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamAlertsRequest.ProtoReflect.Descriptor instead.
+func (*StreamAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_termtrack_proto_rawDescGZIP(), []int{1}
+}
+
+type GetAircraftRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Icao          string                 `protobuf:"bytes,1,opt,name=icao,proto3" json:"icao,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAircraftRequest) Reset() {
+	*x = GetAircraftRequest{}
+	mi := &file_termtrack_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAircraftRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAircraftRequest) ProtoMessage() {}
+
+func (x *GetAircraftRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_termtrack_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAircraftRequest.ProtoReflect.Descriptor instead.
+func (*GetAircraftRequest) Descriptor() ([]byte, []int) {
+	return file_termtrack_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetAircraftRequest) GetIcao() string {
+	if x != nil {
+		return x.Icao
+	}
+	return ""
+}
+
+type Snapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Aircraft      []*Aircraft            `protobuf:"bytes,2,rep,name=aircraft,proto3" json:"aircraft,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Snapshot) Reset() {
+	*x = Snapshot{}
+	mi := &file_termtrack_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Snapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Snapshot) ProtoMessage() {}
+
+func (x *Snapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_termtrack_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Snapshot.ProtoReflect.Descriptor instead.
+func (*Snapshot) Descriptor() ([]byte, []int) {
+	return file_termtrack_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Snapshot) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Snapshot) GetAircraft() []*Aircraft {
+	if x != nil {
+		return x.Aircraft
+	}
+	return nil
+}
+
+type Position struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lat           float64                `protobuf:"fixed64,1,opt,name=lat,proto3" json:"lat,omitempty"`
+	Lon           float64                `protobuf:"fixed64,2,opt,name=lon,proto3" json:"lon,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Position) Reset() {
+	*x = Position{}
+	mi := &file_termtrack_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Position) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Position) ProtoMessage() {}
+
+func (x *Position) ProtoReflect() protoreflect.Message {
+	mi := &file_termtrack_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Position.ProtoReflect.Descriptor instead.
+func (*Position) Descriptor() ([]byte, []int) {
+	return file_termtrack_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Position) GetLat() float64 {
+	if x != nil {
+		return x.Lat
+	}
+	return 0
+}
+
+func (x *Position) GetLon() float64 {
+	if x != nil {
+		return x.Lon
+	}
+	return 0
+}
+
+type Aircraft struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Icao               string                 `protobuf:"bytes,1,opt,name=icao,proto3" json:"icao,omitempty"`
+	Callsign           string                 `protobuf:"bytes,2,opt,name=callsign,proto3" json:"callsign,omitempty"`
+	Position           *Position              `protobuf:"bytes,3,opt,name=position,proto3" json:"position,omitempty"`
+	GroundSpeedKt      float64                `protobuf:"fixed64,4,opt,name=ground_speed_kt,json=groundSpeedKt,proto3" json:"ground_speed_kt,omitempty"`
+	TrackDeg           float64                `protobuf:"fixed64,5,opt,name=track_deg,json=trackDeg,proto3" json:"track_deg,omitempty"`
+	SpeedTrendKtPerMin float64                `protobuf:"fixed64,6,opt,name=speed_trend_kt_per_min,json=speedTrendKtPerMin,proto3" json:"speed_trend_kt_per_min,omitempty"`
+	LastSeen           *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_seen,json=lastSeen,proto3" json:"last_seen,omitempty"`
+	Receivers          []string               `protobuf:"bytes,8,rep,name=receivers,proto3" json:"receivers,omitempty"`
+	AltitudeFt         *int32                 `protobuf:"varint,9,opt,name=altitude_ft,json=altitudeFt,proto3,oneof" json:"altitude_ft,omitempty"`
+	VerticalRateFpm    *int32                 `protobuf:"varint,10,opt,name=vertical_rate_fpm,json=verticalRateFpm,proto3,oneof" json:"vertical_rate_fpm,omitempty"`
+	Squawk             string                 `protobuf:"bytes,11,opt,name=squawk,proto3" json:"squawk,omitempty"`
+	Emergency          string                 `protobuf:"bytes,12,opt,name=emergency,proto3" json:"emergency,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *Aircraft) Reset() {
+	*x = Aircraft{}
+	mi := &file_termtrack_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Aircraft) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Aircraft) ProtoMessage() {}
+
+func (x *Aircraft) ProtoReflect() protoreflect.Message {
+	mi := &file_termtrack_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Aircraft.ProtoReflect.Descriptor instead.
+func (*Aircraft) Descriptor() ([]byte, []int) {
+	return file_termtrack_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Aircraft) GetIcao() string {
+	if x != nil {
+		return x.Icao
+	}
+	return ""
+}
+
+func (x *Aircraft) GetCallsign() string {
+	if x != nil {
+		return x.Callsign
+	}
+	return ""
+}
+
+func (x *Aircraft) GetPosition() *Position {
+	if x != nil {
+		return x.Position
+	}
+	return nil
+}
+
+func (x *Aircraft) GetGroundSpeedKt() float64 {
+	if x != nil {
+		return x.GroundSpeedKt
+	}
+	return 0
+}
+
+func (x *Aircraft) GetTrackDeg() float64 {
+	if x != nil {
+		return x.TrackDeg
+	}
+	return 0
+}
+
+func (x *Aircraft) GetSpeedTrendKtPerMin() float64 {
+	if x != nil {
+		return x.SpeedTrendKtPerMin
+	}
+	return 0
+}
+
+func (x *Aircraft) GetLastSeen() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastSeen
+	}
+	return nil
+}
+
+func (x *Aircraft) GetReceivers() []string {
+	if x != nil {
+		return x.Receivers
+	}
+	return nil
+}
+
+func (x *Aircraft) GetAltitudeFt() int32 {
+	if x != nil && x.AltitudeFt != nil {
+		return *x.AltitudeFt
+	}
+	return 0
+}
+
+func (x *Aircraft) GetVerticalRateFpm() int32 {
+	if x != nil && x.VerticalRateFpm != nil {
+		return *x.VerticalRateFpm
+	}
+	return 0
+}
+
+func (x *Aircraft) GetSquawk() string {
+	if x != nil {
+		return x.Squawk
+	}
+	return ""
+}
+
+func (x *Aircraft) GetEmergency() string {
+	if x != nil {
+		return x.Emergency
+	}
+	return ""
+}
+
+type Alert struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Icao          string                 `protobuf:"bytes,2,opt,name=icao,proto3" json:"icao,omitempty"`
+	Kind          string                 `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_termtrack_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_termtrack_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_termtrack_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Alert) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *Alert) GetIcao() string {
+	if x != nil {
+		return x.Icao
+	}
+	return ""
+}
+
+func (x *Alert) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Alert) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_termtrack_proto protoreflect.FileDescriptor
+
+const file_termtrack_proto_rawDesc = "" +
+	"\n" +
+	"\x0ftermtrack.proto\x12\ftermtrack.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"8\n" +
+	"\x15StreamAircraftRequest\x12\x1f\n" +
+	"\vinterval_ms\x18\x01 \x01(\rR\n" +
+	"intervalMs\"\x15\n" +
+	"\x13StreamAlertsRequest\"(\n" +
+	"\x12GetAircraftRequest\x12\x12\n" +
+	"\x04icao\x18\x01 \x01(\tR\x04icao\"n\n" +
+	"\bSnapshot\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x122\n" +
+	"\baircraft\x18\x02 \x03(\v2\x16.termtrack.v1.AircraftR\baircraft\".\n" +
+	"\bPosition\x12\x10\n" +
+	"\x03lat\x18\x01 \x01(\x01R\x03lat\x12\x10\n" +
+	"\x03lon\x18\x02 \x01(\x01R\x03lon\"\xf1\x03\n" +
+	"\bAircraft\x12\x12\n" +
+	"\x04icao\x18\x01 \x01(\tR\x04icao\x12\x1a\n" +
+	"\bcallsign\x18\x02 \x01(\tR\bcallsign\x122\n" +
+	"\bposition\x18\x03 \x01(\v2\x16.termtrack.v1.PositionR\bposition\x12&\n" +
+	"\x0fground_speed_kt\x18\x04 \x01(\x01R\rgroundSpeedKt\x12\x1b\n" +
+	"\ttrack_deg\x18\x05 \x01(\x01R\btrackDeg\x122\n" +
+	"\x16speed_trend_kt_per_min\x18\x06 \x01(\x01R\x12speedTrendKtPerMin\x127\n" +
+	"\tlast_seen\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\blastSeen\x12\x1c\n" +
+	"\treceivers\x18\b \x03(\tR\treceivers\x12$\n" +
+	"\valtitude_ft\x18\t \x01(\x05H\x00R\n" +
+	"altitudeFt\x88\x01\x01\x12/\n" +
+	"\x11vertical_rate_fpm\x18\n" +
+	" \x01(\x05H\x01R\x0fverticalRateFpm\x88\x01\x01\x12\x16\n" +
+	"\x06squawk\x18\v \x01(\tR\x06squawk\x12\x1c\n" +
+	"\temergency\x18\f \x01(\tR\temergencyB\x0e\n" +
+	"\f_altitude_ftB\x14\n" +
+	"\x12_vertical_rate_fpm\"y\n" +
+	"\x05Alert\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x12\n" +
+	"\x04icao\x18\x02 \x01(\tR\x04icao\x12\x12\n" +
+	"\x04kind\x18\x03 \x01(\tR\x04kind\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage2\xef\x01\n" +
+	"\tTermTrack\x12O\n" +
+	"\x0eStreamAircraft\x12#.termtrack.v1.StreamAircraftRequest\x1a\x16.termtrack.v1.Snapshot0\x01\x12H\n" +
+	"\fStreamAlerts\x12!.termtrack.v1.StreamAlertsRequest\x1a\x13.termtrack.v1.Alert0\x01\x12G\n" +
+	"\vGetAircraft\x12 .termtrack.v1.GetAircraftRequest\x1a\x16.termtrack.v1.AircraftB\x13Z\x11termtrack/api;apib\x06proto3"
+
+var (
+	file_termtrack_proto_rawDescOnce sync.Once
+	file_termtrack_proto_rawDescData []byte
+)
+
+func file_termtrack_proto_rawDescGZIP() []byte {
+	file_termtrack_proto_rawDescOnce.Do(func() {
+		file_termtrack_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_termtrack_proto_rawDesc), len(file_termtrack_proto_rawDesc)))
+	})
+	return file_termtrack_proto_rawDescData
+}
+
+var file_termtrack_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_termtrack_proto_goTypes = []any{
+	(*StreamAircraftRequest)(nil), // 0: termtrack.v1.StreamAircraftRequest
+	(*StreamAlertsRequest)(nil),   // 1: termtrack.v1.StreamAlertsRequest
+	(*GetAircraftRequest)(nil),    // 2: termtrack.v1.GetAircraftRequest
+	(*Snapshot)(nil),              // 3: termtrack.v1.Snapshot
+	(*Position)(nil),              // 4: termtrack.v1.Position
+	(*Aircraft)(nil),              // 5: termtrack.v1.Aircraft
+	(*Alert)(nil),                 // 6: termtrack.v1.Alert
+	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
+}
+var file_termtrack_proto_depIdxs = []int32{
+	7, // 0: termtrack.v1.Snapshot.time:type_name -> google.protobuf.Timestamp
+	5, // 1: termtrack.v1.Snapshot.aircraft:type_name -> termtrack.v1.Aircraft
+	4, // 2: termtrack.v1.Aircraft.position:type_name -> termtrack.v1.Position
+	7, // 3: termtrack.v1.Aircraft.last_seen:type_name -> google.protobuf.Timestamp
+	7, // 4: termtrack.v1.Alert.time:type_name -> google.protobuf.Timestamp
+	0, // 5: termtrack.v1.TermTrack.StreamAircraft:input_type -> termtrack.v1.StreamAircraftRequest
+	1, // 6: termtrack.v1.TermTrack.StreamAlerts:input_type -> termtrack.v1.StreamAlertsRequest
+	2, // 7: termtrack.v1.TermTrack.GetAircraft:input_type -> termtrack.v1.GetAircraftRequest
+	3, // 8: termtrack.v1.TermTrack.StreamAircraft:output_type -> termtrack.v1.Snapshot
+	6, // 9: termtrack.v1.TermTrack.StreamAlerts:output_type -> termtrack.v1.Alert
+	5, // 10: termtrack.v1.TermTrack.GetAircraft:output_type -> termtrack.v1.Aircraft
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_termtrack_proto_init() }
+func file_termtrack_proto_init() {
+	if File_termtrack_proto != nil {
+		return
+	}
+	file_termtrack_proto_msgTypes[5].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_termtrack_proto_rawDesc), len(file_termtrack_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_termtrack_proto_goTypes,
+		DependencyIndexes: file_termtrack_proto_depIdxs,
+		MessageInfos:      file_termtrack_proto_msgTypes,
+	}.Build()
+	File_termtrack_proto = out.File
+	file_termtrack_proto_goTypes = nil
+	file_termtrack_proto_depIdxs = nil
+}
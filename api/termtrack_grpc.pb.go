@@ -0,0 +1,204 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: termtrack.proto
+
+package api
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TermTrack_StreamAircraft_FullMethodName = "/termtrack.v1.TermTrack/StreamAircraft"
+	TermTrack_StreamAlerts_FullMethodName   = "/termtrack.v1.TermTrack/StreamAlerts"
+	TermTrack_GetAircraft_FullMethodName    = "/termtrack.v1.TermTrack/GetAircraft"
+)
+
+// TermTrackClient is the client API for TermTrack service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type TermTrackClient interface {
+	StreamAircraft(ctx context.Context, in *StreamAircraftRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Snapshot], error)
+	StreamAlerts(ctx context.Context, in *StreamAlertsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Alert], error)
+	GetAircraft(ctx context.Context, in *GetAircraftRequest, opts ...grpc.CallOption) (*Aircraft, error)
+}
+
+type termTrackClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTermTrackClient(cc grpc.ClientConnInterface) TermTrackClient {
+	return &termTrackClient{cc}
+}
+
+func (c *termTrackClient) StreamAircraft(ctx context.Context, in *StreamAircraftRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Snapshot], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TermTrack_ServiceDesc.Streams[0], TermTrack_StreamAircraft_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamAircraftRequest, Snapshot]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TermTrack_StreamAircraftClient = grpc.ServerStreamingClient[Snapshot]
+
+func (c *termTrackClient) StreamAlerts(ctx context.Context, in *StreamAlertsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Alert], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TermTrack_ServiceDesc.Streams[1], TermTrack_StreamAlerts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamAlertsRequest, Alert]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TermTrack_StreamAlertsClient = grpc.ServerStreamingClient[Alert]
+
+func (c *termTrackClient) GetAircraft(ctx context.Context, in *GetAircraftRequest, opts ...grpc.CallOption) (*Aircraft, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Aircraft)
+	err := c.cc.Invoke(ctx, TermTrack_GetAircraft_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TermTrackServer is the server API for TermTrack service.
+// All implementations must embed UnimplementedTermTrackServer
+// for forward compatibility.
+type TermTrackServer interface {
+	StreamAircraft(*StreamAircraftRequest, grpc.ServerStreamingServer[Snapshot]) error
+	StreamAlerts(*StreamAlertsRequest, grpc.ServerStreamingServer[Alert]) error
+	GetAircraft(context.Context, *GetAircraftRequest) (*Aircraft, error)
+	mustEmbedUnimplementedTermTrackServer()
+}
+
+// UnimplementedTermTrackServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTermTrackServer struct{}
+
+func (UnimplementedTermTrackServer) StreamAircraft(*StreamAircraftRequest, grpc.ServerStreamingServer[Snapshot]) error {
+	return status.Error(codes.Unimplemented, "method StreamAircraft not implemented")
+}
+func (UnimplementedTermTrackServer) StreamAlerts(*StreamAlertsRequest, grpc.ServerStreamingServer[Alert]) error {
+	return status.Error(codes.Unimplemented, "method StreamAlerts not implemented")
+}
+func (UnimplementedTermTrackServer) GetAircraft(context.Context, *GetAircraftRequest) (*Aircraft, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAircraft not implemented")
+}
+func (UnimplementedTermTrackServer) mustEmbedUnimplementedTermTrackServer() {}
+func (UnimplementedTermTrackServer) testEmbeddedByValue()                   {}
+
+// UnsafeTermTrackServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TermTrackServer will
+// result in compilation errors.
+type UnsafeTermTrackServer interface {
+	mustEmbedUnimplementedTermTrackServer()
+}
+
+func RegisterTermTrackServer(s grpc.ServiceRegistrar, srv TermTrackServer) {
+	// If the following call panics, it indicates UnimplementedTermTrackServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TermTrack_ServiceDesc, srv)
+}
+
+func _TermTrack_StreamAircraft_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamAircraftRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TermTrackServer).StreamAircraft(m, &grpc.GenericServerStream[StreamAircraftRequest, Snapshot]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TermTrack_StreamAircraftServer = grpc.ServerStreamingServer[Snapshot]
+
+func _TermTrack_StreamAlerts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamAlertsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TermTrackServer).StreamAlerts(m, &grpc.GenericServerStream[StreamAlertsRequest, Alert]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type TermTrack_StreamAlertsServer = grpc.ServerStreamingServer[Alert]
+
+func _TermTrack_GetAircraft_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAircraftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TermTrackServer).GetAircraft(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TermTrack_GetAircraft_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TermTrackServer).GetAircraft(ctx, req.(*GetAircraftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TermTrack_ServiceDesc is the grpc.ServiceDesc for TermTrack service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TermTrack_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "termtrack.v1.TermTrack",
+	HandlerType: (*TermTrackServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAircraft",
+			Handler:    _TermTrack_GetAircraft_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamAircraft",
+			Handler:       _TermTrack_StreamAircraft_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamAlerts",
+			Handler:       _TermTrack_StreamAlerts_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "termtrack.proto",
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"termtrack/sbs"
+	mapview "termtrack/ui/map"
+)
+
+// castMsg says where a cast was saved, or why it couldn't be
+type castMsg struct {
+	path string
+	err  error
+	at   time.Time
+}
+
+// saveCastCmd writes an asciinema cast of ac flying its trail into dir,
+// named for the aircraft and the time. The map is a copy, so it's drawn
+// in the background without touching the live one.
+func saveCastCmd(mapModel mapview.Model, ac *sbs.Aircraft, dir string) tea.Cmd {
+	return func() tea.Msg {
+		name := strings.Map(func(r rune) rune {
+			if r == '/' || r == os.PathSeparator {
+				return '_'
+			}
+			return r
+		}, cmp.Or(ac.Callsign, ac.ICAO))
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.cast", name, time.Now().Format("20060102-150405")))
+
+		f, err := os.Create(path)
+		if err != nil {
+			return castMsg{err: err, at: time.Now()}
+		}
+		err = mapModel.WriteCast(f, ac)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(path)
+			return castMsg{err: err, at: time.Now()}
+		}
+		return castMsg{path: path, at: time.Now()}
+	}
+}
@@ -0,0 +1,64 @@
+// Command antennareport reads a TermTrack position log (made with
+// -log-db) and prints a report for tuning the antenna: range by bearing,
+// positions by hour, range by altitude, and gaps in reception.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"termtrack/flightlog"
+)
+
+func main() {
+	dbPath := flag.String("log-db", "", "TermTrack position log to read (required)")
+	receiver := flag.String("receiver", "", "receiver location as `lat,lon`, where ranges are measured from (required)")
+	since := flag.Duration("since", 0, "only look at this much of the log, up to now, e.g. 168h for the last week (0 for all of it)")
+	gap := flag.Duration("gap", flightlog.DefaultReportGap, "shortest time with nothing logged to list as a gap")
+	flag.Parse()
+
+	if *dbPath == "" || *receiver == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	lat, lon, err := parseLocation(*receiver)
+	if err != nil {
+		log.Fatalf("-receiver: %v", err)
+	}
+
+	db, err := flightlog.OpenReadOnly(*dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	opts := flightlog.ReportOptions{Lat: lat, Lon: lon, Gap: *gap}
+	if *since > 0 {
+		opts.Since = time.Now().Add(-*since)
+	}
+	if err := flightlog.WriteReport(os.Stdout, db, opts); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseLocation reads a "lat,lon" pair
+func parseLocation(s string) (lat, lon float64, err error) {
+	latStr, lonStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("want lat,lon, got %q", s)
+	}
+	lat, err = strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return 0, 0, fmt.Errorf("bad latitude %q", latStr)
+	}
+	lon, err = strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return 0, 0, fmt.Errorf("bad longitude %q", lonStr)
+	}
+	return lat, lon, nil
+}
@@ -0,0 +1,125 @@
+// Command sbsexport reads a TermTrack position log (made with -log-db)
+// and rebuilds the SBS stream for a stretch of it, to keep as a file or
+// to serve at its original pace, for TermTrack (pointed at it with
+// -feed) or anything else that reads SBS.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"termtrack/flightlog"
+	"termtrack/testfeed"
+)
+
+// timeLayouts are the ways -from and -to can be written, in local time
+// unless they say otherwise
+var timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+func main() {
+	dbPath := flag.String("log-db", "", "TermTrack position log to read (required)")
+	fromStr := flag.String("from", "", "start of the stretch to export, like 2024-05-01 14:00 (local time) or RFC 3339")
+	toStr := flag.String("to", "", "end of the stretch to export, the same way (empty for the end of the log)")
+	since := flag.Duration("since", 0, "instead of -from, export this much of the log up to now, e.g. 2h")
+	out := flag.String("o", "-", "file to write the SBS lines to, - for standard output")
+	serve := flag.String("serve", "", "instead of writing them out, serve the lines on this address, e.g. localhost:30013, spaced as they were heard")
+	loop := flag.Bool("loop", false, "with -serve, start over at the end instead of hanging up")
+	flag.Parse()
+
+	if *dbPath == "" || (*fromStr == "" && *since == 0) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var from, to time.Time
+	var err error
+	if *since > 0 {
+		from = time.Now().Add(-*since)
+	} else if from, err = parseTime(*fromStr); err != nil {
+		log.Fatalf("-from: %v", err)
+	}
+	if *toStr != "" {
+		if to, err = parseTime(*toStr); err != nil {
+			log.Fatalf("-to: %v", err)
+		}
+	}
+
+	db, err := flightlog.OpenReadOnly(*dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	if *serve != "" {
+		if err := serveLines(*serve, *loop, func(line func(time.Time, string) error) error {
+			return flightlog.ExportSBS(db, from, to, line)
+		}); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	err = flightlog.ExportSBS(db, from, to, func(_ time.Time, line string) error {
+		_, err := bw.WriteString(line + "\r\n")
+		return err
+	})
+	if err == nil {
+		err = bw.Flush()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// serveLines serves the exported lines on addr, each after the gap there
+// was before it was heard
+func serveLines(addr string, loop bool, export func(func(time.Time, string) error) error) error {
+	sc := testfeed.Scenario{Name: "export"}
+	var last time.Time
+	err := export(func(at time.Time, line string) error {
+		var delay time.Duration
+		if !last.IsZero() {
+			delay = at.Sub(last)
+		}
+		last = at
+		sc.Steps = append(sc.Steps, testfeed.Step{Delay: delay, Line: line})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(sc.Steps) == 0 {
+		return fmt.Errorf("nothing logged in that stretch")
+	}
+
+	srv, err := testfeed.Listen(addr, sc, loop)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	log.Printf("serving %d lines on %s", len(sc.Steps), srv.Addr())
+	return srv.Serve()
+}
+
+// parseTime reads a time in any of timeLayouts
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("can't read %q as a time, like 2006-01-02 15:04", s)
+}
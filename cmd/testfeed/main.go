@@ -0,0 +1,43 @@
+// Command testfeed serves a scripted SBS scenario over TCP, for demos and
+// for exercising TermTrack without a receiver.
+package main
+
+import (
+	"flag"
+	"log"
+	"sort"
+	"strings"
+
+	"termtrack/testfeed"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:30003", "address to listen on")
+	name := flag.String("scenario", "takeoff", "scenario to play: "+strings.Join(names(), ", "))
+	loop := flag.Bool("loop", true, "repeat the scenario until the client disconnects")
+	flag.Parse()
+
+	build, ok := testfeed.Scenarios[*name]
+	if !ok {
+		log.Fatalf("unknown scenario %q (have %s)", *name, strings.Join(names(), ", "))
+	}
+
+	srv, err := testfeed.Listen(*addr, build(), *loop)
+	if err != nil {
+		log.Fatalf("listen: %v", err)
+	}
+	log.Printf("serving %q on %s", *name, srv.Addr())
+	if err := srv.Serve(); err != nil {
+		log.Fatalf("serve: %v", err)
+	}
+}
+
+// names returns the scenario names, sorted
+func names() []string {
+	var out []string
+	for name := range testfeed.Scenarios {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"termtrack/dbupdate"
+	"termtrack/export"
+	"termtrack/geofence"
+	"termtrack/rules"
+	"termtrack/sbs"
+	"termtrack/sequence"
+	"termtrack/ui/detail"
+	mapview "termtrack/ui/map"
+)
+
+// fileConfig is what can go in the config file. Everything is optional.
+//
+//	{
+//	  "feed": "pi.local:30003",
+//	  "receiver": "40.7769,-73.8740",
+//	  "map": "mapdata/ne_10m_admin_1_states_provinces.shp",
+//	  "airports": "airportdata/ne_10m_airports.shp",
+//	  "layers": {
+//	    "basemap":     {"glyph": "·", "color": "240", "step": 2},
+//	    "airports":    {"color": "#ffcc00"},
+//	    "trails":      {"color": "118"},
+//	    "graticule":   {"glyph": "·", "step": 4},
+//	    "range_rings": {"color": "244"},
+//	    "order":       ["basemap", "trails", "airports"],
+//	    "hidden":      ["approaches"]
+//	  },
+//	  "categories": {"helicopter": "H", "fighter": "▲", "B2": "b"},
+//	  "approaches": [
+//	    {"name": "JFK 31L", "lat": 40.6235, "lon": -73.7620, "heading": 310}
+//	  ],
+//	  "eta": {"name": "JFK", "lat": 40.6398, "lon": -73.7789},
+//	  "exports": [
+//	    {"dir": "/tmp/termtrack", "format": "csv", "interval": 10, "filter": {"max_altitude": 10000}}
+//	  ],
+//	  "timeouts": {"adsb": 60, "mlat": 180, "uat": 60},
+//	  "geofences": [
+//	    {"name": "31L final", "points": [[40.62, -73.76], [40.58, -73.70], [40.57, -73.72], [40.61, -73.78]], "max_altitude": 4000},
+//	    {"name": "Practice area", "circle": {"lat": 40.85, "lon": -73.10, "nm": 5}}
+//	  ],
+//	  "rules": [
+//	    {"name": "Low and close", "not_area": "31L final", "when": [
+//	      {"field": "altitude", "op": "<", "value": 1500},
+//	      {"field": "distance", "op": "<", "value": 5}
+//	    ]}
+//	  ],
+//	  "databases": [
+//	    {"name": "aircraft", "url": "https://example.org/aircraft.csv.gz", "path": "data/aircraft.csv.gz", "interval": 86400, "lookup": "aircraft"}
+//	  ],
+//	  "feeds": [
+//	    {"name": "north", "address": "pi-north:30003"},
+//	    {"name": "mlat", "address": "pi-north:30105", "source": "mlat"}
+//	  ]
+//	}
+//
+// Flags and TERMTRACK_* variables win over the file.
+type fileConfig struct {
+	// The receiver to connect to, if not the usual localhost:30003:
+	// anything -feed takes, with its host or port swapped for sbs_host
+	// and sbs_port if they're set
+	Feed    string `json:"feed,omitempty"`
+	SBSHost string `json:"sbs_host,omitempty"`
+	SBSPort int    `json:"sbs_port,omitempty"`
+
+	// Where the receiver is, as "lat,lon" like -receiver
+	Receiver string `json:"receiver,omitempty"`
+
+	Map      string         `json:"map,omitempty"`
+	Airports string         `json:"airports,omitempty"` // Re-read when retrying the airports with A
+	Layers   mapview.Layers `json:"layers,omitzero"`
+
+	// Plane glyphs by ADS-B emitter category, for sources that report it
+	Categories mapview.CategoryGlyphs `json:"categories,omitempty"`
+
+	// Runways to draw extended centerlines off, and to sequence arrivals
+	// onto (S)
+	Approaches []mapview.Approach `json:"approaches,omitempty"`
+
+	// Where the detail panel gives the selected aircraft's ETA to
+	ETA *detail.Target `json:"eta,omitempty"`
+
+	// Files to write the aircraft table to, every so often
+	Exports []export.Job `json:"exports,omitempty"`
+
+	// Seconds an aircraft can go unheard before it's lost, by source
+	Timeouts sbs.Timeouts `json:"timeouts,omitempty"`
+
+	// Areas to keep count of the aircraft in, shown in the g panel
+	Geofences []geofence.Fence `json:"geofences,omitempty"`
+
+	// Alerts to raise when an aircraft meets every condition of one
+	Rules []rules.Rule `json:"rules,omitempty"`
+
+	// Lookup databases to keep fresh from upstream
+	Databases []dbupdate.Database `json:"databases,omitempty"`
+
+	// More receivers to read alongside -feed, merged into one picture
+	Feeds []sbs.Endpoint `json:"feeds,omitempty"`
+}
+
+// defaultConfigPath is where we look for a config file when -config isn't
+// given: termtrack/config.json under the user's config directory
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "termtrack", "config.json")
+}
+
+// defaultSightingsPath is where notable sightings are kept: next to the
+// default config file
+func defaultSightingsPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "termtrack", "sightings.json")
+}
+
+// defaultMacrosPath is where recorded macros are kept: next to the default
+// config file
+func defaultMacrosPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "termtrack", "macros.json")
+}
+
+// loadConfig reads the config file at path. A missing file is only an
+// error if it was asked for by name.
+func loadConfig(path string, required bool) (fileConfig, error) {
+	var cfg fileConfig
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && !required {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, fmt.Errorf("config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("config %s: %w", path, err)
+	}
+	if cfg.Receiver != "" {
+		var l location
+		if err := l.Set(cfg.Receiver); err != nil {
+			return cfg, fmt.Errorf("config %s: receiver: %w", path, err)
+		}
+	}
+	if cfg.SBSPort < 0 || cfg.SBSPort > 65535 {
+		return cfg, fmt.Errorf("config %s: sbs_port: %d isn't a port", path, cfg.SBSPort)
+	}
+	if err := cfg.Layers.Validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: layers: %w", path, err)
+	}
+	if err := cfg.Categories.Validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: categories: %w", path, err)
+	}
+	if cfg.ETA != nil {
+		if err := cfg.ETA.Validate(); err != nil {
+			return cfg, fmt.Errorf("config %s: eta: %w", path, err)
+		}
+	}
+	for i, a := range cfg.Approaches {
+		if err := a.Validate(); err != nil {
+			return cfg, fmt.Errorf("config %s: approaches[%d]: %w", path, i, err)
+		}
+	}
+	for i, j := range cfg.Exports {
+		if err := j.Validate(); err != nil {
+			return cfg, fmt.Errorf("config %s: exports[%d]: %w", path, i, err)
+		}
+	}
+	if err := cfg.Timeouts.Validate(); err != nil {
+		return cfg, fmt.Errorf("config %s: timeouts: %w", path, err)
+	}
+	for i, f := range cfg.Geofences {
+		if err := f.Validate(); err != nil {
+			return cfg, fmt.Errorf("config %s: geofences[%d]: %w", path, i, err)
+		}
+	}
+	for i, r := range cfg.Rules {
+		if err := r.Validate(cfg.Geofences); err != nil {
+			return cfg, fmt.Errorf("config %s: rules[%d]: %w", path, i, err)
+		}
+	}
+	for i, d := range cfg.Databases {
+		if err := d.Validate(); err != nil {
+			return cfg, fmt.Errorf("config %s: databases[%d]: %w", path, i, err)
+		}
+	}
+	names := make(map[string]bool)
+	for i, e := range cfg.Feeds {
+		if err := e.Validate(); err != nil {
+			return cfg, fmt.Errorf("config %s: feeds[%d]: %w", path, i, err)
+		}
+		// Aircraft are put down to feeds by name
+		if names[e.Name] {
+			return cfg, fmt.Errorf("config %s: feeds[%d]: %s: name used twice", path, i, e.Name)
+		}
+		names[e.Name] = true
+	}
+	return cfg, nil
+}
+
+// runways are the config's approaches, for sequencing arrivals onto
+func runways(approaches []mapview.Approach) []sequence.Runway {
+	out := make([]sequence.Runway, len(approaches))
+	for i, a := range approaches {
+		out[i] = sequence.Runway{Name: a.Name, Lat: a.Lat, Lon: a.Lon, Heading: a.Heading}
+	}
+	return out
+}
@@ -0,0 +1,167 @@
+// Package control listens on a local UDP or unix datagram socket for
+// simple text commands, so stream decks, hotkey daemons and shell scripts
+// can drive the running TUI:
+//
+//	echo "select A1B2C3" | nc -u -w0 localhost 30007
+//	echo "toggle trails" | socat - UNIX-SENDTO:/tmp/termtrack.sock
+//
+// Each datagram is one command (or several, a line each). The sender gets
+// "ok" or "error: ..." back for each.
+package control
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultAddress is a suggested address to listen on
+const DefaultAddress = "udp:localhost:30007"
+
+// maxDatagram is the longest command datagram we read
+const maxDatagram = 1024
+
+// Command is one parsed command, like "zoom 25" or "toggle trails"
+type Command struct {
+	Name string
+	Args []string
+}
+
+// CommandMsg carries a command to the TUI
+type CommandMsg struct {
+	Command
+}
+
+// commands is what we accept, with how many arguments each takes
+var commands = map[string]struct{ min, max int }{
+	"select": {1, 1}, // An ICAO, "next", "prev" or "none"
+	"zoom":   {1, 1}, // A zoom level, "in" or "out"
+	"center": {1, 1}, // lat,lon
+	"toggle": {1, 1}, // A layer or panel, like "trails" or "weather"
+	"key":    {1, 1}, // A single key, as if pressed
+}
+
+// Parse reads one command line. Names are case-insensitive.
+func Parse(line string) (Command, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Command{}, errors.New("empty command")
+	}
+	c := Command{Name: strings.ToLower(fields[0]), Args: fields[1:]}
+	n, ok := commands[c.Name]
+	if !ok {
+		return c, fmt.Errorf("unknown command %q", fields[0])
+	}
+	if len(c.Args) < n.min || len(c.Args) > n.max {
+		return c, fmt.Errorf("%s takes %d argument(s)", c.Name, n.max)
+	}
+	if c.Name == "zoom" && c.Args[0] != "in" && c.Args[0] != "out" {
+		if z, err := strconv.ParseFloat(c.Args[0], 64); err != nil || z <= 0 {
+			return c, fmt.Errorf("bad zoom level %q", c.Args[0])
+		}
+	}
+	return c, nil
+}
+
+// Listener reads commands off a socket
+type Listener struct {
+	conn     net.PacketConn
+	path     string // The unix socket to remove when we're done, if any
+	commands chan Command
+}
+
+// Listen opens addr, which is "udp:host:port" or "unix:/path/to/socket".
+// A bare host:port means UDP.
+func Listen(addr string) (*Listener, error) {
+	network, where, ok := strings.Cut(addr, ":")
+	if !ok || (network != "udp" && network != "unix") {
+		network, where = "udp", addr
+	}
+
+	l := &Listener{commands: make(chan Command)}
+	var err error
+	if network == "unix" {
+		// A socket left behind by a crash would stop us binding
+		if fi, statErr := os.Stat(where); statErr == nil && fi.Mode()&os.ModeSocket != 0 {
+			os.Remove(where)
+		}
+		l.conn, err = net.ListenPacket("unixgram", where)
+		l.path = where
+	} else {
+		l.conn, err = net.ListenPacket("udp", where)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("control listen: %w", err)
+	}
+
+	go l.run()
+	return l, nil
+}
+
+// Addr is where we're listening
+func (l *Listener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
+
+// Close stops listening and removes the unix socket
+func (l *Listener) Close() error {
+	err := l.conn.Close()
+	if l.path != "" {
+		os.Remove(l.path)
+	}
+	return err
+}
+
+// run reads datagrams until the socket is closed, answering each line
+func (l *Listener) run() {
+	defer close(l.commands)
+	buf := make([]byte, maxDatagram)
+	for {
+		n, from, err := l.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		var replies []string
+		for _, line := range strings.Split(string(buf[:n]), "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			c, err := Parse(line)
+			if err != nil {
+				replies = append(replies, "error: "+err.Error())
+				continue
+			}
+			l.commands <- c
+			replies = append(replies, "ok")
+		}
+		if canReply(from) && len(replies) > 0 {
+			l.conn.WriteTo([]byte(strings.Join(replies, "\n")+"\n"), from)
+		}
+	}
+}
+
+// canReply reports whether a sender has an address to answer to, which
+// unix senders that didn't bind one don't
+func canReply(from net.Addr) bool {
+	if ua, ok := from.(*net.UnixAddr); ok {
+		return ua != nil && ua.Name != ""
+	}
+	return from != nil
+}
+
+// WaitCmd waits for the next command. Ask again after each CommandMsg;
+// once the listener is closed it returns nil.
+func (l *Listener) WaitCmd() tea.Cmd {
+	return func() tea.Msg {
+		c, ok := <-l.commands
+		if !ok {
+			return nil
+		}
+		return CommandMsg{c}
+	}
+}
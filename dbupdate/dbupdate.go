@@ -0,0 +1,249 @@
+// Package dbupdate keeps downloaded lookup databases (aircraft types,
+// routes, lists of interesting aircraft) fresh, fetching each from its
+// upstream URL on a schedule. An ETag kept next to each file means an
+// unchanged database isn't downloaded again, and when the network's down
+// the copy already on disk carries on being used.
+package dbupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minInterval keeps a config from hammering anyone's server
+const minInterval = time.Hour
+
+// DefaultInterval is how often a database is checked if the config
+// doesn't say
+const DefaultInterval = 24 * time.Hour
+
+// retryAfter is how soon a failed fetch is tried again, if that's sooner
+// than the database's interval
+const retryAfter = 15 * time.Minute
+
+// fetchTimeout is as long as one download gets
+const fetchTimeout = 5 * time.Minute
+
+// Database is one file to keep up to date, from the config file:
+//
+//	{"name": "types", "url": "https://example.org/types.csv", "path": "data/types.csv", "interval": 86400}
+type Database struct {
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Path     string `json:"path"`
+	Interval int    `json:"interval,omitempty"` // Seconds between checks, DefaultInterval if 0
+
+	// What TermTrack reads it for: "aircraft" for registrations and types
+	// by ICAO address (see aircraftdb), or empty for one only kept fresh
+	// for something else
+	Lookup string `json:"lookup,omitempty"`
+}
+
+// LookupAircraft marks a database of registrations and types
+const LookupAircraft = "aircraft"
+
+// Validate checks the database can be fetched
+func (d Database) Validate() error {
+	if d.Name == "" {
+		return errors.New("no name")
+	}
+	if !strings.HasPrefix(d.URL, "http://") && !strings.HasPrefix(d.URL, "https://") {
+		return fmt.Errorf("%s: url %q isn't http or https", d.Name, d.URL)
+	}
+	if d.Path == "" {
+		return fmt.Errorf("%s: no path", d.Name)
+	}
+	if d.Interval != 0 && time.Duration(d.Interval)*time.Second < minInterval {
+		return fmt.Errorf("%s: interval %d is under %s", d.Name, d.Interval, minInterval)
+	}
+	if d.Lookup != "" && d.Lookup != LookupAircraft {
+		return fmt.Errorf("%s: unknown lookup %q (%s, or none)", d.Name, d.Lookup, LookupAircraft)
+	}
+	return nil
+}
+
+// interval is how often the database is checked
+func (d Database) interval() time.Duration {
+	if d.Interval == 0 {
+		return DefaultInterval
+	}
+	return time.Duration(d.Interval) * time.Second
+}
+
+// etagPath is where the ETag of the copy on disk is kept
+func (d Database) etagPath() string {
+	return d.Path + ".etag"
+}
+
+// Updater refreshes the databases in the background
+type Updater struct {
+	done    chan struct{}
+	wg      sync.WaitGroup
+	fetched func(Database) // Called when a new copy's in place
+
+	mu   sync.Mutex
+	errs map[string]error // By name, from each database's last fetch
+}
+
+// Start begins keeping the databases up to date. fetched, if it isn't
+// nil, is called from the updater's goroutines whenever a new copy of a
+// database has replaced the old one, to read it again.
+func Start(dbs []Database, fetched func(Database)) *Updater {
+	u := &Updater{done: make(chan struct{}), fetched: fetched, errs: make(map[string]error)}
+	for _, d := range dbs {
+		u.wg.Add(1)
+		go u.run(d)
+	}
+	return u
+}
+
+// Close stops the updates, abandoning any download in progress
+func (u *Updater) Close() {
+	close(u.done)
+	u.wg.Wait()
+}
+
+// Err is why a database's last fetch failed, or nil if they all worked.
+// The copies already on disk are still there to be used.
+func (u *Updater) Err() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.errs) == 0 {
+		return nil
+	}
+	var msgs []string
+	for _, name := range slices.Sorted(maps.Keys(u.errs)) {
+		msgs = append(msgs, u.errs[name].Error())
+	}
+	return errors.New(strings.Join(msgs, "; ")) // One line, for the header
+}
+
+// run fetches the database whenever it's due, starting with right away if
+// the copy on disk is missing or older than the interval
+func (u *Updater) run(d Database) {
+	defer u.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-u.done
+		cancel()
+	}()
+
+	wait := time.Duration(0)
+	if info, err := os.Stat(d.Path); err == nil {
+		wait = max(d.interval()-time.Since(info.ModTime()), 0)
+	}
+	for {
+		select {
+		case <-u.done:
+			return
+		case <-time.After(wait):
+		}
+
+		replaced, err := fetch(ctx, d)
+		if ctx.Err() != nil {
+			return // Closed mid-download; that's not a failure
+		}
+		if replaced && u.fetched != nil {
+			u.fetched(d)
+		}
+		u.mu.Lock()
+		if err != nil {
+			u.errs[d.Name] = err
+		} else {
+			delete(u.errs, d.Name)
+		}
+		u.mu.Unlock()
+
+		wait = d.interval()
+		if err != nil {
+			wait = min(wait, retryAfter)
+		}
+	}
+}
+
+// fetch downloads the database if it's changed upstream since the copy on
+// disk, replacing the copy only once the new one is all there. It reports
+// whether it did.
+func fetch(ctx context.Context, d Database) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.URL, nil)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", d.Name, err)
+	}
+	if _, err := os.Stat(d.Path); err == nil {
+		// Only ask for it as-is if we still have what the ETag is for
+		if etag, err := os.ReadFile(d.etagPath()); err == nil {
+			req.Header.Set("If-None-Match", strings.TrimSpace(string(etag)))
+		}
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", d.Name, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		// Still current; mark it checked, so a restart doesn't fetch it
+		// again straight away
+		now := time.Now()
+		if err := os.Chtimes(d.Path, now, now); err != nil {
+			return false, fmt.Errorf("%s: %w", d.Name, err)
+		}
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("%s: %s", d.Name, resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.Path), 0o755); err != nil {
+		return false, fmt.Errorf("%s: %w", d.Name, err)
+	}
+	// Write then rename, so a dropped connection can't leave half a file
+	tmp := d.Path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", d.Name, err)
+	}
+	_, err = io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, d.Path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return false, fmt.Errorf("%s: %w", d.Name, err)
+	}
+
+	// No ETag this time means none to send next time either
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		err = os.WriteFile(d.etagPath(), []byte(etag+"\n"), 0o644)
+	} else {
+		err = os.Remove(d.etagPath())
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return true, fmt.Errorf("%s: %w", d.Name, err)
+	}
+	return true, nil
+}
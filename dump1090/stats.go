@@ -0,0 +1,106 @@
+package dump1090
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Stats is the subset of dump1090-fa/readsb stats.json we display, taken
+// from the "last1min" period
+type Stats struct {
+	Messages      int     // Messages decoded
+	Signal        float64 // Mean signal level, dBFS
+	Noise         float64 // Noise floor, dBFS
+	PeakSignal    float64 // Strongest signal, dBFS
+	StrongSignals int     // Messages above -3dBFS (too strong, gain may be high)
+	Tracks        int     // Aircraft tracks seen
+	SingleTracks  int     // Tracks with only a single message
+	CPU           float64 // Decoder CPU use, percent of one core
+	Fetched       time.Time
+}
+
+// rawStats mirrors the parts of stats.json we read
+type rawStats struct {
+	Last1Min struct {
+		Start    float64 `json:"start"`
+		End      float64 `json:"end"`
+		Messages int     `json:"messages"`
+		Local    struct {
+			Signal        float64 `json:"signal"`
+			Noise         float64 `json:"noise"`
+			PeakSignal    float64 `json:"peak_signal"`
+			StrongSignals int     `json:"strong_signals"`
+		} `json:"local"`
+		CPU struct {
+			Demod      float64 `json:"demod"`
+			Reader     float64 `json:"reader"`
+			Background float64 `json:"background"`
+		} `json:"cpu"`
+		Tracks struct {
+			All           int `json:"all"`
+			SingleMessage int `json:"single_message"`
+		} `json:"tracks"`
+	} `json:"last1min"`
+}
+
+// FetchStats downloads and decodes stats.json from url
+func FetchStats(ctx context.Context, url string) (*Stats, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("stats request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("stats fetch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("stats fetch: %s", resp.Status)
+	}
+
+	var raw rawStats
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("stats decode: %w", err)
+	}
+
+	p := raw.Last1Min
+	s := &Stats{
+		Messages:      p.Messages,
+		Signal:        p.Local.Signal,
+		Noise:         p.Local.Noise,
+		PeakSignal:    p.Local.PeakSignal,
+		StrongSignals: p.Local.StrongSignals,
+		Tracks:        p.Tracks.All,
+		SingleTracks:  p.Tracks.SingleMessage,
+		Fetched:       time.Now(),
+	}
+	// CPU figures are milliseconds spent over the period
+	if period := p.End - p.Start; period > 0 {
+		s.CPU = (p.CPU.Demod + p.CPU.Reader + p.CPU.Background) / (period * 1000) * 100
+	}
+	return s, nil
+}
+
+// StatsMsg carries the result of a stats.json poll
+type StatsMsg struct {
+	Stats *Stats
+	Err   error
+}
+
+// PollStatsCmd fetches stats.json after delay. Send it again on each
+// StatsMsg to keep polling.
+func PollStatsCmd(url string, delay time.Duration) tea.Cmd {
+	return tea.Tick(delay, func(time.Time) tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		stats, err := FetchStats(ctx, url)
+		return StatsMsg{Stats: stats, Err: err}
+	})
+}
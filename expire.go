@@ -0,0 +1,36 @@
+package main
+
+import (
+	"time"
+
+	"termtrack/sbs"
+)
+
+// expireEvery is how often the store is swept for aircraft to drop
+const expireEvery = time.Second
+
+// expireAircraft drops the aircraft that have been stale for grace past
+// their source's timeout, every expireEvery. It runs on its own goroutine
+// rather than from the TUI's tick, so that headless the APIs don't go on
+// serving aircraft long gone. Nothing goes while the feed's paused or a
+// replay's standing still, when the quiet's just them being held. Call
+// the returned func to stop it.
+func expireAircraft(store *sbs.Store, feed *sbs.Feed, replay *sbs.Player, t sbs.Timeouts, grace time.Duration) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(expireEvery)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if paused, _ := feed.Paused(); paused || replayHeld(replay) {
+					continue
+				}
+				store.Expire(replayTime(replay), t, grace)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
@@ -0,0 +1,250 @@
+// Package export writes the aircraft table to files on a schedule, as JSON
+// or CSV, for scripts that would rather read a file than talk to a socket.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"termtrack/geo"
+	"termtrack/sbs"
+)
+
+// minInterval stops a config from writing files faster than anyone could
+// read them
+const minInterval = time.Second
+
+// Job is one scheduled export, from the config file:
+//
+//	{"dir": "/tmp/termtrack", "format": "csv", "interval": 10,
+//	 "filter": {"near": {"lat": 40.64, "lon": -73.78, "nm": 25}, "max_altitude": 10000}}
+type Job struct {
+	Dir         string `json:"dir"`
+	Format      string `json:"format"`                // "json" or "csv"
+	Interval    int    `json:"interval"`              // Seconds between exports
+	Timestamped bool   `json:"timestamped,omitempty"` // A new file each time rather than overwriting one
+	Filter      Filter `json:"filter"`
+}
+
+// Filter picks which aircraft go in an export. Empty fields don't filter.
+type Filter struct {
+	Callsign    string `json:"callsign,omitempty"` // Glob, like "DAL*"
+	Near        *Area  `json:"near,omitempty"`
+	MinAltitude int    `json:"min_altitude,omitempty"` // Feet; aircraft without an altitude are left out
+	MaxAltitude int    `json:"max_altitude,omitempty"`
+	MaxAge      int    `json:"max_age,omitempty"` // Seconds since last heard
+}
+
+// Area is a circle around a point
+type Area struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+	NM  float64 `json:"nm"`
+}
+
+// Validate checks a job can run
+func (j Job) Validate() error {
+	if j.Dir == "" {
+		return errors.New("no dir")
+	}
+	if j.Format != "json" && j.Format != "csv" {
+		return fmt.Errorf("format %q isn't json or csv", j.Format)
+	}
+	if time.Duration(j.Interval)*time.Second < minInterval {
+		return fmt.Errorf("interval %d is under %s", j.Interval, minInterval)
+	}
+	if _, err := path.Match(j.Filter.Callsign, ""); err != nil {
+		return fmt.Errorf("callsign %q: %w", j.Filter.Callsign, err)
+	}
+	if a := j.Filter.Near; a != nil {
+		if a.Lat < -90 || a.Lat > 90 || a.Lon < -180 || a.Lon > 180 {
+			return fmt.Errorf("near %g,%g isn't a lat,lon", a.Lat, a.Lon)
+		}
+		if a.NM <= 0 {
+			return fmt.Errorf("near radius %g isn't positive", a.NM)
+		}
+	}
+	return nil
+}
+
+// match reports whether an aircraft passes the filter
+func (f Filter) match(ac *sbs.Aircraft, now time.Time) bool {
+	if f.Callsign != "" {
+		if ok, _ := path.Match(f.Callsign, ac.Callsign); !ok {
+			return false
+		}
+	}
+	if f.Near != nil {
+		if ac.Lat == 0 && ac.Lon == 0 {
+			return false
+		}
+		if geo.Distance(f.Near.Lat, f.Near.Lon, ac.Lat, ac.Lon) > f.Near.NM {
+			return false
+		}
+	}
+	if (f.MinAltitude != 0 || f.MaxAltitude != 0) && ac.Altitude == 0 {
+		return false
+	}
+	if f.MinAltitude != 0 && ac.Altitude < f.MinAltitude {
+		return false
+	}
+	if f.MaxAltitude != 0 && ac.Altitude > f.MaxAltitude {
+		return false
+	}
+	if f.MaxAge != 0 && now.Sub(ac.LastSeen) > time.Duration(f.MaxAge)*time.Second {
+		return false
+	}
+	return true
+}
+
+// Exporter runs the jobs in the background
+type Exporter struct {
+	store *sbs.Store
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	mu  sync.Mutex
+	err error // From the last export that failed, cleared by one that works
+}
+
+// Start runs each job on its own schedule until Close
+func Start(store *sbs.Store, jobs []Job) *Exporter {
+	e := &Exporter{store: store, done: make(chan struct{})}
+	for _, j := range jobs {
+		e.wg.Add(1)
+		go e.run(j)
+	}
+	return e
+}
+
+// Close stops the jobs, letting any export in progress finish
+func (e *Exporter) Close() {
+	close(e.done)
+	e.wg.Wait()
+}
+
+// Err is why the last export failed, or nil if it worked
+func (e *Exporter) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err
+}
+
+// run exports on the job's interval
+func (e *Exporter) run(j Job) {
+	defer e.wg.Done()
+	ticker := time.NewTicker(time.Duration(j.Interval) * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.done:
+			return
+		case now := <-ticker.C:
+			err := write(j, e.store.Snapshot(), now)
+			e.mu.Lock()
+			e.err = err
+			e.mu.Unlock()
+		}
+	}
+}
+
+// row is one aircraft as exported
+type row struct {
+	ICAO     string    `json:"icao"`
+	Callsign string    `json:"callsign,omitempty"`
+	Lat      float64   `json:"lat,omitempty"`
+	Lon      float64   `json:"lon,omitempty"`
+	Altitude int       `json:"altitude_ft,omitempty"`
+	Speed    float64   `json:"speed_kt,omitempty"`
+	Track    float64   `json:"track,omitempty"`
+	Category string    `json:"category,omitempty"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// csvHeader names the CSV columns, in row's order
+var csvHeader = []string{"icao", "callsign", "lat", "lon", "altitude_ft", "speed_kt", "track", "category", "last_seen"}
+
+// write exports the matching aircraft, ordered by ICAO. The file is written
+// alongside and renamed into place, so readers never see half of one.
+func write(j Job, all map[string]*sbs.Aircraft, now time.Time) error {
+	var rows []row
+	for _, ac := range all {
+		if !j.Filter.match(ac, now) {
+			continue
+		}
+		rows = append(rows, row{
+			ICAO:     ac.ICAO,
+			Callsign: ac.Callsign,
+			Lat:      ac.Lat,
+			Lon:      ac.Lon,
+			Altitude: ac.Altitude,
+			Speed:    ac.Speed,
+			Track:    ac.Track,
+			Category: ac.Category,
+			LastSeen: ac.LastSeen,
+		})
+	}
+	sort.Slice(rows, func(a, b int) bool { return rows[a].ICAO < rows[b].ICAO })
+
+	var data strings.Builder
+	switch j.Format {
+	case "json":
+		out := struct {
+			Time     time.Time `json:"time"`
+			Aircraft []row     `json:"aircraft"`
+		}{now, rows}
+		if out.Aircraft == nil {
+			out.Aircraft = []row{} // [] rather than null for the scripts
+		}
+		enc := json.NewEncoder(&data)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+	case "csv":
+		w := csv.NewWriter(&data)
+		w.Write(csvHeader)
+		for _, r := range rows {
+			w.Write([]string{
+				r.ICAO, r.Callsign,
+				strconv.FormatFloat(r.Lat, 'f', 5, 64),
+				strconv.FormatFloat(r.Lon, 'f', 5, 64),
+				strconv.Itoa(r.Altitude),
+				strconv.FormatFloat(r.Speed, 'f', 0, 64),
+				strconv.FormatFloat(r.Track, 'f', 0, 64),
+				r.Category,
+				r.LastSeen.UTC().Format(time.RFC3339),
+			})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return fmt.Errorf("export: %w", err)
+		}
+	}
+
+	name := "aircraft." + j.Format
+	if j.Timestamped {
+		name = "aircraft-" + now.UTC().Format("20060102T150405Z") + "." + j.Format
+	}
+	if err := os.MkdirAll(j.Dir, 0o755); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	dest := filepath.Join(j.Dir, name)
+	if err := os.WriteFile(dest+".tmp", []byte(data.String()), 0o644); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	if err := os.Rename(dest+".tmp", dest); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"termtrack/sbs"
+)
+
+// envPrefix goes in front of a flag's name to make its environment
+// variable, so -stats-url can also be set with TERMTRACK_STATS_URL
+const envPrefix = "TERMTRACK_"
+
+// envName is the environment variable for the named flag
+func envName(flagName string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// applyEnv sets every flag whose environment variable is set. Call it
+// before Parse so anything given on the command line still wins.
+func applyEnv(fs *flag.FlagSet) error {
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		v, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			return
+		}
+		if err := fs.Set(f.Name, v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", envName(f.Name), err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// usage is the -h output, with a note about the environment variables
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage of %s:\n", os.Args[0])
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\nEvery flag can also be set from the environment as %s<NAME>,\n", envPrefix)
+	fmt.Fprintf(out, "e.g. %s=localhost:30003. Flags win over the environment.\n", envName("feed"))
+}
+
+// location is a "lat,lon" flag, like the receiver's position
+type location struct {
+	lat, lon float64
+	set      bool
+}
+
+func (l *location) String() string {
+	if l == nil || !l.set {
+		return ""
+	}
+	return fmt.Sprintf("%.5f,%.5f", l.lat, l.lon)
+}
+
+func (l *location) Set(s string) error {
+	latStr, lonStr, ok := strings.Cut(s, ",")
+	if !ok {
+		return fmt.Errorf("want lat,lon, got %q", s)
+	}
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return fmt.Errorf("bad latitude %q", latStr)
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return fmt.Errorf("bad longitude %q", lonStr)
+	}
+	*l = location{lat: lat, lon: lon, set: true}
+	return nil
+}
+
+// sourceFlag is a feed's source flag: adsb, mlat or uat
+type sourceFlag struct {
+	source sbs.Source
+}
+
+func (s *sourceFlag) String() string {
+	if s == nil {
+		return ""
+	}
+	return s.source.String()
+}
+
+func (s *sourceFlag) Set(name string) error {
+	source, err := sbs.ParseSource(name)
+	if err != nil {
+		return err
+	}
+	s.source = source
+	return nil
+}
+
+// feedAddress is the address to connect to: -feed, with its host or port
+// swapped for -sbs-host and -sbs-port if they're set. set holds the flags
+// given on the command line or in the environment; the config file's
+// sbs_host and sbs_port only count if none of the three are.
+func feedAddress(feed, host string, port int, set map[string]bool, cfg fileConfig) (string, error) {
+	if !set["feed"] && !set["sbs-host"] && !set["sbs-port"] {
+		host, port = cfg.SBSHost, cfg.SBSPort
+	}
+	if host == "" && port == 0 {
+		return feed, nil
+	}
+	if port < 0 || port > 65535 {
+		return "", fmt.Errorf("-sbs-port: %d isn't a port", port)
+	}
+	if strings.Contains(feed, "://") {
+		return "", fmt.Errorf("-sbs-host and -sbs-port need a host:port -feed, not %s", feed)
+	}
+	feedHost, feedPort, err := net.SplitHostPort(feed)
+	if err != nil {
+		return "", fmt.Errorf("-feed: %w", err)
+	}
+	if host != "" {
+		feedHost = host
+	}
+	if port != 0 {
+		feedPort = strconv.Itoa(port)
+	}
+	return net.JoinHostPort(feedHost, feedPort), nil
+}
@@ -0,0 +1,62 @@
+package flightlog
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"termtrack/sbs"
+)
+
+// ExportSBS rebuilds the SBS stream for the positions logged from from
+// up to to, in time order, calling line with each line and when it was
+// heard. A zero to means up to the end of the log. Only what the log
+// keeps comes back: a callsign at the start of each flight, then a
+// position and, where it was logged, a velocity for each point.
+func ExportSBS(db *sql.DB, from, to time.Time, line func(at time.Time, line string) error) error {
+	end := int64(1<<63 - 1)
+	if !to.IsZero() {
+		end = to.UnixMilli()
+	}
+	rows, err := db.Query(`SELECT f.id, f.icao, f.callsign, p.at, p.lat, p.lon, p.altitude, p.speed, p.track
+		FROM positions p JOIN flights f ON f.id = p.flight
+		WHERE p.at >= ? AND p.at < ? ORDER BY p.at`, from.UnixMilli(), end)
+	if err != nil {
+		return fmt.Errorf("flightlog: %w", err)
+	}
+	defer rows.Close()
+
+	named := make(map[int64]bool) // Flights whose callsign has gone out
+	for rows.Next() {
+		var id, ms int64
+		var icao, callsign string
+		var lat, lon float64
+		var alt sql.NullInt64
+		var speed, track sql.NullFloat64
+		if err := rows.Scan(&id, &icao, &callsign, &ms, &lat, &lon, &alt, &speed, &track); err != nil {
+			return fmt.Errorf("flightlog: %w", err)
+		}
+
+		ac := &sbs.Aircraft{ICAO: icao, Lat: lat, Lon: lon, LastSeen: time.UnixMilli(ms), Has: sbs.HasPosition}
+		if callsign != "" && !named[id] {
+			ac.Callsign, ac.Has = callsign, ac.Has|sbs.HasCallsign
+			named[id] = true
+		}
+		if alt.Valid {
+			ac.Altitude, ac.Has = int(alt.Int64), ac.Has|sbs.HasAltitude
+		}
+		// SBS carries speed and track together
+		if speed.Valid && track.Valid {
+			ac.Speed, ac.Track, ac.Has = speed.Float64, track.Float64, ac.Has|sbs.HasSpeed|sbs.HasTrack
+		}
+		for _, l := range sbs.Lines(ac) {
+			if err := line(ac.LastSeen, l); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("flightlog: %w", err)
+	}
+	return nil
+}
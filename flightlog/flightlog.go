@@ -0,0 +1,272 @@
+// Package flightlog keeps every position heard in a SQLite database, for
+// looking back over days rather than the minutes the in-memory history
+// holds. Each airframe's positions are split into flights wherever it
+// went unheard for long enough, so a day of legs isn't one long blob.
+package flightlog
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure Go, so no cgo to build
+
+	"termtrack/sbs"
+)
+
+// DefaultGap is how long an aircraft can go unheard before its next
+// position starts a new flight. Turnarounds are rarely under half an hour,
+// and MLAT or range gaps mid-flight rarely over it.
+const DefaultGap = 30 * time.Minute
+
+// writeEvery is how often the changes in the store are written out, in
+// one transaction
+const writeEvery = 2 * time.Second
+
+// schema is created if the database is new. Times are Unix milliseconds.
+const schema = `
+CREATE TABLE IF NOT EXISTS flights (
+	id         INTEGER PRIMARY KEY,
+	icao       TEXT    NOT NULL,
+	callsign   TEXT    NOT NULL DEFAULT '',
+	first_seen INTEGER NOT NULL,
+	last_seen  INTEGER NOT NULL,
+	positions  INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS flights_icao ON flights (icao, last_seen);
+CREATE INDEX IF NOT EXISTS flights_last_seen ON flights (last_seen);
+
+CREATE TABLE IF NOT EXISTS positions (
+	flight   INTEGER NOT NULL REFERENCES flights (id),
+	at       INTEGER NOT NULL,
+	lat      REAL    NOT NULL,
+	lon      REAL    NOT NULL,
+	altitude INTEGER,
+	speed    REAL,
+	track    REAL
+);
+CREATE INDEX IF NOT EXISTS positions_flight ON positions (flight, at);
+CREATE INDEX IF NOT EXISTS positions_at ON positions (at);
+`
+
+// flight is where an airframe's positions are going
+type flight struct {
+	id       int64
+	lastAt   time.Time // Its latest position
+	callsign string
+}
+
+// Logger writes the store's positions to the database as they come in
+type Logger struct {
+	db    *sql.DB
+	store *sbs.Store
+	gap   time.Duration
+
+	version uint64             // Of the store, as of the last write
+	flights map[string]*flight // By ICAO, the flight each is on
+
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	mu  sync.Mutex
+	err error // From the last write
+}
+
+// Open opens (creating if need be) the database at path and starts
+// logging store's positions to it. A gap of 0 means DefaultGap.
+func Open(path string, store *sbs.Store, gap time.Duration) (*Logger, error) {
+	if gap <= 0 {
+		gap = DefaultGap
+	}
+	db, err := OpenDB(path)
+	if err != nil {
+		return nil, err
+	}
+	l := &Logger{
+		db:      db,
+		store:   store,
+		gap:     gap,
+		flights: make(map[string]*flight),
+		done:    make(chan struct{}),
+	}
+	l.wg.Add(1)
+	go l.run()
+	return l, nil
+}
+
+// OpenDB opens the database at path, creating the tables if they aren't
+// there, for reading it or logging to it. It's in WAL mode, so the query
+// panel's reader doesn't hold the writes up (or the other way about), and
+// waits out another process's lock rather than failing straight away.
+func OpenDB(path string) (*sql.DB, error) {
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(2000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("flightlog: %w", err)
+	}
+	// One connection: SQLite only has one writer anyway, and this keeps
+	// it from returning "database is locked" to ourselves
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("flightlog %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Close writes out what's left and closes the database
+func (l *Logger) Close() error {
+	close(l.done)
+	l.wg.Wait()
+	return l.db.Close()
+}
+
+// Err is why the last write failed, or nil if it worked
+func (l *Logger) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// run writes the store's changes every writeEvery, and once more when
+// we're closed
+func (l *Logger) run() {
+	defer l.wg.Done()
+	ticker := time.NewTicker(writeEvery)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.done:
+			l.write()
+			return
+		case <-ticker.C:
+			l.write()
+		}
+	}
+}
+
+// write logs the positions that have come in since the last write, in
+// one transaction
+func (l *Logger) write() {
+	diff := l.store.Changes(l.version)
+	err := l.writeDiff(diff)
+	if err == nil {
+		l.version = diff.Version
+	} else {
+		// Flights we started in the rolled back transaction are gone;
+		// find out where everyone's really up to next time
+		clear(l.flights)
+	}
+	l.mu.Lock()
+	l.err = err
+	l.mu.Unlock()
+}
+
+func (l *Logger) writeDiff(diff sbs.Diff) error {
+	if len(diff.Changed) == 0 {
+		return nil
+	}
+	tx, err := l.db.Begin()
+	if err != nil {
+		return fmt.Errorf("flightlog: %w", err)
+	}
+	defer tx.Rollback() // A no-op once committed
+
+	for hex, ac := range diff.Changed {
+		if err := l.logAircraft(tx, hex, ac); err != nil {
+			return fmt.Errorf("flightlog %s: %w", hex, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("flightlog: %w", err)
+	}
+	return nil
+}
+
+// logAircraft writes the aircraft's trail points newer than the last one
+// logged. Only the latest report of altitude, speed and track is known,
+// so all of them get that; they're at most writeEvery out.
+func (l *Logger) logAircraft(tx *sql.Tx, hex string, ac *sbs.Aircraft) error {
+	f, err := l.current(tx, hex)
+	if err != nil {
+		return err
+	}
+
+	var alt, speed, track any // NULL unless reported
+	if ac.Has&sbs.HasAltitude != 0 {
+		alt = ac.Altitude
+	}
+	if ac.Has&sbs.HasSpeed != 0 {
+		speed = ac.Speed
+	}
+	if ac.Has&sbs.HasTrack != 0 {
+		track = ac.Track
+	}
+
+	for _, p := range ac.Trail {
+		if f != nil && !p.At.After(f.lastAt) {
+			continue // Logged already
+		}
+		if f == nil || p.At.Sub(f.lastAt) > l.gap {
+			if f, err = l.start(tx, hex, p.At); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.Exec(`INSERT INTO positions (flight, at, lat, lon, altitude, speed, track) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			f.id, p.At.UnixMilli(), p.Lat, p.Lon, alt, speed, track); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE flights SET last_seen = ?, positions = positions + 1 WHERE id = ?`, p.At.UnixMilli(), f.id); err != nil {
+			return err
+		}
+		f.lastAt = p.At
+	}
+
+	// The callsign tends to turn up after the first positions
+	if f != nil && ac.Callsign != "" && ac.Callsign != f.callsign {
+		if _, err := tx.Exec(`UPDATE flights SET callsign = ? WHERE id = ?`, ac.Callsign, f.id); err != nil {
+			return err
+		}
+		f.callsign = ac.Callsign
+	}
+	return nil
+}
+
+// current is the flight the aircraft is on, from the database if we've
+// not logged it this session, or nil if it's never been logged
+func (l *Logger) current(tx *sql.Tx, hex string) (*flight, error) {
+	if f, ok := l.flights[hex]; ok {
+		return f, nil
+	}
+	f := &flight{}
+	var last int64
+	err := tx.QueryRow(`SELECT id, last_seen, callsign FROM flights WHERE icao = ? ORDER BY last_seen DESC LIMIT 1`, hex).
+		Scan(&f.id, &last, &f.callsign)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.lastAt = time.UnixMilli(last)
+	l.flights[hex] = f
+	return f, nil
+}
+
+// start begins a new flight for the aircraft at at
+func (l *Logger) start(tx *sql.Tx, hex string, at time.Time) (*flight, error) {
+	res, err := tx.Exec(`INSERT INTO flights (icao, first_seen, last_seen) VALUES (?, ?, ?)`, hex, at.UnixMilli(), at.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	f := &flight{id: id, lastAt: at}
+	l.flights[hex] = f
+	return f, nil
+}
@@ -0,0 +1,183 @@
+package flightlog
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"termtrack/icao"
+)
+
+// MaxRows is as many rows as a query returns; past that, Result.Truncated
+// is set. Nobody's reading ten thousand rows in a terminal.
+const MaxRows = 500
+
+// Result is a query's answer, already turned into text for showing
+type Result struct {
+	Columns   []string
+	Rows      [][]string
+	Truncated bool // There were more than MaxRows
+}
+
+// Canned is a ready-made question about the log. Some take an argument,
+// e.g. which aircraft.
+type Canned struct {
+	Name string
+	Arg  string // What the argument is, "" if it takes none
+	SQL  string
+
+	// args are the SQL's parameters, given the argument and the time now
+	args func(arg string, now time.Time) []any
+}
+
+// Args are the parameters to run the query with
+func (c Canned) Args(arg string, now time.Time) []any {
+	if c.args == nil {
+		return nil
+	}
+	return c.args(arg, now)
+}
+
+// local turns a Unix millisecond column into local time for showing
+func local(col string) string {
+	return fmt.Sprintf("datetime(%s / 1000, 'unixepoch', 'localtime')", col)
+}
+
+// midnight is the start of today, local time, in Unix milliseconds
+func midnight(now time.Time) int64 {
+	y, m, d := now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, now.Location()).UnixMilli()
+}
+
+// weekAgo is seven days back, in Unix milliseconds
+func weekAgo(now time.Time) int64 {
+	return now.AddDate(0, 0, -7).UnixMilli()
+}
+
+// Queries are the canned queries, in the order they're offered
+var Queries = []Canned{
+	{
+		Name: "Flights of an aircraft this week",
+		Arg:  "hex, callsign or registration",
+		SQL: `SELECT icao, callsign, ` + local("first_seen") + ` AS first, ` + local("last_seen") + ` AS last,
+	(last_seen - first_seen) / 60000 AS minutes, positions
+FROM flights
+WHERE (icao = upper(?1) OR callsign = upper(?1)) AND last_seen >= ?2
+ORDER BY first_seen DESC`,
+		args: func(arg string, now time.Time) []any {
+			arg = strings.TrimSpace(arg)
+			if hex, ok := icao.Address(arg); ok {
+				arg = hex // Where the registration says which airframe
+			}
+			return []any{arg, weekAgo(now)}
+		},
+	},
+	{
+		Name: "Busiest hour today",
+		SQL: `SELECT strftime('%H:00', p.at / 1000, 'unixepoch', 'localtime') AS hour,
+	count(DISTINCT f.icao) AS aircraft, count(*) AS positions
+FROM positions p JOIN flights f ON f.id = p.flight
+WHERE p.at >= ?1
+GROUP BY hour
+ORDER BY aircraft DESC, positions DESC`,
+		args: func(_ string, now time.Time) []any {
+			return []any{midnight(now)}
+		},
+	},
+	{
+		Name: "Most seen aircraft this week",
+		SQL: `SELECT icao, count(*) AS flights, group_concat(DISTINCT nullif(callsign, '')) AS callsigns,
+	` + local("max(last_seen)") + ` AS last
+FROM flights
+WHERE last_seen >= ?1
+GROUP BY icao
+ORDER BY flights DESC, last DESC`,
+		args: func(_ string, now time.Time) []any {
+			return []any{weekAgo(now)}
+		},
+	},
+	{
+		Name: "Flights in the last hour",
+		SQL: `SELECT icao, callsign, ` + local("first_seen") + ` AS first, ` + local("last_seen") + ` AS last, positions
+FROM flights
+WHERE last_seen >= ?1
+ORDER BY last_seen DESC`,
+		args: func(_ string, now time.Time) []any {
+			return []any{now.Add(-time.Hour).UnixMilli()}
+		},
+	},
+	{
+		Name: "Longest flights today",
+		SQL: `SELECT icao, callsign, (last_seen - first_seen) / 60000 AS minutes, ` + local("first_seen") + ` AS first, positions
+FROM flights
+WHERE last_seen >= ?1
+ORDER BY minutes DESC`,
+		args: func(_ string, now time.Time) []any {
+			return []any{midnight(now)}
+		},
+	},
+}
+
+// OpenReadOnly opens the database at path for querying. Nothing run on it
+// can change the log, so raw SQL is safe to hand it.
+func OpenReadOnly(path string) (*sql.DB, error) {
+	dsn := "file:" + (&url.URL{Path: path}).EscapedPath() + "?mode=ro&_pragma=query_only(1)&_pragma=busy_timeout(2000)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("flightlog: %w", err)
+	}
+	return db, nil
+}
+
+// Query runs a query and returns its first MaxRows rows as text
+func Query(db *sql.DB, query string, args ...any) (Result, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return Result{}, err
+	}
+	defer rows.Close()
+
+	var res Result
+	if res.Columns, err = rows.Columns(); err != nil {
+		return Result{}, err
+	}
+	values := make([]any, len(res.Columns))
+	ptrs := make([]any, len(values))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	for rows.Next() {
+		if len(res.Rows) == MaxRows {
+			res.Truncated = true
+			break
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return Result{}, err
+		}
+		row := make([]string, len(values))
+		for i, v := range values {
+			row[i] = text(v)
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	return res, rows.Err()
+}
+
+// text shows a column's value
+func text(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Local().Format("2006-01-02 15:04:05")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,239 @@
+package flightlog
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"termtrack/geo"
+)
+
+// DefaultReportGap is the shortest silence the antenna report lists as a
+// gap. Quiet spells of a few minutes are just a quiet sky.
+const DefaultReportGap = 15 * time.Minute
+
+// ReportOptions is what the antenna report is worked out from
+type ReportOptions struct {
+	Lat, Lon float64       // The receiver, where ranges are measured from
+	Since    time.Time     // Only positions from then on; zero for all of them
+	Gap      time.Duration // Shortest silence to count as a gap, DefaultReportGap if 0
+}
+
+// sectorNames are the 16 compass points the range is broken down by
+var sectorNames = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+// altitudeBand is how many feet each altitude band covers, and maxBands
+// how many there are; the last takes everything above
+const (
+	altitudeBand = 5000
+	maxBands     = 9
+)
+
+// maxRangeNM is as far as the range histograms go; anything past it is
+// counted there, and is a bad position more often than not
+const maxRangeNM = 500
+
+// ranges is a histogram of distances, a nautical mile a bin, so
+// percentiles come cheap however many positions there are
+type ranges struct {
+	bins  [maxRangeNM + 1]int
+	count int
+	max   float64
+}
+
+func (r *ranges) add(nm float64) {
+	r.bins[min(int(nm), maxRangeNM)]++
+	r.count++
+	r.max = max(r.max, nm)
+}
+
+// percentile is the distance within which p (0-1) of them are
+func (r *ranges) percentile(p float64) int {
+	want := int(math.Ceil(p * float64(r.count)))
+	seen := 0
+	for nm, n := range r.bins {
+		if seen += n; seen >= want {
+			return nm
+		}
+	}
+	return maxRangeNM
+}
+
+// gap is a stretch with nothing logged
+type gap struct {
+	from, to time.Time
+}
+
+// report is everything the antenna report says, gathered in one pass over
+// the positions
+type report struct {
+	first, last time.Time
+	positions   int
+	days        map[string]bool // Local dates with any positions
+
+	sectors [16]ranges
+	hours   [24]int
+	bands   [maxBands]ranges
+	noAlt   int // Positions without an altitude
+	gaps    []gap
+}
+
+// WriteReport reads the log's positions and writes a plain text report on
+// how the receiver's doing: range by bearing, positions by hour of day,
+// range by altitude, and gaps with nothing heard at all. It's for finding
+// where an antenna is blocked, or when a receiver keeps dropping out.
+func WriteReport(w io.Writer, db *sql.DB, opts ReportOptions) error {
+	if opts.Gap <= 0 {
+		opts.Gap = DefaultReportGap
+	}
+	r, err := gather(db, opts)
+	if err != nil {
+		return fmt.Errorf("flightlog: %w", err)
+	}
+	r.write(w, opts)
+	return nil
+}
+
+// gather goes through the positions in time order
+func gather(db *sql.DB, opts ReportOptions) (*report, error) {
+	rows, err := db.Query(`SELECT at, lat, lon, altitude FROM positions WHERE at >= ? ORDER BY at`, opts.Since.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r := &report{days: make(map[string]bool)}
+	for rows.Next() {
+		var ms int64
+		var lat, lon float64
+		var alt sql.NullInt64
+		if err := rows.Scan(&ms, &lat, &lon, &alt); err != nil {
+			return nil, err
+		}
+		at := time.UnixMilli(ms)
+
+		if r.positions == 0 {
+			r.first = at
+		} else if at.Sub(r.last) >= opts.Gap {
+			r.gaps = append(r.gaps, gap{from: r.last, to: at})
+		}
+		r.last = at
+		r.positions++
+		r.days[at.Format(time.DateOnly)] = true
+		r.hours[at.Hour()]++
+
+		nm := geo.Distance(opts.Lat, opts.Lon, lat, lon)
+		sector := int(math.Mod(geo.Bearing(opts.Lat, opts.Lon, lat, lon)+360.0/32, 360) / (360.0 / 16))
+		r.sectors[sector%16].add(nm)
+		if !alt.Valid {
+			r.noAlt++
+			continue
+		}
+		r.bands[min(max(int(alt.Int64), 0)/altitudeBand, maxBands-1)].add(nm)
+	}
+	return r, rows.Err()
+}
+
+// barWidth is how long the longest bar in a chart is
+const barWidth = 40
+
+// bar is n out of most as a row of #s
+func bar(n, most int) string {
+	if most == 0 {
+		return ""
+	}
+	return strings.Repeat("#", int(math.Round(float64(n)/float64(most)*barWidth)))
+}
+
+// row writes a line of a chart, without the trailing spaces of an empty bar
+func row(w io.Writer, format string, args ...any) {
+	fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf(format, args...), " "))
+}
+
+// write sets the report out as text
+func (r *report) write(w io.Writer, opts ReportOptions) {
+	fmt.Fprintf(w, "Receiver report for %.4f, %.4f\n", opts.Lat, opts.Lon)
+	if r.positions == 0 {
+		fmt.Fprintln(w, "\nNo positions logged yet.")
+		return
+	}
+	days := "days"
+	if len(r.days) == 1 {
+		days = "day"
+	}
+	fmt.Fprintf(w, "%d positions from %s to %s (%d %s)\n",
+		r.positions, r.first.Format("2006-01-02 15:04"), r.last.Format("2006-01-02 15:04"), len(r.days), days)
+
+	// A sector that falls short of the rest is where something's in the
+	// way
+	fmt.Fprintln(w, "\nRange by bearing (nm; 95% of positions are within the first figure)")
+	most := 0
+	for _, s := range r.sectors {
+		most = max(most, s.percentile(0.95))
+	}
+	for i, s := range r.sectors {
+		if s.count == 0 {
+			fmt.Fprintf(w, "  %-3s  %4s  %4s\n", sectorNames[i], "-", "-")
+			continue
+		}
+		p95 := s.percentile(0.95)
+		row(w, "  %-3s  %4d  %4.0f  %s", sectorNames[i], p95, s.max, bar(p95, most))
+	}
+
+	fmt.Fprintln(w, "\nPositions by hour of day (average a day)")
+	most = 0
+	for _, n := range r.hours {
+		most = max(most, n)
+	}
+	for h, n := range r.hours {
+		row(w, "  %02d:00  %7.0f  %s", h, float64(n)/float64(len(r.days)), bar(n, most))
+	}
+
+	// Low aircraft drop below the horizon first, so the low bands' range
+	// says most about the antenna's height and what's around it
+	fmt.Fprintln(w, "\nAltitude (ft): positions, and range in nm (95% within, then max)")
+	most = 0
+	for _, b := range r.bands {
+		most = max(most, b.count)
+	}
+	for i, b := range r.bands {
+		label := fmt.Sprintf("%d-%d", i*altitudeBand, (i+1)*altitudeBand-1)
+		if i == maxBands-1 {
+			label = fmt.Sprintf("%d+", i*altitudeBand)
+		}
+		if b.count == 0 {
+			fmt.Fprintf(w, "  %-11s  %8d  %4s  %4s\n", label, 0, "-", "-")
+			continue
+		}
+		row(w, "  %-11s  %8d  %4d  %4.0f  %s", label, b.count, b.percentile(0.95), b.max, bar(b.count, most))
+	}
+	if r.noAlt > 0 {
+		fmt.Fprintf(w, "  %-11s  %8d\n", "unknown", r.noAlt)
+	}
+
+	fmt.Fprintf(w, "\nGaps of %s or more with nothing logged\n", opts.Gap)
+	if len(r.gaps) == 0 {
+		fmt.Fprintln(w, "  None")
+		return
+	}
+	var total time.Duration
+	for _, g := range r.gaps {
+		total += g.to.Sub(g.from)
+	}
+	fmt.Fprintf(w, "  %d, %s in all", len(r.gaps), total.Round(time.Minute))
+	const maxGaps = 20
+	if len(r.gaps) > maxGaps {
+		fmt.Fprintf(w, "; the %d longest:", maxGaps)
+	}
+	fmt.Fprintln(w)
+	sort.SliceStable(r.gaps, func(i, j int) bool {
+		return r.gaps[i].to.Sub(r.gaps[i].from) > r.gaps[j].to.Sub(r.gaps[j].from)
+	})
+	for _, g := range r.gaps[:min(len(r.gaps), maxGaps)] {
+		fmt.Fprintf(w, "  %s to %s  %s\n", g.from.Format("2006-01-02 15:04"), g.to.Format("2006-01-02 15:04"), g.to.Sub(g.from).Round(time.Minute))
+	}
+}
@@ -0,0 +1,67 @@
+package geo
+
+import "math"
+
+// earthRadiusNM is the mean radius of the Earth in nautical miles
+const earthRadiusNM = 3440.065
+
+// Distance returns the great-circle distance between two points in nautical miles
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := (lat2 - lat1) * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * earthRadiusNM * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+// Within returns the distance between two points in nautical miles, and
+// whether it's no more than limit. A degree of latitude is 60nm, and of
+// longitude no more, so points further apart than that in latitude are
+// ruled out before any trig.
+func Within(lat1, lon1, lat2, lon2, limit float64) (float64, bool) {
+	if math.Abs(lat2-lat1)*60 > limit {
+		return 0, false
+	}
+	dist := Distance(lat1, lon1, lat2, lon2)
+	return dist, dist <= limit
+}
+
+// Bearing returns the initial bearing from point 1 to point 2 in degrees (0-360)
+func Bearing(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+var compassPoints = []string{
+	"north", "north-east", "east", "south-east",
+	"south", "south-west", "west", "north-west",
+}
+
+// CompassPoint turns a bearing into a spoken direction like "north-east"
+func CompassPoint(bearing float64) string {
+	i := int(math.Mod(bearing+22.5+360, 360) / 45)
+	return compassPoints[i%8]
+}
+
+// Destination returns the point dist nautical miles from lat/lon along the
+// given bearing in degrees, following the great circle
+func Destination(lat, lon, bearing, dist float64) (float64, float64) {
+	phi1 := lat * math.Pi / 180
+	lambda1 := lon * math.Pi / 180
+	theta := bearing * math.Pi / 180
+	delta := dist / earthRadiusNM
+
+	phi2 := math.Asin(math.Sin(phi1)*math.Cos(delta) +
+		math.Cos(phi1)*math.Sin(delta)*math.Cos(theta))
+	lambda2 := lambda1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(phi1),
+		math.Cos(delta)-math.Sin(phi1)*math.Sin(phi2))
+	return phi2 * 180 / math.Pi, lambda2 * 180 / math.Pi
+}
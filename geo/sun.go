@@ -0,0 +1,35 @@
+package geo
+
+import (
+	"math"
+	"time"
+)
+
+// Sunset is the sun's elevation, in degrees, as its top edge goes below
+// the horizon: half its width, plus what the air bends it up by
+const Sunset = -0.833
+
+// SunElevation returns the sun's elevation above the horizon in degrees,
+// seen from lat/lon at time t. It's the low-precision almanac formula,
+// good to a minute or so of sunset; plenty for telling day from night.
+func SunElevation(lat, lon float64, t time.Time) float64 {
+	const rad = math.Pi / 180
+
+	// Days since the J2000 epoch, noon UTC on 1 January 2000
+	d := float64(t.Unix())/86400 - 10957.5
+
+	g := (357.529 + 0.98560028*d) * rad // Mean anomaly
+	q := 280.459 + 0.98564736*d         // Mean longitude
+	l := (q + 1.915*math.Sin(g) + 0.020*math.Sin(2*g)) * rad
+	e := (23.439 - 0.00000036*d) * rad // Tilt of the Earth's axis
+
+	ra := math.Atan2(math.Cos(e)*math.Sin(l), math.Cos(l))
+	dec := math.Asin(math.Sin(e) * math.Sin(l))
+
+	// Hour angle from the sidereal time where we are
+	gmst := 18.697374558 + 24.06570982441908*d // Hours
+	h := (gmst*15+lon)*rad - ra
+
+	phi := lat * rad
+	return math.Asin(math.Sin(phi)*math.Sin(dec)+math.Cos(phi)*math.Cos(dec)*math.Cos(h)) / rad
+}
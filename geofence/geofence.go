@@ -0,0 +1,172 @@
+// Package geofence watches named areas of sky, like an approach corridor
+// or a practice area, keeping count of who's in each and how many have
+// gone in over the session.
+package geofence
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"termtrack/geo"
+	"termtrack/sbs"
+	"termtrack/watch"
+)
+
+// Fence is an area from the config: a polygon or a circle, and optionally
+// an altitude band
+type Fence struct {
+	Name string `json:"name"`
+
+	// Corners as [lat, lon], in order around the edge. It closes itself.
+	Points [][2]float64 `json:"points,omitempty"`
+	Circle *Circle      `json:"circle,omitempty"`
+
+	MinAltitude int `json:"min_altitude,omitempty"` // Feet; with either set, aircraft without an altitude are left out
+	MaxAltitude int `json:"max_altitude,omitempty"`
+}
+
+// Circle is a circle around a point
+type Circle struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+	NM  float64 `json:"nm"`
+}
+
+// Validate checks the fence describes an area
+func (f Fence) Validate() error {
+	if f.Name == "" {
+		return errors.New("no name")
+	}
+	switch {
+	case len(f.Points) > 0 && f.Circle != nil:
+		return fmt.Errorf("%s: points and circle both given", f.Name)
+	case f.Circle != nil:
+		c := f.Circle
+		if c.Lat < -90 || c.Lat > 90 || c.Lon < -180 || c.Lon > 180 {
+			return fmt.Errorf("%s: circle center %g,%g isn't a lat,lon", f.Name, c.Lat, c.Lon)
+		}
+		if c.NM <= 0 {
+			return fmt.Errorf("%s: circle radius %g isn't positive", f.Name, c.NM)
+		}
+	case len(f.Points) < 3:
+		return fmt.Errorf("%s: needs a circle or at least 3 points", f.Name)
+	}
+	for _, p := range f.Points {
+		if p[0] < -90 || p[0] > 90 || p[1] < -180 || p[1] > 180 {
+			return fmt.Errorf("%s: point %g,%g isn't a lat,lon", f.Name, p[0], p[1])
+		}
+	}
+	if f.MaxAltitude != 0 && f.MaxAltitude < f.MinAltitude {
+		return fmt.Errorf("%s: max altitude %d is under the min %d", f.Name, f.MaxAltitude, f.MinAltitude)
+	}
+	return nil
+}
+
+// Contains reports whether the aircraft is inside the fence
+func (f Fence) Contains(ac *sbs.Aircraft) bool {
+	if ac.Has&sbs.HasPosition == 0 {
+		return false
+	}
+	if f.MinAltitude != 0 || f.MaxAltitude != 0 {
+		if ac.Has&sbs.HasAltitude == 0 || ac.Altitude < f.MinAltitude {
+			return false
+		}
+		if f.MaxAltitude != 0 && ac.Altitude > f.MaxAltitude {
+			return false
+		}
+	}
+	if c := f.Circle; c != nil {
+		return geo.Distance(c.Lat, c.Lon, ac.Lat, ac.Lon) <= c.NM
+	}
+	return inPolygon(f.Points, ac.Lat, ac.Lon)
+}
+
+// inPolygon is the even-odd test: a point is inside if a line from it
+// due east crosses the edge an odd number of times. Fences are small
+// enough that treating lat,lon as flat is fine.
+func inPolygon(points [][2]float64, lat, lon float64) bool {
+	in := false
+	j := len(points) - 1
+	for i := range points {
+		a, b := points[i], points[j]
+		if (a[0] > lat) != (b[0] > lat) {
+			if crossLon := a[1] + (lat-a[0])/(b[0]-a[0])*(b[1]-a[1]); lon < crossLon {
+				in = !in
+			}
+		}
+		j = i
+	}
+	return in
+}
+
+// Occupancy is how busy a fence is
+type Occupancy struct {
+	Name    string
+	Inside  []string // ICAOs, in the order they went in
+	Entries int      // Over the session
+	Peak    int      // Most inside at once
+}
+
+// Monitor keeps each fence's occupancy up to date
+type Monitor struct {
+	fences    []Fence
+	occupancy []Occupancy
+	watch.Watch
+}
+
+// NewMonitor creates a monitor for the fences
+func NewMonitor(fences []Fence) *Monitor {
+	m := &Monitor{
+		fences:    fences,
+		occupancy: make([]Occupancy, len(fences)),
+	}
+	for i, f := range fences {
+		m.occupancy[i].Name = f.Name
+	}
+	return m
+}
+
+// Check works out who's in each fence (at most every watch.Interval),
+// counting everyone who's gone in since last time
+func (m *Monitor) Check(all map[string]*sbs.Aircraft) {
+	now := time.Now()
+	if !m.Due(now) {
+		return
+	}
+
+	for i, f := range m.fences {
+		o := &m.occupancy[i]
+
+		// Keep the ones still inside in their order, then add the new
+		was := make(map[string]bool, len(o.Inside))
+		var inside []string
+		for _, hex := range o.Inside {
+			was[hex] = true
+			if ac, ok := all[hex]; ok && !m.Quiet(ac, now) && f.Contains(ac) {
+				inside = append(inside, hex)
+			}
+		}
+		var entered []string
+		for hex, ac := range all {
+			if !was[hex] && !m.Quiet(ac, now) && f.Contains(ac) {
+				entered = append(entered, hex)
+			}
+		}
+		slices.Sort(entered) // Same time, so any order; just keep it steady
+		o.Inside = append(inside, entered...)
+		o.Entries += len(entered)
+		o.Peak = max(o.Peak, len(o.Inside))
+	}
+}
+
+// Occupancy is each fence's occupancy, in the order they were configured
+func (m *Monitor) Occupancy() []Occupancy {
+	out := make([]Occupancy, len(m.occupancy))
+	for i, o := range m.occupancy {
+		o.Inside = slices.Clone(o.Inside)
+		out[i] = o
+	}
+	return out
+}
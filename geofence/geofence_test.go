@@ -0,0 +1,63 @@
+package geofence
+
+import (
+	"testing"
+
+	"termtrack/sbs"
+)
+
+func TestInPolygon(t *testing.T) {
+	square := [][2]float64{{40, -74}, {40, -73}, {41, -73}, {41, -74}}
+	// An L: the square with its north-east quarter cut away
+	ell := [][2]float64{{40, -74}, {40, -73}, {40.5, -73}, {40.5, -73.5}, {41, -73.5}, {41, -74}}
+	tests := []struct {
+		name     string
+		points   [][2]float64
+		lat, lon float64
+		want     bool
+	}{
+		{name: "middle", points: square, lat: 40.5, lon: -73.5, want: true},
+		{name: "north", points: square, lat: 41.5, lon: -73.5},
+		{name: "east", points: square, lat: 40.5, lon: -72.5},
+		{name: "west", points: square, lat: 40.5, lon: -74.5},
+		{name: "in the L's foot", points: ell, lat: 40.25, lon: -73.25, want: true},
+		{name: "in the L's upright", points: ell, lat: 40.75, lon: -73.75, want: true},
+		{name: "in the L's notch", points: ell, lat: 40.75, lon: -73.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inPolygon(tt.points, tt.lat, tt.lon); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContains(t *testing.T) {
+	band := Fence{Name: "band", Points: [][2]float64{{40, -74}, {40, -73}, {41, -73}, {41, -74}}, MinAltitude: 1000, MaxAltitude: 4000}
+	circle := Fence{Name: "circle", Circle: &Circle{Lat: 40.5, Lon: -73.5, NM: 5}}
+	at := func(lat, lon float64, alt int) *sbs.Aircraft {
+		return &sbs.Aircraft{Lat: lat, Lon: lon, Altitude: alt, Has: sbs.HasPosition | sbs.HasAltitude}
+	}
+	tests := []struct {
+		name  string
+		fence Fence
+		ac    *sbs.Aircraft
+		want  bool
+	}{
+		{name: "in the band", fence: band, ac: at(40.5, -73.5, 2500), want: true},
+		{name: "under the band", fence: band, ac: at(40.5, -73.5, 500)},
+		{name: "over the band", fence: band, ac: at(40.5, -73.5, 4500)},
+		{name: "no altitude", fence: band, ac: &sbs.Aircraft{Lat: 40.5, Lon: -73.5, Has: sbs.HasPosition}},
+		{name: "no position", fence: circle, ac: &sbs.Aircraft{Altitude: 2500, Has: sbs.HasAltitude}},
+		{name: "in the circle", fence: circle, ac: at(40.55, -73.5, 2500), want: true},
+		{name: "out of the circle", fence: circle, ac: at(40.6, -73.5, 2500)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.fence.Contains(tt.ac); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package icao
+
+import (
+	"sort"
+	"strconv"
+)
+
+// Country is where an aircraft is registered, going by its ICAO address
+type Country struct {
+	Code string // ISO 3166 alpha-2, e.g. "US"
+	Name string
+}
+
+// block is a range of addresses ICAO has allocated to one country
+type block struct {
+	start, end uint32
+	country    Country
+}
+
+// blocks is the allocation table from ICAO Annex 10 Vol III, sorted by
+// start address. Unallocated gaps are simply missing.
+var blocks = []block{
+	{0x004000, 0x0043FF, Country{"ZW", "Zimbabwe"}},
+	{0x006000, 0x006FFF, Country{"MZ", "Mozambique"}},
+	{0x008000, 0x00FFFF, Country{"ZA", "South Africa"}},
+	{0x010000, 0x017FFF, Country{"EG", "Egypt"}},
+	{0x018000, 0x01FFFF, Country{"LY", "Libya"}},
+	{0x020000, 0x027FFF, Country{"MA", "Morocco"}},
+	{0x028000, 0x02FFFF, Country{"TN", "Tunisia"}},
+	{0x030000, 0x0303FF, Country{"BW", "Botswana"}},
+	{0x032000, 0x032FFF, Country{"BI", "Burundi"}},
+	{0x034000, 0x034FFF, Country{"CM", "Cameroon"}},
+	{0x035000, 0x0353FF, Country{"KM", "Comoros"}},
+	{0x036000, 0x036FFF, Country{"CG", "Congo"}},
+	{0x038000, 0x038FFF, Country{"CI", "Côte d'Ivoire"}},
+	{0x03E000, 0x03EFFF, Country{"GA", "Gabon"}},
+	{0x040000, 0x040FFF, Country{"ET", "Ethiopia"}},
+	{0x042000, 0x042FFF, Country{"GQ", "Equatorial Guinea"}},
+	{0x044000, 0x044FFF, Country{"GH", "Ghana"}},
+	{0x046000, 0x046FFF, Country{"GN", "Guinea"}},
+	{0x048000, 0x0483FF, Country{"GW", "Guinea-Bissau"}},
+	{0x04A000, 0x04A3FF, Country{"LS", "Lesotho"}},
+	{0x04C000, 0x04CFFF, Country{"KE", "Kenya"}},
+	{0x050000, 0x050FFF, Country{"LR", "Liberia"}},
+	{0x054000, 0x054FFF, Country{"MG", "Madagascar"}},
+	{0x058000, 0x058FFF, Country{"MW", "Malawi"}},
+	{0x05A000, 0x05A3FF, Country{"MV", "Maldives"}},
+	{0x05C000, 0x05CFFF, Country{"ML", "Mali"}},
+	{0x05E000, 0x05E3FF, Country{"MR", "Mauritania"}},
+	{0x060000, 0x0603FF, Country{"MU", "Mauritius"}},
+	{0x062000, 0x062FFF, Country{"NE", "Niger"}},
+	{0x064000, 0x064FFF, Country{"NG", "Nigeria"}},
+	{0x068000, 0x068FFF, Country{"UG", "Uganda"}},
+	{0x06A000, 0x06A3FF, Country{"QA", "Qatar"}},
+	{0x06C000, 0x06CFFF, Country{"CF", "Central African Republic"}},
+	{0x06E000, 0x06EFFF, Country{"RW", "Rwanda"}},
+	{0x070000, 0x070FFF, Country{"SN", "Senegal"}},
+	{0x074000, 0x0743FF, Country{"SC", "Seychelles"}},
+	{0x076000, 0x0763FF, Country{"SL", "Sierra Leone"}},
+	{0x078000, 0x078FFF, Country{"SO", "Somalia"}},
+	{0x07A000, 0x07A3FF, Country{"SZ", "Eswatini"}},
+	{0x07C000, 0x07CFFF, Country{"SD", "Sudan"}},
+	{0x080000, 0x080FFF, Country{"TZ", "Tanzania"}},
+	{0x084000, 0x084FFF, Country{"TD", "Chad"}},
+	{0x088000, 0x088FFF, Country{"TG", "Togo"}},
+	{0x08A000, 0x08AFFF, Country{"ZM", "Zambia"}},
+	{0x08C000, 0x08CFFF, Country{"CD", "DR Congo"}},
+	{0x090000, 0x090FFF, Country{"AO", "Angola"}},
+	{0x094000, 0x0943FF, Country{"BJ", "Benin"}},
+	{0x096000, 0x0963FF, Country{"CV", "Cape Verde"}},
+	{0x098000, 0x0983FF, Country{"DJ", "Djibouti"}},
+	{0x09A000, 0x09AFFF, Country{"GM", "Gambia"}},
+	{0x09C000, 0x09CFFF, Country{"BF", "Burkina Faso"}},
+	{0x09E000, 0x09E3FF, Country{"ST", "São Tomé and Príncipe"}},
+	{0x0A0000, 0x0A7FFF, Country{"DZ", "Algeria"}},
+	{0x0A8000, 0x0A8FFF, Country{"BS", "Bahamas"}},
+	{0x0AA000, 0x0AA3FF, Country{"BB", "Barbados"}},
+	{0x0AB000, 0x0AB3FF, Country{"BZ", "Belize"}},
+	{0x0AC000, 0x0ACFFF, Country{"CO", "Colombia"}},
+	{0x0AE000, 0x0AEFFF, Country{"CR", "Costa Rica"}},
+	{0x0B0000, 0x0B0FFF, Country{"CU", "Cuba"}},
+	{0x0B2000, 0x0B2FFF, Country{"SV", "El Salvador"}},
+	{0x0B4000, 0x0B4FFF, Country{"GT", "Guatemala"}},
+	{0x0B6000, 0x0B6FFF, Country{"GY", "Guyana"}},
+	{0x0B8000, 0x0B8FFF, Country{"HT", "Haiti"}},
+	{0x0BA000, 0x0BAFFF, Country{"HN", "Honduras"}},
+	{0x0BC000, 0x0BC3FF, Country{"VC", "Saint Vincent and the Grenadines"}},
+	{0x0BE000, 0x0BEFFF, Country{"JM", "Jamaica"}},
+	{0x0C0000, 0x0C0FFF, Country{"NI", "Nicaragua"}},
+	{0x0C2000, 0x0C2FFF, Country{"PA", "Panama"}},
+	{0x0C4000, 0x0C4FFF, Country{"DO", "Dominican Republic"}},
+	{0x0C6000, 0x0C6FFF, Country{"TT", "Trinidad and Tobago"}},
+	{0x0C8000, 0x0C8FFF, Country{"SR", "Suriname"}},
+	{0x0CA000, 0x0CA3FF, Country{"AG", "Antigua and Barbuda"}},
+	{0x0CC000, 0x0CC3FF, Country{"GD", "Grenada"}},
+	{0x0D0000, 0x0D7FFF, Country{"MX", "Mexico"}},
+	{0x0D8000, 0x0DFFFF, Country{"VE", "Venezuela"}},
+	{0x100000, 0x1FFFFF, Country{"RU", "Russia"}},
+	{0x201000, 0x2013FF, Country{"NA", "Namibia"}},
+	{0x202000, 0x2023FF, Country{"ER", "Eritrea"}},
+	{0x300000, 0x33FFFF, Country{"IT", "Italy"}},
+	{0x340000, 0x37FFFF, Country{"ES", "Spain"}},
+	{0x380000, 0x3BFFFF, Country{"FR", "France"}},
+	{0x3C0000, 0x3FFFFF, Country{"DE", "Germany"}},
+	{0x400000, 0x43FFFF, Country{"GB", "United Kingdom"}},
+	{0x440000, 0x447FFF, Country{"AT", "Austria"}},
+	{0x448000, 0x44FFFF, Country{"BE", "Belgium"}},
+	{0x450000, 0x457FFF, Country{"BG", "Bulgaria"}},
+	{0x458000, 0x45FFFF, Country{"DK", "Denmark"}},
+	{0x460000, 0x467FFF, Country{"FI", "Finland"}},
+	{0x468000, 0x46FFFF, Country{"GR", "Greece"}},
+	{0x470000, 0x477FFF, Country{"HU", "Hungary"}},
+	{0x478000, 0x47FFFF, Country{"NO", "Norway"}},
+	{0x480000, 0x487FFF, Country{"NL", "Netherlands"}},
+	{0x488000, 0x48FFFF, Country{"PL", "Poland"}},
+	{0x490000, 0x497FFF, Country{"PT", "Portugal"}},
+	{0x498000, 0x49FFFF, Country{"CZ", "Czechia"}},
+	{0x4A0000, 0x4A7FFF, Country{"RO", "Romania"}},
+	{0x4A8000, 0x4AFFFF, Country{"SE", "Sweden"}},
+	{0x4B0000, 0x4B7FFF, Country{"CH", "Switzerland"}},
+	{0x4B8000, 0x4BFFFF, Country{"TR", "Turkey"}},
+	{0x4C0000, 0x4C7FFF, Country{"RS", "Serbia"}},
+	{0x4C8000, 0x4C83FF, Country{"CY", "Cyprus"}},
+	{0x4CA000, 0x4CAFFF, Country{"IE", "Ireland"}},
+	{0x4CC000, 0x4CCFFF, Country{"IS", "Iceland"}},
+	{0x4D0000, 0x4D03FF, Country{"LU", "Luxembourg"}},
+	{0x4D2000, 0x4D23FF, Country{"MT", "Malta"}},
+	{0x4D4000, 0x4D43FF, Country{"MC", "Monaco"}},
+	{0x500000, 0x5003FF, Country{"SM", "San Marino"}},
+	{0x501000, 0x5013FF, Country{"AL", "Albania"}},
+	{0x501C00, 0x501FFF, Country{"HR", "Croatia"}},
+	{0x502C00, 0x502FFF, Country{"LV", "Latvia"}},
+	{0x503C00, 0x503FFF, Country{"LT", "Lithuania"}},
+	{0x504C00, 0x504FFF, Country{"MD", "Moldova"}},
+	{0x505C00, 0x505FFF, Country{"SK", "Slovakia"}},
+	{0x506C00, 0x506FFF, Country{"SI", "Slovenia"}},
+	{0x507C00, 0x507FFF, Country{"UZ", "Uzbekistan"}},
+	{0x508000, 0x50FFFF, Country{"UA", "Ukraine"}},
+	{0x510000, 0x5103FF, Country{"BY", "Belarus"}},
+	{0x511000, 0x5113FF, Country{"EE", "Estonia"}},
+	{0x512000, 0x5123FF, Country{"MK", "North Macedonia"}},
+	{0x513000, 0x5133FF, Country{"BA", "Bosnia and Herzegovina"}},
+	{0x514000, 0x5143FF, Country{"GE", "Georgia"}},
+	{0x515000, 0x5153FF, Country{"TJ", "Tajikistan"}},
+	{0x516000, 0x5163FF, Country{"ME", "Montenegro"}},
+	{0x600000, 0x6003FF, Country{"AM", "Armenia"}},
+	{0x600800, 0x600BFF, Country{"AZ", "Azerbaijan"}},
+	{0x601000, 0x6013FF, Country{"KG", "Kyrgyzstan"}},
+	{0x601800, 0x601BFF, Country{"TM", "Turkmenistan"}},
+	{0x680000, 0x6803FF, Country{"BT", "Bhutan"}},
+	{0x681000, 0x6813FF, Country{"FM", "Micronesia"}},
+	{0x682000, 0x6823FF, Country{"MN", "Mongolia"}},
+	{0x683000, 0x6833FF, Country{"KZ", "Kazakhstan"}},
+	{0x684000, 0x6843FF, Country{"PW", "Palau"}},
+	{0x700000, 0x700FFF, Country{"AF", "Afghanistan"}},
+	{0x702000, 0x702FFF, Country{"BD", "Bangladesh"}},
+	{0x704000, 0x704FFF, Country{"MM", "Myanmar"}},
+	{0x706000, 0x706FFF, Country{"KW", "Kuwait"}},
+	{0x708000, 0x708FFF, Country{"LA", "Laos"}},
+	{0x70A000, 0x70AFFF, Country{"NP", "Nepal"}},
+	{0x70C000, 0x70C3FF, Country{"OM", "Oman"}},
+	{0x70E000, 0x70EFFF, Country{"KH", "Cambodia"}},
+	{0x710000, 0x717FFF, Country{"SA", "Saudi Arabia"}},
+	{0x718000, 0x71FFFF, Country{"KR", "South Korea"}},
+	{0x720000, 0x727FFF, Country{"KP", "North Korea"}},
+	{0x728000, 0x72FFFF, Country{"IQ", "Iraq"}},
+	{0x730000, 0x737FFF, Country{"IR", "Iran"}},
+	{0x738000, 0x73FFFF, Country{"IL", "Israel"}},
+	{0x740000, 0x747FFF, Country{"JO", "Jordan"}},
+	{0x748000, 0x74FFFF, Country{"LB", "Lebanon"}},
+	{0x750000, 0x757FFF, Country{"MY", "Malaysia"}},
+	{0x758000, 0x75FFFF, Country{"PH", "Philippines"}},
+	{0x760000, 0x767FFF, Country{"PK", "Pakistan"}},
+	{0x768000, 0x76FFFF, Country{"SG", "Singapore"}},
+	{0x770000, 0x777FFF, Country{"LK", "Sri Lanka"}},
+	{0x778000, 0x77FFFF, Country{"SY", "Syria"}},
+	{0x780000, 0x7BFFFF, Country{"CN", "China"}},
+	{0x7C0000, 0x7FFFFF, Country{"AU", "Australia"}},
+	{0x800000, 0x83FFFF, Country{"IN", "India"}},
+	{0x840000, 0x87FFFF, Country{"JP", "Japan"}},
+	{0x880000, 0x887FFF, Country{"TH", "Thailand"}},
+	{0x888000, 0x88FFFF, Country{"VN", "Vietnam"}},
+	{0x890000, 0x890FFF, Country{"YE", "Yemen"}},
+	{0x894000, 0x894FFF, Country{"BH", "Bahrain"}},
+	{0x895000, 0x8953FF, Country{"BN", "Brunei"}},
+	{0x896000, 0x896FFF, Country{"AE", "United Arab Emirates"}},
+	{0x897000, 0x8973FF, Country{"SB", "Solomon Islands"}},
+	{0x898000, 0x898FFF, Country{"PG", "Papua New Guinea"}},
+	{0x899000, 0x8993FF, Country{"TW", "Taiwan"}},
+	{0x8A0000, 0x8A7FFF, Country{"ID", "Indonesia"}},
+	{0x900000, 0x9003FF, Country{"MH", "Marshall Islands"}},
+	{0x901000, 0x9013FF, Country{"CK", "Cook Islands"}},
+	{0x902000, 0x9023FF, Country{"WS", "Samoa"}},
+	{0xA00000, 0xAFFFFF, Country{"US", "United States"}},
+	{0xC00000, 0xC3FFFF, Country{"CA", "Canada"}},
+	{0xC80000, 0xC87FFF, Country{"NZ", "New Zealand"}},
+	{0xC88000, 0xC88FFF, Country{"FJ", "Fiji"}},
+	{0xC8A000, 0xC8A3FF, Country{"NR", "Nauru"}},
+	{0xC8C000, 0xC8C3FF, Country{"LC", "Saint Lucia"}},
+	{0xC8D000, 0xC8D3FF, Country{"TO", "Tonga"}},
+	{0xC8E000, 0xC8E3FF, Country{"KI", "Kiribati"}},
+	{0xC90000, 0xC903FF, Country{"VU", "Vanuatu"}},
+	{0xE00000, 0xE3FFFF, Country{"AR", "Argentina"}},
+	{0xE40000, 0xE7FFFF, Country{"BR", "Brazil"}},
+	{0xE80000, 0xE80FFF, Country{"CL", "Chile"}},
+	{0xE84000, 0xE84FFF, Country{"EC", "Ecuador"}},
+	{0xE88000, 0xE88FFF, Country{"PY", "Paraguay"}},
+	{0xE8C000, 0xE8CFFF, Country{"PE", "Peru"}},
+	{0xE90000, 0xE90FFF, Country{"UY", "Uruguay"}},
+	{0xE94000, 0xE94FFF, Country{"BO", "Bolivia"}},
+}
+
+// CountryOf looks up the country an ICAO address (in hex, as SBS gives it)
+// is allocated to. ok is false for bad addresses and unallocated blocks.
+func CountryOf(hex string) (Country, bool) {
+	addr, err := strconv.ParseUint(hex, 16, 24)
+	if err != nil {
+		return Country{}, false
+	}
+	a := uint32(addr)
+	i := sort.Search(len(blocks), func(i int) bool { return blocks[i].end >= a })
+	if i < len(blocks) && blocks[i].start <= a {
+		return blocks[i].country, true
+	}
+	return Country{}, false
+}
+
+// Flag is the country's flag emoji: its code spelled in regional
+// indicator letters, which terminals with emoji fonts draw as a flag
+func (c Country) Flag() string {
+	if len(c.Code) != 2 {
+		return ""
+	}
+	const regionalA = 0x1F1E6
+	return string([]rune{regionalA + rune(c.Code[0]-'A'), regionalA + rune(c.Code[1]-'A')})
+}
@@ -0,0 +1,61 @@
+package icao
+
+import "strconv"
+
+// militaryBlocks are the parts of countries' allocations set aside for
+// military aircraft, as the community trackers list them. It's not
+// official and it's not complete: plenty of air forces fly out of their
+// country's civil block. Sorted by start address.
+var militaryBlocks = []struct{ start, end uint32 }{
+	{0x010070, 0x01008F}, // Egypt
+	{0x0A4000, 0x0A4FFF}, // Algeria
+	{0x33FF00, 0x33FFFF}, // Italy
+	{0x350000, 0x37FFFF}, // Spain
+	{0x3A8000, 0x3AFFFF}, // France
+	{0x3B0000, 0x3BFFFF}, // France
+	{0x3EA000, 0x3EBFFF}, // Germany
+	{0x3F4000, 0x3FBFFF}, // Germany
+	{0x400000, 0x40003F}, // United Kingdom
+	{0x43C000, 0x43CFFF}, // United Kingdom
+	{0x444000, 0x446FFF}, // Austria
+	{0x44F000, 0x44FFFF}, // Belgium
+	{0x457000, 0x457FFF}, // Bulgaria
+	{0x45F400, 0x45F4FF}, // Denmark
+	{0x468000, 0x4683FF}, // Greece
+	{0x473C00, 0x473C0F}, // Hungary
+	{0x478100, 0x4781FF}, // Norway
+	{0x480000, 0x480FFF}, // Netherlands
+	{0x48D800, 0x48D87F}, // Poland
+	{0x497C00, 0x497CFF}, // Portugal
+	{0x498420, 0x49842F}, // Czechia
+	{0x4B7000, 0x4B7FFF}, // Switzerland
+	{0x4B8200, 0x4B82FF}, // Turkey
+	{0x506F00, 0x506FFF}, // Slovenia
+	{0x70C070, 0x70C07F}, // Oman
+	{0x710258, 0x71028F}, // Saudi Arabia
+	{0x710380, 0x71039F}, // Saudi Arabia
+	{0x738A00, 0x738AFF}, // Israel
+	{0x7C822E, 0x7C84FF}, // Australia
+	{0x7C8800, 0x7C88FF}, // Australia
+	{0x7C9000, 0x7CBFFF}, // Australia
+	{0x7D0000, 0x7FFFFF}, // Australia
+	{0xADF7C8, 0xAFFFFF}, // United States
+	{0xC20000, 0xC3FFFF}, // Canada
+	{0xE40000, 0xE41FFF}, // Brazil
+}
+
+// IsMilitary reports whether an address (in hex) is in a block known to
+// be used by military aircraft
+func IsMilitary(hex string) bool {
+	addr, err := strconv.ParseUint(hex, 16, 24)
+	if err != nil {
+		return false
+	}
+	a := uint32(addr)
+	for _, b := range militaryBlocks {
+		if a >= b.start && a <= b.end {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package icao
+
+import "strings"
+
+// LikelyPIA reports whether an aircraft looks to be on an FAA Privacy ICAO
+// Address. Those come out of the same pool as N-numbers, so no address
+// gives one away; what does is a US address the registration database
+// hasn't got (registered says whether it has), flying with its callsign
+// blocked.
+func LikelyPIA(hex, callsign string, registered bool) bool {
+	c, ok := CountryOf(hex)
+	return ok && c.Code == "US" && !registered && BlockedCallsign(callsign)
+}
+
+// BlockedCallsign reports whether a callsign is a placeholder standing in
+// for one that's been withheld, like "BLOCKED" or a row of zeros
+func BlockedCallsign(callsign string) bool {
+	callsign = strings.TrimSpace(callsign)
+	if callsign == "" {
+		return false
+	}
+	if strings.EqualFold(callsign, "BLOCKED") {
+		return true
+	}
+	return strings.Trim(callsign, "0?@*") == ""
+}
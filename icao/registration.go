@@ -0,0 +1,217 @@
+package icao
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Some countries hand out addresses in step with registrations, so the one
+// can be worked out from the other without a database. These are the ones
+// where that's held up: the US, Canada, and German airliners (D-A).
+
+// --- United States ---
+
+// The US block runs N1, N1A, N1AA, N1AB ... N1Z, N1ZZ, N10, N10A ... from
+// A00001, every registration FAA rules allow in order. Letters skip I and
+// O, and there are at most two of them, only at the end.
+const (
+	nStart   = 0xA00001
+	nLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+
+	// nSuffixes is the count of letter endings: none, then A, AA ... AZ,
+	// B ... ZZ
+	nSuffixes = 1 + len(nLetters)*(1+len(nLetters))
+)
+
+// nSizes is how many registrations there are under each digit, by how
+// many digits have come before it. After the fourth digit there's room for
+// one letter or a fifth digit, nothing more.
+var nSizes = [...]int{
+	nSuffixes + 10*(nSuffixes+10*(nSuffixes+10*(1+len(nLetters)+10))),
+	nSuffixes + 10*(nSuffixes+10*(1+len(nLetters)+10)),
+	nSuffixes + 10*(1+len(nLetters)+10),
+	1 + len(nLetters) + 10,
+}
+
+// nNumber is the N-number for an offset into the US block
+func nNumber(offset int) (string, bool) {
+	if offset < 0 || offset >= 9*nSizes[0] {
+		return "", false
+	}
+	reg := "N" + strconv.Itoa(1+offset/nSizes[0])
+	offset %= nSizes[0]
+	for digits := 1; ; digits++ {
+		if digits == len(nSizes) {
+			// One letter, or a fifth digit
+			switch {
+			case offset == 0:
+			case offset <= len(nLetters):
+				reg += nLetters[offset-1 : offset]
+			default:
+				reg += strconv.Itoa(offset - 1 - len(nLetters))
+			}
+			return reg, true
+		}
+		if offset < nSuffixes {
+			if offset > 0 {
+				offset--
+				reg += nLetters[offset/(1+len(nLetters)) : offset/(1+len(nLetters))+1]
+				if second := offset % (1 + len(nLetters)); second > 0 {
+					reg += nLetters[second-1 : second]
+				}
+			}
+			return reg, true
+		}
+		offset -= nSuffixes
+		reg += strconv.Itoa(offset / nSizes[digits])
+		offset %= nSizes[digits]
+	}
+}
+
+// nOffset is where an N-number falls in the US block
+func nOffset(reg string) (int, bool) {
+	rest, ok := strings.CutPrefix(reg, "N")
+	if !ok || rest == "" || rest[0] < '1' || rest[0] > '9' {
+		return 0, false
+	}
+	offset := int(rest[0]-'1') * nSizes[0]
+	rest = rest[1:]
+	for digits := 1; ; digits++ {
+		if rest == "" {
+			return offset, true
+		}
+		if digits == len(nSizes) {
+			if len(rest) != 1 {
+				return 0, false
+			}
+			if i := strings.IndexByte(nLetters, rest[0]); i >= 0 {
+				return offset + 1 + i, true
+			}
+			if rest[0] >= '0' && rest[0] <= '9' {
+				return offset + 1 + len(nLetters) + int(rest[0]-'0'), true
+			}
+			return 0, false
+		}
+		if rest[0] < '0' || rest[0] > '9' {
+			// The letters, and then it has to end
+			first := strings.IndexByte(nLetters, rest[0])
+			if first < 0 || len(rest) > 2 {
+				return 0, false
+			}
+			offset += 1 + first*(1+len(nLetters))
+			if len(rest) == 2 {
+				second := strings.IndexByte(nLetters, rest[1])
+				if second < 0 {
+					return 0, false
+				}
+				offset += 1 + second
+			}
+			return offset, true
+		}
+		offset += nSuffixes + int(rest[0]-'0')*nSizes[digits]
+		rest = rest[1:]
+	}
+}
+
+// --- Letter registrations ---
+
+// letterBlock is a run of registrations of a prefix and three letters from
+// AAA, counted like a number in a base of its own: the address steps by s1
+// for the first letter, s2 for the second and 1 for the third. Some blocks
+// leave gaps between letters, which is why they're not always 26.
+type letterBlock struct {
+	prefix string
+	start  uint32 // The address of prefix+"AAA"
+	s1, s2 uint32
+	last   string // The last letters the block covers
+}
+
+var letterBlocks = []letterBlock{
+	{"C-F", 0xC00001, 26 * 26, 26, "ZZZ"},
+	{"C-G", 0xC044A9, 26 * 26, 26, "ZZZ"},
+	{"D-A", 0x3C4421, 1024, 32, "OZZ"}, // Past D-AOZZ it falls out of step
+}
+
+// letters are the three letters' indexes, if that's what s is
+func letters(s string) (a, b, c uint32, ok bool) {
+	if len(s) != 3 {
+		return 0, 0, 0, false
+	}
+	for i := range 3 {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return 0, 0, 0, false
+		}
+	}
+	return uint32(s[0] - 'A'), uint32(s[1] - 'A'), uint32(s[2] - 'A'), true
+}
+
+// address is the address of the letters in the block
+func (b letterBlock) address(suffix string) (uint32, bool) {
+	x, y, z, ok := letters(suffix)
+	if !ok || suffix > b.last {
+		return 0, false
+	}
+	return b.start + x*b.s1 + y*b.s2 + z, true
+}
+
+// registration is the registration at addr, if the block has one there
+func (b letterBlock) registration(addr uint32) (string, bool) {
+	if addr < b.start {
+		return "", false
+	}
+	off := addr - b.start
+	x, y, z := off/b.s1, off%b.s1/b.s2, off%b.s2
+	if x >= 26 || y >= 26 || z >= 26 {
+		return "", false // Past the end, or in a gap
+	}
+	suffix := string([]byte{byte('A' + x), byte('A' + y), byte('A' + z)})
+	if suffix > b.last {
+		return "", false
+	}
+	return b.prefix + suffix, true
+}
+
+// Registration is the registration that goes with an address (in hex), for
+// the countries where it can be worked out. The US addresses above N99999
+// are military and have none.
+func Registration(hex string) (string, bool) {
+	addr, err := strconv.ParseUint(hex, 16, 24)
+	if err != nil {
+		return "", false
+	}
+	a := uint32(addr)
+	if reg, ok := nNumber(int(a) - nStart); ok {
+		return reg, true
+	}
+	for _, b := range letterBlocks {
+		if reg, ok := b.registration(a); ok {
+			return reg, true
+		}
+	}
+	return "", false
+}
+
+// Address is the address (in hex) that goes with a registration, for the
+// countries where it can be worked out. The dash is optional, as people
+// often leave it out.
+func Address(reg string) (string, bool) {
+	reg = strings.ToUpper(strings.TrimSpace(reg))
+	if off, ok := nOffset(reg); ok {
+		return fmt.Sprintf("%06X", nStart+off), true
+	}
+	for _, b := range letterBlocks {
+		bare := strings.ReplaceAll(b.prefix, "-", "")
+		suffix, ok := strings.CutPrefix(reg, b.prefix)
+		if !ok {
+			suffix, ok = strings.CutPrefix(reg, bare)
+		}
+		if !ok {
+			continue
+		}
+		if a, ok := b.address(suffix); ok {
+			return fmt.Sprintf("%06X", a), true
+		}
+	}
+	return "", false
+}
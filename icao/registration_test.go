@@ -0,0 +1,85 @@
+package icao
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRegistration(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want string // Empty for none
+	}{
+		{hex: "A00001", want: "N1"},
+		{hex: "A00002", want: "N1A"},
+		{hex: "A00003", want: "N1AA"},
+		{hex: "A0001B", want: "N1B"},
+		{hex: "A00259", want: "N1ZZ"},
+		{hex: "A0025A", want: "N10"},
+		{hex: "A061D9", want: "N12345"},
+		{hex: "ADF7C7", want: "N99999"},
+		{hex: "ADF7C8"}, // Military, past the last N-number
+		{hex: "A00000"},
+		{hex: "C00001", want: "C-FAAA"},
+		{hex: "C044A9", want: "C-GAAA"},
+		{hex: "3C4421", want: "D-AAAA"},
+		{hex: "3C7F5A", want: "D-AOZZ"},
+		{hex: "3C7F5B"}, // Past D-AOZZ
+		{hex: "4CA123"}, // Ireland, no pattern to it
+		{hex: "nothex"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			got, ok := Registration(tt.hex)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("got %q, %v; want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddress(t *testing.T) {
+	tests := []struct {
+		reg  string
+		want string // Empty for none
+	}{
+		{reg: "N1", want: "A00001"},
+		{reg: "n10", want: "A0025A"},
+		{reg: "N12345", want: "A061D9"},
+		{reg: "N99999", want: "ADF7C7"},
+		{reg: "N1ZZ", want: "A00259"},
+		{reg: "C-FAAA", want: "C00001"},
+		{reg: "CGAAA", want: "C044A9"},
+		{reg: "D-AOZZ", want: "3C7F5A"},
+		{reg: "N0"},
+		{reg: "N1I"},     // I and O aren't used
+		{reg: "N1ABC"},   // At most two letters
+		{reg: "N12AB3"},  // Letters only at the end
+		{reg: "N123456"}, // Five digits at most
+		{reg: "N1234AB"}, // Only one letter after four digits
+		{reg: "D-APAA"},  // Out of step past D-AOZZ
+		{reg: "G-ABCD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reg, func(t *testing.T) {
+			got, ok := Address(tt.reg)
+			if got != tt.want || ok != (tt.want != "") {
+				t.Errorf("got %q, %v; want %q", got, ok, tt.want)
+			}
+		})
+	}
+}
+
+// Every address in the US block goes to an N-number and back again
+func TestNNumberRoundTrip(t *testing.T) {
+	for addr := nStart; addr <= 0xADF7C7; addr++ {
+		hex := fmt.Sprintf("%06X", addr)
+		reg, ok := Registration(hex)
+		if !ok {
+			t.Fatalf("%s has no registration", hex)
+		}
+		if back, ok := Address(reg); back != hex || !ok {
+			t.Fatalf("%s is %s, which goes back to %q", hex, reg, back)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+
+	"termtrack/ui/legend"
+)
+
+// keyBinding is a key (or a few that go together) and what it does
+type keyBinding struct {
+	key    string // As msg.String() has it, or the few written together
+	does   string
+	toggle string // What "toggle" on the control socket calls it, if it can
+	footer bool   // Important enough for the footer, which has little room
+}
+
+// keyBindings are the keys the map and its panels take, the one list the
+// footer, the legend panel's key list and the control socket's "toggle"
+// are all made from
+var keyBindings = []keyBinding{
+	{key: "j/k/l/;", does: "Pan", footer: true},
+	{key: "K/L", does: "Zoom", footer: true},
+	{key: "r", does: "Reset"},
+	{key: "tab", does: "Select", footer: true},
+	{key: " ", does: "Freeze", toggle: "freeze"},
+	{key: "<", does: "Slower"},
+	{key: ">", does: "Faster"},
+	{key: "[", does: "Earlier"},
+	{key: "]", does: "Later"},
+	{key: "v", does: "Split", toggle: "split"},
+	{key: "x", does: "Cursor", toggle: "cursor"},
+	{key: "b", does: "Blocks", toggle: "blocks"},
+	{key: "T", does: "Trails", toggle: "trails"},
+	{key: "V", does: "Vectors", toggle: "vectors"},
+	{key: "B", does: "Braille", toggle: "braille"},
+	{key: "c", does: "Color", toggle: "color"},
+	{key: "i", does: "Info", toggle: "info"},
+	{key: "e", does: "ETA"},
+	{key: "E", does: "Cast"},
+	{key: "o", does: "Other map"},
+	{key: "f", does: "Fit trail"},
+	{key: "H", does: "Helicopters", toggle: "helicopters"},
+	{key: "s", does: "Stats", toggle: "stats"},
+	{key: "w", does: "Weather", toggle: "weather"},
+	{key: "g", does: "Geofences", toggle: "geofences"},
+	{key: "R", does: "Regulars", toggle: "regulars"},
+	{key: "S", does: "Arrivals", toggle: "arrivals"},
+	{key: "m", does: "Log", toggle: "log"},
+	{key: "Q", does: "Query log"},
+	{key: "d", does: "Perf", toggle: "perf"},
+	{key: "M", does: "Macro"},
+	{key: "a", does: "Mute", toggle: "mute"},
+	{key: "t", does: "Text", toggle: "text"},
+	{key: "N", does: "Theme", toggle: "theme"},
+	{key: "A", does: "Retry airports"},
+	{key: "q", does: "Quit", footer: true},
+	{key: "?", does: "Help", toggle: "legend", footer: true},
+}
+
+// keyName is how a key's written for people
+func keyName(key string) string {
+	switch key {
+	case " ":
+		return "space"
+	case "tab":
+		return "Tab"
+	}
+	return key
+}
+
+// toggleKey is the key that flips the layer or panel "toggle" calls name
+func toggleKey(name string) (string, bool) {
+	for _, b := range keyBindings {
+		if b.toggle != "" && b.toggle == strings.ToLower(name) {
+			return b.key, true
+		}
+	}
+	return "", false
+}
+
+// footerHelp is the footer's short list of keys, ending with the one that
+// brings up the rest, which is the last the footer will drop for room
+func footerHelp() string {
+	var parts []string
+	for _, b := range keyBindings {
+		if b.footer {
+			parts = append(parts, b.does+": "+keyName(b.key))
+		}
+	}
+	return strings.Join(parts, " | ")
+}
+
+// legendKeys is every key, for the legend panel
+func legendKeys() []legend.Key {
+	keys := make([]legend.Key, len(keyBindings))
+	for i, b := range keyBindings {
+		keys[i] = legend.Key{Key: keyName(b.key), Does: b.does}
+	}
+	return keys
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	shp "github.com/jonas-p/go-shp"
+
+	mapview "termtrack/ui/map"
+)
+
+// progressBarWidth is how wide the splash screen's bars are
+const progressBarWidth = 30
+
+// mapLoadedMsg is sent when the shapefiles have finished loading
+type mapLoadedMsg struct {
+	data *mapview.Data
+	err  error
+}
+
+// loadMapCmd loads the shapefiles in the background, counting into progress
+func loadMapCmd(mapPath, airportPath string, progress *mapview.LoadProgress) tea.Cmd {
+	return func() tea.Msg {
+		data, err := mapview.Load(mapPath, airportPath, progress)
+		return mapLoadedMsg{data: data, err: err}
+	}
+}
+
+// airportsLoadedMsg is sent when a retry of the airports layer finishes
+type airportsLoadedMsg struct {
+	points []*shp.Point
+	path   string
+	err    error
+}
+
+// retryAirportsCmd has another go at the airports layer. Unless the path
+// came from a flag or the environment, the config file is read again
+// first, so fixing the path there and pressing A is enough.
+func (m model) retryAirportsCmd() tea.Cmd {
+	path, configPath, fixed := m.airportPath, m.configPath, m.airportPathFixed
+	return func() tea.Msg {
+		if !fixed {
+			cfg, err := loadConfig(configPath, false)
+			if err != nil {
+				return airportsLoadedMsg{path: path, err: err}
+			}
+			if cfg.Airports != "" {
+				path = cfg.Airports
+			}
+		}
+		points, err := mapview.LoadAirports(path, nil)
+		return airportsLoadedMsg{points: points, path: path, err: err}
+	}
+}
+
+// airportNamesMsg carries the airports with their codes, for placing
+// weather reports and counting traffic
+type airportNamesMsg struct {
+	airports []mapview.Airport
+}
+
+// loadAirportNamesCmd reads the airports' codes and names from the
+// shapefile's .dbf. Without them the weather panel can't say how far
+// away each report is, but still shows them, and there's no traffic to
+// count; neither is worth stopping for, so errors are dropped.
+func loadAirportNamesCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		airports, _ := mapview.LoadAirportNames(path)
+		return airportNamesMsg{airports: airports}
+	}
+}
+
+// airportsWarning is the header's short note about missing airports
+func airportsWarning(err error) string {
+	reason := err.Error()
+	var loadErr *mapview.LoadError
+	if errors.As(err, &loadErr) && len(loadErr.Notes) > 0 {
+		reason = loadErr.Notes[0] // Says it plainer than the error does
+	}
+	return "NO AIRPORTS: " + reason + " (A to retry)"
+}
+
+// progressBar draws one dataset's row on the splash screen
+func (m model) progressBar(name string, p *mapview.Progress) string {
+	done, total := p.Counts()
+	filled := 0
+	if total > 0 {
+		filled = int(float64(done) / float64(total) * progressBarWidth)
+	}
+	bar := strings.Repeat(m.glyphs.BarFull, filled) + strings.Repeat(m.glyphs.BarEmpty, progressBarWidth-filled)
+
+	count := "reading..."
+	if total > 0 {
+		count = fmt.Sprintf("%d / %d shapes", done, total)
+	}
+	return fmt.Sprintf("%-9s %s  %s", name, bar, count)
+}
+
+// loadingView is the splash screen shown while the map loads
+func (m model) loadingView() string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	feed := "Feed      connecting to " + m.feedAddr + "..."
+	if m.connected {
+		feed = "Feed      connected to " + m.feedAddr
+	}
+
+	rows := []string{
+		titleStyle.Render("TermTrack"),
+		"",
+		m.progressBar("Basemap", &m.loadProgress.Basemap),
+		m.progressBar("Airports", &m.loadProgress.Airports),
+		"",
+		dimStyle.Render(feed),
+	}
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+// loadErrors picks the dataset failures out of an error, if that's what it is
+func loadErrors(err error) []*mapview.LoadError {
+	var errs []error
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	} else if err != nil {
+		errs = []error{err}
+	}
+
+	var out []*mapview.LoadError
+	for _, e := range errs {
+		var loadErr *mapview.LoadError
+		if errors.As(e, &loadErr) {
+			out = append(out, loadErr)
+		}
+	}
+	return out
+}
+
+// loadFailedView explains which datasets didn't load and what we found
+// out about them, instead of the one-line error screen
+func (m model) loadFailedView(errs []*mapview.LoadError) string {
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
+	nameStyle := lipgloss.NewStyle().Bold(true)
+	noteStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+
+	rows := []string{titleStyle.Render("Couldn't load the map data"), ""}
+	for _, e := range errs {
+		rows = append(rows,
+			nameStyle.Render(e.Dataset)+" "+e.Path,
+			"  "+e.Err.Error(),
+		)
+		for _, n := range e.Notes {
+			rows = append(rows, noteStyle.Render("  - "+n))
+		}
+		rows = append(rows, "")
+	}
+	rows = append(rows, "The paths can be set with -map and -airports.", "", "Press any key to quit.")
+
+	box := lipgloss.NewStyle().
+		Border(m.glyphs.Border, true).
+		BorderForeground(lipgloss.Color("9")).
+		Padding(1, 2).
+		MaxWidth(m.width).
+		Render(strings.Join(rows, "\n"))
+	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, box)
+}
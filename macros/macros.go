@@ -0,0 +1,95 @@
+// Package macros keeps recorded key sequences, each bound to a function
+// key that plays it back, so a view that takes a dozen keys to set up
+// takes one. They're kept between sessions in a JSON file.
+package macros
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Keys are the keys macros can be bound to
+var Keys = []string{"f1", "f2", "f3", "f4", "f5", "f6", "f7", "f8", "f9", "f10", "f11", "f12"}
+
+// Bindable reports whether key can have a macro bound to it
+func Bindable(key string) bool {
+	return slices.Contains(Keys, key)
+}
+
+// Book is the macros, by the key that plays each
+type Book struct {
+	path   string
+	macros map[string][]string // Keys named as tea.KeyMsg.String() has them
+}
+
+// Load reads the macros saved at path. A missing file is an empty book,
+// and an empty path one that's never saved.
+func Load(path string) (*Book, error) {
+	b := &Book{path: path, macros: make(map[string][]string)}
+	if path == "" {
+		return b, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("macros: %w", err)
+	}
+	if err := json.Unmarshal(data, &b.macros); err != nil {
+		return nil, fmt.Errorf("macros %s: %w", path, err)
+	}
+	for key := range b.macros {
+		if !Bindable(key) {
+			return nil, fmt.Errorf("macros %s: %q isn't a key macros go on (%s)", path, key, strings.Join(Keys, ", "))
+		}
+	}
+	return b, nil
+}
+
+// Save writes the macros back to where they were loaded from
+func (b *Book) Save() error {
+	if b.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(b.macros, "", "  ")
+	if err != nil {
+		return fmt.Errorf("macros: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(b.path), 0o755); err != nil {
+		return fmt.Errorf("macros: %w", err)
+	}
+
+	// Write then rename, so a crash can't leave half a file
+	tmp := b.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("macros: %w", err)
+	}
+	if err := os.Rename(tmp, b.path); err != nil {
+		return fmt.Errorf("macros: %w", err)
+	}
+	return nil
+}
+
+// Bind puts a macro on key, replacing any already there. An empty one
+// just takes it off.
+func (b *Book) Bind(key string, keys []string) {
+	if len(keys) == 0 {
+		delete(b.macros, key)
+		return
+	}
+	b.macros[key] = slices.Clone(keys)
+}
+
+// Get is the macro on key, if there is one
+func (b *Book) Get(key string) ([]string, bool) {
+	keys, ok := b.macros[key]
+	return keys, ok
+}
@@ -1,108 +1,612 @@
 package main
 
 import (
-	"bufio"
+	"cmp"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
-	"net" // <-- Import 'net'
+	"maps"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
 	"time"
 
+	"termtrack/aircraftdb"
+	"termtrack/announce"
+	"termtrack/api"
+	"termtrack/control"
+	"termtrack/dbupdate"
+	"termtrack/dump1090"
+	"termtrack/export"
+	"termtrack/flightlog"
+	"termtrack/geo"
+	"termtrack/geofence"
+	"termtrack/macros"
+	"termtrack/photo"
+	"termtrack/rules"
 	"termtrack/sbs"
+	"termtrack/sequence"
+	"termtrack/sightings"
+	"termtrack/tar1090"
+	"termtrack/traffic"
+	"termtrack/uat"
+	"termtrack/ui/arrivals"
+	"termtrack/ui/detail"
+	"termtrack/ui/fences"
 	"termtrack/ui/footer"
+	"termtrack/ui/glyphs"
 	"termtrack/ui/header"
+	"termtrack/ui/legend"
 	mapview "termtrack/ui/map"
+	"termtrack/ui/perf"
+	"termtrack/ui/query"
+	"termtrack/ui/rawlog"
+	"termtrack/ui/regulars"
+	"termtrack/ui/stats"
+	"termtrack/ui/textview"
+	"termtrack/ui/theme"
+	"termtrack/ui/weather"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
-// mapShapePath is the path to your downloaded shapefile
-const mapShapePath = "mapdata/ne_10m_admin_1_states_provinces.shp"
+// defaultMapPath is the path to your downloaded shapefile
+const defaultMapPath = "mapdata/ne_10m_admin_1_states_provinces.shp"
+
+// historyStep is how far [ and ] move the picture through history
+const historyStep = 15 * time.Second
+
+// notableFlash is how long a notable sighting (or a resume) stays in the header
+const notableFlash = 10 * time.Second
+
+// minSuspend is how long the machine has to have been asleep between
+// ticks for us to say so
+const minSuspend = 10 * time.Second
+
+// rawLogLines is how many raw feed lines we keep for the message log
+const rawLogLines = 2000
+
+// sidebarWidth is the width of the panel on the right of the map
+const sidebarWidth = 36
+
+// compactWidth is the narrowest the full layout fits in. Below it we draw
+// just the map under a one-line status, leaving out the footer and panels
+// until there's room again.
+const compactWidth = 60
+
+// statsPollInterval is how often we fetch the receiver's stats.json
+const statsPollInterval = 10 * time.Second
+
+// sidebar is which panel (if any) is open to the right of the map
+type sidebar int
+
+const (
+	sidebarNone sidebar = iota
+	sidebarStats
+	sidebarDetail
+	sidebarWeather
+	sidebarFences
+	sidebarLegend
+	sidebarRegulars
+	sidebarArrivals
+)
 
 // model holds the application's state
 type model struct {
 	width  int // Terminal width
 	height int // Terminal height
 
-	headerModel header.Model
-	mapModel    mapview.Model
-	footerModel footer.Model
+	headerModel   header.Model
+	mapModel      mapview.Model
+	splitModel    mapview.Model // Beside mapModel while split
+	footerModel   footer.Model
+	rawLogModel   rawlog.Model
+	textModel     textview.Model
+	statsModel    stats.Model
+	detailModel   detail.Model
+	weatherModel  weather.Model
+	fencesModel   fences.Model
+	legendModel   legend.Model
+	regularsModel regulars.Model
+	arrivalsModel arrivals.Model
+	queryModel    query.Model
+
+	showRawLog bool          // Is the raw message log panel open?
+	split      bool          // Two maps side by side, each with its own view
+	splitFocus bool          // Keys go to the second map
+	showQuery  bool          // The log query console in place of the map
+	textMode   bool          // Screen-reader friendly list instead of the map
+	showPerf   bool          // Frame timing overlay
+	frameRate  time.Duration // Between render ticks
+	sidebar    sidebar
+	shift      time.Duration // How far behind live the picture is, 0 when live
+
+	statsURL string      // dump1090/readsb stats.json to poll, if any
+	feedAddr string      // SBS feed to connect to
+	replay   *sbs.Player // The recording being played instead, with -replay
+	uatAddr  string      // dump978 raw output to read weather from, if any
+
+	weather *uat.Weather // Filled by its own goroutine once connected
+	uatErr  error        // Why we couldn't connect to uatAddr, if we couldn't
+
+	// --- Loading ---
+	// The shapefiles load in the background while the feed starts up
+	mapPath          string
+	airportPath      string
+	loading          bool
+	loadProgress     *mapview.LoadProgress
+	airportsErr      error  // Why the airports layer is missing, if it is
+	configPath       string // For re-reading the airports path on a retry
+	airportPathFixed bool   // Set by flag or environment, so the config can't change it
+	connected        bool   // For the splash; errors replace it anyway
+
+	photos   bool   // Fetch photos of the selected aircraft for the detail panel
+	photoFor string // The ICAO we last fetched a photo for
+
+	receiver location       // Where the receiver is, if we've been told
+	eta      *detail.Target // From the config; e swaps it for the crosshair
+
+	perf *perf.Stats // A pointer, so View() can record into it
+
+	announcer *announce.Announcer // Speaks events, if -announce was given
+	control   *control.Listener   // Takes commands from outside, if -control was given
+	sightings *sightings.Tracker  // Notable firsts, kept between sessions
+	traffic   *traffic.Counter    // Arrivals and departures by airport
+	sequencer *sequence.Sequencer // Arrivals lined up on the config's approaches
+	geofences *geofence.Monitor   // Who's in each of the config's geofences
+	rules     *rules.Engine       // The config's alert rules
+	alert     rules.Alert         // The latest, flashed in the header
+	emergency map[string]string   // What each aircraft squawking an emergency code says is wrong, by ICAO
+	exporter  *export.Exporter    // Writes the exports in the config, if any
+	logger    *flightlog.Logger   // Logs positions to SQLite, if -log-db was given
+	recorder  *sbs.Recorder       // Records the raw lines, if -record was given
+	grpc      *api.Listener       // Streams alerts to API clients, if -grpc was given
+	updater   *dbupdate.Updater   // Keeps the config's databases fresh, if it has any
+	lookup    *aircraftdb.DB      // Registrations and types from those databases, nil if none are for that
+	notable   sightings.Event     // The latest, flashed in the header
+	cast      castMsg             // The last cast saved with E, flashed in the header
+	castDir   string              // Where E saves casts
+
+	// --- Macros ---
+	macros    *macros.Book
+	recording bool     // Keys are being recorded into macro
+	binding   bool     // Recording's done; the next key is where it goes
+	macro     []string // Keys as msg.String() has them
+
+	glyphs glyphs.Set
+
+	// --- Theme ---
+	theme      theme.Theme // What we draw with, unless autoTheme
+	autoTheme  bool        // Day in daylight at the receiver, nightTheme after sunset
+	nightTheme theme.Theme
 
 	// --- SBS State ---
-	sbsScanner *bufio.Scanner
-	sbsConn    net.Conn // <-- Store the connection
-	aircraft   map[string]*sbs.Aircraft
+	// The feed writes into the store on its own goroutine; we take a
+	// snapshot of it every render tick.
+	store    *sbs.Store
+	feed     *sbs.Feed
+	feeds    []*sbs.Feed // The config's, beside the main one
+	lineLog  *sbs.LineLog
+	aircraft map[string]*sbs.Aircraft // Latest snapshot
+	version  uint64                   // The store's, as of aircraft; 0 to take it all again
+	selected string                   // ICAO of the selected aircraft
+
+	initialPositionFound bool
 
-	initialPositionFound bool // <-- 1. ADD THIS FLAG
+	lastTick time.Time     // For spotting a suspend between ticks
+	resumed  time.Time     // When we woke from the last suspend
+	slept    time.Duration // How long that was
 	// ---------------
 
 	err error // Store any errors
 }
 
+// options is what initialModel needs from the command line
+type options struct {
+	feedAddr         string
+	mapPath          string
+	airportPath      string
+	statsURL         string
+	uatAddr          string
+	configPath       string
+	airportPathFixed bool
+	receiver         location
+	replay           *sbs.Player // Played instead of connecting to feedAddr, if set
+}
+
 // initialModel creates the starting model
-func initialModel() model {
-	// Create the map model
-	mapMod, err := mapview.New(mapShapePath)
-	if err != nil {
-		return model{err: err} // Store the loading error
-	}
+func initialModel(opts options, store *sbs.Store, feed *sbs.Feed) model {
+	// Create the map model; its shapefiles load in the background
+	mapMod := mapview.New()
 
 	// Create the footer model
-	footerMod := footer.New(mapShapePath)
+	footerMod := footer.New(opts.mapPath)
 
 	// Create the header model
 	headerMod := header.New()
 
-	// Set the initial zoom on the footer
+	// Set the initial zoom and the keys on the footer
 	footerMod.SetZoom(mapMod.GetZoomLevel())
+	footerMod.SetHelp(footerHelp())
+
+	// The raw message log listens in on the feed
+	lineLog := sbs.NewLineLog(rawLogLines)
+	feed.OnLine(lineLog.Append)
 
 	return model{
-		headerModel: headerMod,
-		mapModel:    mapMod,
-		footerModel: footerMod,
-		aircraft:    make(map[string]*sbs.Aircraft),
+		headerModel:      headerMod,
+		mapModel:         mapMod,
+		footerModel:      footerMod,
+		rawLogModel:      rawlog.New(feed.Name(), rawLogLines),
+		textModel:        textview.New(),
+		statsModel:       stats.New(opts.statsURL),
+		detailModel:      detail.New(),
+		weatherModel:     weather.New(opts.uatAddr),
+		fencesModel:      fences.New(),
+		legendModel:      legend.New(legendKeys()),
+		regularsModel:    regulars.New(),
+		arrivalsModel:    arrivals.New(),
+		uatAddr:          opts.uatAddr,
+		weather:          uat.NewWeather(),
+		traffic:          traffic.New(),
+		sequencer:        sequence.New(),
+		geofences:        geofence.NewMonitor(nil),
+		rules:            rules.New(nil, nil),
+		statsURL:         opts.statsURL,
+		mapPath:          opts.mapPath,
+		airportPath:      opts.airportPath,
+		configPath:       opts.configPath,
+		airportPathFixed: opts.airportPathFixed,
+		loading:          true,
+		loadProgress:     &mapview.LoadProgress{},
+		feedAddr:         opts.feedAddr,
+		replay:           opts.replay,
+		receiver:         opts.receiver,
+		perf:             perf.New(),
+		frameRate:        renderFrameRate,
+		store:            store,
+		feed:             feed,
+		lineLog:          lineLog,
+		aircraft:         make(map[string]*sbs.Aircraft),
 		// initialPositionFound is 'false' by default
 	}
 }
 
 func (m model) Init() tea.Cmd {
 	// Start BOTH the connection AND the render ticker
-	return tea.Batch(
-		sbs.ConnectCmd(),
-		TickCmd(),
-	)
+	cmds := []tea.Cmd{
+		loadMapCmd(m.mapPath, m.airportPath, m.loadProgress),
+		m.connectCmd(),
+		TickCmd(m.frameRate),
+	}
+	if m.statsURL != "" {
+		cmds = append(cmds, dump1090.PollStatsCmd(m.statsURL, 0))
+	}
+	if m.control != nil {
+		cmds = append(cmds, m.control.WaitCmd())
+	}
+	if m.uatAddr != "" {
+		cmds = append(cmds, uat.ConnectCmd(m.uatAddr))
+	}
+	cmds = append(cmds, loadAirportNamesCmd(m.airportPath))
+	return tea.Batch(cmds...)
 }
 
-// mergeAircraft is a helper to update the master aircraft list
-func (m *model) mergeAircraft(update *sbs.Aircraft) {
-	if update == nil {
-		return
+// layout sizes each child to fit the terminal
+func (m *model) layout() []tea.Cmd {
+	var cmds []tea.Cmd
+
+	compact := m.compact()
+	headerHeight := 1
+	footerHeight := m.footerModel.Height()
+	if compact {
+		footerHeight = 0
+	}
+	rawLogHeight := 0
+	if m.showRawLog && !compact {
+		rawLogHeight = m.height / 3
 	}
+	perfHeight := 0
+	if m.showPerf && !compact {
+		perfHeight = 1
+	}
+	mapHeight := m.height - headerHeight - footerHeight - rawLogHeight - perfHeight
+
+	// The sidebar sits to the right of the map
+	mapWidth := m.width
+	if m.sidebar != sidebarNone && !compact {
+		mapWidth -= sidebarWidth
+	}
+	for _, mm := range m.maps() {
+		mm.SetCompact(compact)
+	}
+
+	// Send resized messages to children
+	var cmd tea.Cmd
+	m.headerModel, cmd = m.headerModel.Update(tea.WindowSizeMsg{Width: m.width, Height: headerHeight})
+	cmds = append(cmds, cmd)
 
-	// Get or create aircraft in our master list
-	ac, ok := m.aircraft[update.ICAO]
+	// Split, the two maps share the width, the first taking any odd column
+	if m.split && !compact {
+		splitWidth := mapWidth / 2
+		m.mapModel, cmd = m.mapModel.Update(tea.WindowSizeMsg{Width: mapWidth - splitWidth, Height: mapHeight})
+		cmds = append(cmds, cmd)
+		m.splitModel, cmd = m.splitModel.Update(tea.WindowSizeMsg{Width: splitWidth, Height: mapHeight})
+		cmds = append(cmds, cmd)
+	} else {
+		m.mapModel, cmd = m.mapModel.Update(tea.WindowSizeMsg{Width: mapWidth, Height: mapHeight})
+		cmds = append(cmds, cmd)
+	}
+
+	m.textModel, cmd = m.textModel.Update(tea.WindowSizeMsg{Width: mapWidth, Height: mapHeight})
+	cmds = append(cmds, cmd)
+
+	m.queryModel, cmd = m.queryModel.Update(tea.WindowSizeMsg{Width: mapWidth, Height: mapHeight})
+	cmds = append(cmds, cmd)
+
+	m.statsModel, cmd = m.statsModel.Update(tea.WindowSizeMsg{Width: sidebarWidth, Height: mapHeight})
+	cmds = append(cmds, cmd)
+
+	m.detailModel, cmd = m.detailModel.Update(tea.WindowSizeMsg{Width: sidebarWidth, Height: mapHeight})
+	cmds = append(cmds, cmd)
+	m.weatherModel, cmd = m.weatherModel.Update(tea.WindowSizeMsg{Width: sidebarWidth, Height: mapHeight})
+	cmds = append(cmds, cmd)
+	m.fencesModel, cmd = m.fencesModel.Update(tea.WindowSizeMsg{Width: sidebarWidth, Height: mapHeight})
+	cmds = append(cmds, cmd)
+	m.legendModel, cmd = m.legendModel.Update(tea.WindowSizeMsg{Width: sidebarWidth, Height: mapHeight})
+	cmds = append(cmds, cmd)
+	m.regularsModel, cmd = m.regularsModel.Update(tea.WindowSizeMsg{Width: sidebarWidth, Height: mapHeight})
+	cmds = append(cmds, cmd)
+	m.arrivalsModel, cmd = m.arrivalsModel.Update(tea.WindowSizeMsg{Width: sidebarWidth, Height: mapHeight})
+	cmds = append(cmds, cmd)
+
+	m.rawLogModel, cmd = m.rawLogModel.Update(tea.WindowSizeMsg{Width: m.width, Height: rawLogHeight})
+	cmds = append(cmds, cmd)
+
+	m.footerModel, cmd = m.footerModel.Update(tea.WindowSizeMsg{Width: m.width, Height: footerHeight})
+	cmds = append(cmds, cmd)
+
+	return cmds
+}
+
+// compact reports whether the terminal's too narrow for the full layout
+func (m *model) compact() bool {
+	return m.width < compactWidth
+}
+
+// reference is where distances are measured from: the receiver if we know
+// where it is, else the middle of the map
+func (m *model) reference() (lat, lon float64) {
+	if m.receiver.set {
+		return m.receiver.lat, m.receiver.lon
+	}
+	return m.mapModel.Center()
+}
+
+// cursorInfo is what the footer's info line says about the crosshair,
+// after its position: where it is from the receiver, and the aircraft and
+// airport nearest it
+func (m *model) cursorInfo() []string {
+	h, ok := m.focused().Hover()
 	if !ok {
-		ac = update // This is the first time we see it
-		m.aircraft[update.ICAO] = ac
-		return
+		return nil
+	}
+	var info []string
+	if m.receiver.set {
+		info = append(info, fmt.Sprintf("%.1fnm from receiver, bearing %03.0f",
+			geo.Distance(m.receiver.lat, m.receiver.lon, h.Lat, h.Lon),
+			geo.Bearing(m.receiver.lat, m.receiver.lon, h.Lat, h.Lon)))
+	}
+	if ac := h.Aircraft; ac != nil {
+		name := cmp.Or(ac.Callsign, ac.ICAO)
+		if ac.Has&sbs.HasAltitude != 0 {
+			name += fmt.Sprintf(" at %dft", ac.Altitude)
+		}
+		info = append(info, fmt.Sprintf("%s, %.1fnm away", name, geo.Distance(h.Lat, h.Lon, ac.Lat, ac.Lon)))
+	}
+	if a := h.Airport; a != nil {
+		name := a.Name
+		if code := cmp.Or(a.IATA, a.Code); code != "" {
+			name = code + " " + name
+		}
+		info = append(info, fmt.Sprintf("%s, %.1fnm away", name, geo.Distance(h.Lat, h.Lon, a.Lat, a.Lon)))
+	}
+	return info
+}
+
+// syncCursor passes the crosshair on to the footer, making room for its
+// info line if the crosshair's just come up or gone
+func (m *model) syncCursor() []tea.Cmd {
+	height := m.footerModel.Height()
+	m.footerModel.SetCursor(m.focused().Crosshair())
+	m.footerModel.SetCursorInfo(m.cursorInfo())
+	if m.footerModel.Height() != height {
+		return m.layout()
+	}
+	return nil
+}
+
+// syncEmergencies puts the aircraft squawking emergency codes on the
+// footer's alert line, sizing everything again when it comes or goes, and
+// says so in text mode when one starts
+func (m *model) syncEmergencies() []tea.Cmd {
+	emergency := make(map[string]string)
+	for hex, ac := range m.aircraft {
+		if what := ac.SquawkEmergency(); what != "" {
+			emergency[hex] = what
+		}
+	}
+	var lines []string
+	for _, hex := range slices.Sorted(maps.Keys(emergency)) {
+		ac, what := m.aircraft[hex], emergency[hex]
+		line := strings.TrimSpace(fmt.Sprintf("%s %s %s", cmp.Or(ac.Callsign, ac.ICAO), ac.Squawk, what))
+		lines = append(lines, line)
+		if m.emergency[hex] != what {
+			m.textModel.Alert(fmt.Sprintf("Emergency: %s.", line))
+		}
+	}
+	m.emergency = emergency
+
+	height := m.footerModel.Height()
+	m.footerModel.SetEmergencies(lines)
+	if m.footerModel.Height() != height {
+		return m.layout()
+	}
+	return nil
+}
+
+// toggleSidebar opens s, or closes it if it's already open
+func (m *model) toggleSidebar(s sidebar) {
+	if m.sidebar == s {
+		m.sidebar = sidebarNone
+	} else {
+		m.sidebar = s
+	}
+}
+
+// counters gathers the numbers for the stats panel
+func (m *model) counters() stats.Counters {
+	c := stats.Counters{
+		Aircraft:    len(m.aircraft),
+		PerReceiver: make(map[string]int),
+		Latency:     m.feed.Latency(),
+		Airports:    m.traffic.Counts(),
+	}
+	for _, f := range append([]*sbs.Feed{m.feed}, m.feeds...) {
+		lines, updates := f.Counts()
+		c.Lines += lines
+		c.Updates += updates
+		c.Dropped += f.Dropped()
+		cs := f.CallsignStats()
+		c.Callsigns.Trimmed += cs.Trimmed
+		c.Callsigns.Garbled += cs.Garbled
 	}
+	c.Callsigns.Conflicts = m.store.CallsignConflicts()
+	now := time.Now()
+	for _, ac := range m.aircraft {
+		if ac.Lat != 0 || ac.Lon != 0 {
+			c.WithPosition++
+		}
+		for _, name := range ac.HeardBy(now) {
+			c.PerReceiver[name]++
+		}
+	}
+	return c
+}
 
-	// Merge the new data
-	if update.Callsign != "" {
-		ac.Callsign = update.Callsign
+// status is the header note for a paused feed or a replayed picture
+func (m model) status() string {
+	var parts []string
+	if m.airportsErr != nil {
+		parts = append(parts, airportsWarning(m.airportsErr))
+	}
+	if m.replay != nil {
+		parts = append(parts, m.replayStatus())
+	}
+	if paused, held := m.feed.Paused(); paused {
+		parts = append(parts, fmt.Sprintf("FEED PAUSED, %d updates held (space to resume)", held))
+	}
+	for _, f := range m.feeds {
+		if err := f.Err(); err != nil {
+			parts = append(parts, fmt.Sprintf("FEED %s DOWN: %v", f.Name(), err))
+		}
+	}
+	if m.announcer != nil {
+		if m.announcer.Muted() {
+			parts = append(parts, "ANNOUNCEMENTS MUTED (a to unmute)")
+		} else if err := m.announcer.Err(); err != nil {
+			parts = append(parts, "ANNOUNCE FAILED: "+err.Error())
+		}
+	}
+	if m.exporter != nil {
+		if err := m.exporter.Err(); err != nil {
+			parts = append(parts, "EXPORT FAILED: "+err.Error())
+		}
+	}
+	if m.logger != nil {
+		if err := m.logger.Err(); err != nil {
+			parts = append(parts, "LOG FAILED: "+err.Error())
+		}
+	}
+	if m.recorder != nil {
+		if err := m.recorder.Err(); err != nil {
+			parts = append(parts, "RECORDING FAILED: "+err.Error())
+		}
+	}
+	if m.updater != nil {
+		if err := m.updater.Err(); err != nil {
+			parts = append(parts, "DB UPDATE FAILED: "+err.Error())
+		}
+	}
+	if err := m.lookup.Err(); err != nil {
+		parts = append(parts, "AIRCRAFT DB FAILED: "+err.Error())
+	}
+	if time.Since(m.resumed) < notableFlash {
+		parts = append(parts, fmt.Sprintf("RESUMED after %s asleep", m.slept.Round(time.Second)))
 	}
-	if update.Lat != 0 && update.Lon != 0 {
-		ac.Lat = update.Lat
-		ac.Lon = update.Lon
+	if time.Since(m.notable.At) < notableFlash {
+		parts = append(parts, "NOTABLE: "+m.notable.Text)
 	}
-	if update.Speed != 0 {
-		ac.Speed = update.Speed
+	if time.Since(m.cast.at) < notableFlash {
+		if m.cast.err != nil {
+			parts = append(parts, "CAST FAILED: "+m.cast.err.Error())
+		} else {
+			parts = append(parts, "CAST SAVED: "+m.cast.path)
+		}
+	}
+	if time.Since(m.alert.At) < notableFlash {
+		parts = append(parts, "ALERT: "+m.alert.Text)
+	}
+	if m.recording {
+		parts = append(parts, fmt.Sprintf("RECORDING MACRO, %d keys (M to finish)", len(m.macro)))
 	}
-	if update.Track != 0 {
-		ac.Track = update.Track
+	if m.binding {
+		parts = append(parts, "BIND MACRO: press F1-F12 for it (any other key to throw it away)")
 	}
-	ac.LastSeen = time.Now()
+	if m.shift > 0 {
+		parts = append(parts, fmt.Sprintf("REPLAY -%s (] to go forward)", m.shift.Round(time.Second)))
+	}
+	return strings.Join(parts, " | ")
 }
 
+// cycleSelection moves the selection to the next (dir 1) or previous
+// (dir -1) aircraft, ordered by ICAO
+func (m *model) cycleSelection(dir int) {
+	icaos := make([]string, 0, len(m.aircraft))
+	for icao := range m.aircraft {
+		icaos = append(icaos, icao)
+	}
+	if len(icaos) == 0 {
+		return
+	}
+	sort.Strings(icaos)
+
+	i := sort.SearchStrings(icaos, m.selected)
+	switch {
+	case m.selected == "" || i == len(icaos) || icaos[i] != m.selected:
+		// Nothing (or something that's gone) was selected: start at an end
+		if dir > 0 {
+			i = 0
+		} else {
+			i = len(icaos) - 1
+		}
+	default:
+		i = (i + dir + len(icaos)) % len(icaos)
+	}
+	m.selected = icaos[i]
+	for _, mm := range m.maps() {
+		mm.SetSelected(m.selected)
+	}
+	m.detailModel.SetAircraft(m.aircraft[m.selected])
+}
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	// --- Global Error Handling ---
@@ -124,76 +628,417 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		cmds = append(cmds, m.layout()...)
 
-		// --- Layout ---
-		headerHeight := 1
-		footerHeight := 1
-		mapHeight := m.height - headerHeight - footerHeight
-
-		// Send resized messages to children
-		headerMsg := tea.WindowSizeMsg{Width: m.width, Height: headerHeight}
-		m.headerModel, headerCmd = m.headerModel.Update(headerMsg)
-
-		mapMsg := tea.WindowSizeMsg{Width: m.width, Height: mapHeight}
-		m.mapModel, mapCmd = m.mapModel.Update(mapMsg)
-
-		footerMsg := tea.WindowSizeMsg{Width: m.width, Height: footerHeight}
-		m.footerModel, footerCmd = m.footerModel.Update(footerMsg)
+	// --- Handle SBS Messages ---
+	case mapLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err // Show the error
+			return m, nil
+		}
+		for _, mm := range m.maps() {
+			mm.SetData(msg.data)
+		}
+		m.footerModel.SetZoom(m.focused().GetZoomLevel())
+		m.loading = false
+		m.airportsErr = msg.data.AirportsErr() // Not fatal, we carry on without
 
-		cmds = append(cmds, headerCmd, mapCmd, footerCmd)
+	case airportsLoadedMsg:
+		m.airportsErr = msg.err
+		if msg.err == nil {
+			m.airportPath = msg.path
+			for _, mm := range m.maps() {
+				mm.SetAirports(msg.points)
+			}
+		}
 
-	// --- Handle SBS Messages ---
 	case sbs.SbsConnectedMsg:
-		m.sbsScanner = msg.Scanner
-		m.sbsConn = msg.Conn // <-- Save the connection
-		// Start listening for the first line
-		cmds = append(cmds, sbs.WaitForSbsLine(m.sbsScanner))
+		m.connected = true
+		// Hand the connection to the feed, which reads it in the background
+		cmds = append(cmds, m.feed.Start(msg.Conn))
 
 	case sbs.SbsErrorMsg:
 		m.err = msg.Err // Show the error
 		return m, nil
 
-	case sbs.AircraftUpdateMsg:
-		// --- DATA LOOP ---
-		m.mergeAircraft(msg.Update)
+	// --- UAT weather ---
+	case uat.ConnectedMsg:
+		go m.weather.Run(msg.Conn) // Its error ends up in m.weather.Err
+
+	case uat.ErrorMsg:
+		// Not fatal; the weather panel says what went wrong
+		m.uatErr = msg.Err
+
+	case castMsg:
+		m.cast = msg
+
+	case airportNamesMsg:
+		for _, mm := range m.maps() {
+			mm.SetAirportNames(msg.airports)
+		}
+		m.weatherModel.SetAirports(msg.airports)
+		fields := make([]traffic.Airport, 0, len(msg.airports))
+		for _, a := range msg.airports {
+			code := a.Code
+			if code == "" {
+				code = a.IATA
+			}
+			fields = append(fields, traffic.Airport{Code: code, Name: a.Name, Lat: a.Lat, Lon: a.Lon})
+		}
+		m.traffic.SetAirports(fields)
 
-		// --- 2. ADD THIS AUTO-ZOOM BLOCK ---
-		if !m.initialPositionFound && msg.Update != nil && msg.Update.Lat != 0 {
-			m.initialPositionFound = true // Set flag
-			// Tell the map to auto-zoom
-			m.mapModel.SetViewToLocation(msg.Update.Lat, msg.Update.Lon)
-			// Sync the footer's zoom level
-			m.footerModel.SetZoom(m.mapModel.GetZoomLevel())
+	// --- Remote control ---
+	case control.CommandMsg:
+		next, cmd := m.runCommand(msg.Command)
+		return next, tea.Batch(cmd, m.control.WaitCmd())
+
+	case photo.Msg:
+		// No photo (or no network) just means no photo
+		if msg.Err == nil {
+			m.detailModel.SetPhoto(msg.Photo)
 		}
-		// --- END AUTO-ZOOM BLOCK ---
 
-		// Ask for the next line (fast)
-		cmds = append(cmds, sbs.WaitForSbsLine(m.sbsScanner))
-		// --- We DO NOT update the map here ---
+	case dump1090.StatsMsg:
+		// Poll failures are shown in the panel, not fatal
+		m.statsModel, _ = m.statsModel.Update(msg)
+		cmds = append(cmds, dump1090.PollStatsCmd(m.statsURL, statsPollInterval))
 
 	// --- RENDER LOOP ---
 	case TickMsg:
 		// The render ticker fired.
-		// 1. Tell the map to update with the *current* aircraft list
-		m.mapModel.UpdateAircraft(m.aircraft)
-		// 2. Ask for the next tick
-		cmds = append(cmds, TickCmd())
+		// Aircraft age by the monotonic clock, which stops while we're
+		// suspended, so they pick up where they left off; just say so
+		now := time.Now()
+		if !m.lastTick.IsZero() {
+			if slept := sbs.Suspended(m.lastTick, now); slept > minSuspend {
+				m.resumed, m.slept = now, slept
+			}
+		}
+		m.lastTick = now
+
+		// 1. Take a snapshot of the store for this frame, from the past
+		//    if we've stepped back through history. A replay's picture
+		//    is at the recording's time, not ours.
+		var at time.Time
+		if m.replay != nil {
+			at = m.clock()
+		}
+		if m.shift > 0 {
+			at = m.clock().Add(-m.shift)
+			if start := m.store.HistoryStart(); at.Before(start) {
+				at = start // The history we were on has been forgotten
+				m.shift = m.clock().Sub(start)
+			}
+			m.aircraft = m.store.SnapshotAt(at)
+			m.version = 0 // Take the lot again once we're back
+		} else {
+			// Only copy what's changed since the last frame, however
+			// many lines that was. Each frame gets a map of its own, so
+			// nothing handed the last one sees it change underneath it.
+			var last map[string]*sbs.Aircraft
+			if m.version != 0 {
+				last = m.aircraft
+			}
+			diff := m.store.Changes(m.version)
+			m.aircraft = diff.Apply(last)
+			m.version = diff.Version
+		}
+		for _, mm := range m.maps() {
+			mm.SetTime(at)
+		}
+		m.detailModel.SetTime(at)
+		m.textModel.SetTime(at)
+		cmds = append(cmds, m.syncEmergencies()...)
+		m.rawLogModel.Pull(m.lineLog)
+		m.perf.Frame(m.feed.LastLine(), m.store.LastUpdate())
+		if m.sidebar == sidebarStats {
+			m.statsModel.SetCounters(m.counters())
+		}
+		if m.sidebar == sidebarWeather && m.uatAddr != "" {
+			err := m.uatErr
+			if err == nil {
+				err = m.weather.Err()
+			}
+			m.weatherModel.SetReports(m.weather.Reports(), err)
+			m.weatherModel.SetReference(m.reference())
+		}
+		if m.sidebar == sidebarFences {
+			m.fencesModel.SetOccupancy(m.geofences.Occupancy(), m.aircraft)
+		}
+		if m.sidebar == sidebarLegend {
+			m.legendModel.SetEntries(m.focused().Legend())
+			m.legendModel.SetTheme(m.currentTheme(time.Now()).String())
+		}
+		if m.sidebar == sidebarRegulars {
+			m.regularsModel.SetRegulars(m.sightings.Regulars(regulars.MaxListed))
+		}
+		if m.sidebar == sidebarArrivals {
+			when := at
+			if when.IsZero() {
+				when = now
+			}
+			m.arrivalsModel.SetSequences(m.sequencer.Sequences(m.aircraft, when))
+		}
+		m.detailModel.SetAircraft(m.aircraft[m.selected])
+		m.detailModel.SetCallsigns(m.sightings.Callsigns(m.selected))
+		if m.photos && m.sidebar == sidebarDetail && m.selected != "" && m.selected != m.photoFor {
+			m.photoFor = m.selected
+			cmds = append(cmds, photo.FetchCmd(photo.CacheDir(), m.selected))
+		}
+		m.headerModel.SetStatus(m.status())
+		if _, _, ok := m.focused().Crosshair(); ok {
+			m.footerModel.SetCursorInfo(m.cursorInfo())
+		}
+
+		// Auto-zoom to the first aircraft with a position, once
+		// there's a map to zoom
+		if !m.initialPositionFound && !m.loading {
+			for _, ac := range m.aircraft {
+				if ac.Lat != 0 {
+					m.initialPositionFound = true
+					m.mapModel.SetViewToLocation(ac.Lat, ac.Lon)
+					m.footerModel.SetZoom(m.focused().GetZoomLevel())
+					break
+				}
+			}
+		}
+
+		// 2. Tell the map to update with the *current* aircraft list
+		for _, mm := range m.maps() {
+			mm.UpdateAircraft(m.aircraft)
+		}
+		lat, lon := m.reference()
+		if m.textMode {
+			m.textModel.UpdateAircraft(m.aircraft, lat, lon)
+		}
+		if m.shift == 0 {
+			for _, e := range m.sightings.Check(m.aircraft, lat, lon, m.receiver.set) {
+				m.notable = e
+				m.textModel.Alert(e.Text + ".")
+			}
+			m.traffic.Check(m.aircraft)
+			m.geofences.Check(m.aircraft)
+			for _, a := range m.rules.Check(m.aircraft, lat, lon) {
+				m.alert = a
+				m.textModel.Alert(a.Text + ".")
+				if m.grpc != nil {
+					m.grpc.Raise(a.At, a.ICAO, "rule", a.Text)
+				}
+				if m.announcer != nil {
+					m.announcer.Alert(a.Rule, m.aircraft[a.ICAO], lat, lon)
+				}
+			}
+		}
+		if m.announcer != nil && m.shift == 0 {
+			// Only news is worth saying, not what happened in the past
+			m.announcer.Check(m.aircraft, lat, lon)
+		}
+		// 3. Ask for the next tick
+		cmds = append(cmds, TickCmd(m.frameRate))
 
 	case tea.KeyMsg:
+		// The raw log's filter prompt takes every key while it's open
+		if m.showRawLog && m.rawLogModel.Editing() {
+			m.rawLogModel, _ = m.rawLogModel.Update(msg)
+			return m, nil
+		}
+		// So does the query console, bar Esc to close it
+		if m.showQuery && msg.String() != "ctrl+c" {
+			if msg.Type == tea.KeyEsc {
+				m.showQuery = false
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.queryModel, cmd = m.queryModel.Update(msg)
+			return m, cmd
+		}
+
+		// Finishing a macro: a function key takes it, anything else
+		// throws it away
+		if m.binding {
+			m.binding = false
+			if key := msg.String(); macros.Bindable(key) {
+				m.macros.Bind(key, m.macro)
+			}
+			m.macro = nil
+			return m, nil
+		}
+		if keys, ok := m.macros.Get(msg.String()); ok && !m.recording {
+			return m.playMacro(keys)
+		}
+		if m.recording {
+			switch key := msg.String(); {
+			case key == "M":
+			case key == "q" || key == "ctrl+c" || key == "esc" || macros.Bindable(key):
+				// Not worth replaying, or a macro in a macro
+			default:
+				m.macro = append(m.macro, key)
+			}
+		}
+
+		// While it's open the raw log has these keys; otherwise they
+		// go on to the map like any other
+		if m.showRawLog {
+			switch msg.String() {
+			case "p", "pgup", "pgdown", "home", "end", "/":
+				m.rawLogModel, _ = m.rawLogModel.Update(msg)
+				return m, tea.Batch(cmds...)
+			}
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c", "esc":
 			// Cleanly close the connection
-			if m.sbsConn != nil {
-				m.sbsConn.Close()
-			}
+			m.feed.Close()
 			return m, tea.Quit
+		case "t":
+			// Toggle the text-only (screen reader) mode
+			m.textMode = !m.textMode
+		case "Q":
+			// Open the log query console
+			m.showQuery = true
+		case "s":
+			// Toggle the statistics panel
+			m.toggleSidebar(sidebarStats)
+			cmds = append(cmds, m.layout()...)
+		case "i":
+			// Toggle the selected aircraft's detail panel
+			m.toggleSidebar(sidebarDetail)
+			cmds = append(cmds, m.layout()...)
+		case "w":
+			// Toggle the UAT weather panel
+			m.toggleSidebar(sidebarWeather)
+			cmds = append(cmds, m.layout()...)
+		case "g":
+			// Toggle the geofence occupancy panel
+			m.toggleSidebar(sidebarFences)
+			cmds = append(cmds, m.layout()...)
+		case "?":
+			// Toggle the legend of what's on the map, and the keys
+			m.toggleSidebar(sidebarLegend)
+			cmds = append(cmds, m.layout()...)
+		case "R":
+			// Toggle the airframes seen most often from here
+			m.toggleSidebar(sidebarRegulars)
+			cmds = append(cmds, m.layout()...)
+		case "S":
+			// Toggle the arrival sequence onto each approach
+			m.toggleSidebar(sidebarArrivals)
+			cmds = append(cmds, m.layout()...)
+		case " ":
+			// A replay just stops its clock
+			if m.replayKey(" ") {
+				break
+			}
+			// Freeze the picture; the feed keeps the updates for catch-up
+			if paused, _ := m.feed.Paused(); paused {
+				m.feed.Resume()
+				for _, f := range m.feeds {
+					f.Resume()
+				}
+			} else {
+				m.feed.Pause()
+				for _, f := range m.feeds {
+					f.Pause()
+				}
+			}
+		case "<", ">":
+			// Slow a replay down or speed it up
+			m.replayKey(msg.String())
+		case "e":
+			// ETAs to the crosshair, or back to the configured target
+			// when crosshair mode is off
+			if lat, lon, ok := m.focused().Crosshair(); ok {
+				m.detailModel.SetTarget(&detail.Target{Name: "crosshair", Lat: lat, Lon: lon})
+			} else {
+				m.detailModel.SetTarget(m.eta)
+			}
+		case "a":
+			// Mute or unmute the spoken announcements
+			if m.announcer != nil {
+				m.announcer.SetMuted(!m.announcer.Muted())
+			}
+		case "A":
+			// Retry the airports layer if it didn't load
+			if m.airportsErr != nil && !m.loading {
+				cmds = append(cmds, m.retryAirportsCmd())
+			}
+		case "[":
+			// Step back through history, as far as it goes
+			if start := m.store.HistoryStart(); !start.IsZero() {
+				m.shift = min(m.shift+historyStep, m.clock().Sub(start))
+			}
+		case "]":
+			// Step forward again, back to live at the end
+			m.shift = max(m.shift-historyStep, 0)
+		case "tab":
+			m.cycleSelection(1)
+		case "shift+tab":
+			m.cycleSelection(-1)
+		case "d":
+			// Toggle the frame timing overlay
+			m.showPerf = !m.showPerf
+			cmds = append(cmds, m.layout()...)
+		case "N":
+			// Cycle the themes, then auto if we know where the sun is
+			switch {
+			case m.autoTheme:
+				m.autoTheme, m.theme = false, theme.Day
+			case m.theme == theme.Red:
+				m.autoTheme, m.theme = m.receiver.set, theme.Day
+			default:
+				m.theme++
+			}
+		case "E":
+			// Save the selected aircraft flying its trail as a cast
+			if ac, ok := m.aircraft[m.selected]; ok {
+				cmds = append(cmds, saveCastCmd(*m.focused(), ac, m.castDir))
+			}
+		case "M":
+			// Start recording a macro, or stop and ask for a key to bind
+			// it to
+			if m.recording {
+				m.recording, m.binding = false, len(m.macro) > 0
+			} else {
+				m.recording, m.macro = true, nil
+			}
+		case "m":
+			// Toggle the raw message log panel
+			m.showRawLog = !m.showRawLog
+			cmds = append(cmds, m.layout()...)
+		case "v":
+			// Split the map in two, or back to one
+			m.toggleSplit()
+			cmds = append(cmds, m.layout()...)
+			cmds = append(cmds, m.syncCursor()...)
+		case "o":
+			// Send keys to the other map, when split
+			m.setFocus(!m.splitFocus)
+			cmds = append(cmds, m.syncCursor()...)
 		default:
-			// Pass all other keys to the map model
-			m.mapModel, mapCmd = m.mapModel.Update(msg)
+			// Pass all other keys to the map model keys go to
+			mm := m.focused()
+			*mm, mapCmd = mm.Update(msg)
 			cmds = append(cmds, mapCmd)
 
-			// Sync footer zoom level after map update
-			m.footerModel.SetZoom(m.mapModel.GetZoomLevel())
+			// Sync footer zoom level and crosshair after map update
+			m.footerModel.SetZoom(mm.GetZoomLevel())
+			cmds = append(cmds, m.syncCursor()...)
+		}
+
+	case tea.MouseMsg:
+		// Only asked for in crosshair mode, to move it. The map's a line
+		// down, under the header, and the split one right of the main
+		// one; a click off the focused map goes to its nearest edge.
+		if !m.textMode && !m.showQuery {
+			msg.Y = max(msg.Y-1, 0)
+			mm := m.focused()
+			if m.splitFocus {
+				msg.X = max(msg.X-m.mapModel.Width(), 0)
+			}
+			*mm, mapCmd = mm.Update(msg)
+			cmds = append(cmds, mapCmd)
+			cmds = append(cmds, m.syncCursor()...)
 		}
 
 	default:
@@ -201,19 +1046,52 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.headerModel, headerCmd = m.headerModel.Update(msg)
 		m.mapModel, mapCmd = m.mapModel.Update(msg)
 		m.footerModel, footerCmd = m.footerModel.Update(msg)
-		cmds = append(cmds, headerCmd, mapCmd, footerCmd)
+		var queryCmd tea.Cmd
+		m.queryModel, queryCmd = m.queryModel.Update(msg)
+		cmds = append(cmds, headerCmd, mapCmd, footerCmd, queryCmd)
 	}
 
 	return m, tea.Batch(cmds...)
 }
 
+// playMacro presses each of the macro's keys in turn, as if they'd been
+// typed
+func (m model) playMacro(keys []string) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	for _, key := range keys {
+		next, cmd := m.Update(keyMsg(key))
+		m = next.(model)
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// currentTheme is the theme to draw with at now: the one chosen, or in
+// auto mode whichever suits the sun at the receiver
+func (m model) currentTheme(now time.Time) theme.Theme {
+	if !m.autoTheme {
+		return m.theme
+	}
+	if geo.SunElevation(m.receiver.lat, m.receiver.lon, now) < geo.Sunset {
+		return m.nightTheme
+	}
+	return theme.Day
+}
+
 func (m model) View() string {
+	return m.currentTheme(time.Now()).Apply(m.view())
+}
+
+func (m model) view() string {
 	// --- Error View ---
+	if loadErrs := loadErrors(m.err); len(loadErrs) > 0 {
+		return m.loadFailedView(loadErrs)
+	}
 	if m.err != nil {
 		errorStyle := lipgloss.NewStyle().
 			Width(m.width).
 			Height(m.height).
-			Border(lipgloss.DoubleBorder(), true).
+			Border(m.glyphs.Border, true).
 			BorderForeground(lipgloss.Color("9")).
 			Padding(1).
 			Align(lipgloss.Center, lipgloss.Center)
@@ -223,22 +1101,504 @@ func (m model) View() string {
 		)
 	}
 
+	// --- Loading View ---
+	if m.loading {
+		return m.loadingView()
+	}
+
 	// --- Normal View ---
+	start := time.Now()
 	headerView := m.headerModel.View()
-	mapView := m.mapModel.View()
+	var mapView string
+	switch {
+	case m.showQuery:
+		mapView = m.queryModel.View()
+	case m.textMode:
+		mapView = m.textModel.View()
+	default:
+		mapView = m.mapModel.View()
+		if m.split && !m.compact() {
+			mapView = lipgloss.JoinHorizontal(lipgloss.Top, mapView, m.splitModel.View())
+		}
+	}
 	footerView := m.footerModel.View()
 
+	// Too narrow for anything but the map and the header's status line
+	if m.compact() {
+		return lipgloss.JoinVertical(lipgloss.Left, headerView, mapView)
+	}
+
 	// Stack them vertically
-	return lipgloss.JoinVertical(lipgloss.Left,
-		headerView,
-		mapView,
-		footerView,
-	)
+	switch m.sidebar {
+	case sidebarStats:
+		mapView = lipgloss.JoinHorizontal(lipgloss.Top, mapView, m.statsModel.View())
+	case sidebarDetail:
+		mapView = lipgloss.JoinHorizontal(lipgloss.Top, mapView, m.detailModel.View())
+	case sidebarWeather:
+		mapView = lipgloss.JoinHorizontal(lipgloss.Top, mapView, m.weatherModel.View())
+	case sidebarFences:
+		mapView = lipgloss.JoinHorizontal(lipgloss.Top, mapView, m.fencesModel.View())
+	case sidebarLegend:
+		mapView = lipgloss.JoinHorizontal(lipgloss.Top, mapView, m.legendModel.View())
+	case sidebarRegulars:
+		mapView = lipgloss.JoinHorizontal(lipgloss.Top, mapView, m.regularsModel.View())
+	case sidebarArrivals:
+		mapView = lipgloss.JoinHorizontal(lipgloss.Top, mapView, m.arrivalsModel.View())
+	}
+
+	views := []string{headerView, mapView}
+	if m.showRawLog {
+		views = append(views, m.rawLogModel.View())
+	}
+	if m.showPerf {
+		// Shows the numbers from the previous frame; this one isn't done yet
+		views = append(views, m.perf.View(m.width))
+	}
+	views = append(views, footerView)
+	frame := lipgloss.JoinVertical(lipgloss.Left, views...)
+
+	done := time.Now()
+	m.perf.Painted(done.Sub(start), done)
+	return frame
 }
 
 func main() {
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
-		log.Fatalf("Alas, there's been an error: %v", err)
+	var opts options
+	configPath := flag.String("config", "", "JSON config file (default termtrack/config.json in your config directory, if it exists)")
+	flag.StringVar(&opts.feedAddr, "feed", sbs.DefaultAddress, "SBS (BaseStation) feed to connect to, host:port; raw AVR frames ("+sbs.AVRAddress+") and readsb's JSON output ("+sbs.JSONAddress+") work too, or a dump1090/readsb web address like http://pi:8080 to poll its aircraft.json")
+	sbsHost := flag.String("sbs-host", "", "receiver to connect to, in place of -feed's host; handy for a Raspberry Pi elsewhere on the network")
+	sbsPort := flag.Int("sbs-port", 0, "port to connect to, in place of -feed's")
+	var feedSource sourceFlag
+	flag.Var(&feedSource, "feed-source", "what the feed's positions come from: adsb, mlat or uat; MLAT aircraft get longer to go quiet before they're lost")
+	flag.StringVar(&opts.mapPath, "map", defaultMapPath, "map shapefile to draw")
+	flag.StringVar(&opts.airportPath, "airports", mapview.DefaultAirportPath, "airports shapefile to draw")
+	flag.Var(&opts.receiver, "receiver", "receiver location as `lat,lon`, used as the reference point for distances")
+	textMode := flag.Bool("text", false, "start in the screen-reader friendly text-only mode")
+	helicopters := flag.Bool("helicopters", false, "start in helicopter mode: just rotorcraft heard lately, with hovering and circling noted")
+	groupPrivate := flag.Bool("group-private", false, "in text mode, list blocked-callsign aircraft after the rest")
+	photos := flag.Bool("photos", false, "show a photo of the selected aircraft in the detail panel, from planespotters.net (cached on disk)")
+	glyphMode := flag.String("glyphs", "auto", "glyph set to draw with: auto, ascii or unicode")
+	themeName := flag.String("theme", "day", "colors to draw with: day, night (dimmed), red (for dark-adapted eyes), or auto to switch to -night-theme from sunset to sunrise at -receiver")
+	nightTheme := flag.String("night-theme", "night", "theme -theme auto switches to after sunset: night or red")
+	densityLimit := flag.Int("density-limit", mapview.DefaultDensityLimit, "with more aircraft than this in view, leave off their labels, trails and leader lines until you zoom in (0 for no limit)")
+	trailFade := flag.Duration("trail-fade", mapview.DefaultTrailFade, "how long trail points stay bright before fading")
+	vectorTime := flag.Duration("vectors", mapview.DefaultVectorTime, "how far ahead aircraft leader lines point, 0 to hide them")
+	flag.StringVar(&opts.uatAddr, "uat", "", "dump978 raw output to read FIS-B text weather from, e.g. "+uat.DefaultAddress)
+	flag.StringVar(&opts.statsURL, "stats-url", "", "dump1090/readsb stats.json URL to show receiver stats from, e.g. http://pi:8080/data/stats.json")
+	announceCmd := flag.String("announce", "", "text-to-speech command to announce events with, e.g. espeak; the text is its last argument")
+	announceRange := flag.Float64("announce-range", announce.DefaultRange, "announce aircraft coming within this many nautical miles")
+	controlAddr := flag.String("control", "", "take text commands (select, zoom, center, toggle, key) on this UDP or unix datagram socket, e.g. "+control.DefaultAddress+" or unix:/tmp/termtrack.sock")
+	historyWindow := flag.Duration("history", 10*time.Minute, "how much of the feed to keep for stepping back with [ and ] (0 to turn off)")
+	grpcAddr := flag.String("grpc", "", "serve the gRPC streaming API on this address, e.g. localhost:50051")
+	macrosPath := flag.String("macros", defaultMacrosPath(), "where to keep the key macros recorded with M between sessions, empty for this session only")
+	castDir := flag.String("cast-dir", ".", "where E saves the selected aircraft flying its trail, as an asciinema cast")
+	sightingsPath := flag.String("sightings", defaultSightingsPath(), "where to keep notable sightings (firsts, range record, regulars) between sessions, empty for this session only")
+	httpAddr := flag.String("http", "", "serve readsb-style JSON (receiver.json, aircraft.json, globe tiles) under /data/ on this address for tar1090, e.g. localhost:8080")
+	httpHTML := flag.String("http-html", "", "tar1090 html directory to serve at / alongside -http's data")
+	shareAddr := flag.String("share", "", "pass the feed on to other TermTracks started with -follow, listening on this address, e.g. localhost:30103")
+	shareTag := flag.String("share-tag", "", "write this in the session and aircraft ID fields of every SBS line -share passes on, so whatever merges several receivers' feeds can tell them apart")
+	follow := flag.String("follow", "", "follow the TermTrack sharing its feed (with -share) on this address, instead of connecting to -feed")
+	replay := flag.String("replay", "", "play back a recording made with -record (or an SBS file from sbsexport) instead of connecting to -feed, with its original timing; space pauses it, < and > step through 1x, 5x and 30x")
+	record := flag.String("record", "", "record every line received to this file, each stamped with when it came in and which receiver heard it, for playing back later with -replay")
+	logDB := flag.String("log-db", "", "log every position to this SQLite database, split into flights wherever an aircraft goes unheard for -flight-gap")
+	flightGap := flag.Duration("flight-gap", flightlog.DefaultGap, "how long an aircraft can go unheard before -log-db starts a new flight for it")
+	maxRate := flag.Int("max-rate", 0, "for very busy feeds on slow machines: take at most this many updates a second from the feed, positions first (0 for all of them)")
+	renderMode := flag.String("render", renderAuto, "render profile: full, reduced (10 redraws a second, 16 colours and trails that don't fade, for tmux and screen) or auto, reduced inside tmux or screen")
+	lowBandwidth := flag.Bool("low-bandwidth", false, "for slow links like SSH over a phone: redraw 4 times a second instead of 20, and start with trails off")
+	purge := flag.Duration("purge", 5*time.Minute, "how long an aircraft stays on, drawn stale, once it's gone unheard for its source's timeout (see \"timeouts\" in the config), before it's dropped; 0 keeps them all session")
+	headless := flag.Bool("headless", false, "run without the TUI, just the feed, the APIs, sharing, exports, logging and recording (needs one of them)")
+	setup := flag.Bool("setup", false, "walk through writing the config file: find the receiver's feed, say where it is and download map data; done anyway on a first run at a terminal")
+	flag.Usage = usage
+
+	// Environment first, so the command line can override it
+	if err := applyEnv(flag.CommandLine); err != nil {
+		log.Fatal(err)
+	}
+	flag.Parse()
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	// A config file we were pointed at has to exist; the default one needn't
+	path, required := *configPath, true
+	if path == "" {
+		path, required = defaultConfigPath(), false
+	}
+	if *setup || (!*headless && shouldSetup(path, set)) {
+		if err := runSetup(path, os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	}
+	cfg, err := loadConfig(path, required)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts.configPath = path
+
+	// The config file's feed, receiver and map paths count for less than
+	// a flag or environment variable
+	opts.airportPathFixed = set["airports"]
+	if cfg.Airports != "" && !opts.airportPathFixed {
+		opts.airportPath = cfg.Airports
+	}
+	if cfg.Map != "" && !set["map"] {
+		opts.mapPath = cfg.Map
+	}
+	if cfg.Receiver != "" && !set["receiver"] {
+		opts.receiver.Set(cfg.Receiver) // Checked when the config was loaded
 	}
+	if cfg.Feed != "" && !set["feed"] {
+		opts.feedAddr = cfg.Feed
+	}
+	if opts.feedAddr, err = feedAddress(opts.feedAddr, *sbsHost, *sbsPort, set, cfg); err != nil {
+		log.Fatal(err)
+	}
+
+	store := sbs.NewStore()
+	store.SetTimeouts(cfg.Timeouts)
+	if !*headless {
+		store.KeepHistory(*historyWindow)
+	}
+	feedName := "local"
+	if *follow != "" {
+		// A follower's feed is whatever the leader heard
+		opts.feedAddr, feedName = *follow, *follow
+	}
+	if *replay != "" {
+		if *headless || *follow != "" {
+			log.Fatal("-replay can't go with -headless or -follow")
+		}
+		// The config's receivers play back into their own feeds, if
+		// they were recorded too
+		var receivers []string
+		for _, e := range cfg.Feeds {
+			receivers = append(receivers, e.Name)
+		}
+		if opts.replay, err = sbs.Replay(*replay, receivers...); err != nil {
+			log.Fatal(err)
+		}
+		feedName = filepath.Base(*replay)
+	}
+	feed := sbs.NewFeed(feedName, store)
+	if opts.replay != nil {
+		feed.SetClock(opts.replay.Now)
+	}
+	feed.SetSource(feedSource.source)
+	feed.SetRateLimit(*maxRate)
+
+	// The config's other receivers go into the same store, each under its
+	// own name, and keep themselves connected
+	var feeds []*sbs.Feed
+	for _, e := range cfg.Feeds {
+		if e.Name == feedName {
+			log.Fatalf("config feed %q has the main feed's name", e.Name)
+		}
+		f := e.NewFeed(store)
+		f.SetRateLimit(*maxRate)
+		feeds = append(feeds, f)
+	}
+
+	// --- Sharing ---
+	var sharer *sbs.Sharer
+	if strings.ContainsAny(*shareTag, ",\r\n") {
+		log.Fatal("-share-tag can't have commas or line breaks in it; it goes in an SBS line")
+	}
+	if *shareAddr != "" {
+		if sharer, err = sbs.Share(*shareAddr, feed, store, *shareTag); err != nil {
+			log.Fatal(err)
+		}
+		for _, f := range feeds {
+			sharer.AddFeed(f)
+		}
+	}
+
+	// --- Recording ---
+	var recorder *sbs.Recorder
+	if *record != "" {
+		if recorder, err = sbs.Record(*record, feed); err != nil {
+			log.Fatal(err)
+		}
+		for _, f := range feeds {
+			recorder.AddFeed(f)
+		}
+	}
+
+	// --- Exports ---
+	var exporter *export.Exporter
+	if len(cfg.Exports) > 0 {
+		exporter = export.Start(store, cfg.Exports)
+	}
+
+	// --- Position log ---
+	var logger *flightlog.Logger
+	if *logDB != "" {
+		if logger, err = flightlog.Open(*logDB, store, *flightGap); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// --- Lookup databases ---
+	// The aircraft ones are read now, from whatever's on disk, and again
+	// whenever the updater brings in a new copy
+	var aircraftPaths []string
+	for _, d := range cfg.Databases {
+		if d.Lookup == dbupdate.LookupAircraft {
+			aircraftPaths = append(aircraftPaths, d.Path)
+		}
+	}
+	var aircraftDB *aircraftdb.DB
+	if len(aircraftPaths) > 0 {
+		aircraftDB = aircraftdb.Open(aircraftPaths...)
+	}
+	var updater *dbupdate.Updater
+	if len(cfg.Databases) > 0 {
+		updater = dbupdate.Start(cfg.Databases, func(d dbupdate.Database) {
+			if d.Lookup == dbupdate.LookupAircraft {
+				aircraftDB.Reload()
+			}
+		})
+	}
+
+	if *headless && *grpcAddr == "" && *httpAddr == "" && *shareAddr == "" && exporter == nil && logger == nil && recorder == nil {
+		log.Fatal("-headless needs -grpc, -http, -share, -log-db, -record or exports in the config, or there's nothing to do")
+	}
+
+	// --- gRPC API ---
+	var grpcSrv *api.Listener
+	if *grpcAddr != "" {
+		var err error
+		grpcSrv, err = api.ListenAndServe(*grpcAddr, store)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// --- tar1090 data ---
+	var httpSrv *tar1090.Listener
+	if *httpAddr != "" {
+		receiver := tar1090.Receiver{Lat: opts.receiver.lat, Lon: opts.receiver.lon, Set: opts.receiver.set}
+		if httpSrv, err = tar1090.ListenAndServe(*httpAddr, store, feed, receiver, *httpHTML); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Aircraft quiet for their source's timeout are drawn stale, and
+	// dropped from the store -purge after that
+	if *purge > 0 {
+		stopExpiry := expireAircraft(store, feed, opts.replay, cfg.Timeouts, *purge)
+		defer stopExpiry()
+	}
+
+	if *headless {
+		connectFeeds(feeds, cfg.Feeds)
+		err = runHeadless(feed, opts.feedAddr)
+	} else {
+		mod := initialModel(opts, store, feed)
+		mod.feeds = feeds
+		for _, f := range feeds {
+			f.OnLine(mod.lineLog.Append)
+		}
+		if opts.replay != nil {
+			replayFeeds(opts.replay, feeds)
+		} else {
+			connectFeeds(feeds, cfg.Feeds)
+		}
+		mod.textMode = *textMode
+		mod.exporter = exporter
+		mod.logger = logger
+		mod.recorder = recorder
+		mod.grpc = grpcSrv
+		mod.updater = updater
+		mod.lookup = aircraftDB
+		mod.detailModel.SetAircraftDB(aircraftDB)
+		mod.textModel.SetAircraftDB(aircraftDB)
+		mod.mapModel.SetAircraftDB(aircraftDB)
+		if mod.sightings, err = sightings.Load(*sightingsPath); err != nil {
+			log.Fatal(err)
+		}
+		mod.sightings.SetAircraftDB(aircraftDB)
+		if mod.macros, err = macros.Load(*macrosPath); err != nil {
+			log.Fatal(err)
+		}
+		mod.photos = *photos
+		mod.castDir = *castDir
+		mod.textModel.SetGroupPrivate(*groupPrivate)
+		mod.textModel.SetTimeouts(cfg.Timeouts)
+		mod.mapModel.SetTimeouts(cfg.Timeouts)
+		mod.traffic.SetTimeouts(cfg.Timeouts)
+		mod.sequencer.SetTimeouts(cfg.Timeouts)
+		mod.sequencer.SetRunways(runways(cfg.Approaches))
+		mod.geofences = geofence.NewMonitor(cfg.Geofences)
+		mod.geofences.SetTimeouts(cfg.Timeouts)
+		mod.rules = rules.New(cfg.Rules, cfg.Geofences)
+		mod.rules.SetTimeouts(cfg.Timeouts)
+		mod.mapModel.SetHelicopterMode(*helicopters)
+
+		reduced, err := reducedRendering(*renderMode)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Every view draws with the same glyph set
+		g := glyphs.Resolve(*glyphMode)
+		mod.glyphs = g
+		mod.mapModel.SetGlyphs(g)
+		mod.mapModel.SetTrailFade(*trailFade)
+		mod.mapModel.SetDensityLimit(*densityLimit)
+		mod.mapModel.SetVectorTime(*vectorTime)
+		mod.mapModel.SetLayers(cfg.Layers)
+		mod.mapModel.SetCategoryGlyphs(cfg.Categories)
+		mod.mapModel.SetApproaches(cfg.Approaches)
+		if opts.receiver.set {
+			mod.mapModel.SetReceiver(opts.receiver.lat, opts.receiver.lon)
+		}
+		mod.eta = cfg.ETA
+		mod.detailModel.SetTarget(cfg.ETA)
+		mod.rawLogModel.SetGlyphs(g)
+		mod.statsModel.SetGlyphs(g)
+		mod.detailModel.SetGlyphs(g)
+		mod.weatherModel.SetGlyphs(g)
+		mod.fencesModel.SetGlyphs(g)
+		mod.legendModel.SetGlyphs(g)
+		mod.regularsModel.SetGlyphs(g)
+		mod.arrivalsModel.SetGlyphs(g)
+
+		// Auto needs to know where the sun is
+		if *themeName == "auto" {
+			if !opts.receiver.set {
+				log.Fatal("-theme auto needs -receiver, to know when the sun sets")
+			}
+			mod.autoTheme = true
+		} else if mod.theme, err = theme.Parse(*themeName); err != nil {
+			log.Fatal(err)
+		}
+		if mod.nightTheme, err = theme.Parse(*nightTheme); err != nil {
+			log.Fatal(err)
+		}
+
+		// The query console reads the log through its own connection,
+		// which can't write to it
+		var queryDB *sql.DB
+		if *logDB != "" {
+			if queryDB, err = flightlog.OpenReadOnly(*logDB); err != nil {
+				log.Fatal(err)
+			}
+		}
+		mod.queryModel = query.New(queryDB)
+		mod.queryModel.SetGlyphs(g)
+
+		if *controlAddr != "" {
+			if mod.control, err = control.Listen(*controlAddr); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if *announceCmd != "" {
+			if mod.announcer, err = announce.New(*announceCmd, *announceRange); err != nil {
+				log.Fatal(err)
+			}
+			mod.announcer.SetTimeouts(cfg.Timeouts)
+		}
+
+		// Frames go to the terminal whole, and fewer of them through tmux or
+		// screen or on a slow link
+		progOpts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithOutput(syncOutput{os.Stdout})}
+		if reduced {
+			mod.frameRate = reducedFrameRate
+			lipgloss.SetColorProfile(termenv.ANSI)
+			if !set["trail-fade"] {
+				mod.mapModel.SetTrailFade(0)
+			}
+			progOpts = append(progOpts, tea.WithFPS(int(time.Second/reducedFrameRate)))
+		}
+		if *lowBandwidth {
+			mod.frameRate = lowBandwidthFrameRate
+			mod.mapModel.SetTrails(false)
+			progOpts = append(progOpts, tea.WithFPS(int(time.Second/lowBandwidthFrameRate)))
+		}
+
+		p := tea.NewProgram(mod, progOpts...)
+		if _, err = p.Run(); err != nil {
+			err = fmt.Errorf("Alas, there's been an error: %w", err)
+		}
+		if mod.announcer != nil {
+			mod.announcer.Close()
+		}
+		if mod.control != nil {
+			mod.control.Close()
+		}
+		if queryDB != nil {
+			queryDB.Close()
+		}
+		if saveErr := mod.sightings.Save(); saveErr != nil {
+			log.Print(saveErr)
+		}
+		if saveErr := mod.macros.Save(); saveErr != nil {
+			log.Print(saveErr)
+		}
+		fmt.Print(mod.sightings.Summary())
+	}
+
+	// --- Teardown ---
+	// However we got here (q, SIGTERM, the feed dropping), hang up on the
+	// feed and the API clients properly rather than leaving it to exit
+	feed.Close()
+	for _, f := range feeds {
+		f.Close()
+	}
+	if grpcSrv != nil {
+		grpcSrv.Shutdown()
+	}
+	if httpSrv != nil {
+		httpSrv.Shutdown()
+	}
+	if sharer != nil {
+		sharer.Close()
+	}
+	if recorder != nil {
+		if closeErr := recorder.Close(); closeErr != nil {
+			log.Print(closeErr)
+		}
+	}
+	if exporter != nil {
+		exporter.Close()
+	}
+	if updater != nil {
+		updater.Close()
+	}
+	if logger != nil {
+		if closeErr := logger.Close(); closeErr != nil {
+			log.Print(closeErr)
+		}
+	}
+	if err != nil && !errors.Is(err, sbs.ErrFeedClosed) {
+		log.Fatal(err)
+	}
+}
+
+// connectFeeds starts the config's feeds reading from their endpoints
+func connectFeeds(feeds []*sbs.Feed, endpoints []sbs.Endpoint) {
+	for i, f := range feeds {
+		f.Connect(endpoints[i].Address)
+	}
+}
+
+// runHeadless feeds the store until the feed ends or we're told to stop
+func runHeadless(feed *sbs.Feed, addr string) error {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	go func() {
+		<-sig
+		log.Print("headless: shutting down")
+		feed.Close()
+	}()
+
+	conn, err := sbs.Dial(addr)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("headless: reading %s feed", feed.Name())
+	return feed.Run(conn)
 }
\ No newline at end of file
@@ -0,0 +1,31 @@
+package main
+
+import "os"
+
+// Synchronized output: a terminal that knows the mode holds off painting
+// between these, so a frame goes up all at once instead of a line at a
+// time as it trickles in over a slow SSH link. Those that don't know it
+// ignore them.
+const (
+	beginSync = "\x1b[?2026h"
+	endSync   = "\x1b[?2026l"
+)
+
+// syncOutput is the terminal, with every write bracketed in synchronized
+// output. Bubbletea writes each frame's changed lines in one go, so that's
+// a frame at a time. It's still the terminal's file underneath, so raw
+// mode and the window size work as usual.
+type syncOutput struct {
+	*os.File
+}
+
+func (o syncOutput) Write(p []byte) (int, error) {
+	buf := make([]byte, 0, len(beginSync)+len(p)+len(endSync))
+	buf = append(buf, beginSync...)
+	buf = append(buf, p...)
+	buf = append(buf, endSync...)
+	if _, err := o.File.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,202 @@
+// Package photo fetches aircraft photos from planespotters.net by ICAO
+// address, keeps them in a cache on disk and draws them with coloured
+// character cells.
+package photo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg" // planespotters thumbnails are JPEGs
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// apiURL is the planespotters.net public API, by hex address
+const apiURL = "https://api.planespotters.net/pub/photos/hex/"
+
+// userAgent identifies us, which planespotters asks API users to do
+const userAgent = "TermTrack (+https://github.com/SarahRoseLives/TermTrack)"
+
+// fetchTimeout bounds the lookup and the download together
+const fetchTimeout = 10 * time.Second
+
+// maxImageBytes is more than a thumbnail will ever be
+const maxImageBytes = 1 << 20
+
+// recheckAfter is how long we believe there's no photo of an aircraft
+// before asking again
+const recheckAfter = 7 * 24 * time.Hour
+
+// ErrNoPhoto is what Fetch returns for an aircraft nobody's photographed
+var ErrNoPhoto = errors.New("no photo")
+
+// Photo is a picture of one aircraft, with the credit that has to go with it
+type Photo struct {
+	ICAO         string
+	Image        image.Image
+	Photographer string
+	Link         string // The photo's page on planespotters.net
+}
+
+// meta is what we cache next to the image: the credit, or that there
+// wasn't a photo
+type meta struct {
+	Photographer string    `json:"photographer,omitempty"`
+	Link         string    `json:"link,omitempty"`
+	None         bool      `json:"none,omitempty"`
+	Fetched      time.Time `json:"fetched"`
+}
+
+// apiResponse mirrors the parts of the API's answer we read
+type apiResponse struct {
+	Photos []struct {
+		Thumbnail struct {
+			Src string `json:"src"`
+		} `json:"thumbnail"`
+		Link         string `json:"link"`
+		Photographer string `json:"photographer"`
+	} `json:"photos"`
+}
+
+// CacheDir is where photos are kept: termtrack/photos under the user's
+// cache directory
+func CacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "termtrack", "photos")
+}
+
+// Fetch gets the photo of the aircraft with the given hex address, from
+// the cache in dir if it's there (dir "" means no cache)
+func Fetch(ctx context.Context, dir, hex string) (*Photo, error) {
+	hex = strings.ToUpper(hex)
+	if p, err := fromCache(dir, hex); err == nil || errors.Is(err, ErrNoPhoto) {
+		return p, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	var resp apiResponse
+	body, err := get(ctx, apiURL+hex)
+	if err != nil {
+		return nil, fmt.Errorf("photo lookup: %w", err)
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("photo lookup: %w", err)
+	}
+	if len(resp.Photos) == 0 || resp.Photos[0].Thumbnail.Src == "" {
+		toCache(dir, hex, meta{None: true, Fetched: time.Now()}, nil)
+		return nil, ErrNoPhoto
+	}
+
+	first := resp.Photos[0]
+	data, err := get(ctx, first.Thumbnail.Src)
+	if err != nil {
+		return nil, fmt.Errorf("photo download: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("photo decode: %w", err)
+	}
+
+	m := meta{Photographer: first.Photographer, Link: first.Link, Fetched: time.Now()}
+	toCache(dir, hex, m, data)
+	return &Photo{ICAO: hex, Image: img, Photographer: m.Photographer, Link: m.Link}, nil
+}
+
+// get downloads url, as long as it's not unreasonably big
+func get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxImageBytes))
+}
+
+// fromCache loads a cached photo. It returns ErrNoPhoto if we were told
+// lately there isn't one, and some other error if we'll have to ask.
+func fromCache(dir, hex string) (*Photo, error) {
+	if dir == "" {
+		return nil, errors.New("no cache")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, hex+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var m meta
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if m.None {
+		if time.Since(m.Fetched) > recheckAfter {
+			return nil, errors.New("no photo last time, asking again")
+		}
+		return nil, ErrNoPhoto
+	}
+
+	f, err := os.Open(filepath.Join(dir, hex+".jpg"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return nil, err
+	}
+	return &Photo{ICAO: hex, Image: img, Photographer: m.Photographer, Link: m.Link}, nil
+}
+
+// toCache saves a photo (or that there isn't one) for next time. It's only
+// a cache, so failing to write it isn't worth reporting.
+func toCache(dir, hex string, m meta, image []byte) {
+	if dir == "" || os.MkdirAll(dir, 0o755) != nil {
+		return
+	}
+	if image != nil {
+		if os.WriteFile(filepath.Join(dir, hex+".jpg"), image, 0o644) != nil {
+			return
+		}
+	}
+	if data, err := json.Marshal(m); err == nil {
+		os.WriteFile(filepath.Join(dir, hex+".json"), data, 0o644)
+	}
+}
+
+// Msg carries the result of a FetchCmd
+type Msg struct {
+	ICAO  string
+	Photo *Photo
+	Err   error
+}
+
+// FetchCmd fetches the photo of the aircraft with the given hex address
+// in the background
+func FetchCmd(dir, hex string) tea.Cmd {
+	return func() tea.Msg {
+		p, err := Fetch(context.Background(), dir, hex)
+		return Msg{ICAO: strings.ToUpper(hex), Photo: p, Err: err}
+	}
+}
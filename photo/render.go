@@ -0,0 +1,66 @@
+package photo
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// upperHalf splits a cell into two pixels: the top one in the foreground
+// colour and the bottom one in the background
+const upperHalf = "▀"
+
+// Render draws the photo width cells wide, keeping its shape. With half
+// blocks each cell is two pixels, one above the other; without, each cell
+// is one pixel of background colour, at half the vertical detail.
+func (p *Photo) Render(width int, halfBlocks bool) []string {
+	b := p.Image.Bounds()
+	if width <= 0 || b.Dx() == 0 || b.Dy() == 0 {
+		return nil
+	}
+
+	// Cells are about twice as tall as they're wide
+	pixelRows := width * b.Dy() / b.Dx()
+	rows := pixelRows / 2
+	if rows == 0 {
+		return nil
+	}
+
+	lines := make([]string, rows)
+	for y := range rows {
+		var sb strings.Builder
+		for x := range width {
+			style := lipgloss.NewStyle()
+			if halfBlocks {
+				top := average(p.Image, b, x, 2*y, width, 2*rows)
+				bottom := average(p.Image, b, x, 2*y+1, width, 2*rows)
+				sb.WriteString(style.Foreground(top).Background(bottom).Render(upperHalf))
+			} else {
+				sb.WriteString(style.Background(average(p.Image, b, x, y, width, rows)).Render(" "))
+			}
+		}
+		lines[y] = sb.String()
+	}
+	return lines
+}
+
+// average is the mean colour of the image over cell (x, y) of a w by h
+// grid laid over it
+func average(img image.Image, b image.Rectangle, x, y, w, h int) lipgloss.Color {
+	x0, x1 := b.Min.X+x*b.Dx()/w, b.Min.X+(x+1)*b.Dx()/w
+	y0, y1 := b.Min.Y+y*b.Dy()/h, b.Min.Y+(y+1)*b.Dy()/h
+	x1, y1 = max(x1, x0+1), max(y1, y0+1)
+
+	var r, g, bl, n uint64
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			c := color.RGBAModel.Convert(img.At(px, py)).(color.RGBA)
+			r, g, bl = r+uint64(c.R), g+uint64(c.G), bl+uint64(c.B)
+			n++
+		}
+	}
+	return lipgloss.Color(fmt.Sprintf("#%02x%02x%02x", r/n, g/n, bl/n))
+}
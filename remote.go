@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"termtrack/control"
+)
+
+// runCommand carries out a command from the control socket. Anything a key
+// already does goes through the key handling, so the two can't drift apart.
+func (m model) runCommand(c control.Command) (tea.Model, tea.Cmd) {
+	arg := c.Args[0]
+	switch c.Name {
+	case "select":
+		switch strings.ToLower(arg) {
+		case "next":
+			m.cycleSelection(1)
+		case "prev":
+			m.cycleSelection(-1)
+		case "none":
+			m.selected = ""
+		default:
+			m.selected = strings.ToUpper(arg)
+		}
+		for _, mm := range m.maps() {
+			mm.SetSelected(m.selected)
+		}
+		m.detailModel.SetAircraft(m.aircraft[m.selected])
+
+	case "zoom":
+		switch arg {
+		case "in":
+			return m.Update(keyMsg("L"))
+		case "out":
+			return m.Update(keyMsg("K"))
+		}
+		var level float64
+		fmt.Sscan(arg, &level) // Parse has already checked it
+		m.focused().SetZoom(level)
+		m.footerModel.SetZoom(m.focused().GetZoomLevel())
+
+	case "center":
+		var l location
+		if err := l.Set(arg); err != nil {
+			return m, nil
+		}
+		mm := m.focused()
+		zoom := mm.GetZoomLevel()
+		mm.SetViewToLocation(l.lat, l.lon)
+		mm.SetZoom(zoom)
+
+	case "toggle":
+		if key, ok := toggleKey(arg); ok {
+			return m.Update(keyMsg(key))
+		}
+
+	case "key":
+		switch arg {
+		case "q", "esc", "ctrl+c":
+			// Quitting is for whoever's at the keyboard
+		default:
+			return m.Update(keyMsg(arg))
+		}
+	}
+	return m, nil
+}
+
+// keyMsg is a press of the given key, named as msg.String() has it
+func keyMsg(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Render profiles, picked with -render
+const (
+	renderAuto    = "auto"
+	renderFull    = "full"
+	renderReduced = "reduced"
+)
+
+// reducedRendering reports whether to draw with the reduced profile: fewer
+// frames, 16 colours and no trail fading, so there's less for tmux or
+// screen to parse and redraw. They keep their own copy of the screen and
+// repaint the real terminal from it, and full-colour frames at 20 a second
+// can leave them well behind.
+func reducedRendering(mode string) (bool, error) {
+	switch mode {
+	case renderFull:
+		return false, nil
+	case renderReduced:
+		return true, nil
+	case renderAuto:
+		return inMultiplexer(), nil
+	}
+	return false, fmt.Errorf("-render: %q isn't auto, full or reduced", mode)
+}
+
+// inMultiplexer guesses whether we're running inside tmux or screen, going
+// by the variables they set and the TERM they give their windows
+func inMultiplexer() bool {
+	if os.Getenv("TMUX") != "" || os.Getenv("STY") != "" {
+		return true
+	}
+	term := os.Getenv("TERM")
+	return strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"termtrack/sbs"
+)
+
+// connectCmd starts the main feed: the recording, with -replay, or else
+// the connection to the receiver
+func (m model) connectCmd() tea.Cmd {
+	if m.replay != nil {
+		return func() tea.Msg {
+			return sbs.SbsConnectedMsg{Conn: m.replay}
+		}
+	}
+	return sbs.ConnectCmd(m.feedAddr)
+}
+
+// replayFeeds starts the config's feeds reading their receivers' lines
+// from the replay instead of connecting to them, on its clock like the
+// main feed
+func replayFeeds(p *sbs.Player, feeds []*sbs.Feed) {
+	for _, f := range feeds {
+		f.SetClock(p.Now)
+		go f.Run(p.Receiver(f.Name()))
+	}
+}
+
+// replayKey handles the keys that drive a replay: space stops and starts
+// its clock, and < and > step through the speeds. It reports whether the
+// key was one of them.
+func (m *model) replayKey(key string) bool {
+	if m.replay == nil {
+		return false
+	}
+	state := m.replay.State()
+	i := slices.Index(sbs.ReplaySpeeds, state.Speed)
+	switch key {
+	case " ":
+		m.replay.SetPaused(!state.Paused)
+	case "<":
+		m.replay.SetSpeed(sbs.ReplaySpeeds[max(i-1, 0)])
+	case ">":
+		m.replay.SetSpeed(sbs.ReplaySpeeds[min(i+1, len(sbs.ReplaySpeeds)-1)])
+	default:
+		return false
+	}
+	return true
+}
+
+// clock is the time the picture's at, see replayTime
+func (m model) clock() time.Time {
+	return replayTime(m.replay)
+}
+
+// replayTime is where a replay's got to in the recording, or now when
+// there's no replay (or it's yet to start)
+func replayTime(p *sbs.Player) time.Time {
+	if p != nil {
+		if at := p.State().At; !at.IsZero() {
+			return at
+		}
+	}
+	return time.Now()
+}
+
+// replayHeld reports whether a replay's clock is standing still, paused
+// or at the end, when nothing it played should age out
+func replayHeld(p *sbs.Player) bool {
+	if p == nil {
+		return false
+	}
+	state := p.State()
+	return state.Paused || state.Finished
+}
+
+// replayStatus is the header note saying where the replay's got to
+func (m model) replayStatus() string {
+	state := m.replay.State()
+	s := fmt.Sprintf("REPLAY %dx", state.Speed)
+	if !state.At.IsZero() {
+		s += " at " + state.At.Format("2006-01-02 15:04:05")
+	}
+	switch {
+	case state.Err != nil:
+		s += ", STOPPED: " + state.Err.Error()
+	case state.Finished:
+		s += ", FINISHED"
+	case state.Paused:
+		s += ", PAUSED (space to play)"
+	}
+	return s
+}
@@ -0,0 +1,218 @@
+// Package rules raises alerts from the config's rules: conditions on an
+// aircraft's numbers, all of which have to hold, optionally only inside
+// (or outside) one of the geofences. Say, altitude under 1500 ft within
+// 5 nm, but not on the approach.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"termtrack/geo"
+	"termtrack/geofence"
+	"termtrack/sbs"
+	"termtrack/watch"
+)
+
+// Rule is one alert from the config
+type Rule struct {
+	Name string      `json:"name"`
+	When []Condition `json:"when"`
+
+	// Geofences, by name, the aircraft has to be in or out of
+	Area    string `json:"area,omitempty"`
+	NotArea string `json:"not_area,omitempty"`
+}
+
+// Condition compares one of an aircraft's numbers to a threshold, like
+// {"field": "altitude", "op": "<", "value": 1500}
+type Condition struct {
+	Field string  `json:"field"`
+	Op    string  `json:"op"`
+	Value float64 `json:"value"`
+}
+
+// field gets a number off an aircraft, reporting false if it isn't known.
+// Distance is in nautical miles from the reference point. The squawk is
+// its four digits read as a number, so "squawk == 7700" does what it
+// says, and emergency is 1 while the squawk (or the emergency flag) says
+// something's wrong, else 0.
+type field func(ac *sbs.Aircraft, refLat, refLon float64) (float64, bool)
+
+// fields are what conditions can test, by name
+var fields = map[string]field{
+	"altitude": func(ac *sbs.Aircraft, _, _ float64) (float64, bool) {
+		return float64(ac.Altitude), ac.Has&sbs.HasAltitude != 0
+	},
+	"speed": func(ac *sbs.Aircraft, _, _ float64) (float64, bool) {
+		return ac.Speed, ac.Has&sbs.HasSpeed != 0
+	},
+	"track": func(ac *sbs.Aircraft, _, _ float64) (float64, bool) {
+		return ac.Track, ac.Has&sbs.HasTrack != 0
+	},
+	"speed_trend": func(ac *sbs.Aircraft, _, _ float64) (float64, bool) {
+		return ac.SpeedTrend, ac.Has&sbs.HasSpeed != 0
+	},
+	"distance": func(ac *sbs.Aircraft, refLat, refLon float64) (float64, bool) {
+		return geo.Distance(refLat, refLon, ac.Lat, ac.Lon), ac.Has&sbs.HasPosition != 0
+	},
+	"squawk": func(ac *sbs.Aircraft, _, _ float64) (float64, bool) {
+		code, err := strconv.Atoi(ac.Squawk)
+		return float64(code), ac.Has&sbs.HasSquawk != 0 && err == nil
+	},
+	"emergency": func(ac *sbs.Aircraft, _, _ float64) (float64, bool) {
+		if ac.SquawkEmergency() != "" {
+			return 1, true
+		}
+		return 0, ac.Has&(sbs.HasSquawk|sbs.HasEmergency) != 0
+	},
+}
+
+// ops are the comparisons, by how they're written
+var ops = map[string]func(a, b float64) bool{
+	"<":  func(a, b float64) bool { return a < b },
+	"<=": func(a, b float64) bool { return a <= b },
+	">":  func(a, b float64) bool { return a > b },
+	">=": func(a, b float64) bool { return a >= b },
+	"==": func(a, b float64) bool { return a == b },
+	"!=": func(a, b float64) bool { return a != b },
+}
+
+// Validate checks the rule only uses fields, operators and geofences that
+// exist
+func (r Rule) Validate(fences []geofence.Fence) error {
+	if r.Name == "" {
+		return errors.New("no name")
+	}
+	if len(r.When) == 0 && r.Area == "" && r.NotArea == "" {
+		return fmt.Errorf("%s: no conditions, so it'd match everything", r.Name)
+	}
+	for i, c := range r.When {
+		if _, ok := fields[c.Field]; !ok {
+			return fmt.Errorf("%s: when[%d]: unknown field %q (altitude, speed, track, speed_trend, distance, squawk or emergency)", r.Name, i, c.Field)
+		}
+		if _, ok := ops[c.Op]; !ok {
+			return fmt.Errorf("%s: when[%d]: unknown op %q", r.Name, i, c.Op)
+		}
+	}
+	for _, name := range []string{r.Area, r.NotArea} {
+		if name != "" && findFence(fences, name) < 0 {
+			return fmt.Errorf("%s: no geofence named %q", r.Name, name)
+		}
+	}
+	return nil
+}
+
+// findFence is the index of the named fence, or -1
+func findFence(fences []geofence.Fence, name string) int {
+	for i, f := range fences {
+		if f.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Alert is a rule an aircraft has started matching
+type Alert struct {
+	At   time.Time
+	ICAO string
+	Rule string
+	Text string // Like "Low and close: JBU1234"
+}
+
+// compiled is a rule with its fields, ops and fences looked up
+type compiled struct {
+	name    string
+	conds   []func(ac *sbs.Aircraft, refLat, refLon float64) bool
+	area    *geofence.Fence
+	notArea *geofence.Fence
+}
+
+// match reports whether every one of the rule's conditions holds
+func (c compiled) match(ac *sbs.Aircraft, refLat, refLon float64) bool {
+	if c.area != nil && !c.area.Contains(ac) {
+		return false
+	}
+	if c.notArea != nil && c.notArea.Contains(ac) {
+		return false
+	}
+	for _, cond := range c.conds {
+		if !cond(ac, refLat, refLon) {
+			return false
+		}
+	}
+	return true
+}
+
+// Engine runs the rules over the aircraft, alerting once each time an
+// aircraft starts matching one
+type Engine struct {
+	rules    []compiled
+	matching []map[string]bool // By rule, the ICAOs matching it
+	watch.Watch
+}
+
+// New creates an engine for rules, which have been validated against
+// fences
+func New(rules []Rule, fences []geofence.Fence) *Engine {
+	e := &Engine{}
+	for _, r := range rules {
+		c := compiled{name: r.Name}
+		for _, cond := range r.When {
+			get, op, value := fields[cond.Field], ops[cond.Op], cond.Value
+			c.conds = append(c.conds, func(ac *sbs.Aircraft, refLat, refLon float64) bool {
+				v, ok := get(ac, refLat, refLon)
+				return ok && op(v, value)
+			})
+		}
+		if i := findFence(fences, r.Area); i >= 0 {
+			c.area = &fences[i]
+		}
+		if i := findFence(fences, r.NotArea); i >= 0 {
+			c.notArea = &fences[i]
+		}
+		e.rules = append(e.rules, c)
+		e.matching = append(e.matching, make(map[string]bool))
+	}
+	return e
+}
+
+// Check runs the rules (at most every watch.Interval) and returns the
+// alerts for aircraft that have started matching since last time.
+// Distances are from the reference point.
+func (e *Engine) Check(all map[string]*sbs.Aircraft, refLat, refLon float64) []Alert {
+	now := time.Now()
+	if !e.Due(now) {
+		return nil
+	}
+
+	var alerts []Alert
+	for i, r := range e.rules {
+		matching := e.matching[i]
+		for hex, ac := range all {
+			match := !e.Quiet(ac, now) && r.match(ac, refLat, refLon)
+			if match && !matching[hex] {
+				name := ac.Callsign
+				if name == "" {
+					name = hex
+				}
+				alerts = append(alerts, Alert{At: now, ICAO: hex, Rule: r.name, Text: r.name + ": " + name})
+			}
+			if match {
+				matching[hex] = true
+			} else {
+				delete(matching, hex)
+			}
+		}
+		// Forget the ones the store has dropped
+		for hex := range matching {
+			if _, ok := all[hex]; !ok {
+				delete(matching, hex)
+			}
+		}
+	}
+	return alerts
+}
@@ -0,0 +1,124 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"termtrack/geofence"
+	"termtrack/sbs"
+	"termtrack/watch"
+)
+
+var fences = []geofence.Fence{{Name: "final", Points: [][2]float64{{40, -74}, {40, -73}, {41, -73}, {41, -74}}}}
+
+func TestValidate(t *testing.T) {
+	low := Condition{Field: "altitude", Op: "<", Value: 1500}
+	tests := []struct {
+		name string
+		rule Rule
+		want string // Part of the error, empty for none
+	}{
+		{name: "fine", rule: Rule{Name: "low", When: []Condition{low}}},
+		{name: "only an area", rule: Rule{Name: "in", Area: "final"}},
+		{name: "no name", rule: Rule{When: []Condition{low}}, want: "no name"},
+		{name: "nothing to test", rule: Rule{Name: "all"}, want: "match everything"},
+		{name: "unknown field", rule: Rule{Name: "x", When: []Condition{{Field: "colour", Op: "<"}}}, want: `unknown field "colour"`},
+		{name: "unknown op", rule: Rule{Name: "x", When: []Condition{{Field: "speed", Op: "=<"}}}, want: `unknown op "=<"`},
+		{name: "unknown fence", rule: Rule{Name: "x", NotArea: "downwind"}, want: `no geofence named "downwind"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate(fences)
+			if tt.want == "" && err != nil || tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	// The reference point is in the middle of the final fence
+	const refLat, refLon = 40.5, -73.5
+	inside := func(alt int) *sbs.Aircraft {
+		return &sbs.Aircraft{Lat: 40.55, Lon: -73.5, Altitude: alt, Has: sbs.HasPosition | sbs.HasAltitude}
+	}
+	tests := []struct {
+		name string
+		rule Rule
+		ac   *sbs.Aircraft
+		want bool
+	}{
+		{name: "low", rule: Rule{When: []Condition{{Field: "altitude", Op: "<", Value: 1500}}}, ac: inside(1000), want: true},
+		{name: "not low", rule: Rule{When: []Condition{{Field: "altitude", Op: "<", Value: 1500}}}, ac: inside(2000)},
+		{
+			name: "no altitude to test",
+			rule: Rule{When: []Condition{{Field: "altitude", Op: "<", Value: 1500}}},
+			ac:   &sbs.Aircraft{Lat: 40.55, Lon: -73.5, Has: sbs.HasPosition},
+		},
+		{
+			name: "low and close",
+			rule: Rule{When: []Condition{{Field: "altitude", Op: "<", Value: 1500}, {Field: "distance", Op: "<", Value: 5}}},
+			ac:   inside(1000),
+			want: true,
+		},
+		{
+			name: "low but far",
+			rule: Rule{When: []Condition{{Field: "altitude", Op: "<", Value: 1500}, {Field: "distance", Op: ">", Value: 5}}},
+			ac:   inside(1000),
+		},
+		{name: "in the area", rule: Rule{Area: "final"}, ac: inside(1000), want: true},
+		{name: "not out of the area", rule: Rule{NotArea: "final"}, ac: inside(1000)},
+		{
+			name: "squawk",
+			rule: Rule{When: []Condition{{Field: "squawk", Op: "==", Value: 7700}}},
+			ac:   &sbs.Aircraft{Squawk: "7700", Has: sbs.HasSquawk},
+			want: true,
+		},
+		{
+			name: "emergency",
+			rule: Rule{When: []Condition{{Field: "emergency", Op: "==", Value: 1}}},
+			ac:   &sbs.Aircraft{Squawk: "7600", Has: sbs.HasSquawk},
+			want: true,
+		},
+		{
+			name: "no emergency",
+			rule: Rule{When: []Condition{{Field: "emergency", Op: "==", Value: 0}}},
+			ac:   &sbs.Aircraft{Squawk: "1200", Has: sbs.HasSquawk},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New([]Rule{tt.rule}, fences)
+			if got := e.rules[0].match(tt.ac, refLat, refLon); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAlertsOnce(t *testing.T) {
+	e := New([]Rule{{Name: "Low", When: []Condition{{Field: "altitude", Op: "<", Value: 1500}}}}, fences)
+	ac := &sbs.Aircraft{ICAO: "A0B1C2", Callsign: "JBU1234", Altitude: 1000, Has: sbs.HasAltitude, LastSeen: time.Now()}
+	all := map[string]*sbs.Aircraft{ac.ICAO: ac}
+	check := func() []Alert {
+		e.Watch = watch.Watch{} // Due again without waiting out the interval
+		return e.Check(all, 40.5, -73.5)
+	}
+
+	if alerts := check(); len(alerts) != 1 || alerts[0].Text != "Low: JBU1234" {
+		t.Fatalf("first check got %+v, want one alert", alerts)
+	}
+	if alerts := check(); len(alerts) != 0 {
+		t.Errorf("still matching, got %+v", alerts)
+	}
+	ac.Altitude = 2000
+	if alerts := check(); len(alerts) != 0 {
+		t.Errorf("stopped matching, got %+v", alerts)
+	}
+	ac.Altitude = 1000
+	if alerts := check(); len(alerts) != 1 {
+		t.Errorf("matching again, got %+v, want one alert", alerts)
+	}
+}
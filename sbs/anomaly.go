@@ -0,0 +1,63 @@
+package sbs
+
+import (
+	"strings"
+
+	"termtrack/geo"
+)
+
+// Anomaly is a set of contradictions spotted in an aircraft's reports:
+// signs of a decoding problem, or of someone spoofing
+type Anomaly uint8
+
+const (
+	CallsignChanged Anomaly = 1 << iota // A new callsign without dropping out first
+	PositionJump                        // Moved further than anything could fly in the time
+	ImpossibleSpeed                     // Reported a groundspeed nothing flies at
+)
+
+// maxPlausibleSpeed is faster than anything that reports ADS-B flies, in knots
+const maxPlausibleSpeed = 1500.0
+
+// minJump is how far a position has to move before we judge its speed;
+// closer than this and a little position noise looks supersonic
+const minJump = 5.0
+
+var anomalyNames = []struct {
+	a    Anomaly
+	name string
+}{
+	{CallsignChanged, "callsign changed"},
+	{PositionJump, "position jump"},
+	{ImpossibleSpeed, "impossible speed"},
+}
+
+// String lists the anomalies, like "callsign changed, position jump"
+func (a Anomaly) String() string {
+	var names []string
+	for _, n := range anomalyNames {
+		if a&n.a != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// checkUpdate looks for an update contradicting what we already know about
+// the aircraft, before it's merged in. Anomalies stick for as long as the
+// aircraft is tracked.
+func (a *Aircraft) checkUpdate(update *Aircraft) {
+	if update.Has&HasCallsign != 0 && a.Callsign != "" && update.Callsign != a.Callsign {
+		a.Anomalies |= CallsignChanged
+	}
+	if update.Speed > maxPlausibleSpeed {
+		a.Anomalies |= ImpossibleSpeed
+	}
+	if last, ok := a.track.Last(); ok && update.Has&HasPosition != 0 {
+		dist := geo.Distance(last.Lat, last.Lon, update.Lat, update.Lon)
+		hours := rateElapsed(last.At, update.LastSeen).Hours()
+		if dist > minJump && (hours <= 0 || dist/hours > maxPlausibleSpeed) {
+			a.Anomalies |= PositionJump
+		}
+	}
+}
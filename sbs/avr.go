@@ -0,0 +1,292 @@
+package sbs
+
+import (
+	"encoding/hex"
+	"math"
+	"strings"
+	"time"
+)
+
+// AVRAddress is where dump1090 and readsb serve raw frames in the AVR
+// format. A feed takes them as well as SBS lines, so -feed can point here.
+const AVRAddress = "localhost:30002"
+
+// cprMaxAge is how far apart an even and an odd position frame can be and
+// still be decoded together; any further and the aircraft may have moved
+// into another zone
+const cprMaxAge = 10 * time.Second
+
+// avrPruneEvery is how often aircraft that have stopped sending are
+// forgotten by the decoder
+const avrPruneEvery = time.Minute
+
+// isAVR reports whether a line is a raw frame, like "*8D4840D6202CC371C32CE0576098;",
+// or the same with a timestamp, "@" and 12 hex digits in front, rather than SBS
+func isAVR(line string) bool {
+	return strings.HasPrefix(line, "*") || strings.HasPrefix(line, "@")
+}
+
+// cprFrame is the latitude and longitude from one position frame, each
+// 17 bits of a fraction of its zone
+type cprFrame struct {
+	lat, lon float64
+	at       time.Time
+}
+
+// cprPair is an aircraft's latest even and odd position frames, which it
+// takes one of each to place it anywhere on Earth
+type cprPair struct {
+	frames [2]cprFrame // Even, odd
+}
+
+// avrDecoder turns raw Mode S frames into updates. Only DF17 and DF18
+// extended squitters are decoded (identification, airborne position and
+// velocity); the rest carry nothing we show without interrogating. It's
+// used from the feed's goroutine only, so needs no lock.
+type avrDecoder struct {
+	positions map[string]*cprPair // By ICAO address
+	lastPrune time.Time
+}
+
+// decode reads a frame line into an update, or nil if it doesn't carry
+// anything we use, fails its checksum or isn't a frame at all
+func (d *avrDecoder) decode(line string, now time.Time) *Aircraft {
+	msg := frameBytes(line)
+	if len(msg) != 14 || crc(msg) != 0 {
+		return nil
+	}
+	df := msg[0] >> 3
+	if df != 17 && !(df == 18 && msg[0]&7 == 0) {
+		return nil // DF18 is ADS-B only with control field 0
+	}
+
+	update := &Aircraft{
+		ICAO:     strings.ToUpper(hex.EncodeToString(msg[1:4])),
+		LastSeen: now,
+	}
+	me := msg[4:11]
+	switch tc := me[0] >> 3; {
+	case tc >= 1 && tc <= 4:
+		decodeIdentification(update, me, tc)
+	case tc >= 9 && tc <= 18, tc >= 20 && tc <= 22:
+		d.decodePosition(update, me, tc, now)
+	case tc == 19:
+		decodeVelocity(update, me)
+	default:
+		return nil // Surface positions, status and so on
+	}
+	if update.Has == 0 && update.Category == "" {
+		return nil
+	}
+	return update
+}
+
+// frameBytes is the frame in a line, without the "*" or "@" and timestamp
+// in front and the ";" after. Anything that isn't hex gets nothing.
+func frameBytes(line string) []byte {
+	var frame string
+	switch {
+	case strings.HasPrefix(line, "*"):
+		frame = line[1:]
+	case strings.HasPrefix(line, "@") && len(line) > 13:
+		frame = line[13:]
+	default:
+		return nil
+	}
+	frame, _, _ = strings.Cut(frame, ";")
+	msg, err := hex.DecodeString(strings.TrimSpace(frame))
+	if err != nil {
+		return nil
+	}
+	return msg
+}
+
+// crcPoly is the Mode S parity polynomial
+const crcPoly = 0xfff409
+
+// crc is the Mode S parity of a frame, its last three bytes included, so
+// an undamaged extended squitter comes out 0
+func crc(msg []byte) uint32 {
+	var rem uint32
+	for _, b := range msg {
+		rem ^= uint32(b) << 16
+		for range 8 {
+			rem <<= 1
+			if rem&0x1000000 != 0 {
+				rem ^= crcPoly
+			}
+		}
+	}
+	return rem & 0xffffff
+}
+
+// bits reads n bits (up to 32) from data starting at bit start, the first
+// bit being the top of the first byte
+func bits(data []byte, start, n int) uint32 {
+	var v uint32
+	for i := start; i < start+n; i++ {
+		v = v<<1 | uint32(data[i/8]>>(7-i%8))&1
+	}
+	return v
+}
+
+// callsignChars is the 6-bit ADS-B character set; # marks codes not used,
+// which only turn up in garbled messages
+const callsignChars = "#ABCDEFGHIJKLMNOPQRSTUVWXYZ##### ###############0123456789######"
+
+// decodeIdentification reads the callsign and emitter category from an
+// identification message (type codes 1-4)
+func decodeIdentification(update *Aircraft, me []byte, tc byte) {
+	var b strings.Builder
+	for i := range 8 {
+		b.WriteByte(callsignChars[bits(me, 8+6*i, 6)])
+	}
+	// The padding and any #s are left to checkCallsign
+	if update.Callsign = b.String(); strings.TrimSpace(update.Callsign) != "" {
+		update.Has |= HasCallsign
+	}
+	// Type codes 4 down to 1 are category sets A to D, and the low three
+	// bits the category within the set, 0 meaning none given
+	if ca := me[0] & 7; ca != 0 {
+		update.Category = string(rune('A'+4-tc)) + string(rune('0'+ca))
+	}
+}
+
+// decodePosition reads the altitude from an airborne position message and
+// the position too, if there's a recent frame of the other parity to
+// decode it with
+func (d *avrDecoder) decodePosition(update *Aircraft, me []byte, tc byte, now time.Time) {
+	// Barometric altitude for type codes 9-18; 20-22 are GNSS height,
+	// which isn't what anything else reports, so it's left out. Only the
+	// 25ft encoding (the Q bit set) is read, not the old Gillham code.
+	if alt := bits(me, 8, 12); tc <= 18 && alt&0x10 != 0 {
+		update.Altitude = int(alt>>5<<4|alt&0xf)*25 - 1000
+		update.Has |= HasAltitude
+	}
+
+	if d.positions == nil {
+		d.positions = make(map[string]*cprPair)
+	}
+	if now.Sub(d.lastPrune) > avrPruneEvery {
+		for icao, p := range d.positions {
+			if now.Sub(p.frames[0].at) > cprMaxAge && now.Sub(p.frames[1].at) > cprMaxAge {
+				delete(d.positions, icao)
+			}
+		}
+		d.lastPrune = now
+	}
+
+	pair := d.positions[update.ICAO]
+	if pair == nil {
+		pair = &cprPair{}
+		d.positions[update.ICAO] = pair
+	}
+	odd := bits(me, 21, 1)
+	pair.frames[odd] = cprFrame{
+		lat: float64(bits(me, 22, 17)) / (1 << 17),
+		lon: float64(bits(me, 39, 17)) / (1 << 17),
+		at:  now,
+	}
+	other := pair.frames[1-odd]
+	if other.at.IsZero() || now.Sub(other.at) > cprMaxAge {
+		return
+	}
+	if lat, lon, ok := globalCPR(pair.frames[0], pair.frames[1], odd == 1); ok && validPosition(lat, lon) {
+		update.Lat, update.Lon = lat, lon
+		update.Has |= HasPosition
+	}
+}
+
+// globalCPR works out a position from an even and an odd frame, giving the
+// position as of the newer one. It fails if the two straddle a change in
+// the number of longitude zones, when there's no telling which applies.
+func globalCPR(even, odd cprFrame, oddNewer bool) (lat, lon float64, ok bool) {
+	const dLatEven, dLatOdd = 360.0 / 60, 360.0 / 59
+
+	j := math.Floor(59*even.lat - 60*odd.lat + 0.5)
+	latEven := dLatEven * (mod(j, 60) + even.lat)
+	latOdd := dLatOdd * (mod(j, 59) + odd.lat)
+	if latEven >= 270 {
+		latEven -= 360
+	}
+	if latOdd >= 270 {
+		latOdd -= 360
+	}
+	nl := cprNL(latEven)
+	if nl != cprNL(latOdd) {
+		return 0, 0, false
+	}
+
+	m := math.Floor(even.lon*float64(nl-1) - odd.lon*float64(nl) + 0.5)
+	if oddNewer {
+		n := max(nl-1, 1)
+		lat, lon = latOdd, 360/float64(n)*(mod(m, float64(n))+odd.lon)
+	} else {
+		n := max(nl, 1)
+		lat, lon = latEven, 360/float64(n)*(mod(m, float64(n))+even.lon)
+	}
+	if lon >= 180 {
+		lon -= 360
+	}
+	return lat, lon, true
+}
+
+// cprNL is the number of longitude zones at a latitude
+func cprNL(lat float64) int {
+	lat = math.Abs(lat)
+	switch {
+	case lat == 0:
+		return 59
+	case lat == 87:
+		return 2
+	case lat > 87:
+		return 1
+	}
+	const nz = 15
+	a := 1 - math.Cos(math.Pi/(2*nz))
+	b := math.Pow(math.Cos(math.Pi/180*lat), 2)
+	return int(math.Floor(2 * math.Pi / math.Acos(1-a/b)))
+}
+
+// mod is x modulo y, never negative
+func mod(x, y float64) float64 {
+	return x - y*math.Floor(x/y)
+}
+
+// decodeVelocity reads vertical rate, groundspeed and track from an
+// airborne velocity message. Speed and track only come from subtypes 1
+// and 2 (over the ground); 3 and 4 give airspeed and heading, which would
+// jump about against the ground track from positions.
+func decodeVelocity(update *Aircraft, me []byte) {
+	// The vertical rate's the same whichever the subtype, in 64 ft/min
+	// steps
+	if vr := int(bits(me, 37, 9)); vr != 0 {
+		update.VertRate = (vr - 1) * 64
+		if bits(me, 36, 1) == 1 {
+			update.VertRate = -update.VertRate
+		}
+		update.Has |= HasVertRate
+	}
+
+	subtype := me[0] & 7
+	if subtype != 1 && subtype != 2 {
+		return
+	}
+	ew, ns := int(bits(me, 14, 10)), int(bits(me, 25, 10))
+	if ew == 0 || ns == 0 {
+		return // Not available
+	}
+	vx, vy := float64(ew-1), float64(ns-1)
+	if bits(me, 13, 1) == 1 {
+		vx = -vx // Westward
+	}
+	if bits(me, 24, 1) == 1 {
+		vy = -vy // Southward
+	}
+	if subtype == 2 {
+		vx, vy = vx*4, vy*4 // Supersonic, in 4kt steps
+	}
+	update.Speed = math.Hypot(vx, vy)
+	update.Track = mod(math.Atan2(vx, vy)*180/math.Pi, 360)
+	update.Has |= HasSpeed | HasTrack
+}
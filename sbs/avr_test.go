@@ -0,0 +1,129 @@
+package sbs
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// The frames are the worked examples from "The 1090MHz Riddle"
+
+func TestAVRDecode(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		line string
+		want *Aircraft // nil for no update
+	}{
+		{
+			name: "identification",
+			line: "*8D4840D6202CC371C32CE0576098;",
+			want: &Aircraft{ICAO: "4840D6", Callsign: "KLM1023 ", Has: HasCallsign},
+		},
+		{
+			name: "identification with a timestamp",
+			line: "@0123456789AB8D4840D6202CC371C32CE0576098;",
+			want: &Aircraft{ICAO: "4840D6", Callsign: "KLM1023 ", Has: HasCallsign},
+		},
+		{
+			name: "position frame alone has just the altitude",
+			line: "*8D40621D58C382D690C8AC2863A7;",
+			want: &Aircraft{ICAO: "40621D", Altitude: 38000, Has: HasAltitude},
+		},
+		{name: "bad checksum", line: "*8D4840D6202CC371C32CE0576099;"},
+		{name: "not ADS-B", line: "*5D4840D6F3A2C5;"},
+		{name: "not hex", line: "*8D4840D6202CC371C32CE05760ZZ;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d avrDecoder
+			got := d.decode(tt.line, now)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("got %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("got nil")
+			}
+			if got.ICAO != tt.want.ICAO || got.Callsign != tt.want.Callsign || got.Altitude != tt.want.Altitude || got.Has != tt.want.Has {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAVRVelocity(t *testing.T) {
+	var d avrDecoder
+	got := d.decode("*8D485020994409940838175B284F;", time.Now())
+	if got == nil {
+		t.Fatal("got nil")
+	}
+	if got.Has != HasSpeed|HasTrack|HasVertRate {
+		t.Errorf("Has = %v", got.Has)
+	}
+	if math.Abs(got.Speed-159.2) > 0.1 || math.Abs(got.Track-182.88) > 0.01 || got.VertRate != -832 {
+		t.Errorf("got %.2f kt, %.2f°, %d ft/min; want 159.20 kt, 182.88°, -832 ft/min", got.Speed, got.Track, got.VertRate)
+	}
+}
+
+func TestAVRPosition(t *testing.T) {
+	const even, odd = "*8D40621D58C382D690C8AC2863A7;", "*8D40621D58C386435CC412692AD6;"
+	start := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		first    string
+		second   string
+		gap      time.Duration
+		lat, lon float64 // 0, 0 for no position
+	}{
+		{name: "even newer", first: odd, second: even, gap: time.Second, lat: 52.25720, lon: 3.91937},
+		{name: "odd newer", first: even, second: odd, gap: time.Second, lat: 52.26578, lon: 3.93891},
+		{name: "too far apart", first: odd, second: even, gap: cprMaxAge + time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d avrDecoder
+			if u := d.decode(tt.first, start); u == nil || u.Has&HasPosition != 0 {
+				t.Fatalf("first frame: got %+v, want an altitude and no position", u)
+			}
+			u := d.decode(tt.second, start.Add(tt.gap))
+			if u == nil {
+				t.Fatal("second frame: got nil")
+			}
+			if tt.lat == 0 && tt.lon == 0 {
+				if u.Has&HasPosition != 0 {
+					t.Errorf("got a position, %.5f, %.5f", u.Lat, u.Lon)
+				}
+				return
+			}
+			if u.Has&HasPosition == 0 {
+				t.Fatal("got no position")
+			}
+			if math.Abs(u.Lat-tt.lat) > 1e-5 || math.Abs(u.Lon-tt.lon) > 1e-5 {
+				t.Errorf("got %.5f, %.5f; want %.5f, %.5f", u.Lat, u.Lon, tt.lat, tt.lon)
+			}
+		})
+	}
+}
+
+func TestCPRNL(t *testing.T) {
+	tests := []struct {
+		lat  float64
+		want int
+	}{
+		{0, 59},
+		{10.4704713, 58},
+		{52.2572, 36},
+		{-52.2572, 36},
+		{86.9, 2},
+		{87, 2},
+		{88, 1},
+	}
+	for _, tt := range tests {
+		if got := cprNL(tt.lat); got != tt.want {
+			t.Errorf("cprNL(%g) = %d, want %d", tt.lat, got, tt.want)
+		}
+	}
+}
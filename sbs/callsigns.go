@@ -0,0 +1,53 @@
+package sbs
+
+import "time"
+
+// maxCallsigns is how many callsigns we keep per aircraft; plenty for a
+// day of short legs
+const maxCallsigns = 20
+
+// CallsignUse is one callsign an aircraft went by, from when we first
+// heard it to when we last did
+type CallsignUse struct {
+	Callsign string    `json:"callsign"`
+	First    time.Time `json:"first"`
+	Last     time.Time `json:"last"`
+}
+
+// noteCallsign records hearing callsign at at, starting a new use when it
+// isn't the one the aircraft was last going by
+func (a *Aircraft) noteCallsign(callsign string, at time.Time) {
+	if n := len(a.Callsigns); n > 0 && a.Callsigns[n-1].Callsign == callsign {
+		a.Callsigns[n-1].Last = at
+		return
+	}
+	a.Callsigns = append(a.Callsigns, CallsignUse{Callsign: callsign, First: at, Last: at})
+	if len(a.Callsigns) > maxCallsigns {
+		a.Callsigns = a.Callsigns[len(a.Callsigns)-maxCallsigns:]
+	}
+}
+
+// MergeCallsigns folds uses into history, both oldest first, and returns
+// the result. A use already there (same callsign, same start) just has
+// its end brought up to date, so merging the same aircraft again is fine.
+func MergeCallsigns(history, uses []CallsignUse) []CallsignUse {
+	for _, u := range uses {
+		found := false
+		for i := range history {
+			if history[i].Callsign == u.Callsign && history[i].First.Equal(u.First) {
+				if u.Last.After(history[i].Last) {
+					history[i].Last = u.Last
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			history = append(history, u)
+		}
+	}
+	if len(history) > maxCallsigns {
+		history = history[len(history)-maxCallsigns:]
+	}
+	return history
+}
@@ -0,0 +1,32 @@
+package sbs
+
+import "time"
+
+// Every time in the store comes from time.Now when the line arrived, never
+// from the message's own timestamp: receivers' clocks can be anywhere.
+// time.Now also carries a monotonic reading, which is what Sub goes by,
+// so how long since an aircraft was heard doesn't care about the wall
+// clock being set, or jumping hours on a laptop's resume. The monotonic
+// clock stops while the machine's suspended, so after a resume aircraft
+// pick up where they left off and age out as usual, rather than all
+// expiring at once.
+//
+// The one exception is a replay (see Feed.SetClock): its lines are
+// stamped with when they came in as recorded, so that played at 30x they
+// still move at the speed they flew.
+//
+// Rates are the other way round: hours of movement over no time at all
+// would be a supersonic jump and a wild speed trend. They go by whichever
+// clock saw more time pass.
+
+// rateElapsed is the time between two moments, for working out a rate
+func rateElapsed(from, to time.Time) time.Duration {
+	return max(to.Sub(from), to.Round(0).Sub(from.Round(0)))
+}
+
+// Suspended is how much longer the wall clock says passed between two
+// moments from time.Now than the monotonic clock does: near enough, how
+// long the machine was asleep in between
+func Suspended(from, to time.Time) time.Duration {
+	return to.Round(0).Sub(from.Round(0)) - to.Sub(from)
+}
@@ -0,0 +1,332 @@
+package sbs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ErrFeedClosed is what a feed ends with when it was shut on purpose
+var ErrFeedClosed = errors.New("sbs feed closed")
+
+// reconnectAfter is how long Connect waits to dial a feed again after it
+// drops or can't be reached
+const reconnectAfter = 10 * time.Second
+
+// maxHeld is how many updates a paused feed will hold before it starts
+// dropping the oldest, so a forgotten pause can't eat all the memory
+const maxHeld = 100000
+
+// LineFunc is called with every raw line a feed receives
+type LineFunc func(at time.Time, line string)
+
+// Feed reads SBS lines, raw AVR frames (see AVRAddress) or aircraft in
+// JSON (see Dial) on its own goroutine and writes every update straight
+// into a Store, so ingestion never waits on the UI. Anything else that
+// wants the raw lines (the message log, recorders) subscribes with OnLine.
+type Feed struct {
+	name   string // Which receiver this is, for attribution
+	store  *Store
+	source Source           // What kind of positions it carries
+	clock  func() time.Time // What updates are stamped with, time.Now if nil
+
+	mu        sync.Mutex
+	listeners []LineFunc
+	conn      io.Closer
+	closed    bool
+	done      chan struct{} // Closed by Close
+	err       error         // Why Connect's connection is down, if it is
+	lastLine  time.Time
+
+	// While paused the connection stays up and lines keep arriving, but
+	// updates are held back from the store until Resume
+	paused bool
+	held   []*Aircraft
+
+	lines   atomic.Uint64 // Raw lines received
+	updates atomic.Uint64 // Lines that parsed into an update
+	dropped atomic.Uint64 // Updates over the rate limit
+	latency latencyMeter
+	limiter rateLimiter
+	avr     avrDecoder     // For raw frames, kept between them for positions
+	calls   callsignCounts // What checkCallsign's made of the callsigns
+}
+
+// NewFeed creates a feed for the named receiver that writes into store
+func NewFeed(name string, store *Store) *Feed {
+	return &Feed{name: name, store: store, done: make(chan struct{})}
+}
+
+// Name returns the receiver name the feed attributes its updates to
+func (f *Feed) Name() string {
+	return f.name
+}
+
+// SetSource says what kind of positions the feed carries, ADS-B unless
+// set. Call it before Run.
+func (f *Feed) SetSource(s Source) {
+	f.source = s
+}
+
+// Source returns what kind of positions the feed carries
+func (f *Feed) Source() Source {
+	return f.source
+}
+
+// SetClock sets what the feed stamps its updates with in place of
+// time.Now, for a replay going by the recording's time. Call it before Run.
+func (f *Feed) SetClock(clock func() time.Time) {
+	f.clock = clock
+}
+
+// SetRateLimit caps how many updates a second reach the store, 0 (the
+// default) for no cap. Positions are let through before anything else.
+// Lines over the cap still reach OnLine subscribers, so logs and
+// recordings stay whole. Call it before Run.
+func (f *Feed) SetRateLimit(perSecond int) {
+	f.limiter.perSecond = perSecond
+}
+
+// OnLine subscribes fn to every raw line. fn runs on the feed's goroutine,
+// so it must be quick and safe for concurrent use.
+func (f *Feed) OnLine(fn LineFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listeners = append(f.listeners, fn)
+}
+
+// Start begins reading from conn in the background. The returned command
+// delivers an SbsErrorMsg when the feed ends.
+func (f *Feed) Start(conn io.ReadCloser) tea.Cmd {
+	done := make(chan error, 1)
+	go func() {
+		done <- f.Run(conn)
+	}()
+
+	return func() tea.Msg {
+		return SbsErrorMsg{Err: <-done}
+	}
+}
+
+// Run reads from conn until it ends, for when there's no TUI to report to.
+// It always returns the reason the feed stopped: ErrFeedClosed if it was
+// shut with Close.
+func (f *Feed) Run(conn io.ReadCloser) error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		conn.Close()
+		return ErrFeedClosed
+	}
+	f.conn = conn
+	f.mu.Unlock()
+
+	err := f.run(conn)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return ErrFeedClosed
+	}
+	conn.Close() // The other end went away, don't leak our side
+	f.conn = nil
+	return err
+}
+
+// Close shuts the connection, which also ends the reading goroutine.
+// It's safe to call more than once, and the feed won't start again after.
+func (f *Feed) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	close(f.done)
+	if f.conn == nil {
+		return nil
+	}
+	err := f.conn.Close()
+	f.conn = nil
+	return err
+}
+
+// Connect keeps the feed connected to addr in the background, dialling it
+// again a little while after it drops, until Close. It's for the feeds
+// beside the main one, which shouldn't take everything down with them;
+// Err says why one's down.
+func (f *Feed) Connect(addr string) {
+	go func() {
+		for {
+			conn, err := Dial(addr)
+			if err == nil {
+				f.setErr(nil)
+				err = f.Run(conn)
+			}
+			if errors.Is(err, ErrFeedClosed) {
+				return
+			}
+			f.setErr(err)
+			select {
+			case <-f.done:
+				return
+			case <-time.After(reconnectAfter):
+			}
+		}
+	}()
+}
+
+// Err is why the connection Connect keeps up is down, or nil while it's up
+func (f *Feed) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+func (f *Feed) setErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.err = err
+}
+
+// run is the ingestion loop
+func (f *Feed) run(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		now := time.Now()
+		f.lines.Add(1)
+		f.publish(now, line)
+
+		at := now
+		if f.clock != nil {
+			if c := f.clock(); !c.IsZero() {
+				at = c
+			}
+		}
+		var update *Aircraft
+		source := f.source
+		switch {
+		case isAVR(line):
+			update = f.avr.decode(line, at)
+		case isJSON(line):
+			update, source = parseJSON(line, at, source)
+		default:
+			fields := strings.Split(line, ",")
+			if sent, ok := messageTime(fields); ok {
+				f.latency.add(sent, at)
+			}
+			update = parseSbsFields(fields, at)
+		}
+		if update != nil {
+			update.Source = source
+			f.calls.checkCallsign(update)
+			f.updates.Add(1)
+			if !f.limiter.allow(now, update.Has&HasPosition != 0) {
+				f.dropped.Add(1)
+				continue
+			}
+			f.ingest(update)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("sbs read: %w", err)
+	}
+	return fmt.Errorf("sbs feed disconnected")
+}
+
+// ingest writes an update into the store, or holds it if we're paused
+func (f *Feed) ingest(update *Aircraft) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.paused {
+		f.held = append(f.held, update)
+		if len(f.held) > maxHeld {
+			f.held = f.held[len(f.held)-maxHeld:]
+		}
+		return
+	}
+	f.store.Upsert(f.name, update)
+}
+
+// Pause stops updates reaching the store, freezing the picture. The
+// connection stays up and updates are kept for Resume.
+func (f *Feed) Pause() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = true
+}
+
+// Resume catches the store up with everything that arrived while paused,
+// in order and with their original times, then carries on live. It
+// returns how many updates it applied.
+func (f *Feed) Resume() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := len(f.held)
+	for _, update := range f.held {
+		f.store.Upsert(f.name, update)
+	}
+	f.held = nil
+	f.paused = false
+	return n
+}
+
+// Paused reports whether the feed is paused, and how many updates it's holding
+func (f *Feed) Paused() (paused bool, held int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.paused, len(f.held)
+}
+
+// publish hands a raw line to every listener
+func (f *Feed) publish(at time.Time, line string) {
+	f.mu.Lock()
+	listeners := f.listeners
+	f.lastLine = at
+	f.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(at, line)
+	}
+}
+
+// LastLine returns when the feed last received a line
+func (f *Feed) LastLine() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastLine
+}
+
+// Counts returns how many lines the feed has received, and how many of
+// those parsed into aircraft updates
+func (f *Feed) Counts() (lines, updates uint64) {
+	return f.lines.Load(), f.updates.Load()
+}
+
+// Dropped returns how many updates were left out for going over the rate
+// limit
+func (f *Feed) Dropped() uint64 {
+	return f.dropped.Load()
+}
+
+// CallsignStats returns how many callsigns the feed's had to tidy up or
+// throw out. Conflicts between receivers are the store's to count.
+func (f *Feed) CallsignStats() CallsignStats {
+	return CallsignStats{Trimmed: f.calls.trimmed.Load(), Garbled: f.calls.garbled.Load()}
+}
+
+// Latency is how long messages took from the receiver stamping them to
+// reaching us, over the last few seconds. It's only as good as the two
+// clocks agree.
+func (f *Feed) Latency() Latency {
+	return f.latency.get()
+}
@@ -0,0 +1,125 @@
+package sbs
+
+import (
+	"sort"
+	"time"
+)
+
+// keyframeEvery is how often the history takes a full copy of the store.
+// Rebuilding a moment replays at most this much from the keyframe before it.
+const keyframeEvery = 30 * time.Second
+
+// keyframe is the whole store as it was just before update seq
+type keyframe struct {
+	at       time.Time
+	seq      int
+	aircraft map[string]*Aircraft
+}
+
+// historyEntry is one update as it went into the store
+type historyEntry struct {
+	receiver string
+	update   Aircraft
+}
+
+// history is the store's memory of the last window of updates, so the
+// picture can be rebuilt as it was at any moment in it. It's guarded by
+// the store's lock.
+type history struct {
+	window    time.Duration
+	keyframes []keyframe
+	entries   []historyEntry
+	base      int // Sequence number of entries[0]
+}
+
+// KeepHistory makes the store remember the last window of updates for
+// SnapshotAt. Zero turns it off.
+func (s *Store) KeepHistory(window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if window <= 0 {
+		s.history = nil
+		return
+	}
+	s.history = &history{window: window}
+}
+
+// HistoryStart returns the earliest moment SnapshotAt can rebuild, or the
+// zero time if there's no history
+func (s *Store) HistoryStart() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.history == nil || len(s.history.keyframes) == 0 {
+		return time.Time{}
+	}
+	return s.history.keyframes[0].at
+}
+
+// SnapshotAt returns the aircraft as they were at t, like Snapshot does
+// for now. Anything before HistoryStart comes back as the oldest we have.
+func (s *Store) SnapshotAt(t time.Time) map[string]*Aircraft {
+	s.mu.RLock()
+	h := s.history
+	if h == nil || len(h.keyframes) == 0 {
+		s.mu.RUnlock()
+		return s.Snapshot()
+	}
+
+	// 1. Start from the last keyframe at or before t
+	i := sort.Search(len(h.keyframes), func(i int) bool { return h.keyframes[i].at.After(t) })
+	kf := h.keyframes[max(i-1, 0)]
+	replay := NewStore()
+	replay.timeouts = s.timeouts
+	replay.aircraft = copyLive(kf.aircraft)
+
+	// 2. Collect the updates after it, up to t
+	var updates []historyEntry
+	for _, e := range h.entries[kf.seq-h.base:] {
+		if e.update.LastSeen.After(t) {
+			break
+		}
+		updates = append(updates, e)
+	}
+	s.mu.RUnlock()
+
+	// 3. Replay them into the copy
+	for _, e := range updates {
+		update := e.update
+		replay.Upsert(e.receiver, &update)
+	}
+	return copyAircraft(replay.aircraft)
+}
+
+// record notes an update that's about to go into s, taking a keyframe
+// first if it's time for one
+func (h *history) record(s *Store, receiver string, update *Aircraft) {
+	at := update.LastSeen
+	if n := len(h.keyframes); n == 0 || at.Sub(h.keyframes[n-1].at) >= keyframeEvery {
+		h.keyframes = append(h.keyframes, keyframe{
+			at:       at,
+			seq:      h.base + len(h.entries),
+			aircraft: copyLive(s.aircraft),
+		})
+		h.prune(at)
+	}
+	h.entries = append(h.entries, historyEntry{receiver: receiver, update: *update})
+}
+
+// prune forgets what's older than the window, keeping the keyframe the
+// oldest remaining moment is rebuilt from
+func (h *history) prune(now time.Time) {
+	cutoff := now.Add(-h.window)
+	drop := 0
+	for drop+1 < len(h.keyframes) && !h.keyframes[drop+1].at.After(cutoff) {
+		drop++
+	}
+	if drop == 0 {
+		return
+	}
+	h.keyframes = h.keyframes[drop:]
+
+	// Copy rather than reslice so the dropped entries can be freed
+	first := h.keyframes[0].seq - h.base
+	h.entries = append([]historyEntry(nil), h.entries[first:]...)
+	h.base = h.keyframes[0].seq
+}
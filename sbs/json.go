@@ -0,0 +1,103 @@
+package sbs
+
+import (
+	"cmp"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONAddress is where readsb serves its JSON position output, if it's
+// started with --net-json-port 30047: a line of JSON for every position
+// decoded. A feed takes those lines as well as SBS, so -feed can point
+// here.
+const JSONAddress = "localhost:30047"
+
+// jsonAircraft is one aircraft as readsb and dump1090 describe it in
+// JSON, with the fields we read: the entries of aircraft.json and the
+// lines of readsb's JSON port are the same shape. Unlike SBS it has the
+// emitter category and signal level.
+type jsonAircraft struct {
+	Hex      string          `json:"hex"`
+	Type     string          `json:"type,omitempty"` // Where the data came from: "adsb_icao", "mlat" and so on
+	Flight   string          `json:"flight,omitempty"`
+	AltBaro  json.RawMessage `json:"alt_baro,omitempty"`  // Feet, or "ground"
+	BaroRate *float64        `json:"baro_rate,omitempty"` // Feet a minute
+	GeomRate *float64        `json:"geom_rate,omitempty"` // The same from GNSS, when there's no baro_rate
+	GS       *float64        `json:"gs,omitempty"`
+	Track    *float64        `json:"track,omitempty"`
+	Lat      *float64        `json:"lat,omitempty"`
+	Lon      *float64        `json:"lon,omitempty"`
+	Category string          `json:"category,omitempty"`
+	Squawk   string          `json:"squawk,omitempty"`
+	RSSI     *float64        `json:"rssi,omitempty"`
+	Seen     *float64        `json:"seen,omitempty"`     // Seconds since it was last heard
+	SeenPos  *float64        `json:"seen_pos,omitempty"` // Seconds since its position was
+}
+
+// isJSON reports whether a line is an aircraft in JSON rather than SBS
+func isJSON(line string) bool {
+	return strings.HasPrefix(line, "{")
+}
+
+// parseJSON reads a line holding one aircraft's JSON into an update, or
+// nil if it isn't one we can track. Its source is the feed's, unless the
+// line says it's multilaterated.
+func parseJSON(line string, now time.Time, source Source) (*Aircraft, Source) {
+	var j jsonAircraft
+	if err := json.Unmarshal([]byte(line), &j); err != nil {
+		return nil, source
+	}
+	// A ~ marks an address that isn't an ICAO one (TIS-B tracks and the
+	// like), which can't be looked up or told apart from real ones
+	if j.Hex == "" || strings.HasPrefix(j.Hex, "~") {
+		return nil, source
+	}
+	if j.Type == "mlat" {
+		source = SourceMLAT
+	}
+
+	update := &Aircraft{
+		ICAO:     strings.ToUpper(j.Hex),
+		LastSeen: now,
+		Category: j.Category,
+	}
+	if update.Callsign = j.Flight; strings.TrimSpace(update.Callsign) != "" {
+		update.Has |= HasCallsign
+	}
+	// An altitude means it's in the air; "ground" has none to give
+	if alt, err := strconv.Atoi(string(j.AltBaro)); err == nil {
+		update.Altitude = alt
+		update.Has |= HasAltitude | HasGround
+	} else if string(j.AltBaro) == `"ground"` {
+		update.OnGround = true
+		update.Has |= HasGround
+	}
+	if rate := cmp.Or(j.BaroRate, j.GeomRate); rate != nil {
+		update.VertRate = int(math.Round(*rate))
+		update.Has |= HasVertRate
+	}
+	if j.GS != nil {
+		update.Speed = *j.GS
+		update.Has |= HasSpeed
+	}
+	if j.Track != nil {
+		update.Track = *j.Track
+		update.Has |= HasTrack
+	}
+	if j.Lat != nil && j.Lon != nil && validPosition(*j.Lat, *j.Lon) {
+		update.Lat, update.Lon = *j.Lat, *j.Lon
+		update.Has |= HasPosition
+	}
+	if j.Squawk != "" {
+		update.Squawk = j.Squawk
+		update.Has |= HasSquawk
+	}
+	if j.RSSI != nil {
+		update.Signal = *j.RSSI
+		update.Has |= HasSignal
+	}
+	return update, source
+}
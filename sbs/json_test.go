@@ -0,0 +1,74 @@
+package sbs
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseJSON(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		line   string
+		want   *Aircraft // nil for no update
+		source Source
+	}{
+		{
+			name: "everything",
+			line: `{"hex":"a0b1c2","type":"adsb_icao","flight":"JBU1234 ","alt_baro":2500,"baro_rate":-640,"gs":160.5,"track":310.2,"lat":40.62398,"lon":-73.76275,"category":"A3","squawk":"1200","rssi":-20.5}`,
+			want: &Aircraft{ICAO: "A0B1C2", Callsign: "JBU1234 ", Altitude: 2500, VertRate: -640, Speed: 160.5, Track: 310.2,
+				Lat: 40.62398, Lon: -73.76275, Category: "A3", Squawk: "1200", Signal: -20.5,
+				Has: HasCallsign | HasAltitude | HasGround | HasVertRate | HasSpeed | HasTrack | HasPosition | HasSquawk | HasSignal},
+			source: SourceADSB,
+		},
+		{
+			name:   "multilaterated",
+			line:   `{"hex":"a0b1c2","type":"mlat","lat":40.6,"lon":-73.7}`,
+			want:   &Aircraft{ICAO: "A0B1C2", Lat: 40.6, Lon: -73.7, Has: HasPosition},
+			source: SourceMLAT,
+		},
+		{
+			name:   "on the ground, with no altitude",
+			line:   `{"hex":"a0b1c2","alt_baro":"ground","gs":12}`,
+			want:   &Aircraft{ICAO: "A0B1C2", Speed: 12, OnGround: true, Has: HasSpeed | HasGround},
+			source: SourceADSB,
+		},
+		{
+			name:   "geometric rate when there's no barometric one",
+			line:   `{"hex":"a0b1c2","geom_rate":511.6}`,
+			want:   &Aircraft{ICAO: "A0B1C2", VertRate: 512, Has: HasVertRate},
+			source: SourceADSB,
+		},
+		{
+			name:   "position off the Earth",
+			line:   `{"hex":"a0b1c2","lat":91,"lon":200}`,
+			want:   &Aircraft{ICAO: "A0B1C2"},
+			source: SourceADSB,
+		},
+		{name: "non-ICAO address", line: `{"hex":"~a0b1c2","lat":40.6,"lon":-73.7}`, source: SourceADSB},
+		{name: "no address", line: `{"lat":40.6,"lon":-73.7}`, source: SourceADSB},
+		{name: "not JSON", line: `{"hex":`, source: SourceADSB},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, source := parseJSON(tt.line, now, SourceADSB)
+			if source != tt.source {
+				t.Errorf("source = %v, want %v", source, tt.source)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("got %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("got nil")
+			}
+			tt.want.LastSeen = now
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package sbs
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// sbsTimeLayout is a message's date and time fields joined with a space.
+// Receivers that don't give milliseconds still parse.
+const sbsTimeLayout = "2006/01/02 15:04:05.999999999"
+
+// latencyWindow is how long latency is averaged over before it's reported
+const latencyWindow = 10 * time.Second
+
+// zoneStep is the finest step time zones come in. A receiver writing its
+// local time in another zone from ours looks hours out; anything beyond
+// half a step is taken to be that and taken off.
+const zoneStep = 15 * time.Minute
+
+// Latency is how far behind the feed was over the last window
+type Latency struct {
+	Mean, Max time.Duration
+	Samples   int
+}
+
+// latencyMeter averages how long messages took to reach us
+type latencyMeter struct {
+	mu    sync.Mutex
+	start time.Time
+	sum   time.Duration
+	max   time.Duration
+	n     int
+	last  Latency // The last full window
+}
+
+// messageTime is when the receiver generated a message, from fields 6 and
+// 7. SBS times have no zone; receivers write their local time, which we
+// read as ours.
+func messageTime(fields []string) (time.Time, bool) {
+	if len(fields) < 8 || fields[6] == "" || fields[7] == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(sbsTimeLayout, strings.TrimSpace(fields[6])+" "+strings.TrimSpace(fields[7]), time.Local)
+	return t, err == nil
+}
+
+// add records one message generated at sent and received at now
+func (l *latencyMeter) add(sent, now time.Time) {
+	d := now.Sub(sent)
+	if d > zoneStep/2 || d < -zoneStep/2 {
+		d -= d.Round(zoneStep)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.start) > latencyWindow {
+		if l.n > 0 {
+			l.last = Latency{Mean: l.sum / time.Duration(l.n), Max: l.max, Samples: l.n}
+		}
+		l.start, l.sum, l.max, l.n = now, 0, d, 0
+	}
+	l.sum += d
+	l.max = max(l.max, d)
+	l.n++
+}
+
+// get returns the last full window's latency
+func (l *latencyMeter) get() Latency {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.last
+}
@@ -0,0 +1,34 @@
+package sbs
+
+import "time"
+
+// rateLimiter keeps a feed to a budget of updates a second, for busy
+// remote feeds on small machines. It's only used from the feed's
+// goroutine, so needs no lock.
+type rateLimiter struct {
+	perSecond int // 0 for no limit
+	second    time.Time
+	used      int
+}
+
+// allow reports whether an update arriving at now fits in this second's
+// budget. Positions can have all of it but anything else only the first
+// half, so when the feed is busier than the budget it's mostly callsigns
+// and speeds that are dropped, and aircraft keep moving on the map.
+func (l *rateLimiter) allow(now time.Time, position bool) bool {
+	if l.perSecond <= 0 {
+		return true
+	}
+	if second := now.Truncate(time.Second); !second.Equal(l.second) {
+		l.second, l.used = second, 0
+	}
+	budget := l.perSecond
+	if !position {
+		budget = (l.perSecond + 1) / 2
+	}
+	if l.used >= budget {
+		return false
+	}
+	l.used++
+	return true
+}
@@ -0,0 +1,52 @@
+package sbs
+
+import (
+	"sync"
+	"time"
+)
+
+// Line is a raw line as it came off a feed
+type Line struct {
+	Seq  uint64 // Increases by one per line, so readers can pick up where they left off
+	At   time.Time
+	Text string
+}
+
+// LineLog is a fixed-size, concurrency-safe ring of the most recent raw lines
+type LineLog struct {
+	mu    sync.Mutex
+	lines []Line
+	next  uint64 // Seq of the next line to be appended
+}
+
+// NewLineLog creates a log holding at most capacity lines
+func NewLineLog(capacity int) *LineLog {
+	return &LineLog{lines: make([]Line, capacity)}
+}
+
+// Append adds a line, overwriting the oldest once the log is full
+func (l *LineLog) Append(at time.Time, text string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.lines[l.next%uint64(len(l.lines))] = Line{Seq: l.next, At: at, Text: text}
+	l.next++
+}
+
+// Since returns the lines with Seq >= seq still in the log, oldest first,
+// plus the seq to ask for next time
+func (l *LineLog) Since(seq uint64) ([]Line, uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// Lines older than the ring are gone
+	if oldest := l.next - min(l.next, uint64(len(l.lines))); seq < oldest {
+		seq = oldest
+	}
+
+	var out []Line
+	for ; seq < l.next; seq++ {
+		out = append(out, l.lines[seq%uint64(len(l.lines))])
+	}
+	return out, l.next
+}
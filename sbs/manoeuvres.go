@@ -0,0 +1,56 @@
+package sbs
+
+import (
+	"math"
+	"time"
+
+	"termtrack/geo"
+)
+
+// circleWindow is how far back along the trail we look for a full circle
+const circleWindow = 3 * time.Minute
+
+// minLeg is the shortest trail leg (in nautical miles) we take a bearing
+// from; over shorter ones position noise swings it all over
+const minLeg = 0.05
+
+// hoverSpeed is the groundspeed, in knots, below which something with an
+// airborne position is taken to be hovering
+const hoverSpeed = 5.0
+
+// Circling reports whether the aircraft has turned through a full circle,
+// one way, within the last circleWindow of its trail
+func (a *Aircraft) Circling(now time.Time) bool {
+	var turned, lastBearing float64
+	var have bool
+	var prev *TrailPoint
+	for i := range a.Trail {
+		p := &a.Trail[i]
+		if now.Sub(p.At) > circleWindow {
+			continue
+		}
+		if prev != nil && geo.Distance(prev.Lat, prev.Lon, p.Lat, p.Lon) < minLeg {
+			continue // Too close to the last point to say which way it went
+		}
+		if prev != nil {
+			b := geo.Bearing(prev.Lat, prev.Lon, p.Lat, p.Lon)
+			if have {
+				turned += math.Mod(b-lastBearing+540, 360) - 180
+			}
+			lastBearing, have = b, true
+		}
+		prev = p
+	}
+	return math.Abs(turned) >= 360
+}
+
+// Hovering reports whether the aircraft is holding still in the air. SBS
+// only gives us airborne positions, so one with a position and next to no
+// groundspeed is off the ground and not going anywhere. Balloons and
+// gliders drift that slowly as a matter of course, so they never count.
+func (a *Aircraft) Hovering() bool {
+	if a.Category == "B1" || a.Category == "B2" {
+		return false
+	}
+	return (a.Lat != 0 || a.Lon != 0) && a.Speed != 0 && a.SmoothSpeed < hoverSpeed
+}
@@ -0,0 +1,77 @@
+package sbs
+
+import (
+	"strings"
+	"sync/atomic"
+)
+
+// maxCallsignLen is the longest a callsign can be: eight characters is
+// all ADS-B has room for
+const maxCallsignLen = 8
+
+// CallsignStats counts what the callsign checks have made of the
+// callsigns coming in
+type CallsignStats struct {
+	Trimmed   uint64 // Tidied up: stray spaces stripped, or lowercase raised
+	Garbled   uint64 // Thrown out for characters no callsign has
+	Conflicts uint64 // Held back for disagreeing with another receiver
+}
+
+// callsignCounts are a feed's counts for CallsignStats
+type callsignCounts struct {
+	trimmed atomic.Uint64
+	garbled atomic.Uint64
+}
+
+// checkCallsign puts an update's callsign into the one form everything
+// else expects, whichever format it came in: no padding, uppercase. One
+// with anything but letters and digits left in it was garbled on the way
+// (a bit error, a decoder's # for a character it couldn't read, another
+// alphabet) and is dropped rather than shown, the rest of the update
+// going through without it.
+func (c *callsignCounts) checkCallsign(update *Aircraft) {
+	if update.Has&HasCallsign == 0 {
+		return
+	}
+	callsign := strings.ToUpper(strings.TrimSpace(update.Callsign))
+	if !validCallsign(callsign) {
+		c.garbled.Add(1)
+		update.Callsign = ""
+		update.Has &^= HasCallsign
+		return
+	}
+	// Spaces padding it out to eight are how it's sent, so aren't
+	// worth counting
+	if callsign != strings.TrimRight(update.Callsign, " ") {
+		c.trimmed.Add(1)
+	}
+	update.Callsign = callsign
+}
+
+// validCallsign reports whether s could be a callsign: one to eight
+// letters and digits
+func validCallsign(s string) bool {
+	if s == "" || len(s) > maxCallsignLen {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// acceptCallsign reports whether a callsign a receiver heard should
+// become the aircraft's. One receiver disagreeing with the one it got its
+// callsign from is held back until it's heard twice running, from any
+// receiver, so a single garbled copy doesn't flip the label back and
+// forth; a genuine change soon comes in again.
+func (a *Aircraft) acceptCallsign(receiver, callsign string) bool {
+	if a.Callsign == "" || callsign == a.Callsign || receiver == a.callsignBy || callsign == a.pendingCallsign {
+		a.callsignBy, a.pendingCallsign = receiver, ""
+		return true
+	}
+	a.pendingCallsign = callsign
+	return false
+}
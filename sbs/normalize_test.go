@@ -0,0 +1,36 @@
+package sbs
+
+import "testing"
+
+func TestCheckCallsign(t *testing.T) {
+	tests := []struct {
+		name     string
+		callsign string
+		want     string // Empty for dropped
+		trimmed  bool
+		garbled  bool
+	}{
+		{name: "as it should be", callsign: "JBU1234", want: "JBU1234"},
+		{name: "padded to eight", callsign: "JBU1234 ", want: "JBU1234"},
+		{name: "padded short one", callsign: "N123    ", want: "N123"},
+		{name: "lowercase", callsign: "jbu1234 ", want: "JBU1234", trimmed: true},
+		{name: "leading space", callsign: " JBU1234", want: "JBU1234", trimmed: true},
+		{name: "tab", callsign: "JBU1234\t", want: "JBU1234", trimmed: true},
+		{name: "undecodable character", callsign: "JBU#234 ", garbled: true},
+		{name: "too long", callsign: "JBU123456", garbled: true},
+		{name: "blank", callsign: "        ", garbled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c callsignCounts
+			update := &Aircraft{ICAO: "A0B1C2", Callsign: tt.callsign, Has: HasCallsign}
+			c.checkCallsign(update)
+			if update.Callsign != tt.want || (update.Has&HasCallsign != 0) != (tt.want != "") {
+				t.Errorf("got %q (Has %v), want %q", update.Callsign, update.Has, tt.want)
+			}
+			if trimmed, garbled := c.trimmed.Load() == 1, c.garbled.Load() == 1; trimmed != tt.trimmed || garbled != tt.garbled {
+				t.Errorf("trimmed %v, garbled %v; want %v, %v", trimmed, garbled, tt.trimmed, tt.garbled)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package sbs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// pollInterval is how often aircraft.json is fetched; readsb and
+// dump1090 write it afresh every second
+const pollInterval = time.Second
+
+// pollTimeout is as long as one fetch gets
+const pollTimeout = 5 * time.Second
+
+// isURL reports whether a feed address is a web endpoint to poll rather
+// than a port to connect to
+func isURL(addr string) bool {
+	return strings.HasPrefix(addr, "http://") || strings.HasPrefix(addr, "https://")
+}
+
+// aircraftJSONURL is where to find aircraft.json from addr, which is
+// either the file itself or the receiver's web page, like
+// http://pi:8080 or http://pi/tar1090
+func aircraftJSONURL(addr string) (string, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasSuffix(u.Path, ".json") {
+		u = u.JoinPath("data", "aircraft.json")
+	}
+	return u.String(), nil
+}
+
+// aircraftFile is aircraft.json: everything the receiver is tracking, as
+// of now (seconds since the epoch)
+type aircraftFile struct {
+	Now      float64        `json:"now"`
+	Aircraft []jsonAircraft `json:"aircraft"`
+}
+
+// poller fetches aircraft.json over and over and reads like a feed
+// connection: one line of JSON for each aircraft heard since the fetch
+// before, so nothing gets counted twice.
+type poller struct {
+	url    string
+	r      *io.PipeReader
+	w      *io.PipeWriter
+	ctx    context.Context
+	cancel context.CancelFunc
+	once   sync.Once
+
+	// When each aircraft, and its position, was last passed on, in the
+	// receiver's seconds
+	heard, positioned map[string]float64
+}
+
+// poll starts polling the aircraft.json at addr. It fetches once before
+// returning, so a wrong address fails here, the way a refused connection
+// would.
+func poll(addr string) (*poller, error) {
+	u, err := aircraftJSONURL(addr)
+	if err != nil {
+		return nil, fmt.Errorf("sbs poll: %w", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &poller{url: u, ctx: ctx, cancel: cancel, heard: make(map[string]float64), positioned: make(map[string]float64)}
+	file, err := p.fetch()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	p.r, p.w = io.Pipe()
+	go p.run(file)
+	return p, nil
+}
+
+// Read reads the aircraft lines
+func (p *poller) Read(b []byte) (int, error) {
+	return p.r.Read(b)
+}
+
+// Close stops polling
+func (p *poller) Close() error {
+	p.once.Do(func() {
+		p.cancel()
+		p.r.Close()
+	})
+	return nil
+}
+
+// run writes out each fetch's lines until closed, or a fetch fails
+func (p *poller) run(file *aircraftFile) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := io.WriteString(p.w, p.lines(file)); err != nil {
+			return // Closed
+		}
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		var err error
+		if file, err = p.fetch(); err != nil {
+			p.w.CloseWithError(err)
+			return
+		}
+	}
+}
+
+// fetch downloads and decodes aircraft.json
+func (p *poller) fetch() (*aircraftFile, error) {
+	ctx, cancel := context.WithTimeout(p.ctx, pollTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("sbs poll: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("sbs poll: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sbs poll: %s: %s", p.url, resp.Status)
+	}
+	var file aircraftFile
+	if err := json.NewDecoder(resp.Body).Decode(&file); err != nil {
+		return nil, fmt.Errorf("sbs poll: %s: %w", p.url, err)
+	}
+	return &file, nil
+}
+
+// lines is a line for each aircraft in file heard since the last fetch,
+// its position left out if that hasn't changed. Aircraft that have
+// dropped out of the file are forgotten.
+func (p *poller) lines(file *aircraftFile) string {
+	var b strings.Builder
+	present := make(map[string]bool, len(file.Aircraft))
+	for _, ac := range file.Aircraft {
+		present[ac.Hex] = true
+		heard := file.Now
+		if ac.Seen != nil {
+			heard -= *ac.Seen
+		}
+		if last, ok := p.heard[ac.Hex]; ok && heard <= last {
+			continue // Nothing new
+		}
+		p.heard[ac.Hex] = heard
+
+		if ac.Lat != nil && ac.SeenPos != nil {
+			at := file.Now - *ac.SeenPos
+			if last, ok := p.positioned[ac.Hex]; ok && at <= last {
+				ac.Lat, ac.Lon, ac.SeenPos = nil, nil, nil
+			} else {
+				p.positioned[ac.Hex] = at
+			}
+		}
+		line, err := json.Marshal(ac)
+		if err != nil {
+			continue
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	for hex := range p.heard {
+		if !present[hex] {
+			delete(p.heard, hex)
+			delete(p.positioned, hex)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,147 @@
+package sbs
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// recordTimeLayout is how a recording stamps each line with when it was
+// received, before a tab, the receiver's name, another tab and the line
+// as it came
+const recordTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+// recordFlushEvery is how often a recording is written out to disk, so a
+// crash loses at most this much of it
+const recordFlushEvery = time.Second
+
+// Recorder writes every line a feed receives to a file, each stamped with
+// when it arrived and which receiver it came from, for looking over later
+// or playing back with -replay
+type Recorder struct {
+	// flushing is held while the file's written to, or closed. The feeds
+	// only ever take mu, so a slow disk holds up the flush, not them.
+	flushing sync.Mutex
+	f        *os.File // nil once closed
+	spare    []byte   // The buffer last written out, to fill next
+
+	mu     sync.Mutex
+	buf    []byte // Lines waiting for the next flush
+	err    error  // The first write that failed; nothing's written after it
+	closed bool
+	done   chan struct{}
+}
+
+// Record starts recording feed's lines to the file at path, adding to the
+// end of it if it's there already
+func Record(path string, feed *Feed) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("record: %w", err)
+	}
+	r := &Recorder{f: f, done: make(chan struct{})}
+	r.AddFeed(feed)
+	go r.flushEvery(recordFlushEvery)
+	return r, nil
+}
+
+// AddFeed records another feed's lines too, for when there's more than
+// one receiver
+func (r *Recorder) AddFeed(feed *Feed) {
+	name := feed.Name()
+	feed.OnLine(func(at time.Time, line string) {
+		r.write(name, at, line)
+	})
+}
+
+// write records one line from the named receiver. It runs on the feed's
+// goroutine, so it only goes as far as the buffer.
+func (r *Recorder) write(receiver string, at time.Time, line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || r.err != nil {
+		return
+	}
+	r.buf = fmt.Appendf(r.buf, "%s\t%s\t%s\n", at.Format(recordTimeLayout), receiver, line)
+}
+
+// flushEvery writes the buffer out every so often until Close
+func (r *Recorder) flushEvery(every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-t.C:
+			r.flushing.Lock()
+			r.flush()
+			r.flushing.Unlock()
+		}
+	}
+}
+
+// flush swaps the buffer for the spare and writes it out, letting go of
+// mu first so the feeds can carry on filling the other. Call it with
+// flushing held.
+func (r *Recorder) flush() {
+	if r.f == nil {
+		return
+	}
+	r.mu.Lock()
+	buf := r.buf
+	r.buf = r.spare[:0]
+	failed := r.err != nil
+	r.mu.Unlock()
+	if failed || len(buf) == 0 {
+		r.spare = buf[:0]
+		return
+	}
+
+	_, err := r.f.Write(buf)
+	r.spare = buf[:0]
+	if err != nil {
+		r.mu.Lock()
+		r.err = err
+		r.mu.Unlock()
+	}
+}
+
+// Err returns why recording stopped early, if it did
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return fmt.Errorf("record: %w", r.err)
+	}
+	return nil
+}
+
+// Close writes out what's left and closes the file
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.done)
+	r.mu.Unlock()
+
+	r.flushing.Lock()
+	defer r.flushing.Unlock()
+	r.flush()
+	err := r.f.Close()
+	r.f = nil
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+	if r.err != nil {
+		return fmt.Errorf("record: %w", r.err)
+	}
+	return nil
+}
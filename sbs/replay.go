@@ -0,0 +1,250 @@
+package sbs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReplaySpeeds are the speeds a replay can go at, slowest first
+var ReplaySpeeds = []int{1, 5, 30}
+
+// Player plays a recording back as a feed's connection: the lines from a
+// file made with -record, each let through once as long has passed since
+// the one before as did when it was recorded (or a fifth or a thirtieth
+// of that). Plain SBS files, like sbsexport writes, go by the time in
+// each line instead. At the end it holds the picture, rather than hang
+// up like a receiver going away.
+//
+// Lines recorded from one of the receivers it was opened with are played
+// to that receiver's own Receiver, for its feed to read, and the rest to
+// the Player itself.
+type Player struct {
+	f         *os.File
+	pr        *io.PipeReader
+	pw        *io.PipeWriter
+	receivers map[string]*io.PipeReader
+	writers   map[string]*io.PipeWriter
+	wake      chan struct{} // Something's changed that the pacing waits on
+	closed    chan struct{}
+	once      sync.Once
+
+	mu       sync.Mutex
+	speed    int
+	paused   bool
+	base     time.Time // Where in the recording the replay was at anchor
+	anchor   time.Time // When, by the clock, it was there
+	last     time.Time // The latest line played
+	finished bool
+	err      error // Why the file couldn't be read to the end
+}
+
+// Replay opens a recording to play back at 1x, with the lines from the
+// named receivers kept apart for their feeds. Nothing plays until the
+// feeds start reading it.
+func Replay(path string, receivers ...string) (*Player, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: %w", err)
+	}
+	pr, pw := io.Pipe()
+	p := &Player{
+		f:         f,
+		pr:        pr,
+		pw:        pw,
+		receivers: make(map[string]*io.PipeReader),
+		writers:   make(map[string]*io.PipeWriter),
+		wake:      make(chan struct{}, 1),
+		closed:    make(chan struct{}),
+		speed:     1,
+	}
+	for _, name := range receivers {
+		p.receivers[name], p.writers[name] = io.Pipe()
+	}
+	go p.play()
+	return p, nil
+}
+
+func (p *Player) Read(b []byte) (int, error) {
+	return p.pr.Read(b)
+}
+
+// Receiver is the named receiver's share of the recording, for its feed
+// to read in place of a connection. Nil for one Replay wasn't given.
+func (p *Player) Receiver(name string) io.ReadCloser {
+	if r, ok := p.receivers[name]; ok {
+		return r
+	}
+	return nil
+}
+
+// Close stops the replay and closes the file
+func (p *Player) Close() error {
+	p.once.Do(func() {
+		close(p.closed)
+		p.pr.Close()
+		for _, r := range p.receivers {
+			r.Close()
+		}
+	})
+	return nil
+}
+
+// play writes the recording's lines into the pipe as they fall due
+func (p *Player) play() {
+	defer p.f.Close()
+	scanner := bufio.NewScanner(p.f)
+	var last time.Time
+	for scanner.Scan() {
+		at, receiver, line := recordedLine(scanner.Text())
+		if at.IsZero() {
+			at = last // No time of its own; it came with the one before
+		}
+		if !at.IsZero() {
+			if !p.waitFor(at) {
+				return
+			}
+			last = at
+			p.mu.Lock()
+			p.last = at
+			p.mu.Unlock()
+		}
+		if w, ok := p.writers[receiver]; ok {
+			// A receiver whose feed has gone just misses out
+			io.WriteString(w, line+"\n")
+			continue
+		}
+		if _, err := io.WriteString(p.pw, line+"\n"); err != nil {
+			return // Closed
+		}
+	}
+
+	p.mu.Lock()
+	p.finished = true
+	if err := scanner.Err(); err != nil {
+		p.err = fmt.Errorf("replay: %w", err)
+	}
+	p.mu.Unlock()
+}
+
+// waitFor waits until the replay reaches at, going by the speed and
+// standing still while paused. It reports false if the player was closed.
+func (p *Player) waitFor(at time.Time) bool {
+	for {
+		p.mu.Lock()
+		if p.base.IsZero() {
+			p.base, p.anchor = at, time.Now() // The first line starts the clock
+		}
+		wait := time.Hour // Paused: until something changes
+		if !p.paused {
+			wait = at.Sub(p.position(time.Now())) / time.Duration(p.speed)
+		}
+		p.mu.Unlock()
+		if wait <= 0 {
+			return true
+		}
+
+		t := time.NewTimer(wait)
+		select {
+		case <-p.closed:
+			t.Stop()
+			return false
+		case <-p.wake:
+			t.Stop()
+		case <-t.C:
+		}
+	}
+}
+
+// position is where in the recording the replay has got to by now. Call
+// it with mu held.
+func (p *Player) position(now time.Time) time.Time {
+	if p.paused || p.base.IsZero() {
+		return p.base
+	}
+	return p.base.Add(now.Sub(p.anchor) * time.Duration(p.speed))
+}
+
+// reanchor moves the clock's starting point up to now, before the speed
+// changes or it stops or starts. Call it with mu held.
+func (p *Player) reanchor() {
+	now := time.Now()
+	p.base, p.anchor = p.position(now), now
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SetPaused stops or restarts the replay's clock
+func (p *Player) SetPaused(paused bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if paused != p.paused {
+		p.reanchor()
+		p.paused = paused
+	}
+}
+
+// SetSpeed sets how many times faster than recorded the replay goes
+func (p *Player) SetSpeed(speed int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if speed > 0 && speed != p.speed {
+		p.reanchor()
+		p.speed = speed
+	}
+}
+
+// Now is when, as recorded, the latest line played came in: the clock a
+// replayed feed stamps its updates with. Lines with no time of their own
+// go with the one before.
+func (p *Player) Now() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.last
+}
+
+// ReplayState is how a replay's going
+type ReplayState struct {
+	At       time.Time // Where in the recording it's got to, zero before the first line; the end once it's finished
+	Speed    int
+	Paused   bool
+	Finished bool
+	Err      error // Why it finished early, if it did
+}
+
+// State says how the replay's going
+func (p *Player) State() ReplayState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := ReplayState{At: p.position(time.Now()), Speed: p.speed, Paused: p.paused, Finished: p.finished, Err: p.err}
+	if p.finished {
+		s.At = p.last
+	}
+	return s
+}
+
+// recordedLine splits a line from a recording into when it was received,
+// the receiver it came from and the line itself. Recordings from before
+// they named the receiver have just the time; lines without a -record
+// stamp go by their own SBS time, if they've got one, else the time is
+// zero.
+func recordedLine(s string) (time.Time, string, string) {
+	if stamp, rest, ok := strings.Cut(s, "\t"); ok {
+		if at, err := time.Parse(recordTimeLayout, stamp); err == nil {
+			if receiver, line, ok := strings.Cut(rest, "\t"); ok {
+				return at, receiver, line
+			}
+			return at, "", rest
+		}
+	}
+	if at, ok := messageTime(strings.Split(s, ",")); ok {
+		return at, "", s
+	}
+	return time.Time{}, "", s
+}
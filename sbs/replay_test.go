@@ -0,0 +1,71 @@
+package sbs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordedLine(t *testing.T) {
+	at := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	const line = "MSG,3,1,1,A0B1C2,1,,,,,,2500,,,40.6,-73.7,,,,,,"
+	tests := []struct {
+		name     string
+		s        string
+		at       time.Time
+		receiver string
+		line     string
+	}{
+		{name: "recorded", s: "2026-10-15T12:00:00.000Z\tnorth\t" + line, at: at, receiver: "north", line: line},
+		{name: "recorded before receivers were", s: "2026-10-15T12:00:00.000Z\t" + line, at: at, line: line},
+		{
+			name: "plain SBS",
+			s:    "MSG,3,1,1,A0B1C2,1,2026/10/15,12:00:00.000,2026/10/15,12:00:00.000,,2500,,,40.6,-73.7,,,,,,",
+			at:   time.Date(2026, 10, 15, 12, 0, 0, 0, time.Local),
+			line: "MSG,3,1,1,A0B1C2,1,2026/10/15,12:00:00.000,2026/10/15,12:00:00.000,,2500,,,40.6,-73.7,,,,,,",
+		},
+		{name: "no time", s: line, line: line},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at, receiver, line := recordedLine(tt.s)
+			if !at.Equal(tt.at) || receiver != tt.receiver || line != tt.line {
+				t.Errorf("got %v, %q, %q; want %v, %q, %q", at, receiver, line, tt.at, tt.receiver, tt.line)
+			}
+		})
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feed.rec")
+	at := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	r, err := Record(path, NewFeed("local", NewStore()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.write("local", at, "MSG,1")
+	r.write("north", at.Add(time.Second), "MSG,3")
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r.write("local", at, "MSG,4") // After Close, so not written
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2: %q", len(lines), data)
+	}
+	for i, want := range []struct {
+		receiver, line string
+	}{{"local", "MSG,1"}, {"north", "MSG,3"}} {
+		got, receiver, line := recordedLine(lines[i])
+		if !got.Equal(at.Add(time.Duration(i)*time.Second)) || receiver != want.receiver || line != want.line {
+			t.Errorf("line %d plays back as %v, %q, %q", i, got, receiver, line)
+		}
+	}
+}
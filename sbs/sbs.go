@@ -1,9 +1,10 @@
 package sbs
 
 import (
-	"bufio"
 	"fmt"
+	"io"
 	"net"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -11,7 +12,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 )
 
-const sbsAddress = "localhost:30003"
+// DefaultAddress is where dump1090 serves SBS (BaseStation) output
+const DefaultAddress = "localhost:30003"
 
 // Aircraft holds the state of a single aircraft
 type Aircraft struct {
@@ -21,13 +23,143 @@ type Aircraft struct {
 	Lon      float64
 	Speed    float64
 	Track    float64
+	Altitude int // Barometric, in feet; 0 until reported
+	VertRate int // Feet a minute, climbing positive
 	LastSeen time.Time
+	Source   Source // Where its latest position came from
+
+	// Which of the fields above an update carries, since zero is a real
+	// altitude, speed or track. In the store, every field ever reported.
+	Has Field
+
+	// ADS-B emitter category ("A1" light up to "A7" rotorcraft, "B2"
+	// balloon and so on). SBS doesn't carry it, so it's only set by
+	// sources that do.
+	Category string
+
+	// Every callsign it's gone by this session, oldest first, the last one
+	// being Callsign
+	Callsigns []CallsignUse
+
+	// The receiver the callsign was last heard from, and a different
+	// one another receiver's said once, see acceptCallsign
+	callsignBy      string
+	pendingCallsign string
+
+	// Recent positions, oldest first, capped at maxTrail. In the store
+	// they're kept in track and only copied out here for Snapshot and
+	// Changes.
+	Trail []TrailPoint
+	track *Track
+
+	// When its position was last reported, moved or not, where the trail
+	// only notes it moving
+	PositionAt time.Time
+
+	// Receivers maps each feed that has heard this aircraft to when it
+	// last did; see HeardBy
+	Receivers map[string]time.Time
+
+	// Groundspeed trend in knots per minute, see SetSpeed
+	SpeedTrend float64
+	trendSpeed float64
+	trendAt    time.Time
+
+	// Speed and Track with the jitter of individual reports smoothed out,
+	// for drawing; see smoothVelocity
+	SmoothSpeed float64
+	SmoothTrack float64
+
+	// Contradictions spotted in its reports, see checkUpdate
+	Anomalies Anomaly
+
+	// How long it's been watched: without a break since VisibleSince, and
+	// over the whole session, see noteDwell
+	VisibleSince time.Time
+	Observed     time.Duration
+
+	// Signal level of its latest message in dBFS, 0 being the loudest the
+	// receiver can hear, so the weaker the more negative. Only sources
+	// that pass it on (Beast and raw feeds, JSON from readsb) set it.
+	Signal float64
+
+	// The transponder code and the flags that come with it in SBS, each
+	// only as sure as the last message to carry it
+	Squawk    string
+	Alert     bool // The squawk's just changed
+	Emergency bool // It's squawking an emergency code
+	Ident     bool // The pilot's pressed IDENT (SPI)
+	OnGround  bool
+}
+
+// Field is a bit for each field an update can carry
+type Field uint16
+
+const (
+	HasCallsign Field = 1 << iota
+	HasPosition       // Never set for 0,0, which is a receiver's "don't know"
+	HasAltitude
+	HasSpeed
+	HasTrack
+	HasSignal
+	HasSquawk
+	HasAlert
+	HasEmergency
+	HasIdent
+	HasGround
+	HasVertRate
+)
+
+// validPosition reports whether lat,lon is a real position rather than the
+// 0,0 some receivers send when they haven't got one
+func validPosition(lat, lon float64) bool {
+	return (lat != 0 || lon != 0) && lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
+
+// HeardWindow is how recently a receiver must have heard an aircraft to
+// count as currently hearing it
+const HeardWindow = 60 * time.Second
+
+// HeardBy returns the receivers currently hearing the aircraft, sorted
+func (a *Aircraft) HeardBy(now time.Time) []string {
+	var names []string
+	for name, at := range a.Receivers {
+		if now.Sub(at) <= HeardWindow {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TrailPoint is a past position
+type TrailPoint struct {
+	Lat float64
+	Lon float64
+	At  time.Time
+}
+
+// trendSample is the minimum gap between speed samples used for the trend.
+// Back-to-back reports are a second apart and the noise swamps the signal.
+const trendSample = 10 * time.Second
+
+// SetSpeed records a new groundspeed and updates SpeedTrend
+func (a *Aircraft) SetSpeed(speed float64, at time.Time) {
+	a.Speed = speed
+	if a.trendAt.IsZero() {
+		a.trendSpeed, a.trendAt = speed, at
+		return
+	}
+	if elapsed := rateElapsed(a.trendAt, at); elapsed >= trendSample {
+		rate := (speed - a.trendSpeed) / elapsed.Minutes()
+		a.SpeedTrend = 0.5*a.SpeedTrend + 0.5*rate
+		a.trendSpeed, a.trendAt = speed, at
+	}
 }
 
 // SbsConnectedMsg is sent when we successfully connect to the feed
 type SbsConnectedMsg struct {
-	Scanner *bufio.Scanner
-	Conn    net.Conn // <-- ADD THIS
+	Conn io.ReadCloser
 }
 
 // SbsErrorMsg is sent when a connection or parsing error occurs
@@ -35,50 +167,33 @@ type SbsErrorMsg struct {
 	Err error
 }
 
-// AircraftUpdateMsg is sent for a single parsed SBS line
-// We use a pointer so we can merge fields in main.go
-type AircraftUpdateMsg struct {
-	Update *Aircraft
-}
-
-// ConnectCmd returns a command that attempts to connect to the SBS feed
-func ConnectCmd() tea.Cmd {
-	return func() tea.Msg {
-		conn, err := net.Dial("tcp", sbsAddress)
-		if err != nil {
-			return SbsErrorMsg{Err: fmt.Errorf("sbs connect: %w", err)}
-		}
-
-		scanner := bufio.NewScanner(conn)
-		return SbsConnectedMsg{Scanner: scanner, Conn: conn} // <-- PASS CONN HERE
+// Dial connects to the SBS feed at addr. An http:// or https:// address
+// is a receiver's web server instead, whose aircraft.json is polled.
+func Dial(addr string) (io.ReadCloser, error) {
+	if isURL(addr) {
+		return poll(addr)
 	}
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("sbs connect: %w", err)
+	}
+	return conn, nil
 }
 
-// WaitForSbsLine returns a command that waits for the next line from the scanner
-func WaitForSbsLine(scanner *bufio.Scanner) tea.Cmd {
+// ConnectCmd returns a command that attempts to connect to the SBS feed at addr
+func ConnectCmd(addr string) tea.Cmd {
 	return func() tea.Msg {
-		if !scanner.Scan() {
-			if err := scanner.Err(); err != nil {
-				return SbsErrorMsg{Err: fmt.Errorf("sbs read: %w", err)}
-			}
-			return SbsErrorMsg{Err: fmt.Errorf("sbs feed disconnected")}
-		}
-
-		line := scanner.Text()
-
-		// Parse and return the single update
-		if acUpdate := parseSbsLine(line); acUpdate != nil {
-			return AircraftUpdateMsg{Update: acUpdate}
+		conn, err := Dial(addr)
+		if err != nil {
+			return SbsErrorMsg{Err: err}
 		}
-
-		// If parseSbsLine returned nil, we send nil.
-		return AircraftUpdateMsg{Update: nil}
+		return SbsConnectedMsg{Conn: conn}
 	}
 }
 
-// parseSbsLine attempts to parse a single line into an *Aircraft struct
-func parseSbsLine(line string) *Aircraft {
-	fields := strings.Split(line, ",")
+// parseSbsFields attempts to parse a line, split on its commas, into an
+// *Aircraft struct stamped with now
+func parseSbsFields(fields []string, now time.Time) *Aircraft {
 	if len(fields) < 11 || fields[0] != "MSG" {
 		return nil // Not a message, or too short, ignore
 	}
@@ -90,42 +205,130 @@ func parseSbsLine(line string) *Aircraft {
 		return nil // No ICAO, can't track
 	}
 
-	// Create a partial update.
+	// Create a partial update, stamped with when we got it (see clock.go)
 	update := &Aircraft{
 		ICAO:     icao,
-		LastSeen: time.Now(),
+		LastSeen: now,
 	}
 
 	switch msgType {
 	case "1": // Callsign
 		if len(fields) >= 11 {
-			update.Callsign = strings.TrimSpace(fields[10])
+			// Padded to eight; checkCallsign sees to that
+			if update.Callsign = fields[10]; strings.TrimSpace(update.Callsign) != "" {
+				update.Has |= HasCallsign
+			}
+		}
+	case "2", "3": // Surface and airborne position
+		// Field 11 is the altitude the position was reported at. On the
+		// surface there's a groundspeed and track too.
+		parseSbsAltitude(update, fields)
+		if msgType == "2" {
+			parseSbsVelocity(update, fields)
 		}
-	case "3": // Position
 		if len(fields) >= 16 {
-			if lat, err := strconv.ParseFloat(fields[14], 64); err == nil {
-				update.Lat = lat
-			}
-			if lon, err := strconv.ParseFloat(fields[15], 64); err == nil {
-				update.Lon = lon
+			lat, latErr := strconv.ParseFloat(fields[14], 64)
+			lon, lonErr := strconv.ParseFloat(fields[15], 64)
+			if latErr == nil && lonErr == nil && validPosition(lat, lon) {
+				update.Lat, update.Lon = lat, lon
+				update.Has |= HasPosition
 			}
 		}
 	case "4": // Velocity
-		if len(fields) >= 14 {
-			if spd, err := strconv.ParseFloat(fields[11], 64); err == nil {
-				update.Speed = spd
+		// Field 11 is altitude; groundspeed and track follow it, then
+		// the vertical rate in 16
+		parseSbsVelocity(update, fields)
+		if len(fields) >= 17 {
+			if vr, err := strconv.Atoi(strings.TrimSpace(fields[16])); err == nil {
+				update.VertRate = vr
+				update.Has |= HasVertRate
 			}
-			if trk, err := strconv.ParseFloat(fields[12], 64); err == nil {
-				update.Track = trk
+		}
+	case "5", "7": // Surveillance altitude, air-to-air
+		parseSbsAltitude(update, fields)
+	case "6": // Surveillance ID: the squawk
+		parseSbsAltitude(update, fields)
+		if len(fields) >= 18 {
+			if update.Squawk = strings.TrimSpace(fields[17]); update.Squawk != "" {
+				update.Has |= HasSquawk
 			}
 		}
+	case "8": // All-call reply, with nothing but the flags
 	default:
 		return nil // We don't care about this message type
 	}
+	parseSbsFlags(update, msgType, fields)
+
+	// Only return if we actually got something
+	if update.Has == 0 {
+		return nil
+	}
+	return update
+}
+
+// parseSbsAltitude reads field 11, the altitude, if it's there
+func parseSbsAltitude(update *Aircraft, fields []string) {
+	if len(fields) >= 12 {
+		if alt, err := strconv.Atoi(strings.TrimSpace(fields[11])); err == nil {
+			update.Altitude = alt
+			update.Has |= HasAltitude
+		}
+	}
+}
+
+// parseSbsVelocity reads fields 12 and 13, groundspeed and track, if
+// they're there
+func parseSbsVelocity(update *Aircraft, fields []string) {
+	if len(fields) >= 14 {
+		if spd, err := strconv.ParseFloat(fields[12], 64); err == nil {
+			update.Speed = spd
+			update.Has |= HasSpeed
+		}
+		if trk, err := strconv.ParseFloat(fields[13], 64); err == nil {
+			update.Track = trk
+			update.Has |= HasTrack
+		}
+	}
+}
+
+// sbsFlags are the flag fields (18 to 21), what they set, and which
+// message types carry them; the rest leave them empty or just write 0
+var sbsFlags = []struct {
+	field int
+	has   Field
+	types string
+}{
+	{18, HasAlert, "356"},
+	{19, HasEmergency, "36"},
+	{20, HasIdent, "356"},
+	{21, HasGround, "235678"},
+}
 
-	// Only return if we actually got useful data (callsign, pos, or vel)
-	if update.Callsign != "" || update.Lat != 0 || update.Speed != 0 {
-		return update
+// parseSbsFlags reads the flag fields a message type carries. Decoders
+// write -1 (or 1) for set and 0 for not; empty is unknown.
+func parseSbsFlags(update *Aircraft, msgType string, fields []string) {
+	for _, f := range sbsFlags {
+		if len(fields) <= f.field || !strings.Contains(f.types, msgType) {
+			continue
+		}
+		var on bool
+		switch strings.TrimSpace(fields[f.field]) {
+		case "-1", "1":
+			on = true
+		case "0":
+		default:
+			continue
+		}
+		update.Has |= f.has
+		switch f.has {
+		case HasAlert:
+			update.Alert = on
+		case HasEmergency:
+			update.Emergency = on
+		case HasIdent:
+			update.Ident = on
+		case HasGround:
+			update.OnGround = on
+		}
 	}
-	return nil
 }
\ No newline at end of file
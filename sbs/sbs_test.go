@@ -0,0 +1,95 @@
+package sbs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSbsFields(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		line string
+		want *Aircraft // nil for no update
+	}{
+		{
+			name: "callsign",
+			line: "MSG,1,1,1,A0B1C2,1,2026/10/15,12:00:00.000,2026/10/15,12:00:00.000,JBU1234 ,,,,,,,,,,,",
+			want: &Aircraft{ICAO: "A0B1C2", Callsign: "JBU1234 ", Has: HasCallsign},
+		},
+		{
+			name: "airborne position",
+			line: "MSG,3,1,1,A0B1C2,1,2026/10/15,12:00:00.000,2026/10/15,12:00:00.000,,2500,,,40.62398,-73.76275,,,0,0,0,0",
+			want: &Aircraft{ICAO: "A0B1C2", Altitude: 2500, Lat: 40.62398, Lon: -73.76275,
+				Has: HasAltitude | HasPosition | HasAlert | HasEmergency | HasIdent | HasGround},
+		},
+		{
+			name: "surface position has a velocity",
+			line: "MSG,2,1,1,A0B1C2,1,,,,,,0,12,270,40.64,-73.77,,,,,,-1",
+			want: &Aircraft{ICAO: "A0B1C2", Speed: 12, Track: 270, Lat: 40.64, Lon: -73.77, OnGround: true,
+				Has: HasAltitude | HasSpeed | HasTrack | HasPosition | HasGround},
+		},
+		{
+			name: "velocity",
+			line: "MSG,4,1,1,A0B1C2,1,,,,,,,160,310,,,-640,,,,,",
+			want: &Aircraft{ICAO: "A0B1C2", Speed: 160, Track: 310, VertRate: -640, Has: HasSpeed | HasTrack | HasVertRate},
+		},
+		{
+			name: "squawk with the emergency flag",
+			line: "MSG,6,1,1,A0B1C2,1,,,,,,3000,,,,,,7700,-1,-1,0,0",
+			want: &Aircraft{ICAO: "A0B1C2", Altitude: 3000, Squawk: "7700", Alert: true, Emergency: true,
+				Has: HasAltitude | HasSquawk | HasAlert | HasEmergency | HasIdent | HasGround},
+		},
+		{
+			name: "position off the Earth",
+			line: "MSG,3,1,1,A0B1C2,1,,,,,,,,,91.0,200.0,,,,,,",
+		},
+		{name: "no ICAO", line: "MSG,3,1,1,,1,,,,,,2500,,,40.6,-73.7,,,,,,"},
+		{name: "not a message", line: "STA,,5,179,400AE7,10103,2008/11/28,14:58:51.153,2008/11/28,14:58:51.153,RM"},
+		{name: "too short", line: "MSG,3,1,1,A0B1C2"},
+		{name: "unknown type", line: "MSG,9,1,1,A0B1C2,1,,,,,,2500,,,,,,,,,,"},
+		{name: "nothing in it", line: "MSG,3,1,1,A0B1C2,1,,,,,,,,,,,,,,,,"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSbsFields(strings.Split(tt.line, ","), now)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("got %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("got nil")
+			}
+			tt.want.LastSeen = now
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMessageTime(t *testing.T) {
+	tests := []struct {
+		line string
+		want time.Time
+		ok   bool
+	}{
+		{
+			line: "MSG,3,1,1,A0B1C2,1,2026/10/15,12:34:56.789,2026/10/15,12:34:56.789,,2500,,,40.6,-73.7,,,,,,",
+			want: time.Date(2026, 10, 15, 12, 34, 56, 789e6, time.Local), // Receivers send local time
+			ok:   true,
+		},
+		{line: "MSG,3,1,1,A0B1C2,1,,,,,,2500,,,40.6,-73.7,,,,,,"},
+		{line: "MSG,3,1,1,A0B1C2,1,yesterday,teatime,,,,2500"},
+	}
+	for _, tt := range tests {
+		got, ok := messageTime(strings.Split(tt.line, ","))
+		if ok != tt.ok || !got.Equal(tt.want) {
+			t.Errorf("messageTime(%q) = %v, %v; want %v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package sbs
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// shareBacklog is how many lines a follower can fall behind by before we
+// start dropping lines for it, rather than hold the feed up
+const shareBacklog = 4096
+
+// shareRecent is how recently an aircraft has to have been heard for a
+// new follower to be told about it
+const shareRecent = time.Minute
+
+// Sharer passes a feed on to other TermTracks (or anything else that reads
+// SBS), so several views can share one connection to the receiver. A new
+// follower is sent the current picture first, as made-up SBS lines, then
+// every line as it arrives.
+type Sharer struct {
+	ln    net.Listener
+	store *Store
+	tag   string // See Share
+
+	mu        sync.Mutex
+	followers map[net.Conn]chan string
+	closed    bool
+}
+
+// Share starts serving feed's lines on addr in the background. A tag, if
+// given, replaces the session and aircraft ID fields of every SBS line
+// passed on, so something merging several receivers' feeds can tell which
+// each line came from; other lines are passed on as they are.
+func Share(addr string, feed *Feed, store *Store, tag string) (*Sharer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("share listen: %w", err)
+	}
+
+	s := &Sharer{ln: ln, store: store, tag: tag, followers: make(map[net.Conn]chan string)}
+	feed.OnLine(s.publish)
+	go s.accept()
+	return s, nil
+}
+
+// AddFeed passes another feed's lines on too, for when there's more than
+// one receiver
+func (s *Sharer) AddFeed(feed *Feed) {
+	feed.OnLine(s.publish)
+}
+
+// Addr is where followers connect
+func (s *Sharer) Addr() net.Addr {
+	return s.ln.Addr()
+}
+
+// Close stops listening and hangs up on every follower
+func (s *Sharer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for conn, lines := range s.followers {
+		close(lines)
+		delete(s.followers, conn)
+	}
+	return s.ln.Close()
+}
+
+// accept takes followers until the listener is closed
+func (s *Sharer) accept() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		// Subscribe before taking the snapshot, so nothing falls between
+		// them; what arrives meanwhile waits in the backlog
+		lines := make(chan string, shareBacklog)
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			conn.Close()
+			return
+		}
+		s.followers[conn] = lines
+		s.mu.Unlock()
+
+		go s.serve(conn, lines, s.store.Snapshot())
+	}
+}
+
+// serve writes the current picture to a follower, then its lines, until
+// either side hangs up
+func (s *Sharer) serve(conn net.Conn, lines chan string, current map[string]*Aircraft) {
+	defer conn.Close()
+
+	now := time.Now()
+	for _, ac := range current {
+		if now.Sub(ac.LastSeen) > shareRecent {
+			continue // The follower can do without the long gone
+		}
+		for _, line := range Lines(ac) {
+			if _, err := fmt.Fprint(conn, tagLine(line, s.tag)+"\r\n"); err != nil {
+				s.drop(conn)
+				return
+			}
+		}
+	}
+
+	for line := range lines {
+		if _, err := fmt.Fprint(conn, line+"\r\n"); err != nil {
+			s.drop(conn)
+			return
+		}
+	}
+}
+
+// drop forgets a follower that's gone away
+func (s *Sharer) drop(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lines, ok := s.followers[conn]; ok {
+		close(lines)
+		delete(s.followers, conn)
+	}
+}
+
+// publish passes a line on to every follower. It's a LineFunc, so it runs
+// on the feed's goroutine: a follower that can't keep up misses lines
+// rather than holding the feed up.
+func (s *Sharer) publish(at time.Time, line string) {
+	line = tagLine(line, s.tag)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, lines := range s.followers {
+		select {
+		case lines <- line:
+		default:
+		}
+	}
+}
+
+// tagLine writes tag into an SBS line's session and aircraft ID fields,
+// the third and fourth. Lines that aren't SBS, and any line when there's
+// no tag, come back unchanged.
+func tagLine(line, tag string) string {
+	if tag == "" || !strings.HasPrefix(line, "MSG,") {
+		return line
+	}
+	fields := strings.SplitN(line, ",", 5)
+	if len(fields) < 5 {
+		return line
+	}
+	fields[2], fields[3] = tag, tag
+	return strings.Join(fields, ",")
+}
+
+// Lines is what we know about an aircraft as SBS lines: its callsign,
+// position, velocity and squawk messages, for whichever we have, stamped
+// with when it was last seen
+func Lines(ac *Aircraft) []string {
+	date := ac.LastSeen.Format("2006/01/02")
+	clock := ac.LastSeen.Format("15:04:05.000")
+	msg := func(typ string, fields string) string {
+		return fmt.Sprintf("MSG,%s,1,1,%s,1,%s,%s,%s,%s,%s", typ, ac.ICAO, date, clock, date, clock, fields)
+	}
+
+	var out []string
+	if ac.Has&HasCallsign != 0 {
+		out = append(out, msg("1", ac.Callsign+",,,,,,,,,,,"))
+	}
+	if ac.Has&HasPosition != 0 {
+		alt := ""
+		if ac.Has&HasAltitude != 0 {
+			alt = strconv.Itoa(ac.Altitude)
+		}
+		out = append(out, msg("3", fmt.Sprintf(",%s,,,%.5f,%.5f,,,%s,%s,%s,%s", alt, ac.Lat, ac.Lon,
+			sbsFlag(ac.Alert), sbsFlag(ac.Emergency), sbsFlag(ac.Ident), sbsFlag(ac.OnGround))))
+	}
+	if ac.Has&HasSpeed != 0 {
+		vr := ""
+		if ac.Has&HasVertRate != 0 {
+			vr = strconv.Itoa(ac.VertRate)
+		}
+		out = append(out, msg("4", fmt.Sprintf(",,%.0f,%.0f,,,%s,,,,,0", ac.Speed, ac.Track, vr)))
+	}
+	if ac.Has&HasSquawk != 0 {
+		out = append(out, msg("6", fmt.Sprintf(",,,,,,,%s,%s,%s,%s,%s", ac.Squawk,
+			sbsFlag(ac.Alert), sbsFlag(ac.Emergency), sbsFlag(ac.Ident), sbsFlag(ac.OnGround))))
+	}
+	return out
+}
+
+// sbsFlag writes a flag field the way dump1090 does
+func sbsFlag(on bool) string {
+	if on {
+		return "-1"
+	}
+	return "0"
+}
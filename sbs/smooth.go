@@ -0,0 +1,21 @@
+package sbs
+
+import "math"
+
+// smoothing is how much weight each velocity report gets in SmoothSpeed
+// and SmoothTrack. Lower is steadier but lags further behind a turn.
+const smoothing = 0.3
+
+// smoothVelocity folds a velocity report into the smoothed speed and track
+// (an exponential moving average). Track is smoothed the short way round,
+// so 359 and 1 average to 0 rather than 180.
+func (a *Aircraft) smoothVelocity(speed, track float64) {
+	if a.SmoothSpeed == 0 {
+		a.SmoothSpeed, a.SmoothTrack = speed, track
+		return
+	}
+	a.SmoothSpeed += smoothing * (speed - a.SmoothSpeed)
+
+	turn := math.Mod(track-a.SmoothTrack+540, 360) - 180 // -180 to 180
+	a.SmoothTrack = math.Mod(a.SmoothTrack+smoothing*turn+360, 360)
+}
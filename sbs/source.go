@@ -0,0 +1,129 @@
+package sbs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Source is the kind of receiver a feed carries positions from. SBS lines
+// don't say, so it's set per feed.
+type Source uint8
+
+const (
+	SourceADSB Source = iota // 1090MHz ADS-B, a position a second or so
+	SourceMLAT               // Multilateration, much less often and with gaps
+	SourceUAT                // 978MHz UAT
+)
+
+var sourceNames = []string{"adsb", "mlat", "uat"}
+
+// String is the source's name as flags and the config file spell it
+func (s Source) String() string {
+	if int(s) < len(sourceNames) {
+		return sourceNames[s]
+	}
+	return fmt.Sprintf("Source(%d)", s)
+}
+
+// Label is the source's name for display, like "ADS-B"
+func (s Source) Label() string {
+	switch s {
+	case SourceADSB:
+		return "ADS-B"
+	case SourceMLAT:
+		return "MLAT"
+	case SourceUAT:
+		return "UAT"
+	}
+	return s.String()
+}
+
+// ParseSource reads a source name: adsb, mlat or uat
+func ParseSource(name string) (Source, error) {
+	for i, n := range sourceNames {
+		if strings.EqualFold(name, n) {
+			return Source(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown source %q (want adsb, mlat or uat)", name)
+}
+
+// Endpoint is a feed to read alongside the main one, from the config
+// file. Its name is what the aircraft it hears are attributed to.
+//
+//	{"name": "north", "address": "pi-north:30003", "source": "mlat"}
+type Endpoint struct {
+	Name    string `json:"name"`
+	Address string `json:"address"` // Anything -feed takes
+	Source  string `json:"source,omitempty"`
+}
+
+// Validate checks the endpoint has a name and address, and a source we
+// know if it has one
+func (e Endpoint) Validate() error {
+	if e.Name == "" {
+		return errors.New("no name")
+	}
+	if e.Address == "" {
+		return fmt.Errorf("%s: no address", e.Name)
+	}
+	if _, err := e.source(); err != nil {
+		return fmt.Errorf("%s: %w", e.Name, err)
+	}
+	return nil
+}
+
+// source is the endpoint's source, ADS-B unless it says
+func (e Endpoint) source() (Source, error) {
+	if e.Source == "" {
+		return SourceADSB, nil
+	}
+	return ParseSource(e.Source)
+}
+
+// NewFeed creates the endpoint's feed, writing into store. The endpoint
+// must be valid.
+func (e Endpoint) NewFeed(store *Store) *Feed {
+	f := NewFeed(e.Name, store)
+	f.source, _ = e.source()
+	return f
+}
+
+// Timeouts are how long an aircraft can go unheard before it's gone, in
+// seconds, by source name. Missing sources use DefaultTimeouts.
+//
+//	{"adsb": 60, "mlat": 180}
+type Timeouts map[string]int
+
+// DefaultTimeouts give MLAT longer: its positions come every few seconds
+// at best, with gaps while too few receivers hear the aircraft
+var DefaultTimeouts = Timeouts{"adsb": 60, "mlat": 180, "uat": 60}
+
+// Validate checks every source is one we know and every timeout positive
+func (t Timeouts) Validate() error {
+	for name, secs := range t {
+		if _, err := ParseSource(name); err != nil {
+			return err
+		}
+		if secs <= 0 {
+			return fmt.Errorf("%s: %d isn't a positive number of seconds", name, secs)
+		}
+	}
+	return nil
+}
+
+// For is the timeout for aircraft from source s
+func (t Timeouts) For(s Source) time.Duration {
+	if secs, ok := t[s.String()]; ok {
+		return time.Duration(secs) * time.Second
+	}
+	return time.Duration(DefaultTimeouts[s.String()]) * time.Second
+}
+
+// Quiet reports whether the aircraft has gone unheard for longer than its
+// source's timeout
+func (a *Aircraft) Quiet(now time.Time, t Timeouts) bool {
+	return now.Sub(a.LastSeen) > t.For(a.Source)
+}
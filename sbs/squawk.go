@@ -0,0 +1,23 @@
+package sbs
+
+// emergencyCodes are the squawks set aside for emergencies, and what each
+// one says is wrong
+var emergencyCodes = map[string]string{
+	"7500": "hijack",
+	"7600": "radio failure",
+	"7700": "emergency",
+}
+
+// SquawkEmergency is what the aircraft's squawk says is wrong: "hijack",
+// "radio failure" or "emergency", or "" if nothing is. The emergency flag
+// some SBS messages carry counts as 7700 when the code itself hasn't come
+// through.
+func (a *Aircraft) SquawkEmergency() string {
+	if what, ok := emergencyCodes[a.Squawk]; ok {
+		return what
+	}
+	if a.Emergency {
+		return "emergency"
+	}
+	return ""
+}
@@ -0,0 +1,351 @@
+package sbs
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Store is the one source of truth for aircraft state. Feeds write into it
+// from their own goroutines; the TUI (and any other output) reads copies
+// of it with Snapshot, so nobody has to share the live map.
+type Store struct {
+	mu         sync.RWMutex
+	aircraft   map[string]*Aircraft
+	lastUpdate time.Time
+	history    *history // nil unless KeepHistory is on
+	timeouts   Timeouts // How long a gap ends an aircraft's time in view
+
+	// Every upsert bumps the version, and the aircraft it touched is
+	// marked with it, so readers can ask for just what's changed
+	version   uint64
+	changed   map[string]uint64
+	removed   map[string]removal // Those Expire dropped
+	forgotten uint64             // The latest removal since forgotten, see removedFor
+
+	// Callsigns held back for disagreeing with another receiver's
+	callsignConflicts uint64
+}
+
+// removedFor is how long the store remembers which aircraft Expire
+// dropped. A reader that hasn't asked for Changes in that long gets the
+// lot again, as a Full diff, since it may have missed some going.
+const removedFor = 10 * time.Minute
+
+// removal is when an aircraft was dropped
+type removal struct {
+	version uint64
+	at      time.Time // The now Expire was given
+}
+
+// Diff is everything that changed in the store since an earlier version,
+// however many updates that took
+type Diff struct {
+	Version uint64               // Ask for the changes since this next time
+	Changed map[string]*Aircraft // Copies of the new and updated aircraft
+	Removed []string             // ICAOs of the aircraft dropped by Expire
+	Full    bool                 // Changed is every aircraft, not just the changes
+}
+
+// NewStore creates an empty aircraft store
+func NewStore() *Store {
+	return &Store{
+		aircraft: make(map[string]*Aircraft),
+		changed:  make(map[string]uint64),
+		removed:  make(map[string]removal),
+	}
+}
+
+// SetTimeouts sets how long aircraft from each source can go unheard
+// before they're counted as having left view, as Expire and Quiet are
+// given. Sources left out keep their DefaultTimeouts.
+func (s *Store) SetTimeouts(t Timeouts) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.timeouts = t
+}
+
+// Upsert merges a partial update, heard by the named receiver, into the store
+func (s *Store) Upsert(receiver string, update *Aircraft) {
+	if update == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastUpdate = time.Now()
+	s.version++
+	s.changed[update.ICAO] = s.version
+	delete(s.removed, update.ICAO)
+	if s.history != nil {
+		s.history.record(s, receiver, update)
+	}
+
+	// Get or create aircraft in our master list
+	// Only what the update says it carries is merged, so a velocity
+	// message's empty position can't wipe out the last real one
+	ac, ok := s.aircraft[update.ICAO]
+	if !ok {
+		// This is the first time we see it. Start from nothing and
+		// merge, the same as for any other update.
+		ac = &Aircraft{ICAO: update.ICAO, Receivers: make(map[string]time.Time)}
+		s.aircraft[update.ICAO] = ac
+	}
+	if ok && update.Has&HasPosition != 0 && ac.heardPosition(update) {
+		// Another receiver's copy of a position we've had already; the
+		// aircraft was still heard, but mustn't be moved back to it
+		dup := *update
+		dup.Has &^= HasPosition
+		update = &dup
+	}
+	if update.Has&HasCallsign != 0 && !ac.acceptCallsign(receiver, update.Callsign) {
+		s.callsignConflicts++
+		held := *update
+		held.Has &^= HasCallsign
+		update = &held
+	}
+	ac.checkUpdate(update)
+	ac.noteDwell(update.LastSeen, s.timeouts)
+	ac.Has |= update.Has
+	if update.Has&HasCallsign != 0 {
+		ac.Callsign = update.Callsign
+		ac.noteCallsign(update.Callsign, update.LastSeen)
+	}
+	if update.Has&HasPosition != 0 && validPosition(update.Lat, update.Lon) {
+		ac.Lat = update.Lat
+		ac.Lon = update.Lon
+		ac.Source = update.Source
+		ac.PositionAt = update.LastSeen
+		ac.addTrailPoint(update.Lat, update.Lon, update.LastSeen)
+	}
+	if update.Has&HasSpeed != 0 {
+		ac.SetSpeed(update.Speed, update.LastSeen)
+	}
+	if update.Has&HasTrack != 0 {
+		ac.Track = update.Track
+	}
+	if update.Has&HasSpeed != 0 {
+		ac.smoothVelocity(ac.Speed, ac.Track)
+	}
+	if update.Has&HasAltitude != 0 {
+		ac.Altitude = update.Altitude
+	}
+	if update.Has&HasVertRate != 0 {
+		ac.VertRate = update.VertRate
+	}
+	if update.Has&HasSignal != 0 {
+		ac.Signal = update.Signal
+	}
+	if update.Has&HasSquawk != 0 {
+		ac.Squawk = update.Squawk
+	}
+	if update.Has&HasAlert != 0 {
+		ac.Alert = update.Alert
+	}
+	if update.Has&HasEmergency != 0 {
+		ac.Emergency = update.Emergency
+	}
+	if update.Has&HasIdent != 0 {
+		ac.Ident = update.Ident
+	}
+	if update.Has&HasGround != 0 {
+		ac.OnGround = update.OnGround
+	}
+	if update.Category != "" {
+		ac.Category = update.Category
+	}
+	ac.LastSeen = update.LastSeen
+	ac.Receivers[receiver] = update.LastSeen
+}
+
+// dupWindow is how far back a position from one receiver is matched
+// against those from the others. Receivers pass on the same message a
+// fraction of a second apart, or a few seconds for a slow link.
+const dupWindow = 5 * time.Second
+
+// heardPosition reports whether an update's position is one the aircraft
+// has already been at in the last dupWindow: the same message heard by
+// more than one receiver
+func (a *Aircraft) heardPosition(update *Aircraft) bool {
+	for i := a.track.Len() - 1; i >= 0 && update.LastSeen.Sub(a.track.At(i).At) <= dupWindow; i-- {
+		if p := a.track.At(i); p.Lat == update.Lat && p.Lon == update.Lon {
+			return true
+		}
+	}
+	return false
+}
+
+// noteDwell counts the time since the aircraft was last heard (at at) as
+// time it's been watched, unless it had gone quiet in between (for longer
+// than its source's timeout in t), in which case it's only now come back
+// into view. Call it before LastSeen moves on.
+func (a *Aircraft) noteDwell(at time.Time, t Timeouts) {
+	gap := at.Sub(a.LastSeen)
+	if a.LastSeen.IsZero() || gap > t.For(a.Source) {
+		a.VisibleSince = at
+		return
+	}
+	if gap > 0 {
+		a.Observed += gap
+	}
+}
+
+// VisibleFor is how long the aircraft has been in view without a break,
+// as of when it was last heard
+func (a *Aircraft) VisibleFor() time.Duration {
+	return a.LastSeen.Sub(a.VisibleSince)
+}
+
+// Snapshot returns a copy of every aircraft. The copies are the caller's
+// to keep; later updates won't touch them.
+func (s *Store) Snapshot() map[string]*Aircraft {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyAircraft(s.aircraft)
+}
+
+// Get returns a copy of one aircraft, for anything that only wants the
+// one rather than a Snapshot of them all
+func (s *Store) Get(icao string) (*Aircraft, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ac, ok := s.aircraft[icao]
+	if !ok {
+		return nil, false
+	}
+	return copyOne(ac), true
+}
+
+// Changes returns copies of the aircraft updated since version, all of
+// them for version 0, or for a version too old to say what's gone since.
+// A burst of lines about one aircraft is one change.
+func (s *Store) Changes(version uint64) Diff {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if version < s.forgotten {
+		version = 0
+	}
+	d := Diff{Version: s.version, Changed: make(map[string]*Aircraft), Full: version == 0}
+	for icao, v := range s.changed {
+		if v > version {
+			d.Changed[icao] = copyOne(s.aircraft[icao])
+		}
+	}
+	for icao, r := range s.removed {
+		if r.version > version {
+			d.Removed = append(d.Removed, icao)
+		}
+	}
+	return d
+}
+
+// Expire drops the aircraft that have been quiet for grace longer than
+// their source's timeout, and returns how many went. Until then they're
+// only Quiet, for showing as stale.
+func (s *Store) Expire(now time.Time, t Timeouts, grace time.Duration) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for icao, ac := range s.aircraft {
+		if now.Sub(ac.LastSeen) <= t.For(ac.Source)+grace {
+			continue
+		}
+		if n == 0 {
+			s.version++ // One version for the lot
+		}
+		delete(s.aircraft, icao)
+		delete(s.changed, icao)
+		s.removed[icao] = removal{version: s.version, at: now}
+		n++
+	}
+	for icao, r := range s.removed {
+		if now.Sub(r.at) > removedFor {
+			delete(s.removed, icao)
+			s.forgotten = max(s.forgotten, r.version)
+		}
+	}
+	return n
+}
+
+// Apply returns the aircraft in frame with the diff's changes made, as a
+// new map: frame is left alone, so whoever was handed it (the last frame's
+// views, say) can go on reading it. A nil frame, or a Full diff, gives
+// just the changes.
+func (d Diff) Apply(frame map[string]*Aircraft) map[string]*Aircraft {
+	if frame == nil || d.Full {
+		return d.Changed
+	}
+	out := maps.Clone(frame)
+	maps.Copy(out, d.Changed)
+	for _, icao := range d.Removed {
+		delete(out, icao)
+	}
+	return out
+}
+
+// copyAircraft deep-copies a set of aircraft
+func copyAircraft(all map[string]*Aircraft) map[string]*Aircraft {
+	out := make(map[string]*Aircraft, len(all))
+	for icao, ac := range all {
+		out[icao] = copyOne(ac)
+	}
+	return out
+}
+
+// copyOne deep-copies one aircraft
+func copyOne(ac *Aircraft) *Aircraft {
+	c := *ac
+	c.Trail = ac.track.Points()
+	c.track = nil // The store keeps adding to its own
+	c.Receivers = maps.Clone(ac.Receivers)
+	c.Callsigns = slices.Clone(ac.Callsigns) // The last use's end keeps moving
+	return &c
+}
+
+// Track returns a copy of an aircraft's recent positions, oldest first,
+// without copying the rest of it. Nil for one the store doesn't have.
+func (s *Store) Track(icao string) []TrailPoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if ac, ok := s.aircraft[icao]; ok {
+		return ac.track.Points()
+	}
+	return nil
+}
+
+// copyLive deep-copies a set of aircraft as the store keeps them, track
+// and all, so a keyframe can be replayed into like the store itself
+func copyLive(all map[string]*Aircraft) map[string]*Aircraft {
+	out := make(map[string]*Aircraft, len(all))
+	for icao, ac := range all {
+		c := *ac
+		c.track = ac.track.clone()
+		c.Receivers = maps.Clone(ac.Receivers)
+		c.Callsigns = slices.Clone(ac.Callsigns)
+		out[icao] = &c
+	}
+	return out
+}
+
+// CallsignConflicts returns how many callsigns have been held back for
+// disagreeing with the one another receiver gave, see acceptCallsign
+func (s *Store) CallsignConflicts() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.callsignConflicts
+}
+
+// Len returns how many aircraft are in the store
+func (s *Store) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.aircraft)
+}
+
+// LastUpdate returns when the store last changed
+func (s *Store) LastUpdate() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastUpdate
+}
@@ -0,0 +1,259 @@
+package sbs
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+var t0 = time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+// position is an update moving an aircraft to lat, lon at t0 plus secs
+func position(icao string, lat, lon float64, secs int) *Aircraft {
+	return &Aircraft{ICAO: icao, Lat: lat, Lon: lon, Has: HasPosition, LastSeen: t0.Add(time.Duration(secs) * time.Second)}
+}
+
+func TestUpsertMerges(t *testing.T) {
+	s := NewStore()
+	s.Upsert("home", &Aircraft{ICAO: "A0B1C2", Callsign: "JBU1234 ", Has: HasCallsign, LastSeen: t0})
+	s.Upsert("home", &Aircraft{ICAO: "A0B1C2", Altitude: 2500, Has: HasAltitude, LastSeen: t0.Add(time.Second)})
+	s.Upsert("home", position("A0B1C2", 40.6, -73.7, 2))
+	// A velocity message's empty position mustn't wipe out the last real one
+	s.Upsert("home", &Aircraft{ICAO: "A0B1C2", Speed: 160, Has: HasSpeed, LastSeen: t0.Add(3 * time.Second)})
+
+	ac, ok := s.Get("A0B1C2")
+	if !ok {
+		t.Fatal("aircraft not in the store")
+	}
+	if ac.Callsign != "JBU1234 " || ac.Altitude != 2500 || ac.Lat != 40.6 || ac.Lon != -73.7 || ac.Speed != 160 {
+		t.Errorf("got %+v", ac)
+	}
+	if want := HasCallsign | HasAltitude | HasPosition | HasSpeed; ac.Has != want {
+		t.Errorf("Has = %v, want %v", ac.Has, want)
+	}
+	if !ac.LastSeen.Equal(t0.Add(3*time.Second)) || !ac.PositionAt.Equal(t0.Add(2*time.Second)) {
+		t.Errorf("LastSeen = %v, PositionAt = %v", ac.LastSeen, ac.PositionAt)
+	}
+	if len(ac.Trail) != 1 {
+		t.Errorf("trail has %d points, want 1", len(ac.Trail))
+	}
+}
+
+func TestUpsertDropsDuplicatePositions(t *testing.T) {
+	s := NewStore()
+	s.Upsert("home", position("A0B1C2", 40.6, -73.7, 0))
+	s.Upsert("away", position("A0B1C2", 40.61, -73.7, 1))
+	s.Upsert("away", position("A0B1C2", 40.6, -73.7, 2)) // home's, late
+
+	ac, _ := s.Get("A0B1C2")
+	if ac.Lat != 40.61 || len(ac.Trail) != 2 {
+		t.Errorf("at %.2f with %d trail points, want 40.61 with 2", ac.Lat, len(ac.Trail))
+	}
+	if len(ac.Receivers) != 2 {
+		t.Errorf("heard by %d receivers, want 2", len(ac.Receivers))
+	}
+}
+
+func TestDwell(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts Timeouts
+		gap      int           // Seconds between the two sightings
+		since    int           // When it came into view, in seconds
+		observed time.Duration // Over the session, gaps and all but the long ones
+	}{
+		{name: "heard again soon", gap: 30, since: 0, observed: 50 * time.Second},
+		{name: "gone quiet", gap: 90, since: 100, observed: 20 * time.Second},
+		{name: "longer timeout configured", timeouts: Timeouts{"adsb": 120}, gap: 90, since: 0, observed: 110 * time.Second},
+		{name: "shorter timeout configured", timeouts: Timeouts{"adsb": 20}, gap: 30, since: 40, observed: 20 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			s.SetTimeouts(tt.timeouts)
+			s.Upsert("home", position("A0B1C2", 40.6, -73.7, 0))
+			s.Upsert("home", position("A0B1C2", 40.61, -73.7, 10))
+			s.Upsert("home", position("A0B1C2", 40.62, -73.7, 10+tt.gap))
+			s.Upsert("home", position("A0B1C2", 40.63, -73.7, 20+tt.gap))
+
+			ac, _ := s.Get("A0B1C2")
+			if !ac.VisibleSince.Equal(t0.Add(time.Duration(tt.since)*time.Second)) || ac.Observed != tt.observed {
+				t.Errorf("in view since %v, observed %v; want %ds, %v", ac.VisibleSince.Sub(t0), ac.Observed, tt.since, tt.observed)
+			}
+		})
+	}
+}
+
+func TestChanges(t *testing.T) {
+	s := NewStore()
+	s.Upsert("home", position("A0B1C2", 40.6, -73.7, 0))
+	s.Upsert("home", position("C2B1A0", 40.7, -73.8, 0))
+	first := s.Changes(0)
+	if !first.Full || len(first.Changed) != 2 {
+		t.Fatalf("first diff: Full = %v with %d changed, want a full 2", first.Full, len(first.Changed))
+	}
+	frame := first.Apply(nil)
+
+	// Three updates about one aircraft are one change
+	for i := 1; i <= 3; i++ {
+		s.Upsert("home", position("A0B1C2", northward(i), -73.7, i))
+	}
+	d := s.Changes(first.Version)
+	if d.Full || len(d.Changed) != 1 || d.Changed["A0B1C2"] == nil {
+		t.Fatalf("got Full = %v, changed %v", d.Full, slices.Collect(maps.Keys(d.Changed)))
+	}
+	next := d.Apply(frame)
+	if next["A0B1C2"].Lat != northward(3) || next["C2B1A0"] == nil {
+		t.Errorf("applied frame is wrong: %v", next)
+	}
+	if frame["A0B1C2"].Lat != 40.6 {
+		t.Error("Apply changed the frame it was given")
+	}
+
+	// Nothing since
+	if d := s.Changes(d.Version); len(d.Changed) != 0 || len(d.Removed) != 0 {
+		t.Errorf("got %d changed and %d removed, want nothing", len(d.Changed), len(d.Removed))
+	}
+}
+
+func TestExpire(t *testing.T) {
+	timeouts := Timeouts{"adsb": 60, "mlat": 180}
+	tests := []struct {
+		name   string
+		source Source
+		quiet  time.Duration
+		grace  time.Duration
+		gone   bool
+	}{
+		{name: "recent", source: SourceADSB, quiet: 30 * time.Second},
+		{name: "past its timeout", source: SourceADSB, quiet: 61 * time.Second, gone: true},
+		{name: "in its grace", source: SourceADSB, quiet: 61 * time.Second, grace: 10 * time.Second},
+		{name: "MLAT gets longer", source: SourceMLAT, quiet: 120 * time.Second},
+		{name: "MLAT past its timeout", source: SourceMLAT, quiet: 181 * time.Second, gone: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			update := position("A0B1C2", 40.6, -73.7, 0)
+			update.Source = tt.source
+			s.Upsert("home", update)
+			before := s.Changes(0)
+
+			n := s.Expire(t0.Add(tt.quiet), timeouts, tt.grace)
+			_, there := s.Get("A0B1C2")
+			if gone := n == 1 && !there; gone != tt.gone {
+				t.Fatalf("expired %d, still there %v; want gone %v", n, there, tt.gone)
+			}
+			d := s.Changes(before.Version)
+			if tt.gone != slices.Equal(d.Removed, []string{"A0B1C2"}) {
+				t.Errorf("Removed = %v", d.Removed)
+			}
+		})
+	}
+}
+
+func TestExpireForgetsRemovals(t *testing.T) {
+	s := NewStore()
+	s.Upsert("home", position("A0B1C2", 40.6, -73.7, 0))
+	s.Upsert("home", position("C2B1A0", 40.7, -73.8, 100))
+	old := s.Changes(0).Version
+
+	s.Expire(t0.Add(90*time.Second), DefaultTimeouts, 0)
+	if d := s.Changes(old); d.Full || !slices.Equal(d.Removed, []string{"A0B1C2"}) {
+		t.Fatalf("Full = %v, Removed = %v; want A0B1C2 removed", d.Full, d.Removed)
+	}
+
+	// Long enough later the removal's forgotten, so a reader that old
+	// has to start again from everything
+	s.Upsert("home", position("C2B1A0", 40.7, -73.8, 700))
+	s.Expire(t0.Add(90*time.Second+removedFor+time.Second), DefaultTimeouts, 0)
+	if len(s.removed) != 0 {
+		t.Errorf("still remembering %d removals", len(s.removed))
+	}
+	d := s.Changes(old)
+	if !d.Full || len(d.Changed) != 1 || len(d.Removed) != 0 {
+		t.Errorf("Full = %v, %d changed, Removed = %v; want a full diff of 1", d.Full, len(d.Changed), d.Removed)
+	}
+	if frame := d.Apply(map[string]*Aircraft{"A0B1C2": {}}); frame["A0B1C2"] != nil {
+		t.Error("a full diff kept an aircraft from the old frame")
+	}
+}
+
+func TestSnapshotAt(t *testing.T) {
+	s := NewStore()
+	s.KeepHistory(10 * time.Minute)
+	// Two minutes of a position every 10 seconds, so there are keyframes
+	// to start from, and another aircraft turning up half way
+	for i := 0; i <= 12; i++ {
+		s.Upsert("home", position("A0B1C2", northward(i), -73.7, i*10))
+		if i == 6 {
+			s.Upsert("home", position("C2B1A0", 40.7, -73.8, 65))
+		}
+	}
+
+	tests := []struct {
+		name   string
+		at     time.Duration
+		step   int  // Where A0B1C2 is, and so how long its trail is
+		second bool // C2B1A0 is there
+	}{
+		{name: "start", at: 0, step: 0},
+		{name: "between keyframes", at: 45 * time.Second, step: 4},
+		{name: "just after a keyframe", at: 65 * time.Second, step: 6, second: true},
+		{name: "now", at: 2 * time.Minute, step: 12, second: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.SnapshotAt(t0.Add(tt.at))
+			ac := got["A0B1C2"]
+			if ac == nil {
+				t.Fatal("A0B1C2 missing")
+			}
+			if ac.Lat != northward(tt.step) || len(ac.Trail) != tt.step+1 {
+				t.Errorf("at %.2f with %d trail points, want %.2f with %d", ac.Lat, len(ac.Trail), northward(tt.step), tt.step+1)
+			}
+			if _, ok := got["C2B1A0"]; ok != tt.second {
+				t.Errorf("C2B1A0 there = %v, want %v", ok, tt.second)
+			}
+		})
+	}
+
+	// Rebuilding the past mustn't touch the present
+	if ac, _ := s.Get("A0B1C2"); ac.Lat != northward(12) || len(ac.Trail) != 13 {
+		t.Errorf("store now at %.2f with %d trail points", ac.Lat, len(ac.Trail))
+	}
+}
+
+func TestSnapshotAtWithoutHistory(t *testing.T) {
+	s := NewStore()
+	s.Upsert("home", position("A0B1C2", 40.6, -73.7, 0))
+	if got := s.SnapshotAt(t0.Add(-time.Hour)); got["A0B1C2"] == nil {
+		t.Error("without a history SnapshotAt should be a Snapshot")
+	}
+	if !s.HistoryStart().IsZero() {
+		t.Error("HistoryStart without a history isn't zero")
+	}
+}
+
+func TestHistoryPrune(t *testing.T) {
+	s := NewStore()
+	s.KeepHistory(time.Minute)
+	for i := 0; i <= 30; i++ {
+		s.Upsert("home", position("A0B1C2", northward(i), -73.7, i*10))
+	}
+	// The last keyframe is at 300s, and the one at the minute before it
+	// is kept to rebuild from
+	if got, want := s.HistoryStart(), t0.Add(240*time.Second); !got.Equal(want) {
+		t.Errorf("HistoryStart = %v, want %v", got.Sub(t0), want.Sub(t0))
+	}
+	// That keyframe is from just before the update at 240s
+	if got := s.SnapshotAt(t0); got["A0B1C2"].Lat != northward(23) {
+		t.Errorf("before the history got %.2f, want the oldest kept, %.2f", got["A0B1C2"].Lat, northward(23))
+	}
+}
+
+// northward is where the test aircraft is after step updates
+func northward(step int) float64 {
+	return 40.6 + float64(step)/100
+}
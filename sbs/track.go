@@ -0,0 +1,100 @@
+package sbs
+
+import (
+	"slices"
+	"time"
+)
+
+// maxTrail is how many past positions we keep per aircraft
+const maxTrail = 300
+
+// Track is an aircraft's recent positions, oldest first: a ring buffer
+// that drops the oldest once it's full, rather than copying the lot along
+// every time a position comes in. The store keeps one per aircraft and
+// hands out copies of it as Trail.
+type Track struct {
+	points []TrailPoint
+	start  int // Where the oldest is, once it's full
+	size   int // The most it keeps
+}
+
+// NewTrack creates an empty track keeping up to size positions
+func NewTrack(size int) *Track {
+	return &Track{size: max(size, 1)}
+}
+
+// Add records a position, skipping repeats of the last one
+func (t *Track) Add(p TrailPoint) {
+	if last, ok := t.Last(); ok && last.Lat == p.Lat && last.Lon == p.Lon {
+		return
+	}
+	if len(t.points) < t.size {
+		t.points = append(t.points, p)
+		return
+	}
+	t.points[t.start] = p
+	t.start = (t.start + 1) % t.size
+}
+
+// Len returns how many positions the track holds
+func (t *Track) Len() int {
+	if t == nil {
+		return 0
+	}
+	return len(t.points)
+}
+
+// At returns the i'th position, 0 being the oldest
+func (t *Track) At(i int) TrailPoint {
+	return t.points[(t.start+i)%len(t.points)]
+}
+
+// Last returns the newest position, if there is one
+func (t *Track) Last() (TrailPoint, bool) {
+	if t.Len() == 0 {
+		return TrailPoint{}, false
+	}
+	return t.At(t.Len() - 1), true
+}
+
+// Points returns a copy of the positions, oldest first
+func (t *Track) Points() []TrailPoint {
+	if t.Len() == 0 {
+		return nil
+	}
+	out := make([]TrailPoint, 0, len(t.points))
+	out = append(out, t.points[t.start:]...)
+	return append(out, t.points[:t.start]...)
+}
+
+// Since returns a copy of the positions from at on, oldest first
+func (t *Track) Since(at time.Time) []TrailPoint {
+	n := t.Len()
+	i := n
+	for i > 0 && !t.At(i-1).At.Before(at) {
+		i--
+	}
+	out := make([]TrailPoint, 0, n-i)
+	for ; i < n; i++ {
+		out = append(out, t.At(i))
+	}
+	return out
+}
+
+// clone copies the track, nil for nil
+func (t *Track) clone() *Track {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	c.points = slices.Clone(t.points)
+	return &c
+}
+
+// addTrailPoint records a position in the aircraft's track
+func (a *Aircraft) addTrailPoint(lat, lon float64, at time.Time) {
+	if a.track == nil {
+		a.track = NewTrack(maxTrail)
+	}
+	a.track.Add(TrailPoint{Lat: lat, Lon: lon, At: at})
+}
@@ -0,0 +1,170 @@
+// Package sequence lines up the aircraft coming in to land on each of the
+// approaches in the config, roughly as approach control would see them:
+// who looks to be arriving, in what order, and how many miles in trail
+// each is behind the one ahead.
+package sequence
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"termtrack/geo"
+	"termtrack/sbs"
+	"termtrack/watch"
+)
+
+// An aircraft is taken to be arriving on a runway while it's within
+// maxRange nautical miles of the threshold, below maxAltitude feet, on
+// the approach side of it and pointed within maxOffCourse degrees of it
+const (
+	maxRange     = 40.0
+	maxAltitude  = 15000
+	maxOffCourse = 60.0
+)
+
+// It has to look like it's coming down, too: descending at least
+// descentRate feet a minute, or already no higher than a 3° glidepath
+// (glideSlope feet a mile) plus glideMargin. Anything climbing faster
+// than descentRate is a departure or a go-around.
+const (
+	descentRate = 300
+	glideSlope  = 318
+	glideMargin = 1500
+)
+
+// Runway is an approach to sequence arrivals onto
+type Runway struct {
+	Name    string // e.g. "JFK 31L"
+	Lat     float64
+	Lon     float64
+	Heading float64 // Landing direction in degrees
+}
+
+// Arrival is an aircraft in a runway's sequence
+type Arrival struct {
+	ICAO     string
+	Callsign string
+	Distance float64       // Nautical miles to the threshold
+	Altitude int           // Feet, 0 if not reported
+	ETA      time.Duration // At its groundspeed, 0 if that's not known
+	InTrail  float64       // Nautical miles behind the one ahead, 0 for the first
+}
+
+// Sequence is one runway's arrivals, nearest first
+type Sequence struct {
+	Runway
+	Arrivals []Arrival
+}
+
+// Sequencer works out the arrival sequences
+type Sequencer struct {
+	runways []Runway
+	watch.Watch
+}
+
+// New creates a sequencer with no runways yet
+func New() *Sequencer {
+	return &Sequencer{}
+}
+
+// SetRunways sets the approaches to sequence arrivals onto
+func (s *Sequencer) SetRunways(r []Runway) {
+	s.runways = r
+}
+
+// HasRunways reports whether there are any runways to sequence onto
+func (s *Sequencer) HasRunways() bool {
+	return len(s.runways) > 0
+}
+
+// Sequences returns each runway's arrivals as of now, in the runways'
+// order. An aircraft that could be arriving on more than one (parallel
+// runways, say) goes in whichever centerline it's nearest.
+func (s *Sequencer) Sequences(all map[string]*sbs.Aircraft, now time.Time) []Sequence {
+	out := make([]Sequence, len(s.runways))
+	for i, r := range s.runways {
+		out[i].Runway = r
+	}
+
+	for hex, ac := range all {
+		if s.Quiet(ac, now) || !descending(ac) {
+			continue
+		}
+		best, bestOff := -1, math.Inf(1)
+		var bestDist float64
+		for i, r := range s.runways {
+			dist, off, ok := inbound(ac, r)
+			if ok && off < bestOff {
+				best, bestOff, bestDist = i, off, dist
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		a := Arrival{ICAO: hex, Callsign: ac.Callsign, Distance: bestDist, Altitude: ac.Altitude}
+		if ac.Has&sbs.HasSpeed != 0 && ac.Speed > 0 {
+			a.ETA = time.Duration(bestDist / ac.Speed * float64(time.Hour))
+		}
+		out[best].Arrivals = append(out[best].Arrivals, a)
+	}
+
+	for i := range out {
+		arrivals := out[i].Arrivals
+		sort.Slice(arrivals, func(a, b int) bool {
+			return arrivals[a].Distance < arrivals[b].Distance
+		})
+		for j := 1; j < len(arrivals); j++ {
+			arrivals[j].InTrail = arrivals[j].Distance - arrivals[j-1].Distance
+		}
+	}
+	return out
+}
+
+// descending reports whether the aircraft could be on its way down:
+// airborne, with a position, altitude and track, below maxAltitude and
+// not climbing out. Whether it's low enough for how far out it is is up
+// to inbound.
+func descending(ac *sbs.Aircraft) bool {
+	const need = sbs.HasPosition | sbs.HasAltitude | sbs.HasTrack
+	if ac.Has&need != need || ac.OnGround || ac.Altitude > maxAltitude {
+		return false
+	}
+	return ac.Has&sbs.HasVertRate == 0 || ac.VertRate < descentRate
+}
+
+// inbound reports whether the aircraft looks to be arriving on the
+// runway, with how far it is from the threshold and from the extended
+// centerline
+func inbound(ac *sbs.Aircraft, r Runway) (dist, off float64, ok bool) {
+	dist, ok = geo.Within(r.Lat, r.Lon, ac.Lat, ac.Lon, maxRange)
+	if !ok {
+		return 0, 0, false
+	}
+
+	// Behind the threshold, where the approach comes in from
+	from := geo.Bearing(r.Lat, r.Lon, ac.Lat, ac.Lon)
+	behind := angle(from, r.Heading+180)
+	if behind > 90 {
+		return 0, 0, false
+	}
+	// Pointed at it
+	if angle(ac.Track, from+180) > maxOffCourse {
+		return 0, 0, false
+	}
+	// And either coming down, or low enough it's on its way in anyway
+	fell := ac.Has&sbs.HasVertRate != 0 && ac.VertRate <= -descentRate
+	if !fell && float64(ac.Altitude) > dist*glideSlope+glideMargin {
+		return 0, 0, false
+	}
+	return dist, dist * math.Sin(behind*math.Pi/180), true
+}
+
+// angle is the difference between two bearings, 0 to 180 degrees
+func angle(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	if d > 180 {
+		d = 360 - d
+	}
+	return d
+}
@@ -0,0 +1,110 @@
+package sequence
+
+import (
+	"math"
+	"slices"
+	"testing"
+	"time"
+
+	"termtrack/geo"
+	"termtrack/sbs"
+)
+
+var runway = Runway{Name: "JFK 31L", Lat: 40.6235, Lon: -73.7620, Heading: 310}
+
+// approaching is an aircraft dist miles out along bearing from the
+// threshold, at alt feet, flying track and climbing at vr feet a minute
+func approaching(icao string, bearing, dist float64, alt int, track float64, vr int, now time.Time) *sbs.Aircraft {
+	lat, lon := geo.Destination(runway.Lat, runway.Lon, bearing, dist)
+	return &sbs.Aircraft{
+		ICAO: icao, Lat: lat, Lon: lon, Altitude: alt, Track: track, VertRate: vr, Speed: 150, LastSeen: now,
+		Has: sbs.HasPosition | sbs.HasAltitude | sbs.HasTrack | sbs.HasVertRate | sbs.HasSpeed,
+	}
+}
+
+func TestInbound(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		ac   *sbs.Aircraft
+		want bool
+	}{
+		{name: "on final", ac: approaching("A", 130, 5, 1500, 310, -700, now), want: true},
+		{name: "off to the side, turning in", ac: approaching("A", 160, 8, 2500, 340, -700, now), want: true},
+		{name: "high but coming down", ac: approaching("A", 130, 10, 9000, 310, -1500, now), want: true},
+		{name: "high and level", ac: approaching("A", 130, 10, 9000, 310, 0, now)},
+		{name: "past the threshold", ac: approaching("A", 310, 3, 1000, 310, -700, now)},
+		{name: "pointed away", ac: approaching("A", 130, 5, 1500, 130, -700, now)},
+		{name: "out of range", ac: approaching("A", 130, 45, 1500, 310, -700, now)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, got := inbound(tt.ac, runway); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescending(t *testing.T) {
+	now := time.Now()
+	ground := approaching("A", 130, 1, 0, 310, 0, now)
+	ground.OnGround = true
+	noTrack := approaching("A", 130, 5, 1500, 310, -700, now)
+	noTrack.Has &^= sbs.HasTrack
+	tests := []struct {
+		name string
+		ac   *sbs.Aircraft
+		want bool
+	}{
+		{name: "coming down", ac: approaching("A", 130, 5, 1500, 310, -700, now), want: true},
+		{name: "level", ac: approaching("A", 130, 5, 1500, 310, 0, now), want: true},
+		{name: "climbing out", ac: approaching("A", 130, 5, 1500, 310, 2000, now)},
+		{name: "too high", ac: approaching("A", 130, 30, 20000, 310, -2000, now)},
+		{name: "on the ground", ac: ground},
+		{name: "no track", ac: noTrack},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := descending(tt.ac); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequences(t *testing.T) {
+	now := time.Now()
+	all := map[string]*sbs.Aircraft{}
+	for _, ac := range []*sbs.Aircraft{
+		approaching("A00003", 130, 12, 3500, 310, -800, now),
+		approaching("A00001", 130, 4, 1300, 310, -700, now),
+		approaching("A00002", 130, 8, 2500, 310, -700, now),
+		approaching("A00004", 130, 6, 2000, 310, 2500, now), // A go-around
+		approaching("A00005", 130, 7, 2200, 310, -700, now.Add(-time.Hour)),
+	} {
+		all[ac.ICAO] = ac
+	}
+	s := New()
+	s.SetRunways([]Runway{runway})
+	seqs := s.Sequences(all, now)
+	if len(seqs) != 1 {
+		t.Fatalf("got %d sequences, want 1", len(seqs))
+	}
+
+	var order []string
+	for _, a := range seqs[0].Arrivals {
+		order = append(order, a.ICAO)
+	}
+	if want := []string{"A00001", "A00002", "A00003"}; !slices.Equal(order, want) {
+		t.Fatalf("got %v, want %v", order, want)
+	}
+	for i, want := range []float64{0, 4, 4} {
+		if got := seqs[0].Arrivals[i].InTrail; math.Abs(got-want) > 0.05 {
+			t.Errorf("%s is %.2f nm in trail, want %.0f", order[i], got, want)
+		}
+	}
+	if eta := seqs[0].Arrivals[0].ETA; eta < 95*time.Second || eta > 97*time.Second {
+		t.Errorf("4 nm at 150 kt got ETA %v, want 96s", eta)
+	}
+}
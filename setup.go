@@ -0,0 +1,307 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"termtrack/sbs"
+	mapview "termtrack/ui/map"
+)
+
+// probeTimeout is how long setup waits on each port it tries
+const probeTimeout = time.Second
+
+// downloadTimeout is as long as fetching one map file gets
+const downloadTimeout = 5 * time.Minute
+
+// probe is somewhere a receiver on this machine might be serving aircraft
+type probe struct {
+	address string // What -feed would be
+	what    string
+	usable  bool // Whether a feed can read it
+}
+
+// probes are the usual places dump1090 and readsb serve their output
+var probes = []probe{
+	{address: sbs.DefaultAddress, what: "SBS (BaseStation) output", usable: true},
+	{address: "localhost:30005", what: "Beast output, which TermTrack can't read; turn on SBS output (--net-sbs-port 30003) instead"},
+	{address: "http://localhost:8080", what: "web interface, polled for aircraft.json", usable: true},
+}
+
+// mapDownloads are the Natural Earth shapefiles the map is drawn from, by
+// where -map and -airports look for them
+var mapDownloads = []struct {
+	url, dir string
+}{
+	{url: "https://naciscdn.org/naturalearth/10m/cultural/ne_10m_admin_1_states_provinces.zip", dir: filepath.Dir(defaultMapPath)},
+	{url: "https://naciscdn.org/naturalearth/10m/cultural/ne_10m_airports.zip", dir: filepath.Dir(mapview.DefaultAirportPath)},
+}
+
+// shouldSetup reports whether this looks like a first run worth walking
+// through setup for: nothing set by flag, environment or config file, and
+// someone at a terminal to answer the questions
+func shouldSetup(configPath string, set map[string]bool) bool {
+	if configPath == "" || len(set) > 0 {
+		return false
+	}
+	if _, err := os.Stat(configPath); !errors.Is(err, fs.ErrNotExist) {
+		return false
+	}
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// setupWizard asks the questions and writes the answers to the config
+type setupWizard struct {
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// runSetup walks through writing a first config file at path: finding the
+// receiver's feed, asking where the receiver is and fetching map data if
+// it's missing
+func runSetup(path string, in io.Reader, out io.Writer) error {
+	w := setupWizard{in: bufio.NewScanner(in), out: out}
+	fmt.Fprintf(out, "TermTrack setup. This writes %s; Ctrl+C to stop.\n\n", path)
+
+	if _, err := os.Stat(path); err == nil {
+		ok, err := w.confirm(path+" already exists. Replace it?", false)
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	var cfg fileConfig
+	var err error
+	if cfg.Feed, err = w.feed(); err != nil {
+		return err
+	}
+	if cfg.Receiver, err = w.receiver(); err != nil {
+		return err
+	}
+	if cfg.Map, cfg.Airports, err = w.mapData(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("setup: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("setup: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("setup: %w", err)
+	}
+	fmt.Fprintf(out, "\nWrote %s. Run with -setup to do this again.\n", path)
+	return nil
+}
+
+// feed looks for a receiver on this machine and asks which feed to use
+func (w setupWizard) feed() (string, error) {
+	fmt.Fprintln(w.out, "Looking for a receiver on this machine...")
+	var found []string
+	for _, p := range probes {
+		if !reachable(p.address) {
+			continue
+		}
+		fmt.Fprintf(w.out, "  %s: %s\n", p.address, p.what)
+		if p.usable {
+			found = append(found, p.address)
+		}
+	}
+	if len(found) == 0 {
+		fmt.Fprintln(w.out, "  Nothing found. A receiver elsewhere on the network works too, like pi.local:30003 or http://pi.local:8080.")
+		return w.ask("Feed address", sbs.DefaultAddress, nil)
+	}
+	return w.ask("Feed address", found[0], nil)
+}
+
+// receiver asks where the receiver is, for distances and ranges
+func (w setupWizard) receiver() (string, error) {
+	fmt.Fprintln(w.out, "\nWhere's the receiver? Distances, bearings and range records are measured from it.")
+	return w.ask("Receiver as lat,lon, blank to skip", "", func(s string) error {
+		var l location
+		return l.Set(s)
+	})
+}
+
+// mapData fetches the map and airports shapefiles into dir, if they're not
+// where -map and -airports look already and the user wants them. It
+// returns where they went, "" for the usual places.
+func (w setupWizard) mapData(dir string) (mapPath, airportPath string, err error) {
+	if exists(defaultMapPath) && exists(mapview.DefaultAirportPath) {
+		return "", "", nil
+	}
+	fmt.Fprintln(w.out, "\nThe map needs Natural Earth's states and provinces and airports shapefiles (about 15 MB).")
+	ok, err := w.confirm("Download them now?", true)
+	if err != nil || !ok {
+		return "", "", err
+	}
+	for _, d := range mapDownloads {
+		fmt.Fprintf(w.out, "  Fetching %s\n", d.url)
+		if err := downloadShapefile(d.url, filepath.Join(dir, d.dir)); err != nil {
+			return "", "", fmt.Errorf("setup: %w", err)
+		}
+	}
+	return filepath.Join(dir, defaultMapPath), filepath.Join(dir, mapview.DefaultAirportPath), nil
+}
+
+// ask asks a question and returns the answer, or def for a blank one.
+// Answers that don't pass check are asked for again.
+func (w setupWizard) ask(question, def string, check func(string) error) (string, error) {
+	for {
+		if def != "" {
+			fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+		} else {
+			fmt.Fprintf(w.out, "%s: ", question)
+		}
+		if !w.in.Scan() {
+			if err := w.in.Err(); err != nil {
+				return "", fmt.Errorf("setup: %w", err)
+			}
+			return "", errors.New("setup: no answer")
+		}
+		answer := strings.TrimSpace(w.in.Text())
+		if answer == "" {
+			return def, nil
+		}
+		if check == nil {
+			return answer, nil
+		}
+		if err := check(answer); err != nil {
+			fmt.Fprintf(w.out, "  %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// confirm asks a yes or no question
+func (w setupWizard) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := w.ask(question+" ("+hint+")", "", nil)
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// reachable reports whether a feed address answers: a port that takes a
+// connection, or a web interface with an aircraft.json
+func reachable(address string) bool {
+	if !strings.Contains(address, "://") {
+		conn, err := net.DialTimeout("tcp", address, probeTimeout)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address+"/data/aircraft.json", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	var file struct {
+		Aircraft []json.RawMessage `json:"aircraft"`
+	}
+	return resp.StatusCode == http.StatusOK && json.NewDecoder(resp.Body).Decode(&file) == nil && file.Aircraft != nil
+}
+
+// downloadShapefile fetches a zipped shapefile and unpacks its files into
+// dir
+func downloadShapefile(url, dir string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), downloadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return fmt.Errorf("%s: %w", url, err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		// Just the files, by name, wherever they are in the zip
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if err := unzipFile(f, filepath.Join(dir, filepath.Base(f.Name))); err != nil {
+			return fmt.Errorf("%s: %w", url, err)
+		}
+	}
+	return nil
+}
+
+// unzipFile writes one file from a zip to path
+func unzipFile(f *zip.File, path string) error {
+	r, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// exists reports whether there's a file at path
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,325 @@
+// Package sightings keeps track of notable firsts (the first aircraft from
+// each country, the first of each type, the first military one, the
+// longest range) and the callsigns each aircraft has gone by across
+// sessions, in a small JSON file. It also counts how often each airframe
+// comes by, for ranking the regulars.
+package sightings
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"termtrack/aircraftdb"
+	"termtrack/geo"
+	"termtrack/icao"
+	"termtrack/sbs"
+	"termtrack/watch"
+)
+
+// minRecordGain is how far, in nautical miles, a new range has to beat
+// the old record by to count, so position noise doesn't set one a second
+const minRecordGain = 1.0
+
+// visitGap is how long an aircraft has to have been gone for it coming
+// back to count as another visit, so one that's in range all afternoon is
+// one visit but the same airframe on its evening return is two
+const visitGap = time.Hour
+
+// notableCategories are the emitter categories worth a first, for sources
+// that report them
+var notableCategories = map[string]string{
+	"A5": "heavy",
+	"B1": "glider",
+	"B2": "balloon",
+	"B6": "drone",
+	"B7": "spacecraft",
+}
+
+// Event is one notable sighting
+type Event struct {
+	At   time.Time
+	ICAO string
+	Text string // Like "First from Japan: JAL6"
+}
+
+// record is what's kept between sessions
+type record struct {
+	Countries  map[string]time.Time `json:"countries"`       // By ISO code, when first seen
+	Categories map[string]time.Time `json:"categories"`      // By emitter category
+	Types      map[string]time.Time `json:"types,omitempty"` // By ICAO type code, from the aircraft database
+	Military   time.Time            `json:"military,omitzero"`
+	MaxRange   float64              `json:"max_range_nm,omitempty"`
+	MaxRangeBy string               `json:"max_range_by,omitempty"`
+
+	// Callsigns by ICAO, oldest first; many aircraft fly several legs a
+	// day under different flight numbers
+	Callsigns map[string][]sbs.CallsignUse `json:"callsigns,omitempty"`
+
+	// Visits by ICAO, for the regulars
+	Airframes map[string]*Visits `json:"airframes,omitempty"`
+}
+
+// Visits is how often an airframe has come by, and when it first and
+// last did
+type Visits struct {
+	Count int       `json:"count"`
+	First time.Time `json:"first"`
+	Last  time.Time `json:"last"`
+}
+
+// Regular is an airframe that comes by often
+type Regular struct {
+	ICAO     string
+	Callsign string // The one it last went by, "" if it's never given one
+	Visits
+}
+
+// Tracker spots firsts in the aircraft going by
+type Tracker struct {
+	path    string
+	rec     record
+	seen    map[string]bool // ICAOs we've looked at this session
+	events  []Event
+	rangeAt int            // Index in events of this session's range record, -1 for none
+	db      *aircraftdb.DB // Where types come from, nil for nowhere
+	pace    watch.Watch    // How often Check looks
+}
+
+// Load reads the sightings kept at path, if there are any. An empty path
+// keeps them for this session only.
+func Load(path string) (*Tracker, error) {
+	t := &Tracker{
+		path: path,
+		rec: record{
+			Countries:  make(map[string]time.Time),
+			Categories: make(map[string]time.Time),
+			Types:      make(map[string]time.Time),
+			Callsigns:  make(map[string][]sbs.CallsignUse),
+			Airframes:  make(map[string]*Visits),
+		},
+		seen:    make(map[string]bool),
+		rangeAt: -1,
+	}
+	if path == "" {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sightings: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.rec); err != nil {
+		return nil, fmt.Errorf("sightings %s: %w", path, err)
+	}
+	if t.rec.Countries == nil {
+		t.rec.Countries = make(map[string]time.Time)
+	}
+	if t.rec.Categories == nil {
+		t.rec.Categories = make(map[string]time.Time)
+	}
+	if t.rec.Types == nil {
+		t.rec.Types = make(map[string]time.Time)
+	}
+	if t.rec.Callsigns == nil {
+		t.rec.Callsigns = make(map[string][]sbs.CallsignUse)
+	}
+	if t.rec.Airframes == nil {
+		t.rec.Airframes = make(map[string]*Visits)
+	}
+	return t, nil
+}
+
+// SetAircraftDB sets the database aircraft types are looked up in, for
+// the first of each type; nil for none
+func (t *Tracker) SetAircraftDB(db *aircraftdb.DB) {
+	t.db = db
+}
+
+// Save writes the sightings back to where they came from
+func (t *Tracker) Save() error {
+	if t.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(t.rec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("sightings: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return fmt.Errorf("sightings: %w", err)
+	}
+
+	// Write then rename, so a crash can't leave half a file
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("sightings: %w", err)
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		return fmt.Errorf("sightings: %w", err)
+	}
+	return nil
+}
+
+// Check looks for firsts among the aircraft (at most every watch.Interval)
+// and returns any it found. Ranges are only judged when haveRef says the
+// reference point is the receiver.
+func (t *Tracker) Check(all map[string]*sbs.Aircraft, refLat, refLon float64, haveRef bool) []Event {
+	now := time.Now()
+	if !t.pace.Due(now) {
+		return nil
+	}
+
+	var found []Event
+	add := func(ac *sbs.Aircraft, format string, args ...any) {
+		e := Event{At: now, ICAO: ac.ICAO, Text: fmt.Sprintf(format, args...)}
+		found = append(found, e)
+		t.events = append(t.events, e)
+	}
+
+	for hex, ac := range all {
+		t.visit(hex, now)
+		if !t.seen[hex] {
+			t.seen[hex] = true
+			if c, ok := icao.CountryOf(hex); ok {
+				if _, had := t.rec.Countries[c.Code]; !had {
+					t.rec.Countries[c.Code] = now
+					add(ac, "First from %s: %s", c.Name, name(ac))
+				}
+			}
+			if icao.IsMilitary(hex) && t.rec.Military.IsZero() {
+				t.rec.Military = now
+				add(ac, "First military address: %s", name(ac))
+			}
+		}
+
+		if len(ac.Callsigns) > 0 {
+			t.rec.Callsigns[hex] = sbs.MergeCallsigns(t.rec.Callsigns[hex], ac.Callsigns)
+		}
+
+		// Looked up every time rather than once, in case the database
+		// came in after we first saw it
+		if e, ok := t.db.Lookup(hex); ok && e.Type != "" {
+			if _, had := t.rec.Types[e.Type]; !had {
+				t.rec.Types[e.Type] = now
+				add(ac, "First %s: %s", e.Type, name(ac))
+			}
+		}
+
+		if what, ok := notableCategories[ac.Category]; ok {
+			if _, had := t.rec.Categories[ac.Category]; !had {
+				t.rec.Categories[ac.Category] = now
+				add(ac, "First %s: %s", what, name(ac))
+			}
+		}
+
+		// Bad positions make for bogus records
+		if !haveRef || (ac.Lat == 0 && ac.Lon == 0) || ac.Anomalies&sbs.PositionJump != 0 {
+			continue
+		}
+		dist := geo.Distance(refLat, refLon, ac.Lat, ac.Lon)
+		if dist < t.rec.MaxRange+minRecordGain {
+			continue
+		}
+		t.rec.MaxRange, t.rec.MaxRangeBy = dist, hex
+		text := fmt.Sprintf("New range record: %.0f nm, %s", dist, name(ac))
+
+		// An aircraft heading out sets a record every few seconds; keep
+		// that to one event, brought up to date
+		if t.rangeAt >= 0 && t.events[t.rangeAt].ICAO == hex {
+			t.events[t.rangeAt].Text = text
+			continue
+		}
+		add(ac, "%s", text)
+		t.rangeAt = len(t.events) - 1
+	}
+	return found
+}
+
+// visit notes hearing an airframe at now, counting a visit if it's new
+// this session or it's been gone for visitGap
+func (t *Tracker) visit(hex string, now time.Time) {
+	v, ok := t.rec.Airframes[hex]
+	if !ok {
+		v = &Visits{First: now}
+		t.rec.Airframes[hex] = v
+	}
+	if !t.seen[hex] || now.Sub(v.Last) >= visitGap {
+		v.Count++
+	}
+	v.Last = now
+}
+
+// Regulars are the n airframes that have come by most often, this session
+// and before, the most frequent first
+func (t *Tracker) Regulars(n int) []Regular {
+	regulars := make([]Regular, 0, len(t.rec.Airframes))
+	for hex, v := range t.rec.Airframes {
+		r := Regular{ICAO: hex, Visits: *v}
+		if uses := t.rec.Callsigns[hex]; len(uses) > 0 {
+			r.Callsign = uses[len(uses)-1].Callsign
+		}
+		regulars = append(regulars, r)
+	}
+	slices.SortFunc(regulars, func(a, b Regular) int {
+		if c := cmp.Compare(b.Count, a.Count); c != 0 {
+			return c
+		}
+		if c := b.Last.Compare(a.Last); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ICAO, b.ICAO)
+	})
+	return regulars[:min(n, len(regulars))]
+}
+
+// Callsigns are the callsigns the aircraft has gone by, this session and
+// before, oldest first
+func (t *Tracker) Callsigns(hex string) []sbs.CallsignUse {
+	return slices.Clone(t.rec.Callsigns[hex])
+}
+
+// Seen is how many aircraft we've seen this session
+func (t *Tracker) Seen() int {
+	return len(t.seen)
+}
+
+// Events are this session's notable sightings, oldest first
+func (t *Tracker) Events() []Event {
+	return t.events
+}
+
+// Summary sums up the session, for printing when we exit
+func (t *Tracker) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Session: %d aircraft seen", t.Seen())
+	if t.rec.MaxRange > 0 {
+		fmt.Fprintf(&b, ", range record %.0f nm", t.rec.MaxRange)
+	}
+	b.WriteString("\n")
+	if len(t.events) > 0 {
+		b.WriteString("Notable sightings:\n")
+	}
+	for _, e := range t.events {
+		fmt.Fprintf(&b, "  %s %s\n", e.At.Format("15:04"), e.Text)
+	}
+	return b.String()
+}
+
+// name is how an event names an aircraft: its callsign with its hex, or
+// just the hex
+func name(ac *sbs.Aircraft) string {
+	if ac.Callsign != "" && !icao.BlockedCallsign(ac.Callsign) {
+		return fmt.Sprintf("%s (%s)", ac.Callsign, ac.ICAO)
+	}
+	return ac.ICAO
+}
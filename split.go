@@ -0,0 +1,40 @@
+package main
+
+import (
+	mapview "termtrack/ui/map"
+)
+
+// maps are the map panes on screen: the main one, and the second beside
+// it while the view's split
+func (m *model) maps() []*mapview.Model {
+	if m.split {
+		return []*mapview.Model{&m.mapModel, &m.splitModel}
+	}
+	return []*mapview.Model{&m.mapModel}
+}
+
+// focused is the map pane keys go to
+func (m *model) focused() *mapview.Model {
+	if m.split && m.splitFocus {
+		return &m.splitModel
+	}
+	return &m.mapModel
+}
+
+// toggleSplit puts a second map beside the first, closer in on the same
+// spot, or takes it away again. Keys go to the new one.
+func (m *model) toggleSplit() {
+	m.split = !m.split
+	if m.split {
+		m.splitModel = m.mapModel.Split()
+	}
+	m.setFocus(m.split)
+}
+
+// setFocus sends keys to the second map, or back to the main one
+func (m *model) setFocus(second bool) {
+	m.splitFocus = second && m.split
+	m.mapModel.SetInactive(m.splitFocus)
+	m.splitModel.SetInactive(m.split && !m.splitFocus)
+	m.footerModel.SetZoom(m.focused().GetZoomLevel())
+}
@@ -0,0 +1,267 @@
+// Package tar1090 serves the aircraft store the way readsb does, so the
+// stock tar1090 web UI can be pointed at TermTrack: receiver.json,
+// aircraft.json and the globe_NNNN.json tiles of its globe mode, all under
+// /data/. Point -http-html at a tar1090 html directory to serve the UI
+// itself from /.
+package tar1090
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"termtrack/sbs"
+)
+
+// globeGrid is the size of a globe tile in degrees, as readsb's
+// GLOBE_INDEX_GRID. We don't have readsb's special tiles, so every tile is
+// a plain grid square.
+const globeGrid = 3
+
+// globeLatMult is how far the index moves for one row of tiles
+const globeLatMult = 360/globeGrid + 1
+
+// globeIndexBase is the index of the south-west tile; readsb keeps the
+// ones below it for the special tiles
+const globeIndexBase = 1000
+
+// refreshMillis is how often tar1090 should poll
+const refreshMillis = 1000
+
+// staleAfter is how long since it was heard before an aircraft is left out
+const staleAfter = 5 * time.Minute
+
+// shutdownTimeout is how long Shutdown waits for requests to finish
+const shutdownTimeout = 2 * time.Second
+
+// Receiver is where the receiver is, for tar1090 to center on and draw
+// range rings around. The zero value means we don't know.
+type Receiver struct {
+	Lat, Lon float64
+	Set      bool
+}
+
+// globeIndex is the tile a position falls in
+func globeIndex(lat, lon float64) int {
+	i := int(math.Floor((lat + 90) / globeGrid))
+	j := int(math.Floor((lon + 180) / globeGrid))
+	return i*globeLatMult + j + globeIndexBase
+}
+
+// tileBounds is the area a tile covers, or ok false if it isn't one
+func tileBounds(index int) (south, west, north, east float64, ok bool) {
+	index -= globeIndexBase
+	if index < 0 {
+		return 0, 0, 0, 0, false
+	}
+	i, j := index/globeLatMult, index%globeLatMult
+	south, west = float64(i*globeGrid-90), float64(j*globeGrid-180)
+	if south >= 90 || west >= 180 {
+		return 0, 0, 0, 0, false
+	}
+	return south, west, south + globeGrid, west + globeGrid, true
+}
+
+// aircraftJSON is one aircraft as readsb writes it
+type aircraftJSON struct {
+	Hex      string   `json:"hex"`
+	Type     string   `json:"type"`
+	Flight   string   `json:"flight,omitempty"`
+	AltBaro  *int     `json:"alt_baro,omitempty"`
+	GS       *float64 `json:"gs,omitempty"`
+	Track    *float64 `json:"track,omitempty"`
+	Squawk   string   `json:"squawk,omitempty"`
+	Category string   `json:"category,omitempty"`
+	Lat      *float64 `json:"lat,omitempty"`
+	Lon      *float64 `json:"lon,omitempty"`
+	SeenPos  *float64 `json:"seen_pos,omitempty"`
+	Seen     float64  `json:"seen"`
+	RSSI     *float64 `json:"rssi,omitempty"`
+}
+
+// convert turns an aircraft into readsb's shape
+func convert(ac *sbs.Aircraft, now time.Time) aircraftJSON {
+	out := aircraftJSON{
+		Hex:      strings.ToLower(ac.ICAO),
+		Type:     jsonType(ac.Source),
+		Squawk:   ac.Squawk,
+		Category: ac.Category,
+		Seen:     round1(now.Sub(ac.LastSeen).Seconds()),
+	}
+	if ac.Callsign != "" {
+		out.Flight = fmt.Sprintf("%-8s", ac.Callsign)
+	}
+	if ac.Altitude != 0 {
+		out.AltBaro = &ac.Altitude
+	}
+	if ac.Speed != 0 {
+		out.GS, out.Track = &ac.Speed, &ac.Track
+	}
+	if ac.Lat != 0 || ac.Lon != 0 {
+		out.Lat, out.Lon = &ac.Lat, &ac.Lon
+		seen := out.Seen
+		if !ac.PositionAt.IsZero() {
+			seen = round1(now.Sub(ac.PositionAt).Seconds())
+		}
+		out.SeenPos = &seen
+	}
+	if ac.Has&sbs.HasSignal != 0 {
+		rssi := round1(ac.Signal)
+		out.RSSI = &rssi
+	}
+	return out
+}
+
+// jsonType is readsb's name for where an aircraft's data comes from
+func jsonType(s sbs.Source) string {
+	if s == sbs.SourceMLAT {
+		return "mlat"
+	}
+	return "adsb_icao"
+}
+
+// round1 rounds to a tenth, as readsb does
+func round1(f float64) float64 {
+	return math.Round(f*10) / 10
+}
+
+// handler answers tar1090's requests from the store
+type handler struct {
+	store    *sbs.Store
+	feed     *sbs.Feed // For the message count
+	receiver Receiver
+}
+
+// Handler serves /data/ from store, and html (a tar1090 html directory)
+// from /, if given
+func Handler(store *sbs.Store, feed *sbs.Feed, receiver Receiver, html string) http.Handler {
+	h := &handler{store: store, feed: feed, receiver: receiver}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /data/receiver.json", h.receiverJSON)
+	mux.HandleFunc("GET /data/aircraft.json", h.aircraftJSON)
+	mux.HandleFunc("GET /data/{tile}", h.globeTile)
+	if html != "" {
+		mux.Handle("GET /", http.FileServer(http.Dir(html)))
+	}
+	return mux
+}
+
+// receiverJSON says how we're laid out: globe tiles on a plain grid
+func (h *handler) receiverJSON(w http.ResponseWriter, r *http.Request) {
+	out := map[string]any{
+		"version":                "termtrack",
+		"refresh":                refreshMillis,
+		"history":                0,
+		"globeIndexGrid":         globeGrid,
+		"globeIndexSpecialTiles": []any{},
+	}
+	if h.receiver.Set {
+		out["lat"], out["lon"] = h.receiver.Lat, h.receiver.Lon
+	}
+	writeJSON(w, out)
+}
+
+// aircraftJSON is every aircraft heard lately, for tar1090 without globe mode
+func (h *handler) aircraftJSON(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	writeJSON(w, map[string]any{
+		"now":      float64(now.UnixMilli()) / 1000,
+		"messages": h.messages(),
+		"aircraft": heard(h.store.Snapshot(), now, func(*sbs.Aircraft) bool { return true }),
+	})
+}
+
+// globeTile is the aircraft in one globe_NNNN.json tile
+func (h *handler) globeTile(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("tile")
+	digits, ok := strings.CutPrefix(name, "globe_")
+	if ok {
+		digits, ok = strings.CutSuffix(digits, ".json")
+	}
+	index, err := strconv.Atoi(digits)
+	if !ok || err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	south, west, north, east, ok := tileBounds(index)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	now := time.Now()
+	snap := h.store.Snapshot()
+	withPos := 0
+	for _, ac := range snap {
+		if ac.Lat != 0 || ac.Lon != 0 {
+			withPos++
+		}
+	}
+	writeJSON(w, map[string]any{
+		"now":                     float64(now.UnixMilli()) / 1000,
+		"messages":                h.messages(),
+		"global_ac_count_withpos": withPos,
+		"globeIndex":              index,
+		"south":                   south,
+		"west":                    west,
+		"north":                   north,
+		"east":                    east,
+		"aircraft": heard(snap, now, func(ac *sbs.Aircraft) bool {
+			return (ac.Lat != 0 || ac.Lon != 0) && globeIndex(ac.Lat, ac.Lon) == index
+		}),
+	})
+}
+
+// messages is readsb's running message count: for us, lines off the feed
+func (h *handler) messages() uint64 {
+	lines, _ := h.feed.Counts()
+	return lines
+}
+
+// heard converts the aircraft heard lately that pass keep
+func heard(all map[string]*sbs.Aircraft, now time.Time, keep func(*sbs.Aircraft) bool) []aircraftJSON {
+	out := []aircraftJSON{} // [] rather than null
+	for _, ac := range all {
+		if now.Sub(ac.LastSeen) > staleAfter || !keep(ac) {
+			continue
+		}
+		out = append(out, convert(ac, now))
+	}
+	return out
+}
+
+// writeJSON sends v, telling the browser not to cache it
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(v)
+}
+
+// Listener is a running HTTP server
+type Listener struct {
+	srv *http.Server
+}
+
+// ListenAndServe starts serving store on addr in the background
+func ListenAndServe(addr string, store *sbs.Store, feed *sbs.Feed, receiver Receiver, html string) (*Listener, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("http listen: %w", err)
+	}
+	l := &Listener{srv: &http.Server{Handler: Handler(store, feed, receiver, html)}}
+	go l.srv.Serve(ln)
+	return l, nil
+}
+
+// Shutdown stops the server, giving requests in flight a moment to finish
+func (l *Listener) Shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	l.srv.Shutdown(ctx)
+}
@@ -0,0 +1,191 @@
+package testfeed
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Step is one line of a scenario, sent Delay after the previous one
+type Step struct {
+	Delay time.Duration
+	Line  string
+}
+
+// Scenario is a scripted sequence of SBS lines
+type Scenario struct {
+	Name  string
+	Steps []Step
+}
+
+// Scenarios lists the built-in scenarios by name
+var Scenarios = map[string]func() Scenario{
+	"takeoff":   Takeoff,
+	"emergency": Emergency,
+	"malformed": Malformed,
+	"burst":     func() Scenario { return Burst(200) },
+}
+
+// Message holds the SBS fields a scenario cares about. Zero values are
+// left empty on the wire, same as a real decoder does.
+type Message struct {
+	Type     int
+	ICAO     string
+	Callsign string
+	Altitude int
+	Speed    float64
+	Track    float64
+	Lat      float64
+	Lon      float64
+	VertRate int
+	Squawk   string
+	Emerg    bool
+}
+
+// Line formats m as a 22-field SBS line. The date/time fields are left
+// empty; the server stamps them as the line goes out.
+func (m Message) Line() string {
+	f := make([]string, 22)
+	f[0] = "MSG"
+	f[1] = fmt.Sprint(m.Type)
+	f[2], f[3], f[5] = "1", "1", "1"
+	f[4] = m.ICAO
+
+	switch m.Type {
+	case 1:
+		f[10] = fmt.Sprintf("%-8s", m.Callsign)
+	case 3:
+		f[11] = fmt.Sprint(m.Altitude)
+		f[14] = fmt.Sprintf("%.5f", m.Lat)
+		f[15] = fmt.Sprintf("%.5f", m.Lon)
+	case 4:
+		f[12] = fmt.Sprintf("%.0f", m.Speed)
+		f[13] = fmt.Sprintf("%.0f", m.Track)
+		f[16] = fmt.Sprint(m.VertRate)
+	case 6:
+		f[17] = m.Squawk
+	}
+
+	// Flag fields: alert, emergency, SPI, on ground
+	if m.Type == 3 || m.Type == 6 {
+		f[18], f[19], f[20], f[21] = "0", "0", "0", "0"
+		if m.Emerg {
+			f[19] = "-1"
+		}
+	}
+	return strings.Join(f, ",")
+}
+
+// stamp fills in the four date/time fields of an MSG line with now.
+// Anything that isn't a well-formed MSG line goes out untouched.
+func stamp(line string, now time.Time) string {
+	f := strings.Split(line, ",")
+	if len(f) < 10 || f[0] != "MSG" || f[6] != "" {
+		return line
+	}
+	date, clock := now.Format("2006/01/02"), now.Format("15:04:05.000")
+	f[6], f[7], f[8], f[9] = date, clock, date, clock
+	return strings.Join(f, ",")
+}
+
+// move returns the point dist nautical miles along track from lat/lon
+// (flat-earth, fine over the few miles a scenario covers)
+func move(lat, lon, track, dist float64) (float64, float64) {
+	rad := track * math.Pi / 180
+	dLat := dist * math.Cos(rad) / 60
+	dLon := dist * math.Sin(rad) / (60 * math.Cos(lat*math.Pi/180))
+	return lat + dLat, lon + dLon
+}
+
+// Takeoff is a departure off JFK runway 31L, climbing out to 10,000ft
+func Takeoff() Scenario {
+	const icao = "A0B1C2"
+	lat, lon, track := 40.6235, -73.7620, 310.0
+
+	sc := Scenario{Name: "takeoff"}
+	sc.add(0, Message{Type: 1, ICAO: icao, Callsign: "JBU1234"})
+	for i := 0; i < 90; i++ {
+		speed := math.Min(160+float64(i)*2, 280)
+		alt := i * 110
+		lat, lon = move(lat, lon, track, speed/3600)
+		if i == 30 {
+			track = 270 // Turn out west
+		}
+		sc.add(time.Second, Message{Type: 3, ICAO: icao, Altitude: alt, Lat: lat, Lon: lon})
+		sc.add(0, Message{Type: 4, ICAO: icao, Speed: speed, Track: track, VertRate: 2500})
+	}
+	return sc
+}
+
+// Emergency is a cruising aircraft that squawks 7700 and descends
+func Emergency() Scenario {
+	const icao = "AC82EC"
+	lat, lon, track, alt := 40.90, -74.30, 90.0, 34000
+
+	sc := Scenario{Name: "emergency"}
+	sc.add(0, Message{Type: 1, ICAO: icao, Callsign: "DAL88"})
+	sc.add(0, Message{Type: 6, ICAO: icao, Squawk: "4521"})
+	for i := 0; i < 120; i++ {
+		emerg := i >= 20
+		vrate := 0
+		if emerg {
+			vrate = -3000
+			alt -= 50
+		}
+		lat, lon = move(lat, lon, track, 450.0/3600)
+		sc.add(time.Second, Message{Type: 3, ICAO: icao, Altitude: alt, Lat: lat, Lon: lon, Emerg: emerg})
+		sc.add(0, Message{Type: 4, ICAO: icao, Speed: 450, Track: track, VertRate: vrate})
+		if i == 20 {
+			sc.add(0, Message{Type: 6, ICAO: icao, Squawk: "7700", Emerg: true})
+		}
+	}
+	return sc
+}
+
+// Malformed is a selection of the junk real feeds produce, mixed in with
+// a well-behaved aircraft so there's something to look at
+func Malformed() Scenario {
+	sc := Scenario{Name: "malformed"}
+	good := Message{Type: 3, ICAO: "4CA123", Altitude: 12000, Lat: 40.7, Lon: -73.9}
+	junk := []string{
+		"",
+		"garbage",
+		"MSG,3,1,1",                            // Truncated
+		"MSG,3,1,1,,1,,,,,,12000,,,40.7,-73.9", // No ICAO
+		"MSG,3,1,1,4CA124,1,,,,,,12000,,,north,west,,,0,0,0,0",
+		"MSG,4,1,1,4CA125,1,,,,,,,fast,east,,,,,,,,",
+		"MSG,1,1,1,4CA126,1,,,,,\x00\x01\x02,,,,,,,,,,,",
+		"SEL,,1,1,4CA127,1,,,,,,,,,,,,,,,,",
+		"MSG,9,1,1,4CA128,1,,,,,,,,,,,,,,,,", // Unknown type
+		strings.Repeat("MSG,", 200),
+		"MSG,3,1,1,4CA129,1,,,,,,12000,,,0,0,,,0,0,0,0", // Null island
+	}
+	for i := 0; i < 5; i++ {
+		sc.add(500*time.Millisecond, good)
+		for _, line := range junk {
+			sc.Steps = append(sc.Steps, Step{Delay: 100 * time.Millisecond, Line: line})
+		}
+	}
+	return sc
+}
+
+// Burst sends n aircraft's worth of position, callsign and velocity
+// messages with no delay at all, ringed around New York
+func Burst(n int) Scenario {
+	sc := Scenario{Name: "burst"}
+	for i := 0; i < n; i++ {
+		icao := fmt.Sprintf("B%05X", i)
+		angle := float64(i) * 360 / float64(n)
+		lat, lon := move(40.64, -73.78, angle, 5+float64(i%40))
+		sc.add(0, Message{Type: 1, ICAO: icao, Callsign: fmt.Sprintf("TST%d", i)})
+		sc.add(0, Message{Type: 3, ICAO: icao, Altitude: 1000 + i*100, Lat: lat, Lon: lon})
+		sc.add(0, Message{Type: 4, ICAO: icao, Speed: 250, Track: angle, VertRate: 0})
+	}
+	return sc
+}
+
+// add appends a message as a step
+func (sc *Scenario) add(delay time.Duration, m Message) {
+	sc.Steps = append(sc.Steps, Step{Delay: delay, Line: m.Line()})
+}
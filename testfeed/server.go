@@ -0,0 +1,110 @@
+package testfeed
+
+import (
+	"bufio"
+	"net"
+	"sync"
+	"time"
+)
+
+// Server plays a scenario to every client that connects, like a
+// dump1090 SBS port would
+type Server struct {
+	ln       net.Listener
+	scenario Scenario
+	loop     bool
+
+	wg     sync.WaitGroup
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+// Listen starts listening on addr ("localhost:0" picks a free port). If
+// loop is set the scenario repeats until the client goes away.
+func Listen(addr string, sc Scenario, loop bool) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		ln:       ln,
+		scenario: sc,
+		loop:     loop,
+		conns:    make(map[net.Conn]struct{}),
+	}, nil
+}
+
+// Addr returns the address the server is listening on
+func (s *Server) Addr() string {
+	return s.ln.Addr().String()
+}
+
+// Serve accepts clients until Close is called
+func (s *Server) Serve() error {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.play(conn)
+		}()
+	}
+}
+
+// Close stops the server and drops every client
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+// play writes the scenario to one client, then hangs up
+func (s *Server) play(conn net.Conn) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	w := bufio.NewWriter(conn)
+	for {
+		for _, step := range s.scenario.Steps {
+			if step.Delay > 0 {
+				// Flush what we have before waiting, so bursts arrive together
+				if w.Flush() != nil {
+					return
+				}
+				time.Sleep(step.Delay)
+			}
+			if _, err := w.WriteString(stamp(step.Line, time.Now()) + "\r\n"); err != nil {
+				return
+			}
+		}
+		if w.Flush() != nil || !s.loop {
+			return
+		}
+	}
+}
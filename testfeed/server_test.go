@@ -0,0 +1,122 @@
+package testfeed
+
+import (
+	"fmt"
+	"testing"
+
+	"termtrack/sbs"
+)
+
+// instant is sc with its delays taken out, so a test doesn't sit through
+// the minutes a scenario takes to play
+func instant(sc Scenario) Scenario {
+	steps := make([]Step, len(sc.Steps))
+	for i, step := range sc.Steps {
+		steps[i] = Step{Line: step.Line}
+	}
+	return Scenario{Name: sc.Name, Steps: steps}
+}
+
+// play serves sc over TCP to a feed writing into a new store, the same
+// way TermTrack reads dump1090, and returns the store and the feed once
+// the scenario's over and the server's hung up
+func play(t *testing.T, sc Scenario) (*sbs.Store, *sbs.Feed) {
+	t.Helper()
+	srv, err := Listen("localhost:0", instant(sc), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer srv.Close()
+	go srv.Serve()
+
+	conn, err := sbs.Dial(srv.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := sbs.NewStore()
+	feed := sbs.NewFeed("test", store)
+	feed.Run(conn) // Ends when the scenario does
+	return store, feed
+}
+
+func TestScenarios(t *testing.T) {
+	tests := []struct {
+		name     string
+		sc       Scenario
+		icao     string
+		callsign string
+		altitude int
+		speed    float64
+		track    float64
+		squawk   string
+		emerg    bool
+	}{
+		{name: "takeoff", sc: Takeoff(), icao: "A0B1C2", callsign: "JBU1234", altitude: 89 * 110, speed: 280, track: 270},
+		{name: "emergency", sc: Emergency(), icao: "AC82EC", callsign: "DAL88", altitude: 34000 - 100*50, speed: 450, track: 90, squawk: "7700", emerg: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := play(t, tt.sc)
+			if store.Len() != 1 {
+				t.Fatalf("got %d aircraft, want 1", store.Len())
+			}
+			ac, ok := store.Get(tt.icao)
+			if !ok {
+				t.Fatalf("%s not in the store", tt.icao)
+			}
+			got := fmt.Sprintf("%s %d %.0f %.0f %s %v", ac.Callsign, ac.Altitude, ac.Speed, ac.Track, ac.Squawk, ac.Emergency)
+			want := fmt.Sprintf("%s %d %.0f %.0f %s %v", tt.callsign, tt.altitude, tt.speed, tt.track, tt.squawk, tt.emerg)
+			if got != want {
+				t.Errorf("got %q, want %q", got, want)
+			}
+			if ac.Has&sbs.HasPosition == 0 || len(ac.Trail) < 2 {
+				t.Errorf("has position %v with %d trail points", ac.Has&sbs.HasPosition != 0, len(ac.Trail))
+			}
+		})
+	}
+}
+
+func TestMalformed(t *testing.T) {
+	sc := Malformed()
+	store, feed := play(t, sc)
+
+	if lines, _ := feed.Counts(); lines != uint64(len(sc.Steps)) {
+		t.Errorf("read %d lines, want %d", lines, len(sc.Steps))
+	}
+	ac, ok := store.Get("4CA123")
+	if !ok {
+		t.Fatal("the well-behaved aircraft isn't in the store")
+	}
+	if ac.Lat != 40.7 || ac.Lon != -73.9 || ac.Altitude != 12000 {
+		t.Errorf("got %.2f, %.2f at %d, want 40.70, -73.90 at 12000", ac.Lat, ac.Lon, ac.Altitude)
+	}
+	// The junk can leave an altitude behind, but never a position
+	for icao, ac := range store.Snapshot() {
+		if icao != "4CA123" && ac.Has&sbs.HasPosition != 0 {
+			t.Errorf("%s has a position, %.2f, %.2f", icao, ac.Lat, ac.Lon)
+		}
+	}
+}
+
+func TestBurst(t *testing.T) {
+	const n = 200
+	store, feed := play(t, Burst(n))
+
+	if _, updates := feed.Counts(); updates != 3*n {
+		t.Errorf("got %d updates, want %d", updates, 3*n)
+	}
+	if store.Len() != n {
+		t.Fatalf("got %d aircraft, want %d", store.Len(), n)
+	}
+	for i := 0; i < n; i++ {
+		icao := fmt.Sprintf("B%05X", i)
+		ac, ok := store.Get(icao)
+		if !ok {
+			t.Errorf("%s missing", icao)
+			continue
+		}
+		if ac.Callsign != fmt.Sprintf("TST%d", i) || ac.Has&sbs.HasPosition == 0 || ac.Speed != 250 {
+			t.Errorf("%s: got %q, position %v, speed %.0f", icao, ac.Callsign, ac.Has&sbs.HasPosition != 0, ac.Speed)
+		}
+	}
+}
@@ -8,12 +8,20 @@ import (
 // How often we't like to re-render the map
 const renderFrameRate = time.Millisecond * 50 // ~20fps
 
+// lowBandwidthFrameRate is how often with -low-bandwidth, for links where
+// every frame counts
+const lowBandwidthFrameRate = time.Millisecond * 250 // 4fps
+
+// reducedFrameRate is how often with the reduced render profile, for tmux
+// and screen
+const reducedFrameRate = time.Millisecond * 100 // 10fps
+
 // TickMsg is the message sent on every render tick
 type TickMsg struct{}
 
-// TickCmd returns a command that sends a TickMsg after our frame rate delay
-func TickCmd() tea.Cmd {
-	return tea.Tick(renderFrameRate, func(t time.Time) tea.Msg {
+// TickCmd returns a command that sends a TickMsg after a frame's delay
+func TickCmd(every time.Duration) tea.Cmd {
+	return tea.Tick(every, func(t time.Time) tea.Msg {
 		return TickMsg{}
 	})
 }
\ No newline at end of file
@@ -0,0 +1,176 @@
+// Package traffic counts the arrivals and departures at each airport over
+// the session, going by aircraft coming down into or climbing out of the
+// airspace just around it.
+package traffic
+
+import (
+	"sort"
+	"time"
+
+	"termtrack/geo"
+	"termtrack/sbs"
+	"termtrack/watch"
+)
+
+// An aircraft is at an airport while it's within radius nautical miles of
+// it and below ceiling feet. The altitude is pressure altitude, not height
+// above the field, so this is generous; only the climb or descent while
+// there decides anything.
+const (
+	radius  = 4.0
+	ceiling = 3000
+)
+
+// minChange is how many feet an aircraft has to come down (or go up)
+// while at an airport to count as arriving (or departing), so one passing
+// over low and level isn't either
+const minChange = 300
+
+// Airport is a field to count traffic at
+type Airport struct {
+	Code string // ICAO code, or IATA if that's all it has
+	Name string
+	Lat  float64
+	Lon  float64
+}
+
+// Count is one airport's traffic so far
+type Count struct {
+	Airport
+	Arrivals   int
+	Departures int
+}
+
+// Total is the airport's arrivals and departures together
+func (c Count) Total() int {
+	return c.Arrivals + c.Departures
+}
+
+// visit is an aircraft's time at an airport, by altitude
+type visit struct {
+	airport int // Index into the counter's airports
+	entry   int // Altitude when it got there
+	low     int // The lowest it's been since
+	last    int
+}
+
+// Counter watches aircraft come and go at the airports
+type Counter struct {
+	airports []Airport
+	counts   map[int]*Count    // By index into airports
+	visits   map[string]*visit // By ICAO, aircraft at an airport now
+	watch.Watch
+}
+
+// New creates a counter with no airports yet
+func New() *Counter {
+	return &Counter{
+		counts: make(map[int]*Count),
+		visits: make(map[string]*visit),
+	}
+}
+
+// SetAirports sets the airports to count at, starting the counts afresh
+func (c *Counter) SetAirports(airports []Airport) {
+	c.airports = airports
+	clear(c.counts)
+	clear(c.visits)
+}
+
+// Check follows the aircraft in and out of the airports (at most every
+// watch.Interval), counting the arrivals and departures as they finish
+func (c *Counter) Check(all map[string]*sbs.Aircraft) {
+	now := time.Now()
+	if !c.Due(now) {
+		return
+	}
+
+	for hex, ac := range all {
+		if ac.Has&sbs.HasPosition == 0 || ac.Has&sbs.HasAltitude == 0 {
+			continue
+		}
+		v := c.visits[hex]
+		if c.Quiet(ac, now) {
+			if v != nil {
+				c.finish(v)
+				delete(c.visits, hex)
+			}
+			continue
+		}
+
+		at := c.at(ac)
+		if v != nil && v.airport != at {
+			c.finish(v)
+			delete(c.visits, hex)
+			v = nil
+		}
+		switch {
+		case at < 0:
+		case v == nil:
+			c.visits[hex] = &visit{airport: at, entry: ac.Altitude, low: ac.Altitude, last: ac.Altitude}
+		default:
+			v.low = min(v.low, ac.Altitude)
+			v.last = ac.Altitude
+		}
+	}
+
+	// Gone from the store altogether: landed, most likely
+	for hex, v := range c.visits {
+		if _, ok := all[hex]; !ok {
+			c.finish(v)
+			delete(c.visits, hex)
+		}
+	}
+}
+
+// at is the index of the airport the aircraft is at, or -1 if it's not
+// at one
+func (c *Counter) at(ac *sbs.Aircraft) int {
+	if ac.Altitude >= ceiling {
+		return -1
+	}
+	best, bestDist := -1, radius
+	for i, a := range c.airports {
+		if d, ok := geo.Within(ac.Lat, ac.Lon, a.Lat, a.Lon, bestDist); ok {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+// finish counts a visit that's over: one that came down arrived, and one
+// that climbed out departed. A touch and go (or a go-around; they look the
+// same from here) does both.
+func (c *Counter) finish(v *visit) {
+	descended := v.entry-v.low >= minChange
+	climbed := v.last-v.low >= minChange
+	if !descended && !climbed {
+		return // Passed over, or sat on the ground
+	}
+	n := c.counts[v.airport]
+	if n == nil {
+		n = &Count{Airport: c.airports[v.airport]}
+		c.counts[v.airport] = n
+	}
+	if descended {
+		n.Arrivals++
+	}
+	if climbed {
+		n.Departures++
+	}
+}
+
+// Counts are the airports that have seen any traffic, busiest first
+func (c *Counter) Counts() []Count {
+	out := make([]Count, 0, len(c.counts))
+	for _, n := range c.counts {
+		out = append(out, *n)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Total() != out[j].Total() {
+			return out[i].Total() > out[j].Total()
+		}
+		return out[i].Code < out[j].Code
+	})
+	return out
+}
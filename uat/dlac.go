@@ -0,0 +1,36 @@
+package uat
+
+import "strings"
+
+// dlacAlphabet is the 6-bit DO-267 text alphabet. 0x03 ends a product,
+// 0x1E separates its records, and code 28 is a tab whose next code is a
+// count of spaces.
+const dlacAlphabet = "\x03ABCDEFGHIJKLMNOPQRSTUVWXYZ\x1a\t\x1e\n| !\"#$%&'()*+,-./0123456789:;<=>?"
+
+// dlacTab is the code for a run of spaces
+const dlacTab = 28
+
+// decodeDLAC unpacks 6-bit DLAC text, four characters to every three bytes
+func decodeDLAC(data []byte) string {
+	var b strings.Builder
+	tab := false
+	for bit := 0; bit+6 <= len(data)*8; bit += 6 {
+		i, shift := bit/8, bit%8
+		v := int(data[i]) << 8
+		if i+1 < len(data) {
+			v |= int(data[i+1])
+		}
+		ch := (v >> (10 - shift)) & 0x3f
+
+		switch {
+		case tab:
+			b.WriteString(strings.Repeat(" ", ch))
+			tab = false
+		case ch == dlacTab:
+			tab = true
+		default:
+			b.WriteByte(dlacAlphabet[ch])
+		}
+	}
+	return b.String()
+}
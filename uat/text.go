@@ -0,0 +1,44 @@
+package uat
+
+import (
+	"strings"
+	"time"
+)
+
+// textProduct is the FIS-B product ID for text reports (METARs, TAFs,
+// NOTAMs and so on) in DLAC
+const textProduct = 413
+
+// Report is one text weather report, as broadcast
+type Report struct {
+	Type     string // METAR, SPECI, TAF, NOTAM, PIREP...
+	Station  string // Where it's for, usually the airport's ICAO code
+	Time     string // When it was issued, as written (e.g. "121853Z")
+	Text     string
+	Received time.Time
+}
+
+// textReports splits a text product into its reports. Each record reads
+// "TYPE STATION TIME text".
+func textReports(p Product, received time.Time) []Report {
+	text := decodeDLAC(p.Data)
+	if end := strings.IndexByte(text, '\x03'); end >= 0 {
+		text = text[:end]
+	}
+
+	var reports []Report
+	for _, record := range strings.Split(text, "\x1e") {
+		fields := strings.SplitN(strings.TrimSpace(record), " ", 4)
+		if len(fields) < 4 {
+			continue // Padding, or a record too mangled to use
+		}
+		reports = append(reports, Report{
+			Type:     fields[0],
+			Station:  fields[1],
+			Time:     fields[2],
+			Text:     strings.Join(strings.Fields(fields[3]), " "),
+			Received: received,
+		})
+	}
+	return reports
+}
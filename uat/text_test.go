@@ -0,0 +1,92 @@
+package uat
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// encodeDLAC packs text into 6-bit DLAC, the other way to decodeDLAC.
+// Characters outside the alphabet come out as spaces.
+func encodeDLAC(text string) []byte {
+	var codes []int
+	for _, r := range text {
+		i := strings.IndexRune(dlacAlphabet, r)
+		if i < 0 {
+			i = strings.IndexByte(dlacAlphabet, ' ')
+		}
+		codes = append(codes, i)
+	}
+	data := make([]byte, (len(codes)*6+7)/8)
+	for n, code := range codes {
+		for b := 0; b < 6; b++ {
+			if code&(0x20>>b) != 0 {
+				bit := n*6 + b
+				data[bit/8] |= 0x80 >> (bit % 8)
+			}
+		}
+	}
+	return data
+}
+
+func TestDecodeDLAC(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "text", data: encodeDLAC("METAR KJFK"), want: "METAR KJFK"},
+		{name: "digits and punctuation", data: encodeDLAC("27015G25KT 10SM -RA"), want: "27015G25KT 10SM -RA"},
+		{name: "a run of spaces", data: packCodes(1, dlacTab, 5, 2), want: "A     B"},
+		{name: "end of product", data: encodeDLAC("AB\x03C"), want: "AB\x03C"},
+		{name: "nothing", data: nil, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A whole number of characters doesn't always fill the last
+			// byte; whatever the spare bits decode to comes after
+			if got := decodeDLAC(tt.data); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// packCodes packs four DLAC codes into three bytes
+func packCodes(a, b, c, d int) []byte {
+	v := a<<18 | b<<12 | c<<6 | d
+	return []byte{byte(v >> 16), byte(v >> 8), byte(v)}
+}
+
+func TestTextReports(t *testing.T) {
+	received := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		text string
+		want []Report
+	}{
+		{
+			name: "one",
+			text: "METAR KJFK 151151Z 27015KT 10SM FEW250 18/06 A3012\x03",
+			want: []Report{{Type: "METAR", Station: "KJFK", Time: "151151Z", Text: "27015KT 10SM FEW250 18/06 A3012", Received: received}},
+		},
+		{
+			name: "records, with the spacing tidied",
+			text: "METAR KJFK 151151Z 27015KT\n  10SM\x1eTAF KLGA 151130Z 1512/1612 28012KT P6SM\x03PADDING",
+			want: []Report{
+				{Type: "METAR", Station: "KJFK", Time: "151151Z", Text: "27015KT 10SM", Received: received},
+				{Type: "TAF", Station: "KLGA", Time: "151130Z", Text: "1512/1612 28012KT P6SM", Received: received},
+			},
+		},
+		{name: "too mangled", text: "METAR KJFK\x1e\x03"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := textReports(Product{ID: textProduct, Data: encodeDLAC(tt.text)}, received)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+// Package uat reads FIS-B weather broadcast over UAT (978MHz), as
+// demodulated by dump978.
+package uat
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// uplinkBytes is the size of a ground station uplink frame after error
+// correction: an 8 byte header, then the application data
+const (
+	uplinkBytes  = 432
+	uplinkHeader = 8
+)
+
+// Uplink is a decoded ground station broadcast
+type Uplink struct {
+	Lat, Lon      float64 // Where the ground station is
+	PositionValid bool
+	Frames        []InfoFrame
+}
+
+// InfoFrame is one chunk of an uplink's application data
+type InfoFrame struct {
+	Type int // 0 is FIS-B
+	Data []byte
+}
+
+// parseRaw pulls the frame out of a line of dump978's raw output. Uplinks
+// look like "+<hex>;rs=1;ss=..;" and downlinks (aircraft) start with "-",
+// which we've no use for. ok is false for anything but an uplink.
+func parseRaw(line string) (frame []byte, ok bool) {
+	if !strings.HasPrefix(line, "+") {
+		return nil, false
+	}
+	body, _, _ := strings.Cut(line[1:], ";")
+	frame, err := hex.DecodeString(body)
+	if err != nil || len(frame) != uplinkBytes {
+		return nil, false
+	}
+	return frame, true
+}
+
+// decodeUplink splits an uplink frame into its header and info frames
+func decodeUplink(frame []byte) (*Uplink, error) {
+	if len(frame) != uplinkBytes {
+		return nil, errors.New("uplink frame is the wrong size")
+	}
+
+	// Position is 23 and 24 bits of a full circle, packed across the
+	// first six bytes
+	lat := uint32(frame[0])<<15 | uint32(frame[1])<<7 | uint32(frame[2])>>1
+	lon := uint32(frame[2]&1)<<23 | uint32(frame[3])<<15 | uint32(frame[4])<<7 | uint32(frame[5])>>1
+	u := &Uplink{
+		Lat:           float64(lat) * 360 / (1 << 24),
+		Lon:           float64(lon) * 360 / (1 << 24),
+		PositionValid: frame[5]&1 != 0,
+	}
+	if u.Lat > 90 {
+		u.Lat -= 180
+	}
+	if u.Lon > 180 {
+		u.Lon -= 360
+	}
+	if frame[6]&0x20 == 0 {
+		return u, nil // No application data in this one
+	}
+
+	// Each info frame is a 9 bit length, 3 reserved bits and a 4 bit
+	// type, then the data
+	data := frame[uplinkHeader:]
+	for len(data) >= 2 {
+		n := int(data[0])<<1 | int(data[1])>>7
+		typ := int(data[1] & 0x0f)
+		if n == 0 || len(data) < n+2 {
+			break // Zero length marks the end of the frames
+		}
+		u.Frames = append(u.Frames, InfoFrame{Type: typ, Data: data[2 : n+2]})
+		data = data[n+2:]
+	}
+	return u, nil
+}
+
+// Product is a FIS-B product from an info frame
+type Product struct {
+	ID        int
+	Segmented bool // Split across frames; we only read whole products
+	Data      []byte
+}
+
+// fisb decodes the FIS-B product header on an info frame. ok is false if
+// it isn't FIS-B.
+func (f InfoFrame) fisb() (p Product, ok bool) {
+	if f.Type != 0 || len(f.Data) < 4 {
+		return p, false
+	}
+	p.ID = int(f.Data[0]&0x1f)<<6 | int(f.Data[1])>>2
+	p.Segmented = f.Data[1]&0x02 != 0
+
+	// The time option decides how long the header is: hours and minutes,
+	// plus seconds and/or month and day
+	header := 4
+	switch int(f.Data[1]&0x01)<<1 | int(f.Data[2])>>7 {
+	case 1, 2:
+		header = 5
+	case 3:
+		header = 6
+	}
+	if len(f.Data) < header {
+		return p, false
+	}
+	p.Data = f.Data[header:]
+	return p, true
+}
@@ -0,0 +1,147 @@
+package uat
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+)
+
+// uplink builds an uplink frame from a ground station at lat, lon
+// carrying frames, the way decodeUplink unpacks one
+func uplink(lat, lon float64, valid bool, frames ...InfoFrame) []byte {
+	frame := make([]byte, uplinkBytes)
+	la := uint32(math.Round(math.Mod(lat+360, 360)*(1<<24)/360)) & 0x7fffff
+	lo := uint32(math.Round(math.Mod(lon+360, 360)*(1<<24)/360)) & 0xffffff
+	frame[0], frame[1], frame[2] = byte(la>>15), byte(la>>7), byte(la<<1)|byte(lo>>23)
+	frame[3], frame[4], frame[5] = byte(lo>>15), byte(lo>>7), byte(lo<<1)
+	if valid {
+		frame[5] |= 1
+	}
+	if len(frames) > 0 {
+		frame[6] |= 0x20
+	}
+	at := uplinkHeader
+	for _, f := range frames {
+		n := len(f.Data)
+		frame[at], frame[at+1] = byte(n>>1), byte(n&1)<<7|byte(f.Type)
+		at += 2 + copy(frame[at+2:], f.Data)
+	}
+	return frame
+}
+
+// fisbFrame builds a FIS-B info frame holding product id, with the
+// shortest time option (hours and minutes)
+func fisbFrame(id int, data []byte) InfoFrame {
+	header := []byte{byte(id >> 6), byte(id&0x3f) << 2, 0, 0}
+	return InfoFrame{Type: 0, Data: append(header, data...)}
+}
+
+func TestParseRaw(t *testing.T) {
+	frame := hex.EncodeToString(uplink(40, -73, true))
+	tests := []struct {
+		name string
+		line string
+		ok   bool
+	}{
+		{name: "uplink", line: "+" + frame + ";rs=1;ss=200;", ok: true},
+		{name: "uplink without the trailer", line: "+" + frame, ok: true},
+		{name: "downlink", line: "-" + frame[:36] + ";rs=0;"},
+		{name: "too short", line: "+" + frame[:100] + ";"},
+		{name: "not hex", line: "+" + strings.Repeat("zz", uplinkBytes) + ";"},
+		{name: "empty", line: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRaw(tt.line)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && len(got) != uplinkBytes {
+				t.Errorf("frame is %d bytes, want %d", len(got), uplinkBytes)
+			}
+		})
+	}
+}
+
+func TestDecodeUplink(t *testing.T) {
+	// One step of the packed position
+	const step = 360.0 / (1 << 24)
+	metar := fisbFrame(textProduct, []byte("METAR"))
+	other := InfoFrame{Type: 14, Data: []byte{1, 2, 3}}
+
+	tests := []struct {
+		name     string
+		frame    []byte
+		lat, lon float64
+		valid    bool
+		frames   []InfoFrame
+	}{
+		{name: "north west", frame: uplink(40.6398, -73.7789, true), lat: 40.6398, lon: -73.7789, valid: true},
+		{name: "south east", frame: uplink(-33.9461, 151.1772, true), lat: -33.9461, lon: 151.1772, valid: true},
+		{name: "position not valid", frame: uplink(40.6398, -73.7789, false), lat: 40.6398, lon: -73.7789},
+		{
+			name:  "info frames",
+			frame: uplink(40.6398, -73.7789, true, metar, other),
+			lat:   40.6398, lon: -73.7789, valid: true,
+			frames: []InfoFrame{metar, other},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := decodeUplink(tt.frame)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if math.Abs(u.Lat-tt.lat) > step || math.Abs(u.Lon-tt.lon) > step || u.PositionValid != tt.valid {
+				t.Errorf("at %.4f, %.4f (valid %v), want %.4f, %.4f (valid %v)", u.Lat, u.Lon, u.PositionValid, tt.lat, tt.lon, tt.valid)
+			}
+			if len(u.Frames) != len(tt.frames) {
+				t.Fatalf("got %d info frames, want %d", len(u.Frames), len(tt.frames))
+			}
+			for i, f := range u.Frames {
+				if f.Type != tt.frames[i].Type || !bytes.Equal(f.Data, tt.frames[i].Data) {
+					t.Errorf("frame %d is %+v, want %+v", i, f, tt.frames[i])
+				}
+			}
+		})
+	}
+
+	if _, err := decodeUplink(make([]byte, 10)); err == nil {
+		t.Error("a short frame decoded")
+	}
+}
+
+func TestFISB(t *testing.T) {
+	tests := []struct {
+		name      string
+		frame     InfoFrame
+		ok        bool
+		id        int
+		segmented bool
+		data      []byte
+	}{
+		{name: "text", frame: fisbFrame(textProduct, []byte{1, 2}), ok: true, id: textProduct, data: []byte{1, 2}},
+		{name: "with seconds", frame: InfoFrame{Data: []byte{0x06, 0x74, 0x80, 0, 0, 9}}, ok: true, id: textProduct, data: []byte{9}},
+		{name: "with month and day too", frame: InfoFrame{Data: []byte{0x06, 0x75, 0x80, 0, 0, 0, 9}}, ok: true, id: textProduct, data: []byte{9}},
+		{name: "segmented", frame: InfoFrame{Data: []byte{0x06, 0x76, 0, 0, 9}}, ok: true, id: textProduct, segmented: true, data: []byte{9}},
+		{name: "not FIS-B", frame: InfoFrame{Type: 14, Data: []byte{0x06, 0x74, 0, 0}}},
+		{name: "too short", frame: InfoFrame{Data: []byte{0x06, 0x74}}},
+		{name: "too short for its time", frame: InfoFrame{Data: []byte{0x06, 0x75, 0x80, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := tt.frame.fisb()
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+			if !ok {
+				return
+			}
+			if p.ID != tt.id || p.Segmented != tt.segmented || !bytes.Equal(p.Data, tt.data) {
+				t.Errorf("got %+v, want ID %d, segmented %v, data %v", p, tt.id, tt.segmented, tt.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package uat
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// DefaultAddress is where dump978-fa serves its raw output
+const DefaultAddress = "localhost:30978"
+
+// maxReportAge is how long a report is kept without being broadcast again.
+// Ground stations repeat METARs every few minutes and NOTAMs less often.
+const maxReportAge = 2 * time.Hour
+
+// Weather holds the latest text report of each type for each station,
+// written by Run and read by the UI
+type Weather struct {
+	mu      sync.RWMutex
+	reports map[string]Report // Type and station -> latest
+	err     error             // Why the feed stopped, if it has
+}
+
+// NewWeather creates an empty weather store
+func NewWeather() *Weather {
+	return &Weather{reports: make(map[string]Report)}
+}
+
+// Run reads dump978 raw output from conn into the store until the
+// connection drops, then closes it and returns why
+func (w *Weather) Run(conn net.Conn) error {
+	defer conn.Close()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		frame, ok := parseRaw(scanner.Text())
+		if !ok {
+			continue
+		}
+		u, err := decodeUplink(frame)
+		if err != nil {
+			continue
+		}
+		now := time.Now()
+		for _, f := range u.Frames {
+			if p, ok := f.fisb(); ok && p.ID == textProduct && !p.Segmented {
+				w.add(textReports(p, now))
+			}
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil {
+		err = errors.New("connection closed")
+	}
+	w.mu.Lock()
+	w.err = err
+	w.mu.Unlock()
+	return err
+}
+
+// add stores reports, replacing older ones of the same type and station
+func (w *Weather) add(reports []Report) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, r := range reports {
+		w.reports[r.Type+" "+r.Station] = r
+	}
+}
+
+// Reports returns every report still current, by station then type
+func (w *Weather) Reports() []Report {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var out []Report
+	for key, r := range w.reports {
+		if time.Since(r.Received) > maxReportAge {
+			delete(w.reports, key)
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Station != out[j].Station {
+			return out[i].Station < out[j].Station
+		}
+		return out[i].Type < out[j].Type
+	})
+	return out
+}
+
+// Err returns why the feed stopped, or nil while it's running
+func (w *Weather) Err() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.err
+}
+
+// ConnectedMsg is sent when we've connected to dump978
+type ConnectedMsg struct {
+	Conn net.Conn
+}
+
+// ErrorMsg is sent when we couldn't connect to dump978
+type ErrorMsg struct {
+	Err error
+}
+
+// ConnectCmd connects to dump978's raw output at addr
+func ConnectCmd(addr string) tea.Cmd {
+	return func() tea.Msg {
+		conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return ConnectedMsg{Conn: conn}
+	}
+}
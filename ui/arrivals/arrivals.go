@@ -0,0 +1,130 @@
+package arrivals
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"termtrack/sequence"
+	"termtrack/ui/glyphs"
+)
+
+// Model is the panel of each approach's arrival sequence
+type Model struct {
+	width  int
+	height int
+	border lipgloss.Border
+
+	sequences []sequence.Sequence
+}
+
+// New creates a new arrivals panel
+func New() Model {
+	return Model{
+		width:  34,
+		height: 20,
+		border: glyphs.Unicode.Border,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetGlyphs switches the glyph set used for the panel frame
+func (m *Model) SetGlyphs(g glyphs.Set) {
+	m.border = g.Border
+}
+
+// SetSequences sets the arrivals to list, a sequence per approach
+func (m *Model) SetSequences(s []sequence.Sequence) {
+	m.sequences = s
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	style := lipgloss.NewStyle().
+		Border(m.border).
+		BorderForeground(lipgloss.Color("63")).
+		Width(m.width - 2).
+		Height(m.height - 2).
+		MaxHeight(m.height)
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+	runwayStyle := lipgloss.NewStyle().Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	innerWidth := m.width - 2
+	row := func(label, value string) string {
+		pad := innerWidth - lipgloss.Width(label) - lipgloss.Width(value)
+		if pad < 1 {
+			pad = 1
+		}
+		return label + strings.Repeat(" ", pad) + value
+	}
+
+	rows := []string{titleStyle.Render("Arrivals")}
+	if len(m.sequences) == 0 {
+		rows = append(rows, labelStyle.Width(innerWidth).Render(`No approaches in the config. Add runways under "approaches" to sequence the arrivals onto them.`))
+		return style.Render(strings.Join(rows, "\n"))
+	}
+
+	for _, s := range m.sequences {
+		rows = append(rows, "", runwayStyle.Render(runwayName(s.Runway)))
+		if len(s.Arrivals) == 0 {
+			rows = append(rows, labelStyle.Render("  Nothing inbound"))
+			continue
+		}
+		for i, a := range s.Arrivals {
+			rows = append(rows,
+				row(fmt.Sprintf("%d %s", i+1, name(a)), fmt.Sprintf("%.1fnm %s", a.Distance, eta(a.ETA))),
+				labelStyle.Render(row(fmt.Sprintf("  %d ft", a.Altitude), inTrail(i, a))),
+			)
+		}
+	}
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+// runwayName is what a sequence is headed with: the approach's name, or
+// its heading if it hasn't got one
+func runwayName(r sequence.Runway) string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return fmt.Sprintf("Runway heading %03.0f", r.Heading)
+}
+
+// name is how an arrival is listed: by callsign, or hex without one
+func name(a sequence.Arrival) string {
+	if a.Callsign != "" {
+		return a.Callsign
+	}
+	return a.ICAO
+}
+
+// eta is the time to the threshold as m:ss, blank if it's not known
+func eta(d time.Duration) string {
+	if d <= 0 {
+		return "    "
+	}
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// inTrail is how far behind the one ahead an arrival is
+func inTrail(i int, a sequence.Arrival) string {
+	if i == 0 {
+		return "first"
+	}
+	return fmt.Sprintf("%.1fnm in trail", a.InTrail)
+}
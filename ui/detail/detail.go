@@ -0,0 +1,366 @@
+package detail
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"termtrack/aircraftdb"
+	"termtrack/geo"
+	"termtrack/icao"
+	"termtrack/photo"
+	"termtrack/sbs"
+	"termtrack/ui/glyphs"
+)
+
+// metersPerFoot converts altitudes for the metric half of the display
+const metersPerFoot = 0.3048
+
+// maxEarlierCallsigns is how many past callsigns the panel lists
+const maxEarlierCallsigns = 4
+
+// Model is the panel showing everything we know about the selected aircraft
+type Model struct {
+	width    int
+	height   int
+	border   lipgloss.Border
+	flags    bool   // Country flags as emoji rather than ISO codes
+	blocks   bool   // Photos in half blocks, two pixels a cell
+	ellipsis string // Ends a line cut short to fit
+
+	ac     *sbs.Aircraft  // From the latest snapshot, nil if nothing is selected
+	at     time.Time      // The moment on screen, zero when live
+	target *Target        // Where to give an ETA to, nil for nowhere
+	db     *aircraftdb.DB // Registrations and types, nil for none
+
+	callsigns []sbs.CallsignUse // The selected aircraft's, across sessions
+
+	photo      *photo.Photo // Of whichever aircraft it says; shown only for that one
+	photoLines []string     // The photo drawn to fit the panel
+}
+
+// Target is a place to show the selected aircraft's distance and ETA to
+type Target struct {
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
+
+// Validate checks a target is somewhere on Earth
+func (t Target) Validate() error {
+	if t.Lat < -90 || t.Lat > 90 || t.Lon < -180 || t.Lon > 180 {
+		return fmt.Errorf("%g,%g isn't a lat,lon", t.Lat, t.Lon)
+	}
+	return nil
+}
+
+// New creates a new detail panel
+func New() Model {
+	return Model{
+		width:    34,
+		height:   20,
+		border:   glyphs.Unicode.Border,
+		flags:    glyphs.Unicode.Flags,
+		blocks:   glyphs.Unicode.HalfBlock,
+		ellipsis: glyphs.Unicode.Ellipsis,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetGlyphs switches the glyph set used for the panel frame
+func (m *Model) SetGlyphs(g glyphs.Set) {
+	m.border = g.Border
+	m.flags = g.Flags
+	m.blocks = g.HalfBlock
+	m.ellipsis = g.Ellipsis
+	m.renderPhoto()
+}
+
+// SetAircraft sets the aircraft to show (nil for none)
+func (m *Model) SetAircraft(ac *sbs.Aircraft) {
+	m.ac = ac
+}
+
+// SetCallsigns sets every callsign the selected aircraft has gone by,
+// oldest first. Without them the panel makes do with this session's.
+func (m *Model) SetCallsigns(uses []sbs.CallsignUse) {
+	m.callsigns = uses
+}
+
+// SetPhoto sets a photo to show when its aircraft is selected (nil for none)
+func (m *Model) SetPhoto(p *photo.Photo) {
+	m.photo = p
+	m.renderPhoto()
+}
+
+// renderPhoto redraws the photo for the panel's width. Drawing it is
+// slow next to the render tick, so it's only done when something changes.
+func (m *Model) renderPhoto() {
+	m.photoLines = nil
+	if m.photo != nil {
+		m.photoLines = m.photo.Render(m.width-2, m.blocks)
+	}
+}
+
+// SetAircraftDB sets the database registrations and types are looked up
+// in (nil for none, leaving registrations to what the address gives away)
+func (m *Model) SetAircraftDB(db *aircraftdb.DB) {
+	m.db = db
+}
+
+// SetTarget sets where to give the ETA to (nil for nowhere)
+func (m *Model) SetTarget(t *Target) {
+	m.target = t
+}
+
+// SetTime sets the moment the snapshot is from when replaying history.
+// The zero time means live.
+func (m *Model) SetTime(t time.Time) {
+	m.at = t
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		if msg.Width != m.width {
+			m.width = msg.Width
+			m.renderPhoto()
+		}
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	style := lipgloss.NewStyle().
+		Border(m.border).
+		BorderForeground(lipgloss.Color("63")).
+		Width(m.width - 2).
+		Height(m.height - 2).
+		MaxHeight(m.height)
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	if m.ac == nil {
+		return style.Render(labelStyle.Render("No aircraft selected.\nTab/Shift+Tab to select one."))
+	}
+
+	innerWidth := m.width - 2
+	row := func(label, value string) string {
+		pad := innerWidth - lipgloss.Width(label) - lipgloss.Width(value)
+		if pad < 1 {
+			pad = 1
+		}
+		return labelStyle.Render(label) + strings.Repeat(" ", pad) + value
+	}
+	orDash := func(ok bool, s string) string {
+		if !ok {
+			return "-"
+		}
+		return s
+	}
+
+	ac := m.ac
+	now := m.at
+	if now.IsZero() {
+		now = time.Now()
+	}
+	title := ac.Callsign
+	if title == "" {
+		title = ac.ICAO
+	}
+
+	rows := []string{
+		titleStyle.Render(title),
+		row("ICAO", m.address(ac)),
+		row("Callsign", m.callsign(ac.Callsign)),
+		row("Registration", m.registration(ac.ICAO)),
+		row("Type", m.aircraftType(ac.ICAO)),
+		row("Country", m.country(ac.ICAO, innerWidth-len("Country")-1)),
+		row("Position", orDash(ac.Lat != 0 || ac.Lon != 0, fmt.Sprintf("%.4f, %.4f", ac.Lat, ac.Lon))),
+		row("Source", orDash(ac.Lat != 0 || ac.Lon != 0, ac.Source.Label())),
+		row("Altitude", altitude(ac)),
+		row("Vertical rate", verticalRate(ac)),
+		row("Groundspeed", orDash(ac.Speed != 0, fmt.Sprintf("%.0f kt", ac.Speed))),
+		row("Track", orDash(ac.Speed != 0, fmt.Sprintf("%03.0f", ac.Track))),
+		row("Speed trend", orDash(ac.Speed != 0, fmt.Sprintf("%+.0f kt/min", ac.SpeedTrend))),
+		row("Signal", orDash(ac.Has&sbs.HasSignal != 0, fmt.Sprintf("%.1f dBFS", ac.Signal))),
+		row("Squawk", orDash(ac.Has&sbs.HasSquawk != 0, squawk(ac))),
+		row("Last seen", fmt.Sprintf("%.0fs ago", now.Sub(ac.LastSeen).Seconds())),
+		row("In view", ac.VisibleFor().Round(time.Second).String()),
+		row("Watched", fmt.Sprintf("%s all told", ac.Observed.Round(time.Second))),
+	}
+	if ac.Anomalies != 0 {
+		warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+		rows = append(rows, "", warnStyle.Render("Suspect data"), ac.Anomalies.String())
+	}
+
+	// Straight there along the great circle at the current groundspeed
+	if t := m.target; t != nil && (ac.Lat != 0 || ac.Lon != 0) {
+		dist := geo.Distance(ac.Lat, ac.Lon, t.Lat, t.Lon)
+		eta := "-"
+		if ac.Speed > 0 {
+			d := time.Duration(dist / ac.Speed * float64(time.Hour)).Round(time.Second)
+			eta = fmt.Sprintf("%s (%s)", d, now.Add(d).Format("15:04"))
+		}
+		rows = append(rows,
+			"",
+			titleStyle.Render("To "+t.Name),
+			row("Distance", fmt.Sprintf("%.1f nm", dist)),
+			row("Bearing", fmt.Sprintf("%03.0f", geo.Bearing(ac.Lat, ac.Lon, t.Lat, t.Lon))),
+			row("ETA", eta),
+		)
+	}
+
+	// Earlier legs under other flight numbers, latest first
+	uses := m.callsigns
+	if len(uses) == 0 {
+		uses = ac.Callsigns
+	}
+	if n := len(uses); n > 1 {
+		rows = append(rows, "", titleStyle.Render("Earlier callsigns"))
+		for i := n - 2; i >= 0 && i >= n-1-maxEarlierCallsigns; i-- {
+			rows = append(rows, row(m.callsign(uses[i].Callsign), when(uses[i].Last, now)))
+		}
+	}
+
+	rows = append(rows, "", titleStyle.Render("Heard by"))
+
+	heard := ac.HeardBy(now)
+	if len(heard) == 0 {
+		rows = append(rows, labelStyle.Render("No receiver in the last minute"))
+	}
+	for _, name := range heard {
+		rows = append(rows, row(name, fmt.Sprintf("%.0fs ago", now.Sub(ac.Receivers[name]).Seconds())))
+	}
+
+	// The photo goes at the bottom, if there's room for it and its credit
+	if m.photo != nil && m.photo.ICAO == ac.ICAO && len(m.photoLines) > 0 {
+		if room := m.height - 2 - len(rows); room >= len(m.photoLines)+2 {
+			rows = append(rows, "")
+			rows = append(rows, m.photoLines...)
+			rows = append(rows, labelStyle.Render(m.truncate("Photo: "+m.photo.Photographer, innerWidth)))
+		}
+	}
+
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+// when is a time short enough for a row: the time of day if it was today,
+// or the date if not
+func when(t, now time.Time) string {
+	if y, m, d := t.Date(); y == now.Year() && m == now.Month() && d == now.Day() {
+		return t.Format("15:04")
+	}
+	return t.Format("Jan 2")
+}
+
+// truncate cuts s to fit width cells, ending it with the ellipsis
+func (m Model) truncate(s string, width int) string {
+	if lipgloss.Width(s) <= width {
+		return s
+	}
+	return runewidth.Truncate(s, width, m.ellipsis)
+}
+
+// country is where the aircraft is registered, by its ICAO address: the
+// name and flag (or ISO code) if there's room, or just the flag or code
+func (m Model) country(hex string, room int) string {
+	c, ok := icao.CountryOf(hex)
+	if !ok {
+		return "-"
+	}
+	mark := c.Code
+	if m.flags {
+		mark = c.Flag()
+	}
+	if full := c.Name + " " + mark; lipgloss.Width(full) <= room {
+		return full
+	}
+	return mark
+}
+
+// address is the ICAO address, marked if it looks like an FAA privacy
+// address. That takes a database to say the address isn't registered.
+func (m Model) address(ac *sbs.Aircraft) string {
+	_, registered := m.db.Lookup(ac.ICAO)
+	if m.db.Len() > 0 && icao.LikelyPIA(ac.ICAO, ac.Callsign, registered) {
+		return ac.ICAO + " (PIA?)"
+	}
+	return ac.ICAO
+}
+
+// registration is the registration, from the database or where the
+// address gives it away
+func (m Model) registration(hex string) string {
+	if e, ok := m.db.Lookup(hex); ok && e.Registration != "" {
+		return e.Registration
+	}
+	if reg, ok := icao.Registration(hex); ok {
+		return reg
+	}
+	return "-"
+}
+
+// aircraftType is the type, if the database knows it
+func (m Model) aircraftType(hex string) string {
+	if e, ok := m.db.Lookup(hex); ok && e.Type != "" {
+		return e.Type
+	}
+	return "-"
+}
+
+// callsign is the callsign, or what's become of it
+func (m Model) callsign(cs string) string {
+	switch {
+	case cs == "":
+		return "-"
+	case icao.BlockedCallsign(cs):
+		return "blocked"
+	}
+	return cs
+}
+
+// altitude is the altitude row: feet and metres, or on the ground
+func altitude(ac *sbs.Aircraft) string {
+	switch {
+	case ac.OnGround:
+		return "On the ground"
+	case ac.Altitude == 0:
+		return "-"
+	}
+	return fmt.Sprintf("%d ft / %.0f m", ac.Altitude, float64(ac.Altitude)*metersPerFoot)
+}
+
+// verticalRate is the vertical rate row, signed so climbing's plain to see
+func verticalRate(ac *sbs.Aircraft) string {
+	switch {
+	case ac.Has&sbs.HasVertRate == 0 || ac.OnGround:
+		return "-"
+	case ac.VertRate == 0:
+		return "Level"
+	}
+	return fmt.Sprintf("%+d ft/min", ac.VertRate)
+}
+
+// squawk is the squawk row: the code, and whichever of its flags are up
+func squawk(ac *sbs.Aircraft) string {
+	s := ac.Squawk
+	if ac.Emergency {
+		s += " EMERGENCY"
+	}
+	if ac.Alert {
+		s += " changed"
+	}
+	if ac.Ident {
+		s += " IDENT"
+	}
+	return s
+}
@@ -0,0 +1,119 @@
+package fences
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"termtrack/geofence"
+	"termtrack/sbs"
+	"termtrack/ui/glyphs"
+)
+
+// maxListed is how many of the aircraft inside a fence are named; the
+// rest are just counted
+const maxListed = 5
+
+// Model is the panel of geofences and who's in them
+type Model struct {
+	width  int
+	height int
+	border lipgloss.Border
+
+	occupancy []geofence.Occupancy
+	aircraft  map[string]*sbs.Aircraft // For naming the ones inside
+}
+
+// New creates a new geofence panel
+func New() Model {
+	return Model{
+		width:  34,
+		height: 20,
+		border: glyphs.Unicode.Border,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetGlyphs switches the glyph set used for the panel frame
+func (m *Model) SetGlyphs(g glyphs.Set) {
+	m.border = g.Border
+}
+
+// SetOccupancy sets each fence's latest occupancy, and the aircraft the
+// ones inside are looked up in
+func (m *Model) SetOccupancy(occupancy []geofence.Occupancy, aircraft map[string]*sbs.Aircraft) {
+	m.occupancy, m.aircraft = occupancy, aircraft
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	style := lipgloss.NewStyle().
+		Border(m.border).
+		BorderForeground(lipgloss.Color("63")).
+		Width(m.width - 2).
+		Height(m.height - 2).
+		MaxHeight(m.height)
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	innerWidth := m.width - 2
+	row := func(label, value string) string {
+		pad := innerWidth - lipgloss.Width(label) - lipgloss.Width(value)
+		if pad < 1 {
+			pad = 1
+		}
+		return labelStyle.Render(label) + strings.Repeat(" ", pad) + value
+	}
+
+	rows := []string{titleStyle.Render("Geofences")}
+	if len(m.occupancy) == 0 {
+		rows = append(rows, labelStyle.Width(innerWidth).Render(`No geofences. Add areas to watch under "geofences" in the config file.`))
+		return style.Render(strings.Join(rows, "\n"))
+	}
+
+	for _, o := range m.occupancy {
+		rows = append(rows,
+			"",
+			titleStyle.Render(o.Name),
+			row("Inside now", fmt.Sprintf("%d (peak %d)", len(o.Inside), o.Peak)),
+			row("Entries", fmt.Sprint(o.Entries)),
+		)
+		for _, hex := range o.Inside[:min(len(o.Inside), maxListed)] {
+			rows = append(rows, row("  "+m.name(hex), m.altitude(hex)))
+		}
+		if more := len(o.Inside) - maxListed; more > 0 {
+			rows = append(rows, labelStyle.Render(fmt.Sprintf("  and %d more", more)))
+		}
+	}
+
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+// name is how an aircraft inside is listed: by callsign, or hex without
+func (m Model) name(hex string) string {
+	if ac, ok := m.aircraft[hex]; ok && ac.Callsign != "" {
+		return ac.Callsign
+	}
+	return hex
+}
+
+// altitude is an aircraft's altitude, if it's known
+func (m Model) altitude(hex string) string {
+	if ac, ok := m.aircraft[hex]; ok && ac.Has&sbs.HasAltitude != 0 {
+		return fmt.Sprintf("%d ft", ac.Altitude)
+	}
+	return "-"
+}
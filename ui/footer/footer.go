@@ -2,6 +2,7 @@ package footer
 
 import (
     "fmt"
+    "strings"
 
     tea "github.com/charmbracelet/bubbletea"
     "github.com/charmbracelet/lipgloss"
@@ -12,6 +13,17 @@ type Model struct {
     width        int
     mapShapePath string
     zoomLevel    float64
+
+    // Crosshair position, when crosshair mode is on
+    cursorOn   bool
+    cursorLat  float64
+    cursorLon  float64
+    cursorInfo []string // What else there is to say about where it is
+
+    // Aircraft squawking emergency codes, each like "DAL88 7700 emergency"
+    emergencies []string
+
+    help string // The few keys there's room for
 }
 
 // New creates a new footer model
@@ -32,6 +44,42 @@ func (m *Model) SetZoom(z float64) {
     m.zoomLevel = z
 }
 
+// SetCursor allows the parent model to update the crosshair position
+func (m *Model) SetCursor(lat, lon float64, ok bool) {
+    m.cursorLat, m.cursorLon, m.cursorOn = lat, lon, ok
+}
+
+// SetCursorInfo sets what the info line says after the crosshair's
+// position: how far it is from the receiver, what's near it and so on
+func (m *Model) SetCursorInfo(info []string) {
+    m.cursorInfo = info
+}
+
+// SetEmergencies sets the aircraft the alert line warns of, none to take
+// it down
+func (m *Model) SetEmergencies(emergencies []string) {
+    m.emergencies = emergencies
+}
+
+// SetHelp sets the keys listed on the right, like "Zoom: K/L | Quit: q",
+// most important last
+func (m *Model) SetHelp(help string) {
+    m.help = help
+}
+
+// Height is how many lines the footer takes: one, and one more each for
+// the emergency alert and the crosshair's info line while they're up
+func (m Model) Height() int {
+    height := 1
+    if len(m.emergencies) > 0 {
+        height++
+    }
+    if m.cursorOn {
+        height++
+    }
+    return height
+}
+
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
     switch msg := msg.(type) {
     case tea.WindowSizeMsg:
@@ -46,17 +94,53 @@ func (m Model) View() string {
         Padding(0, 1)
 
     // Calculate zoom level
-    footerLeft := footerStyle.Render(fmt.Sprintf(
-        "TermTrack | Map: %s | Zoom: %.1fx",
-        m.mapShapePath, m.zoomLevel,
-    ))
+    left := fmt.Sprintf("TermTrack | Map: %s | Zoom: %.1fx", m.mapShapePath, m.zoomLevel)
+    footerLeft := footerStyle.Render(left)
 
-    footerHelp := "Pan: j/k/l/; | Zoom: K/L | Reset: r | Quit: q"
+    footerHelp := m.help
 
-    // Use the component's width
-    footerRight := footerStyle.Width(m.width - lipgloss.Width(footerLeft) - 1).
+    // Use the component's width, dropping keys off the front of the help
+    // rather than wrapping, so the last few (quit and help) stay put
+    rightWidth := m.width - lipgloss.Width(footerLeft) - 1
+    avail := max(rightWidth-2, 0)
+    for len(footerHelp) > avail {
+        if _, rest, ok := strings.Cut(footerHelp, " | "); ok {
+            footerHelp = rest
+        } else {
+            footerHelp = footerHelp[:avail]
+        }
+    }
+    footerRight := footerStyle.Width(rightWidth).
         Align(lipgloss.Right).
         Render(footerHelp)
 
-    return lipgloss.JoinHorizontal(lipgloss.Left, footerLeft, footerRight)
-}
\ No newline at end of file
+    footer := lipgloss.JoinHorizontal(lipgloss.Left, footerLeft, footerRight)
+
+    // The alert and info lines go above, cut short like the help
+    var lines []string
+    if len(m.emergencies) > 0 {
+        alertStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true).Inline(true).MaxWidth(m.width)
+        lines = append(lines, alertStyle.Render(" EMERGENCY: "+strings.Join(m.emergencies, " | ")))
+    }
+    if m.cursorOn {
+        info := "Cursor: " + strings.Join(append([]string{formatLatLon(m.cursorLat, m.cursorLon)}, m.cursorInfo...), " | ")
+        infoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250")).Inline(true).MaxWidth(m.width)
+        lines = append(lines, infoStyle.Render(" "+info))
+    }
+    if len(lines) == 0 {
+        return footer
+    }
+    return lipgloss.JoinVertical(lipgloss.Left, append(lines, footer)...)
+}
+
+// formatLatLon renders a position like "40.6413N 73.7781W"
+func formatLatLon(lat, lon float64) string {
+    ns, ew := "N", "E"
+    if lat < 0 {
+        ns, lat = "S", -lat
+    }
+    if lon < 0 {
+        ew, lon = "W", -lon
+    }
+    return fmt.Sprintf("%.4f%s %.4f%s", lat, ns, lon, ew)
+}
@@ -0,0 +1,120 @@
+package glyphs
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Set is the collection of glyphs the UI draws with
+type Set struct {
+	Plane     string
+	Balloon   string // Lighter than air, by emitter category
+	Glider    string
+	Airport   string
+	MapPoint  string
+	Crosshair string
+	Trail     string
+	Ring      string // Round positions that might be off
+	Climb     string // Beside the altitude in labels
+	Descend   string
+	BarFull   string // Progress bars
+	BarEmpty  string
+	Ellipsis  string    // Where text's cut short to fit
+	Braille   bool      // Whether braille dots can stand in for the glyphs
+	Vectors   [4]string // Leader lines running | / - \
+	Flags     bool      // Whether to show country flags as emoji
+	HalfBlock bool      // Whether ▀ can split a cell into two pixels for photos
+	Border    lipgloss.Border
+}
+
+// Unicode is the default set for terminals that can draw it
+var Unicode = Set{
+	Plane:     "✈",
+	Balloon:   "○",
+	Glider:    "△",
+	Airport:   "*",
+	MapPoint:  ".",
+	Crosshair: "╋",
+	Trail:     "·",
+	Ring:      "∘",
+	Climb:     "↑",
+	Descend:   "↓",
+	BarFull:   "█",
+	BarEmpty:  "░",
+	Ellipsis:  "…",
+	Braille:   true,
+	Flags:     true,
+	HalfBlock: true,
+	Vectors:   [4]string{"│", "╱", "─", "╲"},
+	Border:    lipgloss.RoundedBorder(),
+}
+
+// ASCII is the fallback set for terminals without good Unicode support
+var ASCII = Set{
+	Plane:     "+",
+	Balloon:   "o",
+	Glider:    "^",
+	Airport:   "*",
+	MapPoint:  ".",
+	Crosshair: "X",
+	Trail:     ":",
+	Ring:      "~",
+	Climb:     "^",
+	Descend:   "v",
+	BarFull:   "#",
+	BarEmpty:  "-",
+	Ellipsis:  "...",
+	Braille:   false,
+	Flags:     false,
+	HalfBlock: false,
+	Vectors:   [4]string{"|", "/", "-", "\\"},
+	Border: lipgloss.Border{
+		Top:         "-",
+		Bottom:      "-",
+		Left:        "|",
+		Right:       "|",
+		TopLeft:     "+",
+		TopRight:    "+",
+		BottomLeft:  "+",
+		BottomRight: "+",
+	},
+}
+
+// Resolve picks a set from a mode of "auto", "ascii" or "unicode"
+func Resolve(mode string) Set {
+	switch mode {
+	case "ascii":
+		return ASCII
+	case "unicode":
+		return Unicode
+	}
+	if SupportsUnicode() {
+		return Unicode
+	}
+	return ASCII
+}
+
+// SupportsUnicode guesses whether the terminal can draw Unicode glyphs,
+// going by the locale and a few terminals known not to
+func SupportsUnicode() bool {
+	switch os.Getenv("TERM") {
+	case "linux", "vt100", "vt220", "dumb":
+		return false
+	}
+
+	// Windows Terminal doesn't set a locale, but handles Unicode fine
+	if os.Getenv("WT_SESSION") != "" {
+		return true
+	}
+
+	// The first locale variable that's set wins, same as libc
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			v = strings.ToLower(v)
+			return strings.Contains(v, "utf-8") || strings.Contains(v, "utf8")
+		}
+	}
+	return false
+}
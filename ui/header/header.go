@@ -7,8 +7,9 @@ import (
 
 // Model holds the header's state
 type Model struct {
-    width int
-    style lipgloss.Style
+    width  int
+    style  lipgloss.Style
+    status string // Shown after the title, e.g. when the feed is paused
 }
 
 // New creates a new header model
@@ -26,6 +27,11 @@ func (m Model) Init() tea.Cmd {
     return nil
 }
 
+// SetStatus allows the parent model to show a short note next to the title
+func (m *Model) SetStatus(s string) {
+    m.status = s
+}
+
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
     switch msg := msg.(type) {
     case tea.WindowSizeMsg:
@@ -35,6 +41,11 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+    title := "TermTrack"
+    if m.status != "" {
+        title += " | " + m.status
+    }
+
     // Render the title, forcing it to fill the width
-    return m.style.Width(m.width).Render("TermTrack")
+    return m.style.Width(m.width).MaxHeight(1).Render(title)
 }
\ No newline at end of file
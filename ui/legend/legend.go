@@ -0,0 +1,131 @@
+package legend
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"termtrack/ui/glyphs"
+	mapview "termtrack/ui/map"
+)
+
+// Model is the panel explaining the map's glyphs and colors, and every
+// key there's no room for in the footer
+type Model struct {
+	width  int
+	height int
+	border lipgloss.Border
+
+	entries []mapview.LegendEntry
+	theme   string // The theme the colors are shown in, "" for day
+	keys    []Key
+}
+
+// Key is a key and what it does, for the list under the legend
+type Key struct {
+	Key  string
+	Does string
+}
+
+// New creates a new legend panel listing keys
+func New(keys []Key) Model {
+	return Model{
+		width:  34,
+		height: 20,
+		border: glyphs.Unicode.Border,
+		keys:   keys,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetGlyphs switches the glyph set used for the panel frame
+func (m *Model) SetGlyphs(g glyphs.Set) {
+	m.border = g.Border
+}
+
+// SetEntries sets what the legend explains, from the map as it's drawn
+func (m *Model) SetEntries(entries []mapview.LegendEntry) {
+	m.entries = entries
+}
+
+// SetTheme says which theme the colors are in, so the legend can say the
+// map's colors have been changed from the usual ones. "" or "day" for none.
+func (m *Model) SetTheme(name string) {
+	m.theme = name
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	style := lipgloss.NewStyle().
+		Border(m.border).
+		BorderForeground(lipgloss.Color("63")).
+		Width(m.width - 2).
+		Height(m.height - 2).
+		MaxHeight(m.height)
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	// Symbols line up in a column as wide as the widest, with the text
+	// wrapped beside them
+	innerWidth := m.width - 2
+	symbolWidth := 1
+	for _, e := range m.entries {
+		symbolWidth = max(symbolWidth, lipgloss.Width(e.Symbol))
+	}
+	textStyle := labelStyle.Width(max(innerWidth-symbolWidth-2, 1))
+
+	rows := []string{titleStyle.Render("Legend")}
+	for _, e := range m.entries {
+		symbol := e.Symbol + strings.Repeat(" ", symbolWidth-lipgloss.Width(e.Symbol)+2)
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, symbol, textStyle.Render(e.Text)))
+	}
+	if m.theme != "" && m.theme != "day" {
+		rows = append(rows, "", labelStyle.Width(innerWidth).Render("Colors as the "+m.theme+" theme shows them (N to change)"))
+	}
+	if len(m.keys) > 0 {
+		rows = append(rows, "", titleStyle.Render("Keys"))
+		rows = append(rows, m.keyRows(innerWidth, labelStyle)...)
+	}
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+// keyRows lays the keys out in two columns, down the first and then the
+// second, each key lined up before what it does
+func (m Model) keyRows(width int, labelStyle lipgloss.Style) []string {
+	half := (len(m.keys) + 1) / 2
+	left := keyColumn(m.keys[:half], width/2, labelStyle)
+	right := keyColumn(m.keys[half:], width-width/2, labelStyle)
+	for i := range right {
+		left[i] += right[i]
+	}
+	return left
+}
+
+// keyColumn is one column of keys, as wide as width
+func keyColumn(keys []Key, width int, labelStyle lipgloss.Style) []string {
+	keyWidth := 1
+	for _, k := range keys {
+		keyWidth = max(keyWidth, lipgloss.Width(k.Key))
+	}
+	// Cut short rather than wrapped, with a space before the next column
+	cut := lipgloss.NewStyle().Inline(true).MaxWidth(width - 1)
+	rows := make([]string, len(keys))
+	for i, k := range keys {
+		key := k.Key + strings.Repeat(" ", keyWidth-lipgloss.Width(k.Key)+1)
+		rows[i] = cut.Render(key + labelStyle.Render(k.Does))
+		rows[i] += strings.Repeat(" ", width-lipgloss.Width(rows[i]))
+	}
+	return rows
+}
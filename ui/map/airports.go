@@ -7,21 +7,16 @@ import (
 )
 
 // loadAirportData reads the airport shapefile and returns a slice of points.
-func loadAirportData(path string) ([]*shp.Point, error) {
-	shapeFile, err := shp.Open(path)
+func loadAirportData(path string, progress *Progress) ([]*shp.Point, error) {
+	recs, err := readRecords(path, progress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open airport shapefile: %w", err)
+		return nil, err
 	}
-	defer shapeFile.Close()
 
-	var points []*shp.Point
-	for shapeFile.Next() {
-		_, shape := shapeFile.Shape()
-		point, ok := shape.(*shp.Point)
-		if !ok {
-			continue // Skip if it's not a point (e.g., polygon, polyline)
-		}
-		points = append(points, point)
+	// Anything that isn't a point (e.g., polygon, polyline) is skipped
+	points, err := parseAll(recs, progress, parsePoint)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
 
 	if len(points) == 0 {
@@ -30,4 +25,4 @@ func loadAirportData(path string) ([]*shp.Point, error) {
 	}
 
 	return points, nil
-}
\ No newline at end of file
+}
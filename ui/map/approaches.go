@@ -0,0 +1,113 @@
+package mapview
+
+import (
+	"fmt"
+	"math"
+
+	"termtrack/geo"
+)
+
+// Approach is a runway to draw an extended centerline off, out along the
+// way aircraft come in to land on it
+type Approach struct {
+	Name    string  `json:"name,omitempty"` // Drawn at the far end, e.g. "JFK 31L"
+	Lat     float64 `json:"lat"`            // The runway threshold
+	Lon     float64 `json:"lon"`
+	Heading float64 `json:"heading"`          // Landing direction in degrees
+	Length  float64 `json:"length,omitempty"` // Nautical miles; defaults to defaultApproachLength
+}
+
+// defaultApproachLength is how far out a centerline goes when the config
+// doesn't say
+const defaultApproachLength = 15.0
+
+// approachTickEvery is the distance between ticks along a centerline, and
+// approachTickWidth how far each sticks out either side, in nautical miles
+const (
+	approachTickEvery = 5.0
+	approachTickWidth = 0.5
+)
+
+// Validate checks an approach is somewhere on Earth and points somewhere
+func (a Approach) Validate() error {
+	if a.Lat < -90 || a.Lat > 90 || a.Lon < -180 || a.Lon > 180 {
+		return fmt.Errorf("threshold %g,%g isn't a lat,lon", a.Lat, a.Lon)
+	}
+	if a.Heading < 0 || a.Heading > 360 {
+		return fmt.Errorf("heading %g isn't 0-360", a.Heading)
+	}
+	if a.Length < 0 {
+		return fmt.Errorf("length %g is negative", a.Length)
+	}
+	return nil
+}
+
+// SetApproaches sets the runways to draw approach centerlines for
+func (m *Model) SetApproaches(a []Approach) {
+	m.approaches = a
+	m.contentVersion++
+}
+
+// length is how many nautical miles out the centerline goes
+func (a Approach) length() float64 {
+	if a.Length == 0 {
+		return defaultApproachLength
+	}
+	return a.Length
+}
+
+// drawApproaches draws each approach's extended centerline with a tick
+// every approachTickEvery miles. Their names are labels, placed along with
+// the rest by addApproachLabels.
+func (m *Model) drawApproaches(g *grid, viewWidth, viewHeight int) {
+	style := g.style(layerStyle(m.layers.Approaches))
+	for _, a := range m.approaches {
+		length := a.length()
+		out := math.Mod(a.Heading+180, 360) // Aircraft on approach are behind the runway
+
+		// Everything is worked out from the threshold on the copy of the
+		// world nearest the view
+		lon := m.nearView(a.Lon)
+		at := func(lat, lon, bearing, dist float64) (float64, float64) {
+			pLat, pLon := geo.Destination(lat, lon, bearing, dist)
+			return m.projectF(unwrapFrom(lon, pLon), pLat, viewWidth, viewHeight)
+		}
+		// The glyph follows the exact angle, which short ticks lose once
+		// they're rounded to cells
+		segment := func(x0, y0, x1, y1 float64) {
+			glyph := layerGlyph(m.layers.Approaches, m.vectorGlyph(x1-x0, y1-y0))
+			line(int(x0), int(y0), int(x1), int(y1), func(x, y int) {
+				setCell(g, x, y, glyph, style)
+			})
+		}
+
+		x0, y0 := m.projectF(lon, a.Lat, viewWidth, viewHeight)
+		x1, y1 := at(a.Lat, lon, out, length)
+		segment(x0, y0, x1, y1)
+
+		for d := approachTickEvery; d <= length; d += approachTickEvery {
+			tLat, tLon := geo.Destination(a.Lat, lon, out, d)
+			tLon = unwrapFrom(lon, tLon)
+			lx, ly := at(tLat, tLon, out-90, approachTickWidth)
+			rx, ry := at(tLat, tLon, out+90, approachTickWidth)
+			segment(lx, ly, rx, ry)
+		}
+	}
+}
+
+// addApproachLabels asks for each approach's name at the far end of its
+// centerline
+func (m *Model) addApproachLabels(lm *labelManager, style styleID, viewWidth, viewHeight int) {
+	for i, a := range m.approaches {
+		if a.Name == "" {
+			continue
+		}
+		lon := m.nearView(a.Lon)
+		lat, endLon := geo.Destination(a.Lat, lon, math.Mod(a.Heading+180, 360), a.length())
+		x, y := m.project(unwrapFrom(lon, endLon), lat, viewWidth, viewHeight)
+		if x < 0 || y < 0 || x >= viewWidth || y >= viewHeight {
+			continue
+		}
+		lm.add(labelRequest{priority: priorityApproach, rank: i, key: a.Name, x: x, y: y, forms: []string{a.Name}, style: style})
+	}
+}
@@ -0,0 +1,94 @@
+package mapview
+
+import (
+	"math"
+)
+
+// brailleBase is the empty braille pattern; each of the 8 dots adds a bit
+const brailleBase = 0x2800
+
+// brailleBits is the bit for each dot, by its column and row in the cell
+var brailleBits = [2][4]rune{
+	{0x01, 0x02, 0x04, 0x40},
+	{0x08, 0x10, 0x20, 0x80},
+}
+
+// brailleCell is one terminal cell's worth of dots
+type brailleCell struct {
+	bits  rune
+	style styleID // Whoever drew last; a cell only has one color
+}
+
+// brailleCanvas is a grid of dots, two across and four down per cell, so
+// things can move in quarter-cell steps instead of jumping cell to cell
+type brailleCanvas struct {
+	w, h  int // In cells
+	cells [][]brailleCell
+}
+
+// newBrailleCanvas creates an empty canvas w cells by h cells
+func newBrailleCanvas(w, h int) *brailleCanvas {
+	cells := make([][]brailleCell, h)
+	for i := range cells {
+		cells[i] = make([]brailleCell, w)
+	}
+	return &brailleCanvas{w: w, h: h, cells: cells}
+}
+
+// dot sets the dot at x, y (in dots, not cells) and gives its cell style
+func (c *brailleCanvas) dot(x, y int, style styleID) {
+	if x < 0 || y < 0 || x >= c.w*2 || y >= c.h*4 {
+		return
+	}
+	cell := &c.cells[y/4][x/2]
+	cell.bits |= brailleBits[x%2][y%4]
+	cell.style = style
+}
+
+// draw writes every cell with dots in it onto the grid
+func (c *brailleCanvas) draw(g *grid) {
+	for y, row := range c.cells {
+		for x, cell := range row {
+			if cell.bits != 0 {
+				setCell(g, x, y, string(brailleBase+cell.bits), cell.style)
+			}
+		}
+	}
+}
+
+// toDots converts a position in cells (from projectF) to the nearest dot
+func toDots(x, y float64) (int, int) {
+	return int(math.Floor(x * 2)), int(math.Floor(y * 4))
+}
+
+// plotTrails draws every trail as a line of dots, in g's styles
+func (m *Model) plotTrails(c *brailleCanvas, g *grid, viewWidth, viewHeight int) {
+	m.eachTrailSegment(g, func(lon0, lat0, lon1, lat1 float64, style styleID) {
+		x0, y0 := toDots(m.projectF(lon0, lat0, viewWidth, viewHeight))
+		x1, y1 := toDots(m.projectF(lon1, lat1, viewWidth, viewHeight))
+		line(x0, y0, x1, y1, func(x, y int) {
+			c.dot(x, y, style)
+		})
+	})
+}
+
+// plotPlane draws a plane as a 2x2 block of dots centered on its position.
+// It returns the cell to hang its label off: the one under the block's
+// left column and bottom row, so a label below won't cover it.
+func (m *Model) plotPlane(c *brailleCanvas, lon, lat float64, style styleID, viewWidth, viewHeight int) (x, y int, ok bool) {
+	fx, fy := m.projectF(lon, lat, viewWidth, viewHeight)
+	dx, dy := toDots(fx-0.25, fy-0.125) // Half a dot up and left, to center the block
+	if dx+1 < 0 || dy+1 < 0 || dx >= c.w*2 || dy >= c.h*4 {
+		return 0, 0, false
+	}
+	for _, d := range [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}} {
+		c.dot(dx+d[0], dy+d[1], style)
+	}
+	return clamp(dx/2, 0, c.w-1), clamp((dy+1)/4, 0, c.h-1), true
+}
+
+// toggleBraille switches trails and planes between glyphs and braille dots,
+// if the glyph set can draw braille
+func (m *Model) toggleBraille() {
+	m.braille = !m.braille && m.glyphs.Braille
+}
@@ -0,0 +1,96 @@
+package mapview
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"termtrack/geo"
+	"termtrack/sbs"
+)
+
+// castLength is the longest a cast plays for: a flight is sped up to fit,
+// since nobody watches an hour of one plane crawling across a map
+const castLength = 30 * time.Second
+
+// castHold is how long the last frame stays up before the cast ends
+const castHold = 3 * time.Second
+
+// castHeader is the first line of an asciinema (v2) cast
+type castHeader struct {
+	Version   int    `json:"version"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	Title     string `json:"title,omitempty"`
+}
+
+// WriteCast writes an asciinema cast of an aircraft flying its trail,
+// drawn as the map is now (glyphs, layers, trails) but fitted to the trail
+// and with only that aircraft on it. asciinema can play it, and tools like
+// agg turn it into a GIF.
+func (m Model) WriteCast(w io.Writer, ac *sbs.Aircraft) error {
+	if len(ac.Trail) < 2 {
+		return errors.New("no trail to play")
+	}
+	// A copy of the map of our own, so the live one's view and cache are
+	// left alone
+	m.cache = &staticCache{}
+	m.heliMode, m.crosshair = false, false
+	m.aircraft = map[string]*sbs.Aircraft{ac.ICAO: ac}
+	m.selected = ac.ICAO
+	m.fitTrail(ac.ICAO)
+
+	trail := ac.Trail
+	start := trail[0].At
+	scale := 1.0
+	if d := trail[len(trail)-1].At.Sub(start); d > castLength {
+		scale = float64(castLength) / float64(d)
+	}
+
+	title := ac.ICAO
+	if ac.Callsign != "" {
+		title = ac.Callsign
+	}
+	enc := json.NewEncoder(w)
+	var at float64
+	for i, p := range trail {
+		frame := *ac
+		frame.Trail = trail[:i+1]
+		frame.Lat, frame.Lon, frame.LastSeen = p.Lat, p.Lon, p.At
+		if i > 0 {
+			// Point it along the trail, and leave out the leader line,
+			// which would run from what the speed was at the end
+			prev := trail[i-1]
+			frame.Track = geo.Bearing(prev.Lat, prev.Lon, p.Lat, p.Lon)
+			frame.SmoothTrack = frame.Track
+		}
+		frame.Speed, frame.SmoothSpeed = 0, 0
+		m.aircraft[ac.ICAO] = &frame
+		m.at = p.At
+
+		view := m.View()
+		if i == 0 {
+			if err := enc.Encode(castHeader{
+				Version:   2,
+				Width:     lipgloss.Width(view),
+				Height:    lipgloss.Height(view),
+				Timestamp: time.Now().Unix(),
+				Title:     fmt.Sprintf("%s, %s", title, start.Format("2006-01-02 15:04")),
+			}); err != nil {
+				return err
+			}
+			view = "\x1b[2J" + view
+		}
+		at = p.At.Sub(start).Seconds() * scale
+		if err := enc.Encode([]any{at, "o", "\x1b[H" + strings.ReplaceAll(view, "\n", "\r\n")}); err != nil {
+			return err
+		}
+	}
+	return enc.Encode([]any{at + castHold.Seconds(), "o", ""})
+}
@@ -0,0 +1,95 @@
+package mapview
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+
+	"termtrack/sbs"
+)
+
+// CategoryGlyphs maps aircraft categories to the glyph to draw them with.
+// Keys are ADS-B emitter categories ("A7") or their names ("helicopter").
+type CategoryGlyphs map[string]string
+
+// categoryNames are the friendlier names for the emitter categories
+var categoryNames = map[string]string{
+	"light":            "A1",
+	"small":            "A2",
+	"large":            "A3",
+	"high-vortex":      "A4",
+	"heavy":            "A5",
+	"high-performance": "A6", // Fighters and the like
+	"fighter":          "A6",
+	"rotorcraft":       "A7",
+	"helicopter":       "A7",
+	"glider":           "B1",
+	"balloon":          "B2", // Lighter than air
+	"parachutist":      "B3",
+	"ultralight":       "B4",
+	"uav":              "B6",
+	"drone":            "B6",
+	"spacecraft":       "B7",
+	"emergency":        "C1", // Surface vehicles
+	"service":          "C2",
+	"obstacle":         "C3",
+}
+
+// categoryPattern is an emitter category code
+var categoryPattern = regexp.MustCompile(`^[A-D][0-7]$`)
+
+// categoryCode turns a key into its emitter category code, or "" if it
+// isn't one we know
+func categoryCode(key string) string {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if code, ok := categoryNames[key]; ok {
+		return code
+	}
+	if code := strings.ToUpper(key); categoryPattern.MatchString(code) {
+		return code
+	}
+	return ""
+}
+
+// Validate checks every category is one we know and every glyph is one
+// cell wide, like the plane glyph it replaces
+func (c CategoryGlyphs) Validate() error {
+	for key, glyph := range c {
+		if categoryCode(key) == "" {
+			return fmt.Errorf("%q isn't an emitter category (A0-D7) or a category name", key)
+		}
+		if runewidth.StringWidth(glyph) != 1 {
+			return fmt.Errorf("%s: glyph %q isn't one cell wide", key, glyph)
+		}
+	}
+	return nil
+}
+
+// SetCategoryGlyphs sets the glyphs for aircraft by category. Aircraft
+// whose category isn't in it, or isn't known, keep the glyph set's plane.
+func (m *Model) SetCategoryGlyphs(c CategoryGlyphs) {
+	m.categoryGlyphs = make(map[string]string, len(c))
+	for key, glyph := range c {
+		if code := categoryCode(key); code != "" {
+			m.categoryGlyphs[code] = glyph
+		}
+	}
+}
+
+// planeGlyph is the glyph to draw an aircraft with: the configured one
+// for its category, else the glyph set's for balloons and gliders, which
+// don't look anything like planes on the move
+func (m *Model) planeGlyph(ac *sbs.Aircraft) string {
+	if glyph, ok := m.categoryGlyphs[ac.Category]; ok {
+		return glyph
+	}
+	switch ac.Category {
+	case categoryNames["balloon"]:
+		return m.glyphs.Balloon
+	case categoryNames["glider"]:
+		return m.glyphs.Glider
+	}
+	return m.glyphs.Plane
+}
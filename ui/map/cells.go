@@ -0,0 +1,195 @@
+package mapview
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// Each grid cell holds one terminal column. A glyph two columns wide lives
+// in its left cell, and the cell to its right holds the empty string so the
+// row still joins up to exactly the view width.
+const wideContinuation = ""
+
+// styleID is a style's place in a grid's style table. 0 is unstyled.
+type styleID uint16
+
+// cell is one terminal column of the map: what's in it and how it's drawn
+type cell struct {
+	text  string
+	style styleID
+}
+
+// blank is an empty cell
+var blank = cell{text: " "}
+
+// styleCodes are the escape sequences a style wraps text in
+type styleCodes struct {
+	open, close string
+}
+
+// styleTable hands out an id per distinct style, so cells can carry a
+// small number and the escape sequences are only worked out once
+type styleTable struct {
+	ids   map[string]styleID // By opening sequence
+	codes []styleCodes       // By id
+}
+
+// grid is a frame of the map, cell by cell. Nothing's rendered until
+// String, which only writes escape sequences where the style changes.
+type grid struct {
+	rows   [][]cell
+	styles styleTable
+
+	// What's under this grid's layer, if it should keep out of its way:
+	// cells taken there aren't free here
+	under *grid
+}
+
+// newGrid creates a blank w by h grid
+func newGrid(w, h int) *grid {
+	rows := make([][]cell, h)
+	for y := range rows {
+		rows[y] = make([]cell, w)
+		for x := range rows[y] {
+			rows[y][x] = blank
+		}
+	}
+	return &grid{
+		rows:   rows,
+		styles: styleTable{ids: map[string]styleID{"": 0}, codes: []styleCodes{{}}},
+	}
+}
+
+// clone copies the grid, so a cached one can be drawn over
+func (g *grid) clone() *grid {
+	rows := make([][]cell, len(g.rows))
+	for y, row := range g.rows {
+		rows[y] = slices.Clone(row)
+	}
+	return &grid{
+		rows:   rows,
+		styles: styleTable{ids: maps.Clone(g.styles.ids), codes: slices.Clone(g.styles.codes)},
+	}
+}
+
+// style returns the id for a style, adding it to the table if it's new.
+// Rendering a style to find its codes isn't cheap, so look each up once
+// per layer or aircraft rather than per cell.
+func (g *grid) style(s lipgloss.Style) styleID {
+	open, close, _ := strings.Cut(s.Render("x"), "x")
+	return g.addCodes(styleCodes{open: open, close: close})
+}
+
+// addCodes returns the id for a style's codes, adding them if they're new
+func (g *grid) addCodes(codes styleCodes) styleID {
+	if id, ok := g.styles.ids[codes.open]; ok {
+		return id
+	}
+	id := styleID(len(g.styles.codes))
+	g.styles.ids[codes.open] = id
+	g.styles.codes = append(g.styles.codes, codes)
+	return id
+}
+
+// String renders the grid a row per line
+func (g *grid) String() string {
+	var b strings.Builder
+	for _, row := range g.rows {
+		current := styleID(0)
+		for _, c := range row {
+			if c.text == wideContinuation {
+				continue
+			}
+			if c.style != current {
+				b.WriteString(g.styles.codes[current].close)
+				b.WriteString(g.styles.codes[c.style].open)
+				current = c.style
+			}
+			b.WriteString(c.text)
+		}
+		b.WriteString(g.styles.codes[current].close)
+		b.WriteRune('\n')
+	}
+	return b.String()
+}
+
+// setCell draws glyph at x,y, keeping wide glyphs from shearing the row.
+// It reports whether the glyph was drawn (a wide glyph won't fit in the
+// last column).
+func setCell(g *grid, x, y int, glyph string, style styleID) bool {
+	if y < 0 || y >= len(g.rows) || x < 0 || x >= len(g.rows[y]) {
+		return false
+	}
+	row := g.rows[y]
+
+	w := runewidth.StringWidth(glyph)
+	if w == 0 {
+		// Combining marks ride along with whatever is drawn to their left
+		if x > 0 && row[x-1].text != " " && row[x-1].text != wideContinuation {
+			row[x-1].text += glyph
+		}
+		return false
+	}
+	if w > 1 && x+1 >= len(row) {
+		return false
+	}
+
+	clearCell(row, x)
+	if w > 1 {
+		clearCell(row, x+1)
+		row[x+1] = cell{text: wideContinuation, style: style}
+	}
+	row[x] = cell{text: glyph, style: style}
+	return true
+}
+
+// clearCell blanks a cell, also blanking the other half of any wide glyph
+// it was part of
+func clearCell(row []cell, x int) {
+	if row[x].text == wideContinuation && x > 0 {
+		row[x-1] = blank // We're the right half; the left half is now orphaned
+	}
+	if x+1 < len(row) && row[x+1].text == wideContinuation {
+		row[x+1] = blank // We're the left half of a wide glyph
+	}
+	row[x] = blank
+}
+
+// cellsFree reports whether the w cells starting at x,y are empty, here
+// and in whatever's under the grid
+func (g *grid) cellsFree(x, y, w int) bool {
+	if y < 0 || y >= len(g.rows) || x < 0 || x+w > len(g.rows[y]) {
+		return false
+	}
+	for i := x; i < x+w; i++ {
+		if g.rows[y][i].text != " " {
+			return false
+		}
+	}
+	return g.under == nil || g.under.cellsFree(x, y, w)
+}
+
+// drawText writes text into row y starting at column x, stepping by each
+// character's display width. Cells that already hold something are left
+// alone (so labels don't overwrite map lines), and text is clipped at the
+// edge of the grid rather than spilling past it.
+func drawText(g *grid, x, y int, text string, style styleID) {
+	if y < 0 || y >= len(g.rows) {
+		return
+	}
+	row := g.rows[y]
+	for _, r := range text {
+		w := runewidth.RuneWidth(r)
+		if x+w > len(row) {
+			return
+		}
+		if w == 0 || g.cellsFree(x, y, w) {
+			setCell(g, x, y, string(r), style)
+		}
+		x += w
+	}
+}
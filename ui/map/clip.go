@@ -0,0 +1,84 @@
+package mapview
+
+import (
+	shp "github.com/jonas-p/go-shp"
+)
+
+// chunkSize is how many consecutive polygon points share a bounding box.
+// Smaller chunks clip tighter but cost more boxes to check.
+const chunkSize = 64
+
+// chunk is a run of a polygon's points and the box around them
+type chunk struct {
+	start, end int // points[start:end]
+	box        shp.Box
+}
+
+// chunkPolygon splits a polygon's outline into chunks, so a render can
+// skip the parts of a huge polygon that are off screen without looking
+// at their points
+func chunkPolygon(p *shp.Polygon) []chunk {
+	var chunks []chunk
+	for start := 0; start < len(p.Points); start += chunkSize {
+		end := min(start+chunkSize, len(p.Points))
+		box := shp.Box{
+			MinX: p.Points[start].X, MaxX: p.Points[start].X,
+			MinY: p.Points[start].Y, MaxY: p.Points[start].Y,
+		}
+		for _, pt := range p.Points[start+1 : end] {
+			box.MinX = min(box.MinX, pt.X)
+			box.MaxX = max(box.MaxX, pt.X)
+			box.MinY = min(box.MinY, pt.Y)
+			box.MaxY = max(box.MaxY, pt.Y)
+		}
+		chunks = append(chunks, chunk{start: start, end: end, box: box})
+	}
+	return chunks
+}
+
+// screenBounds is the part of the world that's actually on screen. It's
+// wider than viewBounds: project squashes x by charAspect, so the view's
+// longitude span only fills part of the width.
+func (m *Model) screenBounds() shp.Box {
+	b := m.viewBounds
+	b.MaxX = b.MinX + (b.MaxX-b.MinX)*charAspect
+	return b
+}
+
+// boxesOverlap reports whether two boxes share any area
+func boxesOverlap(a, b shp.Box) bool {
+	return a.MaxX >= b.MinX && a.MinX <= b.MaxX && a.MaxY >= b.MinY && a.MinY <= b.MaxY
+}
+
+// inBox reports whether a point is inside a box
+func inBox(x, y float64, b shp.Box) bool {
+	return x >= b.MinX && x <= b.MaxX && y >= b.MinY && y <= b.MaxY
+}
+
+// drawPolygon plots every step-th point of a polygon that's on screen,
+// skipping whole chunks that aren't before projecting anything. Each
+// shift is a copy of the world to draw it in (see worldShifts).
+func (m *Model) drawPolygon(p *shp.Polygon, chunks []chunk, step int, shifts []float64, viewWidth, viewHeight int, plot func(x, y int)) {
+	screen := m.screenBounds()
+	for _, shift := range shifts {
+		// Work in the polygon's own longitudes, so shift the screen instead
+		box := shiftBox(screen, -shift)
+		if !boxesOverlap(p.Box, box) { // From the file; BBox() would walk every point
+			continue
+		}
+		for _, c := range chunks {
+			if !boxesOverlap(c.box, box) {
+				continue
+			}
+			// Keep to the same every-step-th points whichever chunks we skip
+			first := (c.start + step - 1) / step * step
+			for i := first; i < c.end; i += step {
+				pt := p.Points[i]
+				if !inBox(pt.X, pt.Y, box) {
+					continue
+				}
+				plot(m.project(pt.X+shift, pt.Y, viewWidth, viewHeight))
+			}
+		}
+	}
+}
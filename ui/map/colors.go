@@ -0,0 +1,116 @@
+package mapview
+
+import (
+	"hash/fnv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"termtrack/sbs"
+)
+
+// colorMode decides how aircraft icons and labels are colored
+type colorMode int
+
+const (
+	colorDefault colorMode = iota // Everything the same color
+	colorAirline                  // Stable color per airline
+	colorSignal                   // Green when heard loud and clear, red when faint
+	numColorModes
+)
+
+// airlinePalette is a set of 256-color codes that stay distinguishable on
+// a dark background (and from the map, airport and trail colors)
+var airlinePalette = []string{
+	"196", "202", "208", "214", "226", "118", "46", "48",
+	"51", "39", "27", "93", "129", "165", "201", "213",
+	"160", "130", "142", "71", "37", "61", "97", "168",
+}
+
+// airlinePrefix returns the ICAO airline designator from a callsign like
+// "UAL123", or "" for callsigns that aren't airline flights (e.g. N-numbers)
+func airlinePrefix(callsign string) string {
+	if len(callsign) < 4 {
+		return ""
+	}
+	for i := 0; i < 3; i++ {
+		if c := callsign[i]; c < 'A' || c > 'Z' {
+			return ""
+		}
+	}
+	// Airline callsigns go letters then a flight number; registrations
+	// like GABCD are letters all the way
+	if c := callsign[3]; c < '0' || c > '9' {
+		return ""
+	}
+	return callsign[:3]
+}
+
+// airlineColor hashes an airline designator to a palette color, so the
+// same airline gets the same color every session
+func airlineColor(prefix string) lipgloss.Color {
+	h := fnv.New32a()
+	h.Write([]byte(prefix))
+	return lipgloss.Color(airlinePalette[h.Sum32()%uint32(len(airlinePalette))])
+}
+
+// emergencyColor is for aircraft squawking an emergency code, whatever the
+// color mode, so they can't be missed
+const emergencyColor = lipgloss.Color("196") // Red
+
+// staleColor is for aircraft that have gone unheard for longer than their
+// source's timeout, until they're dropped
+const staleColor = lipgloss.Color("240") // Grey
+
+// blinkPeriod is how long an emergency's plane takes to blink on and off
+const blinkPeriod = time.Second
+
+// blinkOn reports whether a blinking plane is in the reversed half of its
+// blink at t
+func blinkOn(t time.Time) bool {
+	return t.UnixMilli()%blinkPeriod.Milliseconds() < blinkPeriod.Milliseconds()/2
+}
+
+// aircraftStyle returns the style for an aircraft under the current color
+// mode, falling back to def
+func (m *Model) aircraftStyle(ac *sbs.Aircraft, def lipgloss.Style) lipgloss.Style {
+	if ac.SquawkEmergency() != "" {
+		return def.Foreground(emergencyColor).Bold(true)
+	}
+	if ac.Quiet(m.now(), m.timeouts) {
+		return def.Foreground(staleColor)
+	}
+	switch m.colorMode {
+	case colorAirline:
+		if prefix := airlinePrefix(ac.Callsign); prefix != "" {
+			return def.Foreground(airlineColor(prefix))
+		}
+	case colorSignal:
+		if ac.Has&sbs.HasSignal != 0 {
+			return def.Foreground(signalColor(ac.Signal))
+		}
+	}
+	return def
+}
+
+// signalScale is the color for each signal level, in dBFS, from the
+// loudest down; anything fainter than the last gets red
+var signalScale = []struct {
+	dbfs  float64
+	color lipgloss.Color
+}{
+	{-6, "46"},   // Green
+	{-12, "118"}, // Light green
+	{-18, "226"}, // Yellow
+	{-24, "208"}, // Orange
+}
+
+// signalColor is the color for a signal level
+func signalColor(dbfs float64) lipgloss.Color {
+	for _, s := range signalScale {
+		if dbfs >= s.dbfs {
+			return s.color
+		}
+	}
+	return "196" // Red
+}
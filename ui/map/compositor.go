@@ -0,0 +1,170 @@
+package mapview
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jonas-p/go-shp"
+)
+
+// layerID is one of the things the map draws, each into its own grid
+type layerID int
+
+const (
+	layerBasemap layerID = iota
+	layerGraticule
+	layerAirports
+	layerApproaches
+	layerRangeRings // Around the receiver
+	layerTrails
+	layerVectors
+	layerRings // Position uncertainty
+	layerAircraft
+	layerLabels
+	layerOverlay // The crosshair
+	numLayers
+)
+
+// layerNames are what the config calls the layers, by id
+var layerNames = [numLayers]string{"basemap", "graticule", "airports", "approaches", "range_rings", "trails", "vectors", "rings", "aircraft", "labels", "overlay"}
+
+// staticLayers only change when the view does, so they're cached
+var staticLayers = []layerID{layerBasemap, layerGraticule, layerAirports, layerApproaches, layerRangeRings}
+
+func (id layerID) String() string {
+	if id >= 0 && id < numLayers {
+		return layerNames[id]
+	}
+	return fmt.Sprintf("layer(%d)", int(id))
+}
+
+// parseLayer finds a layer by its config name
+func parseLayer(name string) (layerID, error) {
+	if i := slices.Index(layerNames[:], name); i >= 0 {
+		return layerID(i), nil
+	}
+	return 0, fmt.Errorf("unknown layer %q", name)
+}
+
+// layerOrder is the order layers stack in, bottom first: the ones named in
+// order, then the rest in their default order on top
+func layerOrder(order []string) []layerID {
+	var ids []layerID
+	for _, name := range order {
+		if id, err := parseLayer(name); err == nil && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	for id := range numLayers {
+		if !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// staticKey is everything the static layers depend on, so the cache can
+// tell for itself when they need drawing again
+type staticKey struct {
+	bounds        shp.Box
+	width, height int
+	content       int // The model's contentVersion
+}
+
+// staticCache holds the static layers as last drawn
+type staticCache struct {
+	key    staticKey
+	valid  bool
+	layers [numLayers]*grid
+}
+
+// compositor stacks the layers' grids into one frame. Blank cells are
+// transparent, so whatever's below shows through them.
+type compositor struct {
+	w, h   int
+	grids  [numLayers]*grid
+	order  []layerID // Bottom first
+	hidden [numLayers]bool
+}
+
+// newCompositor creates a compositor for a w by h view
+func newCompositor(w, h int, order []layerID) *compositor {
+	return &compositor{w: w, h: h, order: order}
+}
+
+// hide leaves a layer out of the frame
+func (c *compositor) hide(id layerID) {
+	c.hidden[id] = true
+}
+
+// visible reports whether a layer will be in the frame, so hidden ones
+// needn't be drawn at all
+func (c *compositor) visible(id layerID) bool {
+	return !c.hidden[id]
+}
+
+// set uses g, which won't be changed, for a layer; e.g. a cached one
+func (c *compositor) set(id layerID, g *grid) {
+	c.grids[id] = g
+}
+
+// layer returns the grid to draw a layer into, blank to start with
+func (c *compositor) layer(id layerID) *grid {
+	if c.grids[id] == nil {
+		c.grids[id] = newGrid(c.w, c.h)
+	}
+	return c.grids[id]
+}
+
+// below flattens every visible layer under id but those in except, e.g.
+// for labels to find room among
+func (c *compositor) below(id layerID, except ...layerID) *grid {
+	i := slices.Index(c.order, id)
+	if i < 0 {
+		i = len(c.order)
+	}
+	var ids []layerID
+	for _, l := range c.order[:i] {
+		if !slices.Contains(except, l) {
+			ids = append(ids, l)
+		}
+	}
+	return c.stack(ids)
+}
+
+// flatten stacks every visible layer into the frame
+func (c *compositor) flatten() *grid {
+	return c.stack(c.order)
+}
+
+// stack draws the visible layers in ids, bottom first, onto a blank grid
+func (c *compositor) stack(ids []layerID) *grid {
+	out := newGrid(c.w, c.h)
+	for _, id := range ids {
+		if g := c.grids[id]; g != nil && !c.hidden[id] {
+			out.overlay(g)
+		}
+	}
+	return out
+}
+
+// overlay draws every non-blank cell of src over g. They have to be the
+// same size.
+func (g *grid) overlay(src *grid) {
+	// src's style ids mean nothing here; find or add each one in g's table
+	ids := make([]styleID, len(src.styles.codes))
+	for i, codes := range src.styles.codes {
+		ids[i] = g.addCodes(codes)
+	}
+
+	for y, row := range src.rows {
+		dst := g.rows[y]
+		for x, c := range row {
+			if c.text == " " {
+				continue
+			}
+			clearCell(dst, x) // Don't leave half of a wide glyph below
+			dst[x] = cell{text: c.text, style: ids[c.style]}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package mapview
+
+// toggleCrosshair turns crosshair mode on (starting at the view center) or off
+func (m *Model) toggleCrosshair() {
+	m.crosshair = !m.crosshair
+	if m.crosshair {
+		m.centerCrosshair()
+	}
+}
+
+// centerCrosshair puts the crosshair on the cell at the center of the view bounds
+func (m *Model) centerCrosshair() {
+	w, h := m.viewSize()
+	lat, lon := m.Center()
+	m.crossX, m.crossY = m.project(lon, lat, w, h)
+}
+
+// moveCrosshair moves the crosshair by dx,dy cells, keeping it on screen
+func (m *Model) moveCrosshair(dx, dy int) {
+	w, h := m.viewSize()
+	m.crossX = clamp(m.crossX+dx, 0, w-1)
+	m.crossY = clamp(m.crossY+dy, 0, h-1)
+}
+
+// zoomOnCrosshair recenters the view on the crosshair, then zooms
+func (m *Model) zoomOnCrosshair(factor float64) {
+	lat, lon, _ := m.Crosshair()
+	halfWidth := (m.viewBounds.MaxX - m.viewBounds.MinX) / 2
+	halfHeight := (m.viewBounds.MaxY - m.viewBounds.MinY) / 2
+
+	m.viewBounds.MinX = lon - halfWidth
+	m.viewBounds.MaxX = lon + halfWidth
+	m.viewBounds.MinY = lat - halfHeight
+	m.viewBounds.MaxY = lat + halfHeight
+
+	m.zoom(factor)
+	m.centerCrosshair()
+}
+
+// Crosshair returns the lat/lon under the crosshair, and whether crosshair
+// mode is on
+func (m Model) Crosshair() (lat, lon float64, ok bool) {
+	if !m.crosshair {
+		return 0, 0, false
+	}
+	w, h := m.viewSize()
+	lat, lon = m.unproject(m.crossX, m.crossY, w, h)
+	return lat, wrapLon(lon), true
+}
+
+// unproject converts terminal x/y back to lat/lon, using the middle of the cell
+func (m Model) unproject(x, y, viewWidth, viewHeight int) (lat, lon float64) {
+	// Undo the aspect squash from project
+	nx := (float64(x) + 0.5) * charAspect / float64(viewWidth)
+	ny := (float64(y) + 0.5) / float64(viewHeight)
+
+	lon = m.viewBounds.MinX + nx*(m.viewBounds.MaxX-m.viewBounds.MinX)
+	lat = m.viewBounds.MaxY - ny*(m.viewBounds.MaxY-m.viewBounds.MinY)
+	return lat, lon
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
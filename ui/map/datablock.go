@@ -0,0 +1,127 @@
+package mapview
+
+import (
+	"fmt"
+
+	"github.com/mattn/go-runewidth"
+
+	"termtrack/sbs"
+	"termtrack/ui/glyphs"
+)
+
+// dataBlock builds a radar scope style label: callsign and altitude, then
+// speed and track, then the groundspeed trend, then the type if it's known
+func dataBlock(ac *sbs.Aircraft, typ string, g glyphs.Set) []string {
+	name := ac.Callsign
+	if name == "" {
+		name = ac.ICAO
+	}
+	if alt := altitudeTag(ac, g); alt != "" {
+		name += " " + alt
+	}
+	lines := []string{name}
+
+	// Speed and trend wait for a velocity report
+	if ac.Speed != 0 {
+		lines = append(lines, fmt.Sprintf("%03.0f %.0fkt", ac.SmoothTrack, ac.SmoothSpeed))
+		switch {
+		case ac.SpeedTrend >= 1:
+			lines = append(lines, fmt.Sprintf("+%.0fkt/m", ac.SpeedTrend))
+		case ac.SpeedTrend <= -1:
+			lines = append(lines, fmt.Sprintf("%.0fkt/m", ac.SpeedTrend))
+		default:
+			lines = append(lines, "steady")
+		}
+	}
+	if typ != "" {
+		lines = append(lines, typ)
+	}
+	return lines
+}
+
+// levelRate is the vertical rate, in feet a minute, under which an
+// aircraft counts as level: the odd hundred either way is just holding
+// altitude
+const levelRate = 300
+
+// transitionAltitude is where labels go from feet to flight levels:
+// 18,000 ft, as in the US. It's lower in most other places, but only
+// changes how the altitude's written.
+const transitionAltitude = 18000
+
+// altitudeTag is an aircraft's altitude as labels show it: "FL350" up
+// high, "3500ft" lower down, "GND" on the ground, or "" if it hasn't said.
+// An arrow after it says which way it's going, if it's climbing or
+// descending.
+func altitudeTag(ac *sbs.Aircraft, g glyphs.Set) string {
+	var tag string
+	switch {
+	case ac.OnGround:
+		return "GND"
+	case ac.Has&sbs.HasAltitude == 0:
+		return ""
+	case ac.Altitude >= transitionAltitude:
+		tag = fmt.Sprintf("FL%03d", (ac.Altitude+50)/100)
+	default:
+		tag = fmt.Sprintf("%dft", ac.Altitude)
+	}
+	if ac.Has&sbs.HasVertRate != 0 {
+		switch {
+		case ac.VertRate >= levelRate:
+			tag += g.Climb
+		case ac.VertRate <= -levelRate:
+			tag += g.Descend
+		}
+	}
+	return tag
+}
+
+// placeBlock picks the top-left corner for a data block next to the plane
+// at px,py. The block is kept a column clear of the plane so it never
+// covers the target; we try each corner in turn and take the first one
+// that's on screen and clear of other labels, falling back to the first
+// one that's merely on screen.
+func placeBlock(g *grid, px, py int, lines []string, viewWidth, viewHeight int) (x, y int, ok bool) {
+	w := 0
+	for _, line := range lines {
+		if lw := runewidth.StringWidth(line); lw > w {
+			w = lw
+		}
+	}
+	h := len(lines)
+
+	candidates := [][2]int{
+		{px + 2, py + 1},     // Below right
+		{px + 2, py - h},     // Above right
+		{px - w - 1, py + 1}, // Below left
+		{px - w - 1, py - h}, // Above left
+	}
+
+	fallback := -1
+	for i, c := range candidates {
+		cx, cy := c[0], c[1]
+		if cx < 0 || cy < 0 || cx+w > viewWidth || cy+h > viewHeight {
+			continue
+		}
+		if fallback < 0 {
+			fallback = i
+		}
+		if blockFree(g, cx, cy, w, h) {
+			return cx, cy, true
+		}
+	}
+	if fallback < 0 {
+		return 0, 0, false
+	}
+	return candidates[fallback][0], candidates[fallback][1], true
+}
+
+// blockFree reports whether a w by h area is entirely blank
+func blockFree(g *grid, x, y, w, h int) bool {
+	for row := y; row < y+h; row++ {
+		if !g.cellsFree(x, row, w) {
+			return false
+		}
+	}
+	return true
+}
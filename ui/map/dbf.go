@@ -0,0 +1,103 @@
+package mapview
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Airport is an airport from the shapefile, with what its .dbf says about it
+type Airport struct {
+	Code string // ICAO code ("KJFK"), where it has one
+	IATA string // "JFK"
+	Name string
+	Rank int // Natural Earth's scalerank: lower is bigger, and labelled first
+	Lat  float64
+	Lon  float64
+}
+
+// unranked is the rank of an airport the .dbf doesn't rank, after all
+// those it does
+const unranked = 100
+
+// LoadAirportNames reads the airports along with their codes and names,
+// pairing each point in the .shp with its row in the .dbf alongside it
+func LoadAirportNames(path string) ([]Airport, error) {
+	recs, err := readRecords(path, &Progress{})
+	if err != nil {
+		return nil, err
+	}
+	rows, err := readDBF(strings.TrimSuffix(path, filepath.Ext(path))+".dbf", "gps_code", "iata_code", "name", "scalerank")
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) != len(recs) {
+		return nil, fmt.Errorf("%s: %d points but %d rows of attributes", path, len(recs), len(rows))
+	}
+
+	var airports []Airport
+	for i, rec := range recs {
+		p, err := parsePoint(rec)
+		if err != nil || p == nil {
+			continue
+		}
+		rank, err := strconv.Atoi(rows[i][3])
+		if err != nil {
+			rank = unranked
+		}
+		airports = append(airports, Airport{Code: rows[i][0], IATA: rows[i][1], Name: rows[i][2], Rank: rank, Lat: p.Y, Lon: p.X})
+	}
+	return airports, nil
+}
+
+// readDBF reads the named fields of every row of a dBase file, as trimmed
+// strings. A field the file doesn't have reads as "".
+func readDBF(path string, fields ...string) ([][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 32 {
+		return nil, fmt.Errorf("%s: too short to be a .dbf", path)
+	}
+	count := int(binary.LittleEndian.Uint32(data[4:]))
+	headerLen := int(binary.LittleEndian.Uint16(data[8:]))
+	rowLen := int(binary.LittleEndian.Uint16(data[10:]))
+	if headerLen+count*rowLen > len(data) {
+		return nil, fmt.Errorf("%s: rows run past the end of the file", path)
+	}
+
+	// Field descriptors are 32 bytes each, ending with 0x0D. Each row
+	// starts with a deletion flag, then the fields at fixed widths.
+	type span struct{ off, n int }
+	spans := make([]span, len(fields))
+	for i := range spans {
+		spans[i].off = -1
+	}
+	off := 1
+	for d := 32; d+32 <= headerLen && data[d] != 0x0d; d += 32 {
+		name, _, _ := strings.Cut(string(data[d:d+11]), "\x00")
+		n := int(data[d+16])
+		for i, f := range fields {
+			if strings.EqualFold(name, f) {
+				spans[i] = span{off, n}
+			}
+		}
+		off += n
+	}
+
+	rows := make([][]string, count)
+	for r := range rows {
+		row := data[headerLen+r*rowLen : headerLen+(r+1)*rowLen]
+		rows[r] = make([]string, len(fields))
+		for i, s := range spans {
+			if s.off >= 0 && s.off+s.n <= len(row) {
+				rows[r][i] = strings.TrimSpace(string(row[s.off : s.off+s.n]))
+			}
+		}
+	}
+	return rows, nil
+}
@@ -0,0 +1,25 @@
+package mapview
+
+import (
+	shp "github.com/jonas-p/go-shp"
+)
+
+// outlineWiggle is how much longer a real coastline is than its bounding
+// box's perimeter, roughly. Overestimating it just draws a few more points.
+const outlineWiggle = 2.0
+
+// decimationStep picks how many of a polygon's points to step over so we
+// draw about one per screen cell along its outline: a world view thins
+// the coastlines right down, a close-up draws every point
+func (m *Model) decimationStep(box shp.Box, points, viewWidth, viewHeight int) int {
+	// How many cells the polygon's bounding box covers at this zoom
+	cellsX := (box.MaxX - box.MinX) / (m.viewBounds.MaxX - m.viewBounds.MinX) * float64(viewWidth) / charAspect
+	cellsY := (box.MaxY - box.MinY) / (m.viewBounds.MaxY - m.viewBounds.MinY) * float64(viewHeight)
+
+	// The outline crosses about that box's perimeter in cells
+	outline := 2 * (cellsX + cellsY) * outlineWiggle
+	if outline < 1 {
+		outline = 1
+	}
+	return max(int(float64(points)/outline), 1)
+}
@@ -0,0 +1,56 @@
+package mapview
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultDensityLimit is how many aircraft can be in view before the map
+// stops drawing their labels, trails and leader lines. Past it, around
+// Heathrow or LAX zoomed out, they'd only be a smear, and placing that
+// many labels makes every frame slow.
+const DefaultDensityLimit = 150
+
+// densityStyle is the notice saying what's been left off
+var densityStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("214"))
+
+// SetDensityLimit sets how many aircraft can be in view before labels,
+// trails and leader lines go, 0 for no limit
+func (m *Model) SetDensityLimit(n int) {
+	m.densityLimit = n
+}
+
+// crowded returns how many aircraft are in view, if that's more than the
+// density limit, else 0
+func (m *Model) crowded(viewWidth, viewHeight int) int {
+	if m.densityLimit <= 0 || len(m.aircraft) <= m.densityLimit {
+		return 0
+	}
+	n := 0
+	for _, ac := range m.aircraft {
+		if ac.Lat == 0 && ac.Lon == 0 {
+			continue
+		}
+		x, y := m.project(m.nearView(ac.Lon), ac.Lat, viewWidth, viewHeight)
+		if x >= 0 && x < viewWidth && y >= 0 && y < viewHeight {
+			n++
+		}
+	}
+	if n <= m.densityLimit {
+		return 0
+	}
+	return n
+}
+
+// drawDensityNotice says, across the top of the finished frame, why
+// there are no labels or trails
+func drawDensityNotice(g *grid, n int) {
+	text := fmt.Sprintf(" %d aircraft in view: labels and trails off until you zoom in ", n)
+	style := g.style(densityStyle)
+	for x, r := range text { // All one column wide
+		if !setCell(g, x, 0, string(r), style) {
+			return
+		}
+	}
+}
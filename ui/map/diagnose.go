@@ -0,0 +1,126 @@
+package mapview
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	shp "github.com/jonas-p/go-shp"
+)
+
+// shpFileCode is the magic number every .shp file starts with
+const shpFileCode = 9994
+
+// shapeNames are the shape types we might find, for saying what a file holds
+var shapeNames = map[shp.ShapeType]string{
+	shp.NULL:        "null",
+	shp.POINT:       "point",
+	shp.POLYLINE:    "polyline",
+	shp.POLYGON:     "polygon",
+	shp.MULTIPOINT:  "multipoint",
+	shp.POINTZ:      "point Z",
+	shp.POLYLINEZ:   "polyline Z",
+	shp.POLYGONZ:    "polygon Z",
+	shp.MULTIPOINTZ: "multipoint Z",
+	shp.POINTM:      "point M",
+	shp.POLYLINEM:   "polyline M",
+	shp.POLYGONM:    "polygon M",
+	shp.MULTIPOINTM: "multipoint M",
+	shp.MULTIPATCH:  "multipatch",
+}
+
+// shapeName names a shape type, even one we don't know
+func shapeName(t shp.ShapeType) string {
+	if name, ok := shapeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (type %d)", t)
+}
+
+// LoadError is why a dataset failed to load, with whatever we could find
+// out about its files to help put it right
+type LoadError struct {
+	Dataset string   // "basemap" or "airports"
+	Path    string   // The .shp file
+	Err     error    // What went wrong
+	Notes   []string // Diagnostics, e.g. missing sidecar files
+}
+
+func (e *LoadError) Error() string {
+	return fmt.Sprintf("%s (%s): %v", e.Dataset, e.Path, e.Err)
+}
+
+func (e *LoadError) Unwrap() error {
+	return e.Err
+}
+
+// diagnose wraps a load failure in a LoadError, poking at the files to
+// work out what's wrong with them
+func diagnose(dataset, path string, want shp.ShapeType, err error) *LoadError {
+	e := &LoadError{Dataset: dataset, Path: path, Err: err}
+	note := func(format string, args ...any) {
+		e.Notes = append(e.Notes, fmt.Sprintf(format, args...))
+	}
+
+	// 1. Is the file there at all?
+	info, statErr := os.Stat(path)
+	switch {
+	case os.IsNotExist(statErr):
+		note("%s doesn't exist", path)
+		dir := filepath.Dir(path)
+		if others, _ := filepath.Glob(filepath.Join(dir, "*.shp")); len(others) > 0 {
+			note("shapefiles in %s: %s", dir, strings.Join(baseNames(others), ", "))
+		} else if _, err := os.Stat(dir); os.IsNotExist(err) {
+			note("the %s directory doesn't exist either; is TermTrack running from the right place?", dir)
+		}
+		return e
+	case statErr != nil:
+		note("can't read %s: %v", path, statErr)
+		return e
+	case info.IsDir():
+		note("%s is a directory, not a .shp file", path)
+		return e
+	}
+
+	// 2. A shapefile comes with sidecars; missing ones mean a bad unzip
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, ext := range []string{".shx", ".dbf"} {
+		if _, err := os.Stat(base + ext); os.IsNotExist(err) {
+			note("%s is missing, so the download looks incomplete", filepath.Base(base+ext))
+		}
+	}
+
+	// 3. Is it a shapefile, and of the right shapes?
+	f, openErr := os.Open(path)
+	if openErr != nil {
+		note("can't open %s: %v", path, openErr)
+		return e
+	}
+	defer f.Close()
+
+	header := make([]byte, shpHeaderLen)
+	if _, err := io.ReadFull(f, header); err != nil {
+		note("%s is too short to be a shapefile (%d bytes)", filepath.Base(path), info.Size())
+		return e
+	}
+	if code := binary.BigEndian.Uint32(header); code != shpFileCode {
+		note("%s doesn't start like a shapefile; is it really a .shp?", filepath.Base(path))
+		return e
+	}
+	if got := shp.ShapeType(le32(header[32:])); got != want {
+		note("%s holds %s shapes, but the %s layer needs %s shapes", filepath.Base(path), shapeName(got), dataset, shapeName(want))
+	}
+	return e
+}
+
+// baseNames strips the directories off paths
+func baseNames(paths []string) []string {
+	out := make([]string, len(paths))
+	for i, p := range paths {
+		out[i] = filepath.Base(p)
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+package mapview
+
+import "math"
+
+// fitMargin is how much room to leave around a trail when fitting the
+// view to it, as a fraction of the trail's size
+const fitMargin = 0.1
+
+// maxFitZoom stops a short trail (or a plane sat on the ground) from
+// zooming in to nothing
+const maxFitZoom = 500.0
+
+// fitTrail zooms and pans so the whole of an aircraft's trail is on
+// screen, keeping the map's shape. It reports whether there was a trail
+// to fit to.
+func (m *Model) fitTrail(icao string) bool {
+	ac, ok := m.aircraft[icao]
+	if !ok || len(ac.Trail) == 0 {
+		return false
+	}
+
+	// Unwrap the trail from its newest point, as it's drawn, so one that
+	// crosses ±180 is fitted the short way round
+	lon := m.nearView(ac.Trail[len(ac.Trail)-1].Lon)
+	minX, maxX := lon, lon
+	minY, maxY := ac.Trail[len(ac.Trail)-1].Lat, ac.Trail[len(ac.Trail)-1].Lat
+	for i := len(ac.Trail) - 2; i >= 0; i-- {
+		lon = unwrapFrom(lon, ac.Trail[i].Lon)
+		minX, maxX = math.Min(minX, lon), math.Max(maxX, lon)
+		minY, maxY = math.Min(minY, ac.Trail[i].Lat), math.Max(maxY, ac.Trail[i].Lat)
+	}
+
+	// The longitude span on screen is the view's stretched by charAspect
+	// (see screenBounds), so that's what has to cover the trail's width
+	aspect := (m.viewBounds.MaxX - m.viewBounds.MinX) / (m.viewBounds.MaxY - m.viewBounds.MinY)
+	height := math.Max((maxY-minY)*(1+2*fitMargin), (maxX-minX)*(1+2*fitMargin)/(charAspect*aspect))
+	height = math.Max(height, (m.originalBounds.MaxY-m.originalBounds.MinY)/maxFitZoom)
+	if height > m.originalBounds.MaxY-m.originalBounds.MinY {
+		m.viewBounds = m.originalBounds
+		return true
+	}
+	width := height * aspect
+
+	centerX, centerY := (minX+maxX)/2, (minY+maxY)/2
+	m.viewBounds.MinX = centerX - width*charAspect/2
+	m.viewBounds.MaxX = m.viewBounds.MinX + width
+	m.viewBounds.MinY = centerY - height/2
+	m.viewBounds.MaxY = centerY + height/2
+	m.wrapView()
+	return true
+}
@@ -0,0 +1,44 @@
+package mapview
+
+import "math"
+
+// graticuleSpacings are the degrees apart lines of latitude and longitude
+// can be drawn; the zoom picks one
+var graticuleSpacings = []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 15, 30}
+
+// graticuleSpacing is how far apart the lines are at this zoom: the
+// closest that still leaves no more than six from top to bottom
+func (m *Model) graticuleSpacing() float64 {
+	height := m.viewBounds.MaxY - m.viewBounds.MinY
+	for _, s := range graticuleSpacings {
+		if height/s <= 6 {
+			return s
+		}
+	}
+	return graticuleSpacings[len(graticuleSpacings)-1]
+}
+
+// drawGraticule draws the lines of latitude and longitude in view. On
+// this projection they're straight down and straight across the screen.
+func (m *Model) drawGraticule(g *grid, viewWidth, viewHeight int) {
+	style := g.style(layerStyle(m.layers.Graticule))
+	step := max(m.layers.Graticule.Step, 1)
+	spacing := m.graticuleSpacing()
+	screen := m.screenBounds()
+
+	// Counting in whole spacings keeps the lines on round numbers
+	glyph := layerGlyph(m.layers.Graticule, m.glyphs.Vectors[0])
+	for k := math.Ceil(screen.MinX / spacing); k*spacing <= screen.MaxX; k++ {
+		x, _ := m.project(k*spacing, 0, viewWidth, viewHeight)
+		for y := 0; y < viewHeight; y += step {
+			setCell(g, x, y, glyph, style)
+		}
+	}
+	glyph = layerGlyph(m.layers.Graticule, m.glyphs.Vectors[2])
+	for k := math.Ceil(max(screen.MinY, -90) / spacing); k*spacing <= min(screen.MaxY, 90); k++ {
+		_, y := m.project(0, k*spacing, viewWidth, viewHeight)
+		for x := 0; x < viewWidth; x += step {
+			setCell(g, x, y, glyph, style)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package mapview
+
+import (
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"termtrack/sbs"
+)
+
+// hoverCells is how near the cursor, in cells across or down, an aircraft
+// or airport has to be drawn for Hover to name it
+const hoverCells = 3
+
+// Hover is what's at and around the crosshair
+type Hover struct {
+	Lat, Lon float64
+	Aircraft *sbs.Aircraft // The nearest one drawn within hoverCells, or nil
+	Airport  *Airport      // Likewise
+}
+
+// Hover returns what's under the crosshair, and whether crosshair mode is
+// on. Aircraft and airports only count if their layers are showing.
+func (m Model) Hover() (Hover, bool) {
+	lat, lon, ok := m.Crosshair()
+	if !ok {
+		return Hover{}, false
+	}
+	h := Hover{Lat: lat, Lon: lon}
+	w, ht := m.viewSize()
+	shown := func(id layerID) bool {
+		return !slices.Contains(m.layers.Hidden, id.String())
+	}
+
+	// Cells are about twice as tall as they're wide, so a cell down is
+	// as far as two across
+	near := func(lat, lon float64) (int, bool) {
+		x, y := m.project(m.nearView(lon), lat, w, ht)
+		dx, dy := x-m.crossX, y-m.crossY
+		if abs(dx) > hoverCells || abs(dy) > hoverCells {
+			return 0, false
+		}
+		return dx*dx + 4*dy*dy, true
+	}
+
+	if shown(layerAircraft) {
+		best := -1
+		for _, ac := range m.aircraft {
+			if ac.Lat == 0 && ac.Lon == 0 {
+				continue
+			}
+			if d, ok := near(ac.Lat, ac.Lon); ok && (best < 0 || d < best) {
+				best, h.Aircraft = d, ac
+			}
+		}
+	}
+	if shown(layerAirports) {
+		best := -1
+		for i := range m.airportNames {
+			a := &m.airportNames[i]
+			if d, ok := near(a.Lat, a.Lon); ok && (best < 0 || d < best) {
+				best, h.Airport = d, a
+			}
+		}
+	}
+	return h, true
+}
+
+// mouseCrosshair moves the crosshair to follow the mouse, which only
+// reports anything while crosshair mode is on. x and y are from the top
+// left of the map, frame and all.
+func (m *Model) mouseCrosshair(msg tea.MouseMsg) {
+	if !m.crosshair || msg.Action != tea.MouseActionMotion {
+		return
+	}
+	style := m.frameStyle()
+	x := msg.X - style.GetBorderLeftSize()
+	y := msg.Y - style.GetBorderTopSize()
+	if w, h := m.viewSize(); x >= 0 && y >= 0 && x < w && y < h {
+		m.crossX, m.crossY = x, y
+	}
+}
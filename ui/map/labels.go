@@ -0,0 +1,170 @@
+package mapview
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// labelPriority is how much a label matters when it wants the same cells
+// as another: lower goes first, and so wins
+type labelPriority int
+
+const (
+	prioritySelected labelPriority = iota // The selected aircraft's
+	priorityAircraft
+	priorityApproach
+	priorityAirport
+)
+
+// labelRequest is a label that wants a spot on the map
+type labelRequest struct {
+	priority labelPriority
+	rank     int    // Within a priority, lower goes first
+	key      string // Settles ties, so the same labels win every frame
+	x, y     int    // The cell it labels
+
+	// Either the forms of a one-line label, longest first, or the lines
+	// of a data block
+	forms []string
+	block []string
+
+	// Draw the shortest form somewhere even with no room for it, into
+	// whatever cells are free. Aircraft do; nothing else is worth it.
+	always bool
+	style  styleID
+}
+
+// labelManager gives every label on the map its place. Labels are placed
+// in order of priority rather than of whichever layer drew last, so at any
+// zoom the ones that matter most are the ones that show.
+type labelManager struct {
+	requests []labelRequest
+}
+
+// add asks for a label
+func (lm *labelManager) add(r labelRequest) {
+	lm.requests = append(lm.requests, r)
+}
+
+// place draws the labels into g, most important first. Each keeps off the
+// ones placed before it, and (drawText sees to this) off whatever's under
+// g. Nothing is remembered between frames, so a label that lost its spot
+// comes back as soon as there's room for it.
+func (lm *labelManager) place(g *grid, viewWidth, viewHeight int) {
+	slices.SortFunc(lm.requests, func(a, b labelRequest) int {
+		return cmp.Or(
+			cmp.Compare(a.priority, b.priority),
+			cmp.Compare(a.rank, b.rank),
+			cmp.Compare(a.key, b.key),
+		)
+	})
+	for _, r := range lm.requests {
+		if r.block != nil {
+			x, y, ok := placeBlock(g, r.x, r.y, r.block, viewWidth, viewHeight)
+			if !ok {
+				continue
+			}
+			for i, line := range r.block {
+				drawText(g, x, y+i, line, r.style)
+			}
+			continue
+		}
+		if text, x, y, ok := chooseLabel(g, r, viewWidth, viewHeight); ok {
+			drawText(g, x, y, text, r.style)
+		}
+	}
+}
+
+// chooseLabel picks the text and spot for a one-line label: the first
+// form, longest first, that fits on screen clear of the labels already
+// placed. Those that aren't drawn regardless have to fit whole, clear of
+// the map under them too, with a blank cell either side so neighbouring
+// names don't run together.
+func chooseLabel(g *grid, r labelRequest, viewWidth, viewHeight int) (text string, x, y int, ok bool) {
+	for _, text := range r.forms {
+		w := runewidth.StringWidth(text)
+		x, y, ok = placeLabel(r.x, r.y, w, viewWidth, viewHeight)
+		if !ok {
+			return "", 0, 0, false
+		}
+		if w > viewWidth {
+			continue
+		}
+		if r.always && !labelled(g, x, y, w) {
+			return text, x, y, true
+		}
+		if !r.always && !labelled(g, x-1, y, w+2) && g.cellsFree(x, y, w) {
+			return text, x, y, true
+		}
+	}
+	if !r.always || len(r.forms) == 0 {
+		return "", 0, 0, false
+	}
+	return r.forms[len(r.forms)-1], x, y, true
+}
+
+// labelled reports whether any of the w cells from x,y already hold a
+// label. Cells off the side of the view don't.
+func labelled(g *grid, x, y, w int) bool {
+	row := g.rows[y]
+	for i := max(x, 0); i < min(x+w, len(row)); i++ {
+		if row[i].text != " " {
+			return true
+		}
+	}
+	return false
+}
+
+// callsignForms are the ways a callsign can be labelled, longest first
+func callsignForms(callsign string) []string {
+	forms := []string{callsign}
+	if short := shortCallsign(callsign); short != callsign {
+		forms = append(forms, short)
+	}
+	return forms
+}
+
+// shortCallsign drops the airline prefix from an airline callsign, keeping
+// the flight number ("JBU1234" becomes "1234"). Anything else, like a
+// registration, has nothing to drop and comes back as it is.
+func shortCallsign(callsign string) string {
+	if prefix := airlinePrefix(callsign); prefix != "" {
+		return callsign[len(prefix):]
+	}
+	return callsign
+}
+
+// airportLabelRank is the least important rank of airport that gets a
+// label, going by how many degrees wide the view is: only the biggest
+// (rank 2 or so) with the whole world in view, all of them over a city
+func airportLabelRank(span float64) int {
+	return int(11 - math.Log2(max(span, 1e-6)))
+}
+
+// addAirportLabels asks for a code beside each airport drawn in airports
+// that's big enough for the zoom. IATA codes are shorter, so they're used
+// where there is one.
+func (m *Model) addAirportLabels(lm *labelManager, airports *grid, style styleID, viewWidth, viewHeight int) {
+	screen := m.screenBounds()
+	maxRank := airportLabelRank(screen.MaxX - screen.MinX)
+	for _, shift := range m.worldShifts() {
+		box := shiftBox(screen, -shift)
+		for _, a := range m.airportNames {
+			if a.Rank > maxRank || !inBox(a.Lon, a.Lat, box) {
+				continue
+			}
+			code := cmp.Or(a.IATA, a.Code)
+			if code == "" {
+				continue
+			}
+			x, y := m.project(a.Lon+shift, a.Lat, viewWidth, viewHeight)
+			if x < 0 || y < 0 || x >= viewWidth || y >= viewHeight || airports.rows[y][x].text == " " {
+				continue // Not drawn, so nothing to label
+			}
+			lm.add(labelRequest{priority: priorityAirport, rank: a.Rank, key: code, x: x, y: y, forms: []string{code}, style: style})
+		}
+	}
+}
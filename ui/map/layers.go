@@ -0,0 +1,131 @@
+package mapview
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+)
+
+// LayerStyle is how one map layer is drawn. Anything left empty keeps the
+// default, so a config only has to mention what it changes.
+type LayerStyle struct {
+	Glyph string `json:"glyph,omitempty"` // One cell wide; defaults to the glyph set's
+	Color string `json:"color,omitempty"` // ANSI number ("220") or hex ("#ffcc00")
+	Step  int    `json:"step,omitempty"`  // Draw every Nth point, to thin out dense layers
+}
+
+// Layers is the style of every layer the map draws
+type Layers struct {
+	Basemap  LayerStyle `json:"basemap"` // With no step, thinned to suit the zoom
+	Airports LayerStyle `json:"airports"`
+	Trails   LayerStyle `json:"trails"` // Color is the fresh end, old points still fade to grey; no step
+
+	// Glyph replaces the line drawing; no step
+	Approaches LayerStyle `json:"approaches"`
+
+	// Lines of latitude and longitude, and rings round the receiver when
+	// we know where it is. How far apart they are follows the zoom.
+	Graticule  LayerStyle `json:"graticule"`
+	RangeRings LayerStyle `json:"range_rings"`
+
+	// Layers from the bottom up; any left out go on top in the usual
+	// order: basemap, graticule, airports, approaches, range_rings,
+	// trails, vectors, rings, aircraft, labels, overlay
+	Order []string `json:"order,omitempty"`
+
+	// Layers not to draw at all
+	Hidden []string `json:"hidden,omitempty"`
+}
+
+// defaultLayers is what we draw with when nothing's configured
+var defaultLayers = Layers{
+	Basemap:  LayerStyle{Color: "255"},          // Bright White, step picked by zoom
+	Airports: LayerStyle{Color: "220", Step: 1}, // Yellow
+	Trails:   LayerStyle{Color: "45"},           // Bright cyan
+
+	Approaches: LayerStyle{Color: "75"}, // Steel blue
+
+	Graticule:  LayerStyle{Color: "237", Step: 2}, // Dark grey, dotted
+	RangeRings: LayerStyle{Color: "242", Step: 1}, // Grey
+}
+
+// colorPattern is what lipgloss understands as a color
+var colorPattern = regexp.MustCompile(`^([0-9]{1,3}|#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6})$`)
+
+// Validate checks a layer style makes sense before we draw with it
+func (s LayerStyle) Validate() error {
+	if s.Glyph != "" && runewidth.StringWidth(s.Glyph) != 1 {
+		return fmt.Errorf("glyph %q isn't one cell wide", s.Glyph)
+	}
+	if s.Color != "" && !colorPattern.MatchString(s.Color) {
+		return fmt.Errorf("color %q isn't an ANSI number or #hex", s.Color)
+	}
+	if s.Step < 0 {
+		return fmt.Errorf("step %d is negative", s.Step)
+	}
+	return nil
+}
+
+// Validate checks every layer's style
+func (l Layers) Validate() error {
+	for name, s := range map[string]LayerStyle{"basemap": l.Basemap, "airports": l.Airports, "trails": l.Trails, "approaches": l.Approaches,
+		"graticule": l.Graticule, "range_rings": l.RangeRings} {
+		if err := s.Validate(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	for _, name := range append(slices.Clone(l.Order), l.Hidden...) {
+		if _, err := parseLayer(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge fills in s's empty fields from def
+func (s LayerStyle) merge(def LayerStyle) LayerStyle {
+	if s.Glyph == "" {
+		s.Glyph = def.Glyph
+	}
+	if s.Color == "" {
+		s.Color = def.Color
+	}
+	if s.Step == 0 {
+		s.Step = def.Step
+	}
+	return s
+}
+
+// SetLayers sets the layer styles, falling back to the defaults for
+// anything left empty
+func (m *Model) SetLayers(l Layers) {
+	m.layers = Layers{
+		Basemap:  l.Basemap.merge(defaultLayers.Basemap),
+		Airports: l.Airports.merge(defaultLayers.Airports),
+		Trails:   l.Trails.merge(defaultLayers.Trails),
+
+		Approaches: l.Approaches.merge(defaultLayers.Approaches),
+		Graticule:  l.Graticule.merge(defaultLayers.Graticule),
+		RangeRings: l.RangeRings.merge(defaultLayers.RangeRings),
+
+		Order:  l.Order,
+		Hidden: l.Hidden,
+	}
+	m.contentVersion++
+}
+
+// layerGlyph is a layer's glyph, or the glyph set's when it has none
+func layerGlyph(s LayerStyle, fallback string) string {
+	if s.Glyph != "" {
+		return s.Glyph
+	}
+	return fallback
+}
+
+// layerStyle is the lipgloss style for a layer
+func layerStyle(s LayerStyle) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(s.Color))
+}
@@ -0,0 +1,177 @@
+package mapview
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// LegendEntry is one line of the legend: a symbol, drawn just as the map
+// draws it, and what it stands for
+type LegendEntry struct {
+	Symbol string
+	Text   string
+}
+
+// Braille stand-ins for a plane and a trail point, as the braille canvas
+// plots them
+const (
+	braillePlane = "⠛"
+	brailleTrail = "⠂"
+)
+
+// Legend explains what's on the map as it's drawn right now: the glyphs
+// and colors of the glyph set, color mode and configured layers, leaving
+// out the layers that are off
+func (m Model) Legend() []LegendEntry {
+	var entries []LegendEntry
+	add := func(glyph string, style lipgloss.Style, text string) {
+		entries = append(entries, LegendEntry{Symbol: style.Render(glyph), Text: text})
+	}
+	shown := func(id layerID) bool {
+		return !slices.Contains(m.layers.Hidden, id.String())
+	}
+
+	if shown(layerAircraft) {
+		plane := m.glyphs.Plane
+		if m.braille {
+			plane = braillePlane
+		}
+		switch m.colorMode {
+		case colorAirline:
+			var planes strings.Builder
+			for _, prefix := range []string{"AAL", "DAL", "UAL"} {
+				planes.WriteString(planeStyle.Foreground(airlineColor(prefix)).Render(plane))
+			}
+			entries = append(entries, LegendEntry{Symbol: planes.String(), Text: "Aircraft, a color per airline"})
+			add(plane, planeStyle, "Aircraft with no airline callsign")
+		case colorSignal:
+			for i, s := range signalScale {
+				text := fmt.Sprintf("Signal %.0f dBFS or more", s.dbfs)
+				if i > 0 {
+					text = fmt.Sprintf("Signal %.0f to %.0f dBFS", s.dbfs, signalScale[i-1].dbfs)
+				}
+				add(plane, planeStyle.Foreground(s.color), text)
+			}
+			add(plane, planeStyle.Foreground(signalColor(signalScale[len(signalScale)-1].dbfs-1)),
+				fmt.Sprintf("Signal under %.0f dBFS", signalScale[len(signalScale)-1].dbfs))
+			add(plane, planeStyle, "No signal level")
+		default:
+			add(plane, planeStyle, "Aircraft")
+		}
+		add(plane, planeStyle.Reverse(true).Bold(true), "Selected aircraft")
+		add(plane, planeStyle.Foreground(emergencyColor).Bold(true), "Squawking 7500, 7600 or 7700 (blinks)")
+		add(plane, planeStyle.Foreground(staleColor), "Not heard lately, stale")
+		if !m.braille {
+			for _, c := range m.categoryLegend() {
+				add(c.glyph, planeStyle, c.text)
+			}
+		}
+	}
+
+	if shown(layerLabels) {
+		add(m.glyphs.Climb+m.glyphs.Descend, callsignStyle, "Climbing, descending (after the altitude)")
+	}
+
+	if m.showTrails && shown(layerTrails) {
+		glyph := layerGlyph(m.layers.Trails, m.glyphs.Trail)
+		if m.braille {
+			glyph = brailleTrail
+		}
+		fresh := lipgloss.Color(m.layers.Trails.Color)
+		add(glyph, lipgloss.NewStyle().Foreground(fresh), "Trail")
+		if m.trailFade > 0 {
+			add(glyph, lipgloss.NewStyle().Foreground(trailColor(3*m.trailFade, m.trailFade, fresh)),
+				"Trail over "+legendDuration(m.trailFade)+" old")
+		}
+	}
+	if m.vectors && m.vectorTime > 0 && shown(layerVectors) {
+		add(m.glyphs.Vectors[2], planeStyle, "Where it'll be in "+legendDuration(m.vectorTime))
+	}
+	if shown(layerRings) {
+		glyph := m.glyphs.Ring
+		if m.braille {
+			glyph = brailleTrail
+		}
+		add(glyph, ringStyle, "Round a position that may be off: MLAT, or not heard for "+legendDuration(ringAge))
+	}
+	if len(m.approaches) > 0 && shown(layerApproaches) {
+		add(layerGlyph(m.layers.Approaches, m.glyphs.Vectors[2]), layerStyle(m.layers.Approaches), "Runway centerline")
+	}
+	if m.receiver != nil && shown(layerRangeRings) {
+		add(layerGlyph(m.layers.RangeRings, m.glyphs.Trail), layerStyle(m.layers.RangeRings),
+			fmt.Sprintf("Range rings, every %g nm", m.rangeRingSpacing()))
+	}
+	if shown(layerAirports) {
+		add(layerGlyph(m.layers.Airports, m.glyphs.Airport), layerStyle(m.layers.Airports), "Airport")
+	}
+	if shown(layerBasemap) {
+		add(layerGlyph(m.layers.Basemap, m.glyphs.MapPoint), layerStyle(m.layers.Basemap), "Coast and borders")
+	}
+	if shown(layerGraticule) {
+		add(layerGlyph(m.layers.Graticule, m.glyphs.Vectors[0]), layerStyle(m.layers.Graticule),
+			fmt.Sprintf("Lat and lon lines, every %g°", m.graticuleSpacing()))
+	}
+	if m.crosshair && shown(layerOverlay) {
+		add(m.glyphs.Crosshair, crossStyle, "Cursor")
+	}
+	return entries
+}
+
+// categoryGlyph is a plane glyph the legend explains, for a category
+type categoryGlyph struct {
+	glyph, text string
+}
+
+// categoryLegend is every glyph that stands in for the plane for some
+// category, the glyph set's and the config's, in category order
+func (m Model) categoryLegend() []categoryGlyph {
+	glyphs := map[string]string{
+		categoryNames["balloon"]: m.glyphs.Balloon,
+		categoryNames["glider"]:  m.glyphs.Glider,
+	}
+	for code, glyph := range m.categoryGlyphs {
+		glyphs[code] = glyph
+	}
+	var out []categoryGlyph
+	for _, code := range slices.Sorted(maps.Keys(glyphs)) {
+		if glyphs[code] == m.glyphs.Plane {
+			continue
+		}
+		out = append(out, categoryGlyph{glyph: glyphs[code], text: categoryLabel(code)})
+	}
+	return out
+}
+
+// categoryLabel names an emitter category, by the first of its names
+// alphabetically, like "Helicopter (A7)"
+func categoryLabel(code string) string {
+	var names []string
+	for name, c := range categoryNames {
+		if c == code {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "Category " + code
+	}
+	name := slices.Min(names)
+	return fmt.Sprintf("%s%s (%s)", strings.ToUpper(name[:1]), name[1:], code)
+}
+
+// legendDuration is a duration the way the legend says it: "5m" rather
+// than "5m0s"
+func legendDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,247 @@
+package mapview
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	shp "github.com/jonas-p/go-shp"
+)
+
+// shpHeaderLen is the size of a .shp file's header; the records follow it
+const shpHeaderLen = 100
+
+// Progress counts one shapefile's records as they're parsed, for a
+// loading screen. It's safe to read while Load runs.
+type Progress struct {
+	done  atomic.Int64
+	total atomic.Int64
+}
+
+// LoadProgress is the progress of each dataset Load reads
+type LoadProgress struct {
+	Basemap  Progress
+	Airports Progress
+}
+
+// Counts returns how many records have been parsed, out of how many found so far
+func (p *Progress) Counts() (done, total int64) {
+	return p.done.Load(), p.total.Load()
+}
+
+// Data is the map's static layers, as loaded from the shapefiles
+type Data struct {
+	polygons []*shp.Polygon
+	chunks   [][]chunk
+	bounds   shp.Box
+
+	airports    []*shp.Point
+	airportsErr error // Why there are no airports, if there aren't
+}
+
+// AirportsErr returns why the airports didn't load, or nil if they did
+func (d *Data) AirportsErr() error {
+	return d.airportsErr
+}
+
+// Load reads the map and airport shapefiles at the same time, parsing
+// their records across every core. progress may be nil. If the basemap
+// fails the error is a *LoadError; the airports are optional, so if they
+// fail the map loads without them and AirportsErr says why.
+func Load(mapPath, airportPath string, progress *LoadProgress) (*Data, error) {
+	if progress == nil {
+		progress = &LoadProgress{}
+	}
+
+	var (
+		d      Data
+		mapErr error
+		wg     sync.WaitGroup
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		d.polygons, d.bounds, mapErr = loadMapData(mapPath, &progress.Basemap)
+		if mapErr != nil {
+			mapErr = diagnose("basemap", mapPath, shp.POLYGON, mapErr)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		d.airports, d.airportsErr = LoadAirports(airportPath, &progress.Airports)
+	}()
+	wg.Wait()
+
+	if mapErr != nil {
+		return nil, mapErr
+	}
+
+	d.chunks = make([][]chunk, len(d.polygons))
+	for i, p := range d.polygons {
+		d.chunks[i] = chunkPolygon(p)
+	}
+	return &d, nil
+}
+
+// LoadAirports reads just the airport shapefile, say to retry it after
+// Load couldn't. progress may be nil. Failures are a *LoadError.
+func LoadAirports(path string, progress *Progress) ([]*shp.Point, error) {
+	if progress == nil {
+		progress = &Progress{}
+	}
+	points, err := loadAirportData(path, progress)
+	if err != nil {
+		return nil, diagnose("airports", path, shp.POINT, err)
+	}
+	return points, nil
+}
+
+// loadMapData reads the basemap polygons and the box around all of them
+func loadMapData(path string, progress *Progress) ([]*shp.Polygon, shp.Box, error) {
+	recs, err := readRecords(path, progress)
+	if err != nil {
+		return nil, shp.Box{}, err
+	}
+	polygons, err := parseAll(recs, progress, parsePolygon)
+	if err != nil {
+		return nil, shp.Box{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(polygons) == 0 {
+		return nil, shp.Box{}, fmt.Errorf("no polygons found in shapefile")
+	}
+
+	bounds := polygons[0].Box
+	for _, p := range polygons[1:] {
+		bounds.Extend(p.Box)
+	}
+	return polygons, bounds, nil
+}
+
+// readRecords reads a .shp file and splits it into its records' contents
+func readRecords(path string, progress *Progress) ([][]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open shapefile: %w", err)
+	}
+	if len(data) < shpHeaderLen {
+		return nil, fmt.Errorf("%s: too short to be a shapefile", path)
+	}
+
+	// Each record is a big-endian number and length (in 16-bit words),
+	// then that many words of content
+	var recs [][]byte
+	for off := shpHeaderLen; off+8 <= len(data); {
+		n := int(binary.BigEndian.Uint32(data[off+4:])) * 2
+		start := off + 8
+		if start+n > len(data) {
+			return nil, fmt.Errorf("%s: record at byte %d runs past the end of the file", path, off)
+		}
+		recs = append(recs, data[start:start+n])
+		off = start + n
+	}
+	progress.total.Add(int64(len(recs)))
+	return recs, nil
+}
+
+// parseAll parses every record across all cores, keeping them in order.
+// parse returns nil for records it skips (null shapes, say).
+func parseAll[T any](recs [][]byte, progress *Progress, parse func([]byte) (*T, error)) ([]*T, error) {
+	out := make([]*T, len(recs))
+	workers := runtime.GOMAXPROCS(0)
+	per := (len(recs) + workers - 1) / workers
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for start := 0; start < len(recs); start += per {
+		end := min(start+per, len(recs))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := start; i < end; i++ {
+				v, err := parse(recs[i])
+				if err != nil {
+					errOnce.Do(func() { firstErr = fmt.Errorf("record %d: %w", i+1, err) })
+					return
+				}
+				out[i] = v
+				if (i-start)%256 == 255 {
+					progress.done.Add(256)
+				}
+			}
+			progress.done.Add(int64((end - start) % 256))
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	// Squeeze out the skipped records
+	kept := out[:0]
+	for _, v := range out {
+		if v != nil {
+			kept = append(kept, v)
+		}
+	}
+	return kept, nil
+}
+
+// parsePolygon decodes a polygon record. Other shape types are skipped.
+func parsePolygon(rec []byte) (*shp.Polygon, error) {
+	if len(rec) < 4 || shp.ShapeType(le32(rec)) != shp.POLYGON {
+		return nil, nil
+	}
+	if len(rec) < 44 {
+		return nil, fmt.Errorf("polygon record too short")
+	}
+
+	p := &shp.Polygon{
+		Box:       shp.Box{MinX: f64(rec[4:]), MinY: f64(rec[12:]), MaxX: f64(rec[20:]), MaxY: f64(rec[28:])},
+		NumParts:  int32(le32(rec[36:])),
+		NumPoints: int32(le32(rec[40:])),
+	}
+	if p.NumParts < 0 || p.NumPoints < 0 || 44+int(p.NumParts)*4+int(p.NumPoints)*16 > len(rec) {
+		return nil, fmt.Errorf("polygon record says it has more parts or points than it holds")
+	}
+
+	off := 44
+	p.Parts = make([]int32, p.NumParts)
+	for i := range p.Parts {
+		p.Parts[i] = int32(le32(rec[off:]))
+		off += 4
+	}
+	p.Points = make([]shp.Point, p.NumPoints)
+	for i := range p.Points {
+		p.Points[i] = shp.Point{X: f64(rec[off:]), Y: f64(rec[off+8:])}
+		off += 16
+	}
+	return p, nil
+}
+
+// parsePoint decodes a point record. Other shape types are skipped.
+func parsePoint(rec []byte) (*shp.Point, error) {
+	if len(rec) < 4 || shp.ShapeType(le32(rec)) != shp.POINT {
+		return nil, nil
+	}
+	if len(rec) < 20 {
+		return nil, fmt.Errorf("point record too short")
+	}
+	return &shp.Point{X: f64(rec[4:]), Y: f64(rec[12:])}, nil
+}
+
+// le32 reads a little-endian uint32
+func le32(b []byte) uint32 {
+	return binary.LittleEndian.Uint32(b)
+}
+
+// f64 reads a little-endian float64
+func f64(b []byte) float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(b))
+}
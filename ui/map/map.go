@@ -1,14 +1,16 @@
 package mapview
 
 import (
-	"fmt"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/jonas-p/go-shp"
 
+	"termtrack/aircraftdb"
 	"termtrack/sbs"
+	"termtrack/ui/glyphs"
 )
 
 // Constants for Panning and Zooming
@@ -17,7 +19,13 @@ const (
 	zoomFactor = 1.2
 )
 
-const airportShapePath = "airportdata/ne_10m_airports.shp"
+// A value of 2.0 assumes chars are 2x tall as wide.
+// A smaller value (like 1.9 or 1.8) squashes the map less.
+// You said 2.0 was too wide, so I'm using 1.9.
+const charAspect = 1.9
+
+// DefaultAirportPath is where the airports shapefile lives unless told otherwise
+const DefaultAirportPath = "airportdata/ne_10m_airports.shp"
 
 // Model holds the map's state
 type Model struct {
@@ -25,97 +33,160 @@ type Model struct {
 	height int
 
 	mapPolygons   []*shp.Polygon
+	mapChunks     [][]chunk // Each polygon's outline in boxed runs, for clipping
 	airportPoints []*shp.Point
+	airportNames  []Airport // For labels, once they're loaded
 	aircraft      map[string]*sbs.Aircraft
 	originalBounds shp.Box
 	viewBounds     shp.Box
 
+	glyphs     glyphs.Set
+	dataBlocks bool // Radar-style multi-line labels instead of just callsigns
+	showTrails bool
+	colorMode  colorMode
+	braille    bool          // Trails and planes as braille dots, for finer positions
+	selected   string        // ICAO of the selected aircraft, drawn highlighted
+	trailFade  time.Duration // Trail points older than this are drawn dimmer
+	vectorTime time.Duration // How far ahead leader lines point, 0 for none
+	vectors    bool          // Leader lines on or off
+	at         time.Time     // The moment on screen, zero when live
+	timeouts   sbs.Timeouts  // How long aircraft can go unheard before they're drawn stale
+	layers     Layers
+
+	categoryGlyphs map[string]string // Emitter category -> plane glyph
+	approaches     []Approach
+	heliMode       bool // Just the helicopters, with hovering and circling noted
+	compact        bool // No frame and the shortest labels, for tiny terminals
+	inactive       bool // Keys go to another map beside this one; see SetInactive
+	densityLimit   int  // Aircraft in view past which labels and trails go, 0 for no limit
+
+	db       *aircraftdb.DB // Types for the labels, nil for none
+	receiver *[2]float64    // Lat, lon the range rings go round, nil for none
+
+	// --- Crosshair ---
+	crosshair bool // Arrow keys move the crosshair, zoom centers on it
+	crossX    int
+	crossY    int
+
 	// --- Caching ---
-	cachedStaticGrid [][]string
-	needsRedraw      bool
+	// The static layers are kept for as long as nothing they're drawn
+	// from changes. A pointer, so View() can fill it.
+	cache          *staticCache
+	contentVersion int // Bumped by anything but the view that changes them
 	// ---------------
 }
 
-// loadMapData reads the shapefile and computes bounding box manually
-func loadMapData(path string) ([]*shp.Polygon, shp.Box, error) {
-	shapeFile, err := shp.Open(path)
-	if err != nil {
-		return nil, shp.Box{}, fmt.Errorf("failed to open shapefile: %w", err)
+// New creates a new map model. It draws nothing until SetData gives it
+// the shapefiles from Load.
+func New() Model {
+	return Model{
+		aircraft:   make(map[string]*sbs.Aircraft),
+		width:      80,
+		height:     23,
+		cache:      &staticCache{},
+		glyphs:     glyphs.Unicode,
+		showTrails: true,
+		trailFade:  DefaultTrailFade,
+		vectorTime: DefaultVectorTime,
+		vectors:    true,
+		layers:     defaultLayers,
+
+		densityLimit: DefaultDensityLimit,
 	}
-	defer shapeFile.Close()
+}
 
-	var polygons []*shp.Polygon
-	bounds := shp.Box{
-		MinX: 1e9,
-		MinY: 1e9,
-		MaxX: -1e9,
-		MaxY: -1e9,
-	}
+// SetAirports replaces the airports layer, e.g. once a retry loads it
+func (m *Model) SetAirports(points []*shp.Point) {
+	m.airportPoints = points
+	m.contentVersion++
+}
 
-	for shapeFile.Next() {
-		_, shape := shapeFile.Shape()
-		polygon, ok := shape.(*shp.Polygon)
-		if !ok {
-			continue
-		}
-		polygons = append(polygons, polygon)
+// SetAirportNames gives the map the airports' codes and ranks, so they
+// can be labelled
+func (m *Model) SetAirportNames(airports []Airport) {
+	m.airportNames = airports
+}
 
-		// Compute bounding box manually
-		for _, p := range polygon.Points {
-			if p.X < bounds.MinX {
-				bounds.MinX = p.X
-			}
-			if p.X > bounds.MaxX {
-				bounds.MaxX = p.X
-			}
-			if p.Y < bounds.MinY {
-				bounds.MinY = p.Y
-			}
-			if p.Y > bounds.MaxY {
-				bounds.MaxY = p.Y
-			}
-		}
-	}
+// SetTrails turns the trails on or off, as T does
+func (m *Model) SetTrails(on bool) {
+	m.showTrails = on
+}
 
-	if len(polygons) == 0 {
-		return nil, shp.Box{}, fmt.Errorf("no polygons found in shapefile")
-	}
+// SetCompact draws the map for a tiny terminal: no frame, and callsigns
+// cut to the flight number with no data blocks
+func (m *Model) SetCompact(on bool) {
+	m.compact = on
+}
 
-	return polygons, bounds, nil
+// SetData gives the map its static layers and resets the view to show
+// all of them
+func (m *Model) SetData(d *Data) {
+	m.mapPolygons = d.polygons
+	m.mapChunks = d.chunks
+	m.airportPoints = d.airports
+	m.originalBounds = d.bounds
+	m.viewBounds = d.bounds
+	m.contentVersion++
 }
 
-// New creates a new map model
-func New(mapShapePath string) (Model, error) {
-	// 1. Load polygons (map data)
-	polygons, bounds, err := loadMapData(mapShapePath)
-	if err != nil {
-		return Model{}, err
-	}
+func (m Model) Init() tea.Cmd {
+	return nil
+}
 
-	// 2. Load points (airport data) from airports.go
-	points, err := loadAirportData(airportShapePath)
-	if err != nil {
-		return Model{}, fmt.Errorf("failed to load airport data: %w", err)
-	}
+// SetGlyphs switches the glyph set used to draw the map
+func (m *Model) SetGlyphs(g glyphs.Set) {
+	m.glyphs = g
+	m.braille = m.braille && g.Braille
+	m.contentVersion++
+}
 
-	return Model{
-		mapPolygons:   polygons,
-		airportPoints: points,
-		aircraft:      make(map[string]*sbs.Aircraft),
-		originalBounds: bounds,
-		viewBounds:    bounds,
-		width:         80,
-		height:        23,
-		needsRedraw:   true,
-	}, nil
+// SetVectorTime sets how far ahead leader lines point; 0 turns them off
+func (m *Model) SetVectorTime(d time.Duration) {
+	m.vectorTime = d
 }
 
-func (m Model) Init() tea.Cmd {
-	return nil
+// SetTrailFade sets how long trail points stay bright before dimming
+func (m *Model) SetTrailFade(d time.Duration) {
+	m.trailFade = d
+}
+
+// SetTime sets the moment the picture shows, for ageing trails when
+// replaying history. The zero time means live.
+func (m *Model) SetTime(t time.Time) {
+	m.at = t
+}
+
+// SetTimeouts sets how long aircraft from each source can go unheard
+// before they're drawn dimmed, as stale
+func (m *Model) SetTimeouts(t sbs.Timeouts) {
+	m.timeouts = t
+}
+
+// SetAircraftDB sets the database the labels look types up in, nil for
+// none
+func (m *Model) SetAircraftDB(db *aircraftdb.DB) {
+	m.db = db
+}
+
+// now is the time the picture is drawn at
+func (m Model) now() time.Time {
+	if m.at.IsZero() {
+		return time.Now()
+	}
+	return m.at
+}
+
+// SetSelected sets which aircraft is highlighted ("" for none)
+func (m *Model) SetSelected(icao string) {
+	m.selected = icao
 }
 
-// UpdateAircraft receives the master list from main.go
+// UpdateAircraft receives this frame's aircraft from main.go. They're a
+// snapshot: nothing changes the map or the aircraft in it afterwards.
 func (m *Model) UpdateAircraft(allAircraft map[string]*sbs.Aircraft) {
+	if m.heliMode {
+		allAircraft = m.heliAircraft(allAircraft)
+	}
 	m.aircraft = allAircraft
 }
 
@@ -143,8 +214,6 @@ func (m *Model) SetViewToLocation(lat, lon float64) {
 	m.viewBounds.MaxX = lon + halfWidth
 	m.viewBounds.MinY = lat - halfHeight
 	m.viewBounds.MaxY = lat + halfHeight
-
-	m.needsRedraw = true
 }
 
 // zoom zooms the viewBounds in or out, centered on the current view
@@ -159,7 +228,6 @@ func (m *Model) zoom(factor float64) {
 
 	if newWidth > (m.originalBounds.MaxX-m.originalBounds.MinX) || newHeight > (m.originalBounds.MaxY-m.originalBounds.MinY) {
 		m.viewBounds = m.originalBounds
-		m.needsRedraw = true
 		return
 	}
 
@@ -167,7 +235,6 @@ func (m *Model) zoom(factor float64) {
 	m.viewBounds.MaxX = centerX + (newWidth / 2)
 	m.viewBounds.MinY = centerY - (newHeight / 2)
 	m.viewBounds.MaxY = centerY + (newHeight / 2)
-	m.needsRedraw = true
 }
 
 // pan moves the viewBounds
@@ -182,7 +249,12 @@ func (m *Model) pan(dx, dy float64) {
 	m.viewBounds.MaxX += panX
 	m.viewBounds.MinY += panY
 	m.viewBounds.MaxY += panY
-	m.needsRedraw = true
+	m.wrapView()
+}
+
+// Center returns the lat/lon at the middle of the current view
+func (m Model) Center() (lat, lon float64) {
+	return (m.viewBounds.MinY + m.viewBounds.MaxY) / 2, (m.viewBounds.MinX + m.viewBounds.MaxX) / 2
 }
 
 // GetZoomLevel returns the current zoom factor
@@ -194,16 +266,63 @@ func (m Model) GetZoomLevel() float64 {
 	return (m.originalBounds.MaxX - m.originalBounds.MinX) / (m.viewBounds.MaxX - m.viewBounds.MinX)
 }
 
+// SetZoom zooms to the given level, as GetZoomLevel reports it, keeping
+// the same center
+func (m *Model) SetZoom(level float64) {
+	if level <= 0 {
+		return
+	}
+	m.zoom(m.GetZoomLevel() / level)
+}
+
 // Update handles key and window messages
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		m.needsRedraw = true
+
+	case tea.MouseMsg:
+		m.mouseCrosshair(msg)
 
 	case tea.KeyMsg:
+		// In crosshair mode the arrows move the crosshair, and zooming
+		// centers on it
+		if m.crosshair {
+			switch msg.String() {
+			case "up":
+				m.moveCrosshair(0, -1)
+				return m, nil
+			case "down":
+				m.moveCrosshair(0, 1)
+				return m, nil
+			case "left":
+				m.moveCrosshair(-1, 0)
+				return m, nil
+			case "right":
+				m.moveCrosshair(1, 0)
+				return m, nil
+			case "K":
+				m.zoomOnCrosshair(1 / zoomFactor)
+				return m, nil
+			case "L":
+				m.zoomOnCrosshair(zoomFactor)
+				return m, nil
+			}
+		}
+
 		switch msg.String() {
+		case "x":
+			m.toggleCrosshair()
+			// The mouse moves the crosshair too, but only while it's up:
+			// holding on to it the rest of the time would stop the
+			// terminal selecting text
+			if m.crosshair {
+				return m, tea.EnableMouseAllMotion
+			}
+			return m, tea.DisableMouse
+		case "B":
+			m.toggleBraille()
 		case "k", "up":
 			m.pan(0, panFactor)
 		case "l", "down":
@@ -218,7 +337,18 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 			m.zoom(zoomFactor)
 		case "r":
 			m.viewBounds = m.originalBounds
-			m.needsRedraw = true
+		case "b":
+			m.dataBlocks = !m.dataBlocks
+		case "T":
+			m.showTrails = !m.showTrails
+		case "V":
+			m.vectors = !m.vectors
+		case "f":
+			m.fitTrail(m.selected)
+		case "H":
+			m.heliMode = !m.heliMode
+		case "c":
+			m.colorMode = (m.colorMode + 1) % numColorModes
 		}
 	}
 
@@ -230,6 +360,13 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 // ---
 // project converts lon/lat to terminal x/y coordinates
 func (m *Model) project(lon, lat float64, viewWidth, viewHeight int) (int, int) {
+	x, y := m.projectF(lon, lat, viewWidth, viewHeight)
+	return int(x), int(y)
+}
+
+// projectF is project without rounding to a cell, for drawing finer
+// than a cell (braille dots)
+func (m *Model) projectF(lon, lat float64, viewWidth, viewHeight int) (float64, float64) {
 	if m.viewBounds.MaxX == m.viewBounds.MinX {
 		m.viewBounds.MaxX += 1e-6
 	}
@@ -241,40 +378,43 @@ func (m *Model) project(lon, lat float64, viewWidth, viewHeight int) (int, int)
 	x := (lon - m.viewBounds.MinX) / (m.viewBounds.MaxX - m.viewBounds.MinX)
 	y := (m.viewBounds.MaxY - lat) / (m.viewBounds.MaxY - m.viewBounds.MinY)
 
-	// A value of 2.0 assumes chars are 2x tall as wide.
-	// A smaller value (like 1.9 or 1.8) squashes the map less.
-	// You said 2.0 was too wide, so I'm using 1.9.
-	const charAspect = 1.9
-
 	// We DIVIDE x by the aspect ratio to "squash" the wide horizontal axis
-	tuiX := int(x * float64(viewWidth) / charAspect)
-	tuiY := int(y * float64(viewHeight))
+	tuiX := x * float64(viewWidth) / charAspect
+	tuiY := y * float64(viewHeight)
 	return tuiX, tuiY
 }
 
-// copyGrid duplicates a 2D string slice
-func (m *Model) copyGrid(source [][]string) [][]string {
-	if source == nil {
-		return nil
-	}
-
-	height := len(source)
-	if height == 0 {
-		return [][]string{}
+// placeLabel picks where a label of width w goes for a plane at px,py.
+// Labels sit one row below the plane, starting at its column; near the
+// right edge they're pulled left so they end under the plane, and on the
+// bottom row they move above it.
+func placeLabel(px, py, w, viewWidth, viewHeight int) (x, y int, ok bool) {
+	y = py + 1
+	if y >= viewHeight {
+		y = py - 1
 	}
-	width := len(source[0])
-	if width == 0 {
-		return make([][]string, height)
+	if y < 0 {
+		return 0, 0, false // Single-row view, nowhere to put it
 	}
 
-	dest := make([][]string, height)
-	for i := range dest {
-		dest[i] = make([]string, width)
-		copy(dest[i], source[i])
+	x = px
+	if x+w > viewWidth {
+		x = px - w + 1
+	}
+	if x < 0 {
+		x = 0 // Wider than the view; drawText clips the end
 	}
-	return dest
+	return x, y, true
 }
 
+// Styles for the aircraft, their labels and the crosshair; the legend
+// shows them too
+var (
+	planeStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("81"))             // Bright Purple/Blue
+	callsignStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("86"))             // Cyan
+	crossStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true) // Red
+)
+
 // renderMapViewport generates ASCII map
 func (m *Model) renderMapViewport(viewWidth, viewHeight int) string {
 	if viewWidth <= 0 {
@@ -284,62 +424,79 @@ func (m *Model) renderMapViewport(viewWidth, viewHeight int) string {
 		viewHeight = 1
 	}
 
-	// --- Define styles for map elements ---
-	mapStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("255"))     // Bright White
-	airportStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("220")) // Yellow
-	planeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("81"))  // Bright Purple/Blue
-	callsignStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("86")) // Cyan
-
-
-	// --- 1. Render static map only once or on pan/zoom ---
-	if m.needsRedraw || m.cachedStaticGrid == nil || len(m.cachedStaticGrid) != viewHeight || len(m.cachedStaticGrid[0]) != viewWidth {
-
-		grid := make([][]string, viewHeight)
-		for i := range grid {
-			grid[i] = make([]string, viewWidth)
-			for j := range grid[i] {
-				grid[i][j] = " "
-			}
+	// Every layer gets its own grid, stacked in the configured order
+	c := newCompositor(viewWidth, viewHeight, layerOrder(m.layers.Order))
+	for _, name := range m.layers.Hidden {
+		if id, err := parseLayer(name); err == nil {
+			c.hide(id)
 		}
+	}
+	if !m.showTrails {
+		c.hide(layerTrails)
+	}
+	if !m.vectors {
+		c.hide(layerVectors)
+	}
+	// Too many aircraft to tell apart: just the planes, and the selected
+	// one's label
+	crowded := m.crowded(viewWidth, viewHeight)
+	if crowded > 0 {
+		c.hide(layerTrails)
+		c.hide(layerVectors)
+	}
 
-		// Draw Polygons
-		for _, polygon := range m.mapPolygons {
-			polyBounds := polygon.BBox()
-			if polyBounds.MaxX < m.viewBounds.MinX ||
-				polyBounds.MinX > m.viewBounds.MaxX ||
-				polyBounds.MaxY < m.viewBounds.MinY ||
-				polyBounds.MinY > m.viewBounds.MaxY {
-				continue
-			}
+	// --- 1. Render static layers only when what they show changes ---
+	key := staticKey{bounds: m.viewBounds, width: viewWidth, height: viewHeight, content: m.contentVersion}
+	if !m.cache.valid || m.cache.key != key {
+		m.cache.layers = m.drawStaticLayers(viewWidth, viewHeight)
+		m.cache.key, m.cache.valid = key, true
+	}
+	for _, id := range staticLayers {
+		c.set(id, m.cache.layers[id])
+	}
 
-			step := 3
-			for i := 0; i < len(polygon.Points); i += step {
-				point := polygon.Points[i]
-				x, y := m.project(point.X, point.Y, viewWidth, viewHeight)
-				if x >= 0 && x < viewWidth && y >= 0 && y < viewHeight {
-					grid[y][x] = mapStyle.Render(".")
-				}
-			}
+	// --- 2. Draw trails under the aircraft ---
+	// In braille mode trails and planes are dots on a finer canvas, a cell
+	// only has one color, so they all go in the aircraft layer once
+	// they're plotted
+	var canvas *brailleCanvas
+	if m.braille {
+		canvas = newBrailleCanvas(viewWidth, viewHeight)
+	}
+	if c.visible(layerTrails) {
+		if canvas != nil {
+			m.plotTrails(canvas, c.layer(layerAircraft), viewWidth, viewHeight)
+		} else {
+			m.drawTrails(c.layer(layerTrails), viewWidth, viewHeight)
 		}
+	}
 
-		// Draw Airports
-		for _, point := range m.airportPoints {
-			x, y := m.project(point.X, point.Y, viewWidth, viewHeight)
-			if x >= 0 && x < viewWidth && y >= 0 && y < viewHeight {
-				grid[y][x] = airportStyle.Render("*")
-			}
+	// Leader lines go over trails but under every plane
+	if c.visible(layerVectors) {
+		g := c.layer(layerVectors)
+		if canvas != nil {
+			g = c.layer(layerAircraft)
+		}
+		vectorStyle := func(icao string) styleID {
+			return g.style(m.aircraftStyle(m.aircraft[icao], planeStyle))
+		}
+		if canvas != nil {
+			m.plotVectors(canvas, vectorStyle, viewWidth, viewHeight)
+		} else {
+			m.drawVectors(g, vectorStyle, viewWidth, viewHeight)
 		}
-
-		// Save static grid to cache
-		m.cachedStaticGrid = grid
-		m.needsRedraw = false
 	}
 
-	// --- 2. Copy cached static grid ---
-	grid := m.copyGrid(m.cachedStaticGrid)
-	if grid == nil { // Failsafe
-		grid = make([][]string, viewHeight)
-		for i := range grid { grid[i] = make([]string, viewWidth) }
+	// Rings round the positions that might be off, for MLAT and for those
+	// not heard from in a while, under the planes they're round
+	if c.visible(layerRings) {
+		g := c.layer(layerRings)
+		if canvas != nil {
+			g = c.layer(layerAircraft)
+			m.plotRings(canvas, g.style(ringStyle), viewWidth, viewHeight)
+		} else {
+			m.drawRings(g, g.style(ringStyle), viewWidth, viewHeight)
+		}
 	}
 
 	// --- 3. Draw Aircraft (Icons, then Labels) ---
@@ -351,65 +508,200 @@ func (m *Model) renderMapViewport(viewWidth, viewHeight int) string {
 	}
 	planePositions := make(map[string]planePosition) // ICAO -> position
 
+	planes := c.layer(layerAircraft)
 	for icao, ac := range m.aircraft {
 		if ac.Lat == 0 && ac.Lon == 0 {
 			continue
 		}
-		x, y := m.project(ac.Lon, ac.Lat, viewWidth, viewHeight)
-		if x >= 0 && x < viewWidth && y >= 0 && y < viewHeight {
-			grid[y][x] = planeStyle.Render("✈")
-			planePositions[icao] = planePosition{x: x, y: y}
+		st := m.aircraftStyle(ac, planeStyle)
+		if icao == m.selected {
+			st = st.Reverse(true).Bold(true)
 		}
-	}
-
-	// Pass 2: Draw callsigns under the icons
-	for icao, pos := range planePositions {
-		ac := m.aircraft[icao] // Get the full aircraft data
-		if ac.Callsign == "" {
-			continue // No callsign to draw
+		// An emergency blinks, by going in and out of reverse; the wall
+		// clock's, so it blinks in replay and while frozen too
+		if ac.SquawkEmergency() != "" && blinkOn(time.Now()) {
+			st = st.Reverse(icao != m.selected)
 		}
-
-		// Calculate position for the callsign (one row below)
-		yi := pos.y + 1
-
-		// Stop if the callsign row is off-screen
-		if yi >= viewHeight {
+		style := planes.style(st)
+		if canvas != nil {
+			if x, y, ok := m.plotPlane(canvas, m.nearView(ac.Lon), ac.Lat, style, viewWidth, viewHeight); ok {
+				planePositions[icao] = planePosition{x: x, y: y}
+			}
 			continue
 		}
+		x, y := m.project(m.nearView(ac.Lon), ac.Lat, viewWidth, viewHeight)
+		if setCell(planes, x, y, m.planeGlyph(ac), style) {
+			planePositions[icao] = planePosition{x: x, y: y}
+		}
+	}
+	if canvas != nil {
+		canvas.draw(planes)
+	}
 
-		// Draw the callsign character by character
-		callsignRunes := []rune(ac.Callsign)
-		for i, r := range callsignRunes {
-			xi := pos.x + i // Start at the same X as the plane
+	// Pass 2: Label everything that has a label: callsigns (or data
+	// blocks), approach names and airport codes. They all want the same
+	// space, so the manager hands it out by priority, selected plane
+	// first, and they keep off whatever's drawn below them.
+	if c.visible(layerLabels) {
+		labels := c.layer(layerLabels)
+		// Rings are only a hint, so labels can go over them
+		labels.under = c.below(layerLabels, layerRings)
+		var lm labelManager
+		for icao, pos := range planePositions {
+			if crowded > 0 && icao != m.selected {
+				continue
+			}
+			ac := m.aircraft[icao] // Get the full aircraft data
+			r := labelRequest{
+				priority: priorityAircraft,
+				key:      icao,
+				x:        pos.x,
+				y:        pos.y,
+				always:   true,
+				style:    labels.style(m.aircraftStyle(ac, callsignStyle)),
+			}
+			if icao == m.selected {
+				r.priority = prioritySelected
+			}
 
-			// Stop if we go off the right side of the screen
-			if xi >= viewWidth {
-				break
+			tag := ""
+			if m.heliMode {
+				tag = heliTag(ac, m.now())
 			}
 
-			// Only draw if the cell is empty (so we don't overwrite map lines)
-			if grid[yi][xi] == " " {
-				grid[yi][xi] = callsignStyle.Render(string(r))
+			entry, _ := m.db.Lookup(icao)
+			if m.dataBlocks && !m.compact {
+				r.block = dataBlock(ac, entry.Type, m.glyphs)
+				if tag != "" {
+					r.block = append(r.block, tag)
+				}
+			} else {
+				label := strings.TrimSpace(ac.Callsign + " " + tag)
+				if label == "" {
+					continue // No callsign to draw
+				}
+				r.forms = callsignForms(label)
+				if alt := altitudeTag(ac, m.glyphs); alt != "" {
+					// With the altitude if there's room, else without
+					r.forms = append([]string{label + " " + alt}, r.forms...)
+				}
+				if entry.Type != "" {
+					// And the type before that, if there's room for it too
+					r.forms = append([]string{r.forms[0] + " " + entry.Type}, r.forms...)
+				}
+				if m.compact {
+					r.forms = r.forms[len(r.forms)-1:]
+				}
 			}
+			lm.add(r)
+		}
+		if c.visible(layerApproaches) {
+			m.addApproachLabels(&lm, labels.style(layerStyle(m.layers.Approaches)), viewWidth, viewHeight)
 		}
+		if c.visible(layerAirports) {
+			m.addAirportLabels(&lm, c.layer(layerAirports), labels.style(layerStyle(m.layers.Airports)), viewWidth, viewHeight)
+		}
+		lm.place(labels, viewWidth, viewHeight)
 	}
 
-	// --- 4. Convert to string ---
-	var b strings.Builder
-	for _, row := range grid {
-		b.WriteString(strings.Join(row, ""))
-		b.WriteRune('\n')
+	// --- 4. Crosshair goes on top of everything ---
+	if m.crosshair {
+		overlay := c.layer(layerOverlay)
+		setCell(overlay, m.crossX, m.crossY, m.glyphs.Crosshair, overlay.style(crossStyle))
 	}
-	return b.String()
+
+	// --- 5. Stack the layers and convert to string ---
+	out := c.flatten()
+	if crowded > 0 {
+		drawDensityNotice(out, crowded)
+	}
+	return out.String()
 }
 
+// drawStaticLayers draws the layers that only change with the view: the
+// basemap, graticule, airports, approach centerlines and range rings
+func (m *Model) drawStaticLayers(viewWidth, viewHeight int) [numLayers]*grid {
+	var layers [numLayers]*grid
+
+	// Draw Polygons, clipped to the screen before projecting, once
+	// for each copy of the world in view
+	g := newGrid(viewWidth, viewHeight)
+	basemapStyle := g.style(layerStyle(m.layers.Basemap))
+	screen := m.screenBounds()
+	shifts := m.worldShifts()
+	for i, polygon := range m.mapPolygons {
+		polyBounds := polygon.Box
+
+		// A configured step is fixed, otherwise it follows the zoom
+		step := m.layers.Basemap.Step
+		if step == 0 {
+			step = m.decimationStep(polyBounds, len(polygon.Points), viewWidth, viewHeight)
+		}
+		glyph := layerGlyph(m.layers.Basemap, m.glyphs.MapPoint)
+		m.drawPolygon(polygon, m.mapChunks[i], step, shifts, viewWidth, viewHeight, func(x, y int) {
+			setCell(g, x, y, glyph, basemapStyle)
+		})
+	}
+	layers[layerBasemap] = g
+
+	g = newGrid(viewWidth, viewHeight)
+	m.drawGraticule(g, viewWidth, viewHeight)
+	layers[layerGraticule] = g
+
+	// Draw Airports
+	g = newGrid(viewWidth, viewHeight)
+	airportStyle := g.style(layerStyle(m.layers.Airports))
+	airportGlyph := layerGlyph(m.layers.Airports, m.glyphs.Airport)
+	for _, shift := range shifts {
+		box := shiftBox(screen, -shift)
+		for i := 0; i < len(m.airportPoints); i += max(m.layers.Airports.Step, 1) {
+			point := m.airportPoints[i]
+			if !inBox(point.X, point.Y, box) {
+				continue
+			}
+			x, y := m.project(point.X+shift, point.Y, viewWidth, viewHeight)
+			setCell(g, x, y, airportGlyph, airportStyle)
+		}
+	}
+	layers[layerAirports] = g
+
+	// Approach centerlines go over the airports they lead to, their
+	// names keeping off both
+	g = newGrid(viewWidth, viewHeight)
+	g.under = newGrid(viewWidth, viewHeight)
+	g.under.overlay(layers[layerBasemap])
+	g.under.overlay(layers[layerAirports])
+	m.drawApproaches(g, viewWidth, viewHeight)
+	g.under = nil
+	layers[layerApproaches] = g
+
+	g = newGrid(viewWidth, viewHeight)
+	m.drawRangeRings(g, viewWidth, viewHeight)
+	layers[layerRangeRings] = g
+
+	return layers
+}
 
-func (m Model) View() string {
-	mapStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("63")).
+
+// frameStyle is the border drawn around the map
+func (m Model) frameStyle() lipgloss.Style {
+	if m.compact {
+		return lipgloss.NewStyle().Width(m.width).Height(m.height).MaxHeight(m.height)
+	}
+	frameColor := lipgloss.Color("63")
+	if m.inactive {
+		frameColor = "240"
+	}
+	return lipgloss.NewStyle().
+		Border(m.glyphs.Border).
+		BorderForeground(frameColor).
 		Width(m.width - 2).
 		Height(m.height - 2)
+}
+
+// viewSize returns the size of the map grid inside the frame
+func (m Model) viewSize() (int, int) {
+	mapStyle := m.frameStyle()
 
 	hBorders := mapStyle.GetBorderLeftSize() + mapStyle.GetBorderRightSize()
 	vBorders := mapStyle.GetBorderTopSize() + mapStyle.GetBorderBottomSize()
@@ -425,8 +717,12 @@ func (m Model) View() string {
 	if mapViewHeight <= 0 {
 		mapViewHeight = 1
 	}
+	return mapViewWidth, mapViewHeight
+}
 
+func (m Model) View() string {
+	mapViewWidth, mapViewHeight := m.viewSize()
 	mapContent := m.renderMapViewport(mapViewWidth, mapViewHeight)
 
-	return mapStyle.Render(mapContent)
-}
\ No newline at end of file
+	return m.frameStyle().Render(mapContent)
+}
@@ -0,0 +1,76 @@
+package mapview
+
+import (
+	"math"
+
+	"termtrack/geo"
+)
+
+// rangeRingSpacings are the distances apart, in nautical miles, range
+// rings can be drawn; the zoom picks one
+var rangeRingSpacings = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000}
+
+// maxRangeRings is the most rings we'll draw, however far the view reaches
+const maxRangeRings = 20
+
+// SetReceiver sets where the range rings are drawn around
+func (m *Model) SetReceiver(lat, lon float64) {
+	m.receiver = &[2]float64{lat, lon}
+	m.contentVersion++
+}
+
+// rangeRingSpacing is how far apart the rings are at this zoom: the
+// closest that still leaves no more than five across the longer half of
+// the view
+func (m *Model) rangeRingSpacing() float64 {
+	screen := m.screenBounds()
+	midLat := (screen.MinY + screen.MaxY) / 2
+	half := max(screen.MaxY-screen.MinY, (screen.MaxX-screen.MinX)*math.Cos(midLat*math.Pi/180)) * 60 / 2
+	for _, s := range rangeRingSpacings {
+		if half/s <= 5 {
+			return s
+		}
+	}
+	return rangeRingSpacings[len(rangeRingSpacings)-1]
+}
+
+// drawRangeRings draws rings every rangeRingSpacing miles round the
+// receiver, out as far as the corner of the view furthest from it
+func (m *Model) drawRangeRings(g *grid, viewWidth, viewHeight int) {
+	if m.receiver == nil {
+		return
+	}
+	style := g.style(layerStyle(m.layers.RangeRings))
+	glyph := layerGlyph(m.layers.RangeRings, m.glyphs.Trail)
+	step := max(m.layers.RangeRings.Step, 1)
+	lat, lon := m.receiver[0], m.nearView(m.receiver[1])
+
+	screen := m.screenBounds()
+	var reach float64
+	for _, corner := range [][2]float64{{screen.MinY, screen.MinX}, {screen.MinY, screen.MaxX}, {screen.MaxY, screen.MinX}, {screen.MaxY, screen.MaxX}} {
+		reach = max(reach, geo.Distance(lat, lon, corner[0], corner[1]))
+	}
+
+	// Enough points round each ring that neighbours are about a cell apart
+	spacing := m.rangeRingSpacing()
+	cellsPerNM := float64(viewHeight) / ((m.viewBounds.MaxY - m.viewBounds.MinY) * 60)
+	for i := 1; i <= maxRangeRings && float64(i-1)*spacing < reach; i++ {
+		r := float64(i) * spacing
+		points := min(max(int(2*math.Pi*r*cellsPerNM*2), 36), 4096)
+		n := 0
+		var px, py int
+		for j := 0; j <= points; j++ {
+			pLat, pLon := geo.Destination(lat, lon, 360*float64(j)/float64(points), r)
+			x, y := m.project(unwrapFrom(lon, pLon), pLat, viewWidth, viewHeight)
+			if j > 0 {
+				line(px, py, x, y, func(x, y int) {
+					if n%step == 0 {
+						setCell(g, x, y, glyph, style)
+					}
+					n++
+				})
+			}
+			px, py = x, y
+		}
+	}
+}
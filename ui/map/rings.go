@@ -0,0 +1,96 @@
+package mapview
+
+import (
+	"math"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"termtrack/geo"
+	"termtrack/sbs"
+)
+
+// ringAge is how old a position can get before it's drawn with a ring
+// round it, whatever its source. ADS-B sends a couple a second.
+const ringAge = 10 * time.Second
+
+// maxRing is the widest a ring is drawn, in cells, however long the
+// aircraft's gone without a position: past that it's anyone's guess
+const maxRing = 8
+
+// sourceError is about how far out a fresh position from each source can
+// be, in nm. ADS-B and UAT positions are GPS fixes, near enough exact;
+// multilateration is off by a few hundred metres at best.
+var sourceError = map[sbs.Source]float64{
+	sbs.SourceMLAT: 0.25,
+}
+
+// ringStyle is the color of the rings, quiet so they don't compete with
+// the planes
+var ringStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("242"))
+
+// positionError is how far an aircraft might be from where it's drawn, in
+// nm: its source's error, plus however far it could have flown since its
+// last position. ok is false if that's not worth a ring.
+func (m *Model) positionError(ac *sbs.Aircraft) (nm float64, ok bool) {
+	if ac.Has&sbs.HasPosition == 0 || ac.PositionAt.IsZero() {
+		return 0, false
+	}
+	age := m.now().Sub(ac.PositionAt)
+	if ac.Source != sbs.SourceMLAT && age < ringAge {
+		return 0, false
+	}
+	return sourceError[ac.Source] + ac.SmoothSpeed*max(age, 0).Hours(), true
+}
+
+// eachRing calls fn with every ring to draw: where its aircraft is, and
+// how far it reaches across and down, in cells. A ring is always at least
+// a cell out, so it clears the plane it's round.
+func (m *Model) eachRing(viewWidth, viewHeight int, fn func(x, y, rx, ry float64)) {
+	for _, ac := range m.aircraft {
+		nm, ok := m.positionError(ac)
+		if !ok {
+			continue
+		}
+		lon := m.nearView(ac.Lon)
+		x, y := m.projectF(lon, ac.Lat, viewWidth, viewHeight)
+		nLat, _ := geo.Destination(ac.Lat, ac.Lon, 0, nm)
+		_, eLon := geo.Destination(ac.Lat, ac.Lon, 90, nm)
+		ex, _ := m.projectF(unwrapFrom(lon, eLon), ac.Lat, viewWidth, viewHeight)
+		_, ny := m.projectF(lon, nLat, viewWidth, viewHeight)
+		rx := min(max(ex-x, 1), maxRing)
+		ry := min(max(y-ny, 1), maxRing)
+		fn(x, y, rx, ry)
+	}
+}
+
+// ringPoints calls fn for the points of an ellipse round x, y, close
+// enough together to join up on a grid of unit cells
+func ringPoints(x, y, rx, ry float64, fn func(x, y int)) {
+	n := max(8, int(4*math.Pi*max(rx, ry)))
+	for i := range n {
+		a := 2 * math.Pi * float64(i) / float64(n)
+		fn(int(math.Floor(x+rx*math.Cos(a))), int(math.Floor(y+ry*math.Sin(a))))
+	}
+}
+
+// drawRings draws the rings onto the grid, clear of the planes' own cells
+func (m *Model) drawRings(g *grid, style styleID, viewWidth, viewHeight int) {
+	m.eachRing(viewWidth, viewHeight, func(x, y, rx, ry float64) {
+		px, py := int(x), int(y)
+		ringPoints(x, y, rx, ry, func(cx, cy int) {
+			if cx != px || cy != py {
+				setCell(g, cx, cy, m.glyphs.Ring, style)
+			}
+		})
+	})
+}
+
+// plotRings draws the rings as dots
+func (m *Model) plotRings(c *brailleCanvas, style styleID, viewWidth, viewHeight int) {
+	m.eachRing(viewWidth, viewHeight, func(x, y, rx, ry float64) {
+		ringPoints(x*2, y*4, rx*2, ry*4, func(dx, dy int) {
+			c.dot(dx, dy, style)
+		})
+	})
+}
@@ -0,0 +1,53 @@
+package mapview
+
+import (
+	"time"
+
+	"termtrack/sbs"
+)
+
+// rotorcraftCategory is the ADS-B emitter category for helicopters
+const rotorcraftCategory = "A7"
+
+// heliStale is how long an aircraft can go unheard before helicopter mode
+// stops drawing it. Helicopters fly low and drop out of coverage often, so
+// a long-gone one is more likely landed than still out there.
+const heliStale = 30 * time.Second
+
+// SetHelicopterMode turns helicopter mode on or off; see heliShown
+func (m *Model) SetHelicopterMode(on bool) {
+	m.heliMode = on
+}
+
+// heliShown reports whether helicopter mode draws an aircraft: anything
+// heard recently that isn't known to be something other than a rotorcraft.
+// SBS doesn't say what anything is, so over SBS that's everything recent.
+func (m *Model) heliShown(ac *sbs.Aircraft, now time.Time) bool {
+	if ac.Category != "" && ac.Category != rotorcraftCategory {
+		return false
+	}
+	return now.Sub(ac.LastSeen) <= heliStale
+}
+
+// heliAircraft is the aircraft helicopter mode draws
+func (m *Model) heliAircraft(all map[string]*sbs.Aircraft) map[string]*sbs.Aircraft {
+	now := m.now()
+	shown := make(map[string]*sbs.Aircraft, len(all))
+	for icao, ac := range all {
+		if m.heliShown(ac, now) {
+			shown[icao] = ac
+		}
+	}
+	return shown
+}
+
+// heliTag is the manoeuvre helicopter mode notes after a callsign, if any
+func heliTag(ac *sbs.Aircraft, now time.Time) string {
+	switch {
+	case ac.Hovering():
+		return "HOVER"
+	case ac.Circling(now):
+		return "CIRCLING"
+	}
+	return ""
+}
@@ -0,0 +1,26 @@
+package mapview
+
+// splitZoom is how much closer a split-off map starts than the one it's
+// split from: close enough to tell a second view from the first
+const splitZoom = 4
+
+// Split is a second view of the same map, starting closer in on the same
+// spot: it shares the shapefiles and aircraft, but has bounds, layers and
+// a cache of its own, so the two can be panned, zoomed and toggled apart
+func (m Model) Split() Model {
+	m.cache = &staticCache{}
+	m.SetZoom(m.GetZoomLevel() * splitZoom)
+	return m
+}
+
+// SetInactive dims the frame, for a map beside another that keys go to
+// instead
+func (m *Model) SetInactive(on bool) {
+	m.inactive = on
+}
+
+// Width is how many columns the map takes, frame and all, for finding
+// where the one beside it starts
+func (m Model) Width() int {
+	return m.width
+}
@@ -0,0 +1,115 @@
+package mapview
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// DefaultTrailFade is how long trail points stay at full brightness
+const DefaultTrailFade = 2 * time.Minute
+
+// trailColor picks a color for a trail point of the given age: the fresh
+// color within the fade window, then dimming through the grey ramp until
+// it bottoms out at three times the window
+func trailColor(age, fade time.Duration, fresh lipgloss.Color) lipgloss.Color {
+	if fade <= 0 || age <= fade {
+		return fresh
+	}
+
+	// 250 (light grey) down to 238 (dark grey) over the next two windows
+	t := float64(age-fade) / float64(2*fade)
+	if t > 1 {
+		t = 1
+	}
+	return lipgloss.Color(strconv.Itoa(250 - int(t*12)))
+}
+
+// drawTrails draws every aircraft's trail onto the grid, joining
+// consecutive points with a line so zoomed-in trails don't break up
+func (m *Model) drawTrails(g *grid, viewWidth, viewHeight int) {
+	glyph := layerGlyph(m.layers.Trails, m.glyphs.Trail)
+	m.eachTrailSegment(g, func(lon0, lat0, lon1, lat1 float64, style styleID) {
+		x0, y0 := m.project(lon0, lat0, viewWidth, viewHeight)
+		x1, y1 := m.project(lon1, lat1, viewWidth, viewHeight)
+		line(x0, y0, x1, y1, func(x, y int) {
+			setCell(g, x, y, glyph, style)
+		})
+	})
+}
+
+// eachTrailSegment calls fn for every leg of every trail, oldest first,
+// with the style (in g's table) its age calls for
+func (m *Model) eachTrailSegment(g *grid, fn func(lon0, lat0, lon1, lat1 float64, style styleID)) {
+	now := m.now()
+	fresh := lipgloss.Color(m.layers.Trails.Color)
+
+	// Styles are cached per color; there are only a handful
+	styles := make(map[lipgloss.Color]styleID)
+
+	for _, ac := range m.aircraft {
+		if len(ac.Trail) == 0 {
+			continue
+		}
+		// Anchor the newest point on the same copy of the world as the
+		// plane, then work back so the trail crosses ±180 the short way
+		lons := make([]float64, len(ac.Trail))
+		lons[len(lons)-1] = m.nearView(ac.Trail[len(lons)-1].Lon)
+		for i := len(lons) - 2; i >= 0; i-- {
+			lons[i] = unwrapFrom(lons[i+1], ac.Trail[i].Lon)
+		}
+
+		for i := 1; i < len(ac.Trail); i++ {
+			from, to := ac.Trail[i-1], ac.Trail[i]
+			color := trailColor(now.Sub(to.At), m.trailFade, fresh)
+			style, ok := styles[color]
+			if !ok {
+				style = g.style(lipgloss.NewStyle().Foreground(color))
+				styles[color] = style
+			}
+			fn(lons[i-1], from.Lat, lons[i], to.Lat, style)
+		}
+	}
+}
+
+// line calls plot for every cell on the line between two cells (Bresenham)
+func line(x0, y0, x1, y1 int, plot func(x, y int)) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+
+	// Guard against huge jumps (e.g. a garbled position) drawing forever
+	if dx-dy > 4096 {
+		return
+	}
+
+	err := dx + dy
+	for {
+		plot(x0, y0)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,71 @@
+package mapview
+
+import (
+	"math"
+	"time"
+
+	"termtrack/geo"
+)
+
+// DefaultVectorTime is how far ahead leader lines point
+const DefaultVectorTime = time.Minute
+
+// vectorEnd returns where an aircraft will be after d at its current
+// groundspeed and track, on the same copy of the world as the plane.
+// ok is false when it isn't moving.
+func (m *Model) vectorEnd(lon, lat, speed, track float64, d time.Duration) (float64, float64, bool) {
+	if speed <= 0 || d <= 0 {
+		return 0, 0, false
+	}
+	endLat, endLon := geo.Destination(lat, lon, track, speed*d.Hours())
+	return unwrapFrom(lon, endLon), endLat, true
+}
+
+// vectorGlyph picks the leader line glyph closest to the line's angle on
+// screen. Cells are about twice as tall as wide, so dy counts double.
+func (m *Model) vectorGlyph(dx, dy float64) string {
+	angle := math.Atan2(-dy*charAspect, dx) * 180 / math.Pi
+	// 0: |, 1: /, 2: -, 3: \, folding opposite directions together
+	i := int(math.Mod(90-angle+22.5+360, 180) / 45)
+	return m.glyphs.Vectors[i%4]
+}
+
+// eachVector calls fn with the start and end of every aircraft's leader line
+func (m *Model) eachVector(fn func(icao string, lon0, lat0, lon1, lat1 float64)) {
+	for icao, ac := range m.aircraft {
+		if ac.Lat == 0 && ac.Lon == 0 {
+			continue
+		}
+		lon := m.nearView(ac.Lon)
+		if endLon, endLat, ok := m.vectorEnd(lon, ac.Lat, ac.SmoothSpeed, ac.SmoothTrack, m.vectorTime); ok {
+			fn(icao, lon, ac.Lat, endLon, endLat)
+		}
+	}
+}
+
+// drawVectors draws every aircraft's leader line onto the grid, leaving
+// the plane's own cell for its icon
+func (m *Model) drawVectors(g *grid, style func(icao string) styleID, viewWidth, viewHeight int) {
+	m.eachVector(func(icao string, lon0, lat0, lon1, lat1 float64) {
+		x0, y0 := m.project(lon0, lat0, viewWidth, viewHeight)
+		x1, y1 := m.project(lon1, lat1, viewWidth, viewHeight)
+		glyph, st := m.vectorGlyph(float64(x1-x0), float64(y1-y0)), style(icao)
+		line(x0, y0, x1, y1, func(x, y int) {
+			if x != x0 || y != y0 {
+				setCell(g, x, y, glyph, st)
+			}
+		})
+	})
+}
+
+// plotVectors draws every aircraft's leader line as dots
+func (m *Model) plotVectors(c *brailleCanvas, style func(icao string) styleID, viewWidth, viewHeight int) {
+	m.eachVector(func(icao string, lon0, lat0, lon1, lat1 float64) {
+		x0, y0 := toDots(m.projectF(lon0, lat0, viewWidth, viewHeight))
+		x1, y1 := toDots(m.projectF(lon1, lat1, viewWidth, viewHeight))
+		st := style(icao)
+		line(x0, y0, x1, y1, func(x, y int) {
+			c.dot(x, y, st)
+		})
+	})
+}
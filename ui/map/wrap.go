@@ -0,0 +1,59 @@
+package mapview
+
+import (
+	"math"
+
+	shp "github.com/jonas-p/go-shp"
+)
+
+// worldWidth is how many degrees of longitude there are before the map repeats
+const worldWidth = 360.0
+
+// wrapLon brings a longitude into [-180, 180)
+func wrapLon(lon float64) float64 {
+	return lon - worldWidth*math.Floor((lon+180)/worldWidth)
+}
+
+// wrapView keeps the view's center within [-180, 180) once a pan has
+// carried it across the antimeridian. The map repeats either side, so
+// the picture doesn't change.
+func (m *Model) wrapView() {
+	center := (m.viewBounds.MinX + m.viewBounds.MaxX) / 2
+	shift := wrapLon(center) - center
+	m.viewBounds.MinX += shift
+	m.viewBounds.MaxX += shift
+}
+
+// worldShifts returns the offset, in degrees, of each copy of the world
+// that's at least partly on screen: just 0 unless the view crosses ±180
+func (m *Model) worldShifts() []float64 {
+	screen := m.screenBounds()
+	var shifts []float64
+	first := math.Ceil((screen.MinX - 180) / worldWidth)
+	last := math.Floor((screen.MaxX + 180) / worldWidth)
+	for k := first; k <= last; k++ {
+		shifts = append(shifts, k*worldWidth)
+	}
+	return shifts
+}
+
+// shiftBox moves a box east by shift degrees
+func shiftBox(b shp.Box, shift float64) shp.Box {
+	b.MinX += shift
+	b.MaxX += shift
+	return b
+}
+
+// nearView moves lon by whole turns of the world to wherever is closest
+// to the middle of the screen, for things drawn once, like aircraft
+func (m *Model) nearView(lon float64) float64 {
+	screen := m.screenBounds()
+	mid := (screen.MinX + screen.MaxX) / 2
+	return lon + worldWidth*math.Round((mid-lon)/worldWidth)
+}
+
+// unwrapFrom returns lon moved by whole turns to be as close as possible
+// to prev, so a line from prev across the antimeridian goes the short way
+func unwrapFrom(prev, lon float64) float64 {
+	return prev + wrapLon(lon-prev)
+}
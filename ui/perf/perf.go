@@ -0,0 +1,94 @@
+package perf
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// window is how many frames the averages cover
+const window = 60
+
+// Stats collects frame timings. View() has a value receiver in bubbletea,
+// so the model holds a *Stats and the numbers survive the copies.
+type Stats struct {
+	mu sync.Mutex
+
+	frames []time.Duration // Render times, ring of the last window frames
+	next   int
+	last   time.Duration
+
+	lastFeed   time.Time // Newest feed message
+	lastUpdate time.Time // Newest store update in the frame being painted
+	paintLag   time.Duration
+}
+
+// New creates an empty stats collector
+func New() *Stats {
+	return &Stats{frames: make([]time.Duration, 0, window)}
+}
+
+// Frame records what the frame about to be painted contains: the time of
+// the last feed message and of the newest store update in its snapshot
+func (s *Stats) Frame(lastFeed, lastUpdate time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastFeed = lastFeed
+	s.lastUpdate = lastUpdate
+}
+
+// Painted records how long a frame took to render, finishing at done
+func (s *Stats) Painted(took time.Duration, done time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.frames) < window {
+		s.frames = append(s.frames, took)
+	} else {
+		s.frames[s.next] = took
+	}
+	s.next = (s.next + 1) % window
+	s.last = took
+
+	if !s.lastUpdate.IsZero() {
+		s.paintLag = done.Sub(s.lastUpdate)
+	}
+}
+
+// View renders the stats as a single status line
+func (s *Stats) View(width int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var total, worst time.Duration
+	if n := len(s.frames); n > 0 {
+		for _, f := range s.frames {
+			total += f
+			worst = max(worst, f)
+		}
+		total /= time.Duration(n)
+	}
+
+	feed := "never"
+	if !s.lastFeed.IsZero() {
+		feed = fmt.Sprintf("%.1fs ago", time.Since(s.lastFeed).Seconds())
+	}
+
+	text := fmt.Sprintf("frame %s (avg %s, max %s) | last feed msg %s | update-to-paint %s",
+		ms(s.last), ms(total), ms(worst), feed, ms(s.paintLag))
+
+	return lipgloss.NewStyle().
+		Width(width).
+		MaxHeight(1).
+		Padding(0, 1).
+		Background(lipgloss.Color("236")).
+		Foreground(lipgloss.Color("214")).
+		Render(text)
+}
+
+// ms formats a duration in milliseconds
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.1fms", float64(d.Microseconds())/1000)
+}
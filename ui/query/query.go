@@ -0,0 +1,282 @@
+package query
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+
+	"termtrack/flightlog"
+	"termtrack/ui/glyphs"
+)
+
+// maxColumnWidth keeps one long column (a callsign list, say) from
+// pushing the rest off the screen
+const maxColumnWidth = 30
+
+// resultMsg is a finished query
+type resultMsg struct {
+	seq    int
+	result flightlog.Result
+	err    error
+	took   time.Duration
+}
+
+// Model is the console for asking questions of the position log: pick a
+// canned query, or type SQL
+type Model struct {
+	width    int
+	height   int
+	border   lipgloss.Border
+	ellipsis string // Ends a value cut short to fit its column
+
+	db *sql.DB // Read only; nil if there's no log
+
+	selected int    // Which canned query
+	input    string // The canned query's argument, or raw SQL
+
+	seq     int // Of the latest query run, so older answers are dropped
+	running bool
+	ran     string // What the result is the answer to
+	result  flightlog.Result
+	err     error
+	took    time.Duration
+	offset  int // How many rows we're scrolled down
+}
+
+// New creates a new query console over db, which can be nil if there's
+// no log to query
+func New(db *sql.DB) Model {
+	return Model{
+		width:    80,
+		height:   20,
+		border:   glyphs.Unicode.Border,
+		ellipsis: glyphs.Unicode.Ellipsis,
+		db:       db,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetGlyphs switches the glyph set used for the panel frame and the
+// values cut short
+func (m *Model) SetGlyphs(g glyphs.Set) {
+	m.border = g.Border
+	m.ellipsis = g.Ellipsis
+}
+
+// raw reports whether the prompt holds SQL rather than an argument
+func raw(input string) bool {
+	word, _, _ := strings.Cut(strings.TrimSpace(input), " ")
+	switch strings.ToUpper(word) {
+	case "SELECT", "WITH", "PRAGMA", "EXPLAIN":
+		return true
+	}
+	return false
+}
+
+// run starts the query in the prompt, or the selected canned one
+func (m *Model) run() tea.Cmd {
+	if m.db == nil {
+		return nil
+	}
+	query, args := m.input, []any(nil)
+	m.ran = strings.TrimSpace(m.input)
+	if !raw(m.input) {
+		c := flightlog.Queries[m.selected]
+		if c.Arg != "" && strings.TrimSpace(m.input) == "" {
+			m.err = fmt.Errorf("%s needs a %s: type it, then Enter", c.Name, c.Arg)
+			return nil
+		}
+		query, args = c.SQL, c.Args(m.input, time.Now())
+		m.ran = c.Name
+		if c.Arg != "" {
+			m.ran += ": " + strings.TrimSpace(m.input)
+		}
+	}
+
+	m.seq++
+	m.running = true
+	seq, db := m.seq, m.db
+	return func() tea.Msg {
+		start := time.Now()
+		res, err := flightlog.Query(db, query, args...)
+		return resultMsg{seq: seq, result: res, err: err, took: time.Since(start)}
+	}
+}
+
+// bodyHeight is how many result rows fit below the menu and prompt
+func (m Model) bodyHeight() int {
+	// Border, title, the menu, a blank, the prompt, a blank, the status
+	// line and the column headings
+	h := m.height - 2 - 1 - len(flightlog.Queries) - 1 - 1 - 1 - 1 - 1
+	return max(h, 1)
+}
+
+// scroll moves the results by n rows
+func (m *Model) scroll(n int) {
+	m.offset = min(m.offset+n, len(m.result.Rows)-m.bodyHeight())
+	m.offset = max(m.offset, 0)
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case resultMsg:
+		if msg.seq != m.seq {
+			break // Something newer was asked for since
+		}
+		m.running = false
+		m.result, m.err, m.took = msg.result, msg.err, msg.took
+		m.offset = 0
+
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyEnter:
+			return m, m.run()
+		case tea.KeyUp:
+			m.selected = (m.selected + len(flightlog.Queries) - 1) % len(flightlog.Queries)
+		case tea.KeyDown:
+			m.selected = (m.selected + 1) % len(flightlog.Queries)
+		case tea.KeyPgUp:
+			m.scroll(-m.bodyHeight())
+		case tea.KeyPgDown:
+			m.scroll(m.bodyHeight())
+		case tea.KeyCtrlU:
+			m.input = ""
+		case tea.KeyBackspace:
+			if r := []rune(m.input); len(r) > 0 {
+				m.input = string(r[:len(r)-1])
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			m.input += string(msg.Runes)
+		}
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	style := lipgloss.NewStyle().
+		Border(m.border).
+		BorderForeground(lipgloss.Color("63")).
+		Width(m.width - 2).
+		Height(m.height - 2).
+		MaxHeight(m.height)
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Bold(true)
+	headingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	innerWidth := max(m.width-2, 1)
+
+	rows := []string{titleStyle.Render(truncate("Log query | Up/Down: pick  Enter: run  PgUp/PgDn: scroll  ctrl+u: clear  Esc: close", innerWidth))}
+	if m.db == nil {
+		rows = append(rows, labelStyle.Width(innerWidth).Render("No log database. Start with -log-db to keep every position, then come back here to ask about them."))
+		return style.Render(strings.Join(rows, "\n"))
+	}
+
+	// --- Canned queries ---
+	for i, c := range flightlog.Queries {
+		name := c.Name
+		if c.Arg != "" {
+			name += " (type a " + c.Arg + ")"
+		}
+		if i == m.selected {
+			rows = append(rows, selectedStyle.Render(truncate("> "+name, innerWidth)))
+		} else {
+			rows = append(rows, labelStyle.Render(truncate("  "+name, innerWidth)))
+		}
+	}
+
+	// --- Prompt ---
+	// Long SQL scrolls off to the left, keeping the end in view
+	prompt := []rune("> " + m.input + "_")
+	if len(prompt) > innerWidth {
+		prompt = prompt[len(prompt)-innerWidth:]
+	}
+	line := string(prompt)
+	if m.input == "" {
+		line += labelStyle.Render(truncate("  (or type SELECT ... to run your own SQL)", innerWidth-len(prompt)))
+	}
+	rows = append(rows, "", line, "")
+
+	// --- Results ---
+	switch {
+	case m.running:
+		rows = append(rows, labelStyle.Render("Running..."))
+	case m.err != nil:
+		rows = append(rows, errStyle.Width(innerWidth).Render(m.err.Error()))
+	case m.ran == "":
+		rows = append(rows, labelStyle.Render("Pick a query and press Enter."))
+	default:
+		status := fmt.Sprintf("%s | %d rows in %s", m.ran, len(m.result.Rows), m.took.Round(time.Millisecond))
+		if m.result.Truncated {
+			status += fmt.Sprintf(" (first %d only)", flightlog.MaxRows)
+		}
+		if m.offset > 0 {
+			status += fmt.Sprintf(" | from row %d", m.offset+1)
+		}
+		rows = append(rows, labelStyle.Render(truncate(status, innerWidth)))
+		rows = append(rows, m.table(headingStyle, innerWidth)...)
+	}
+
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+// table lays out the visible rows of the result in columns as wide as
+// their widest value, heading first
+func (m Model) table(headingStyle lipgloss.Style, width int) []string {
+	end := min(m.offset+m.bodyHeight(), len(m.result.Rows))
+	visible := m.result.Rows[m.offset:end]
+
+	widths := make([]int, len(m.result.Columns))
+	for i, c := range m.result.Columns {
+		widths[i] = runewidth.StringWidth(c)
+	}
+	for _, row := range visible {
+		for i, v := range row {
+			widths[i] = max(widths[i], runewidth.StringWidth(v))
+		}
+	}
+	for i := range widths {
+		widths[i] = min(widths[i], maxColumnWidth)
+	}
+
+	line := func(values []string) string {
+		var b strings.Builder
+		for i, v := range values {
+			if i > 0 {
+				b.WriteString("  ")
+			}
+			b.WriteString(runewidth.FillRight(runewidth.Truncate(v, widths[i], m.ellipsis), widths[i]))
+		}
+		return runewidth.Truncate(strings.TrimRight(b.String(), " "), width, "")
+	}
+
+	lines := []string{headingStyle.Render(line(m.result.Columns))}
+	for _, row := range visible {
+		lines = append(lines, line(row))
+	}
+	return lines
+}
+
+// truncate cuts s down to at most n runes
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	if len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,276 @@
+package rawlog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"termtrack/sbs"
+	"termtrack/ui/glyphs"
+)
+
+// entry is a single raw line as it came off the feed
+type entry struct {
+	at      time.Time
+	line    string
+	icao    string
+	msgType string
+}
+
+// Model holds the raw message log's state
+type Model struct {
+	width    int
+	height   int
+	name     string // The feed's, for the title
+	maxLines int    // How much scrollback we keep in memory
+
+	lines   []entry
+	pending []entry // Lines received while paused
+	nextSeq uint64  // Where we are in the feed's line log
+	paused  bool
+	offset  int // How many lines we're scrolled up from the bottom
+
+	filter  string
+	input   string
+	editing bool
+
+	border lipgloss.Border
+}
+
+// New creates a new raw log model for the named feed, keeping as many
+// lines as its line log does
+func New(name string, maxLines int) Model {
+	return Model{
+		width:    80,
+		height:   10,
+		name:     name,
+		maxLines: maxLines,
+		border:   glyphs.Unicode.Border,
+	}
+}
+
+// SetGlyphs switches the glyph set used for the panel frame
+func (m *Model) SetGlyphs(g glyphs.Set) {
+	m.border = g.Border
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// Pull adds any lines that have arrived in the feed's log since last time
+func (m *Model) Pull(log *sbs.LineLog) {
+	var lines []sbs.Line
+	lines, m.nextSeq = log.Since(m.nextSeq)
+	for _, l := range lines {
+		m.Add(l.At, l.Text)
+	}
+}
+
+// Add appends a raw line to the log
+func (m *Model) Add(at time.Time, line string) {
+	e := entry{at: at, line: line}
+
+	// Pull out the bits we filter on: MSG,<type>,<session>,<aircraft>,<icao>,...
+	fields := strings.SplitN(line, ",", 6)
+	if len(fields) >= 2 {
+		e.msgType = fields[1]
+	}
+	if len(fields) >= 5 {
+		e.icao = strings.ToUpper(fields[4])
+	}
+
+	if m.paused {
+		m.pending = append(m.pending, e)
+		if len(m.pending) > m.maxLines {
+			m.pending = m.pending[len(m.pending)-m.maxLines:]
+		}
+		return
+	}
+	m.push(e)
+}
+
+// push adds an entry to the visible buffer, trimming old lines
+func (m *Model) push(e entry) {
+	m.lines = append(m.lines, e)
+	if len(m.lines) > m.maxLines {
+		m.lines = m.lines[len(m.lines)-m.maxLines:]
+	}
+	// Keep the view anchored on the same lines while scrolled back
+	if m.offset > 0 && m.matches(e) {
+		m.offset++
+	}
+}
+
+// Editing reports whether the filter prompt is active (it wants every key)
+func (m Model) Editing() bool {
+	return m.editing
+}
+
+// matches reports whether an entry passes the current filter.
+// A single digit (or "MSG,3") filters on message type, anything else
+// is matched against the ICAO hex.
+func (m Model) matches(e entry) bool {
+	if m.filter == "" {
+		return true
+	}
+	f := strings.ToUpper(m.filter)
+	if strings.HasPrefix(f, "MSG,") {
+		return e.msgType == strings.TrimPrefix(f, "MSG,")
+	}
+	if len(f) == 1 && f[0] >= '0' && f[0] <= '9' {
+		return e.msgType == f
+	}
+	return strings.Contains(e.icao, f)
+}
+
+// visibleLines returns the filtered lines
+func (m Model) visibleLines() []entry {
+	if m.filter == "" {
+		return m.lines
+	}
+	var out []entry
+	for _, e := range m.lines {
+		if m.matches(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// bodyHeight is how many log lines fit inside the border and title
+func (m Model) bodyHeight() int {
+	h := m.height - 3 // Border top/bottom + title line
+	if h < 1 {
+		h = 1
+	}
+	return h
+}
+
+// scroll moves the view by n lines (positive = back in time)
+func (m *Model) scroll(n int) {
+	m.offset += n
+	maxOffset := len(m.visibleLines()) - m.bodyHeight()
+	if m.offset > maxOffset {
+		m.offset = maxOffset
+	}
+	if m.offset < 0 {
+		m.offset = 0
+	}
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case tea.KeyMsg:
+		// --- Filter prompt ---
+		if m.editing {
+			switch msg.Type {
+			case tea.KeyEnter:
+				m.filter = strings.TrimSpace(m.input)
+				m.editing = false
+				m.offset = 0
+			case tea.KeyEsc:
+				m.editing = false
+			case tea.KeyBackspace:
+				if r := []rune(m.input); len(r) > 0 {
+					m.input = string(r[:len(r)-1])
+				}
+			case tea.KeyRunes, tea.KeySpace:
+				m.input += string(msg.Runes)
+			}
+			return m, nil
+		}
+
+		switch msg.String() {
+		case "p":
+			m.paused = !m.paused
+			if !m.paused {
+				for _, e := range m.pending {
+					m.push(e)
+				}
+				m.pending = nil
+			}
+		case "pgup":
+			m.scroll(m.bodyHeight())
+		case "pgdown":
+			m.scroll(-m.bodyHeight())
+		case "home":
+			m.scroll(len(m.lines))
+		case "end":
+			m.offset = 0
+		case "/":
+			m.editing = true
+			m.input = m.filter
+		}
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	style := lipgloss.NewStyle().
+		Border(m.border).
+		BorderForeground(lipgloss.Color("240")).
+		Width(m.width - 2).
+		Height(m.height - 2)
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+	lineStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("250"))
+	timeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	innerWidth := m.width - 2
+	if innerWidth < 1 {
+		innerWidth = 1
+	}
+
+	// --- Title ---
+	title := "Raw feed: " + m.name
+	if m.paused {
+		title += fmt.Sprintf(" [PAUSED, %d queued]", len(m.pending))
+	}
+	if m.editing {
+		title += " | filter: " + m.input + "_"
+	} else if m.filter != "" {
+		title += " | filter: " + m.filter
+	}
+	if m.offset > 0 {
+		title += fmt.Sprintf(" | -%d", m.offset)
+	}
+	title += " | p: pause  /: filter  PgUp/PgDn: scroll"
+
+	// --- Body ---
+	lines := m.visibleLines()
+	end := len(lines) - m.offset
+	if end < 0 {
+		end = 0
+	}
+	start := end - m.bodyHeight()
+	if start < 0 {
+		start = 0
+	}
+
+	rows := []string{titleStyle.Render(truncate(title, innerWidth))}
+	for _, e := range lines[start:end] {
+		ts := e.at.Format("15:04:05.000") + " "
+		rows = append(rows, timeStyle.Render(ts)+lineStyle.Render(truncate(e.line, innerWidth-len(ts))))
+	}
+
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+// truncate cuts s down to at most n runes
+func truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	r := []rune(s)
+	if len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
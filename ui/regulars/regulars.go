@@ -0,0 +1,108 @@
+package regulars
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"termtrack/sightings"
+	"termtrack/ui/glyphs"
+)
+
+// MaxListed is the most regulars the panel has room for on a tall
+// terminal; a shorter one shows fewer
+const MaxListed = 20
+
+// Model is the panel of the airframes seen most often from here
+type Model struct {
+	width  int
+	height int
+	border lipgloss.Border
+
+	regulars []sightings.Regular
+}
+
+// New creates a new regulars panel
+func New() Model {
+	return Model{
+		width:  34,
+		height: 20,
+		border: glyphs.Unicode.Border,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetGlyphs switches the glyph set used for the panel frame
+func (m *Model) SetGlyphs(g glyphs.Set) {
+	m.border = g.Border
+}
+
+// SetRegulars sets the airframes to list, the most frequent first
+func (m *Model) SetRegulars(regulars []sightings.Regular) {
+	m.regulars = regulars
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	style := lipgloss.NewStyle().
+		Border(m.border).
+		BorderForeground(lipgloss.Color("63")).
+		Width(m.width - 2).
+		Height(m.height - 2).
+		MaxHeight(m.height)
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+	innerWidth := m.width - 2
+	row := func(label, value string) string {
+		pad := innerWidth - lipgloss.Width(label) - lipgloss.Width(value)
+		if pad < 1 {
+			pad = 1
+		}
+		return label + strings.Repeat(" ", pad) + value
+	}
+
+	rows := []string{titleStyle.Render("Regulars")}
+	if len(m.regulars) == 0 {
+		rows = append(rows, labelStyle.Width(innerWidth).Render("Nothing seen yet. Airframes are counted each time they come by, and kept between sessions."))
+		return style.Render(strings.Join(rows, "\n"))
+	}
+
+	// Two lines each, under the title and a blank line
+	rows = append(rows, "")
+	fits := max((m.height-4)/2, 1)
+	for _, r := range m.regulars[:min(len(m.regulars), fits)] {
+		visits := "1 visit"
+		if r.Count != 1 {
+			visits = fmt.Sprintf("%d visits", r.Count)
+		}
+		rows = append(rows,
+			row(name(r), visits),
+			labelStyle.Render(row("  "+r.First.Format("2006-01-02"), "last "+r.Last.Format("2006-01-02"))),
+		)
+	}
+
+	return style.Render(strings.Join(rows, "\n"))
+}
+
+// name is how a regular is listed: by its last callsign with its hex, or
+// just the hex
+func name(r sightings.Regular) string {
+	if r.Callsign != "" {
+		return fmt.Sprintf("%s (%s)", r.Callsign, r.ICAO)
+	}
+	return r.ICAO
+}
@@ -0,0 +1,210 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"termtrack/dump1090"
+	"termtrack/sbs"
+	"termtrack/traffic"
+	"termtrack/ui/glyphs"
+)
+
+// rateWindow is how far back the message rate is averaged
+const rateWindow = 5 * time.Second
+
+// maxAirports is how many of the busiest airports are listed
+const maxAirports = 8
+
+// Counters are TermTrack's own numbers, gathered each tick
+type Counters struct {
+	Lines        uint64 // Raw lines received
+	Updates      uint64 // Lines that parsed into an aircraft update
+	Dropped      uint64 // Updates left out for going over -max-rate
+	Callsigns    sbs.CallsignStats
+	Aircraft     int
+	WithPosition int
+	PerReceiver  map[string]int  // Aircraft currently heard by each receiver
+	Latency      sbs.Latency     // From the messages' timestamps, if they have them
+	Airports     []traffic.Count // Arrivals and departures, busiest first
+}
+
+// sample is a line count at a point in time, for the rate
+type sample struct {
+	at    time.Time
+	lines uint64
+}
+
+// Model is the statistics panel
+type Model struct {
+	width  int
+	height int
+	border lipgloss.Border
+
+	counters Counters
+	samples  []sample
+
+	receiver    *dump1090.Stats
+	receiverErr error
+	receiverURL string // Empty if we're not polling stats.json
+}
+
+// New creates a new stats panel. receiverURL is the stats.json being
+// polled, if any.
+func New(receiverURL string) Model {
+	return Model{
+		width:       34,
+		height:      20,
+		border:      glyphs.Unicode.Border,
+		receiverURL: receiverURL,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetGlyphs switches the glyph set used for the panel frame
+func (m *Model) SetGlyphs(g glyphs.Set) {
+	m.border = g.Border
+}
+
+// SetCounters records the latest counters
+func (m *Model) SetCounters(c Counters) {
+	now := time.Now()
+	m.counters = c
+	m.samples = append(m.samples, sample{at: now, lines: c.Lines})
+
+	// Drop samples that have fallen out of the window, keeping one to measure from
+	for len(m.samples) > 2 && now.Sub(m.samples[1].at) > rateWindow {
+		m.samples = m.samples[1:]
+	}
+}
+
+// rate returns messages per second over the window
+func (m Model) rate() float64 {
+	if len(m.samples) < 2 {
+		return 0
+	}
+	first, last := m.samples[0], m.samples[len(m.samples)-1]
+	secs := last.at.Sub(first.at).Seconds()
+	if secs <= 0 {
+		return 0
+	}
+	return float64(last.lines-first.lines) / secs
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case dump1090.StatsMsg:
+		m.receiverErr = msg.Err
+		if msg.Err == nil {
+			m.receiver = msg.Stats
+		}
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	style := lipgloss.NewStyle().
+		Border(m.border).
+		BorderForeground(lipgloss.Color("63")).
+		Width(m.width - 2).
+		Height(m.height - 2).
+		MaxHeight(m.height)
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+
+	innerWidth := m.width - 2
+	row := func(label, value string) string {
+		pad := innerWidth - lipgloss.Width(label) - lipgloss.Width(value)
+		if pad < 1 {
+			pad = 1
+		}
+		return labelStyle.Render(label) + strings.Repeat(" ", pad) + value
+	}
+
+	c := m.counters
+	rows := []string{
+		titleStyle.Render("TermTrack"),
+		row("Messages", fmt.Sprint(c.Lines)),
+		row("Msg rate", fmt.Sprintf("%.0f/s", m.rate())),
+		row("Aircraft updates", fmt.Sprint(c.Updates)),
+		row("Unparsed lines", fmt.Sprint(c.Lines-min(c.Updates, c.Lines))),
+	}
+	if c.Dropped > 0 {
+		rows = append(rows, row("Over rate limit", fmt.Sprint(c.Dropped)))
+	}
+	rows = append(rows,
+		row("Aircraft", fmt.Sprint(c.Aircraft)),
+		row("With position", fmt.Sprint(c.WithPosition)),
+	)
+	if c.Latency.Samples > 0 {
+		rows = append(rows,
+			row("Latency", c.Latency.Mean.Round(time.Millisecond).String()),
+			row("Latency max", c.Latency.Max.Round(time.Millisecond).String()),
+		)
+	}
+
+	if cs := c.Callsigns; cs.Trimmed+cs.Garbled+cs.Conflicts > 0 {
+		rows = append(rows, "", titleStyle.Render("Callsign checks"),
+			row("Tidied up", fmt.Sprint(cs.Trimmed)),
+			row("Garbled, dropped", fmt.Sprint(cs.Garbled)),
+			row("Receivers disagreed", fmt.Sprint(cs.Conflicts)),
+		)
+	}
+
+	if len(c.PerReceiver) > 0 {
+		rows = append(rows, "", titleStyle.Render("Aircraft by receiver"))
+		names := make([]string, 0, len(c.PerReceiver))
+		for name := range c.PerReceiver {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			rows = append(rows, row(name, fmt.Sprint(c.PerReceiver[name])))
+		}
+	}
+
+	if len(c.Airports) > 0 {
+		rows = append(rows, "", titleStyle.Render("Traffic by airport"))
+		for _, a := range c.Airports[:min(len(c.Airports), maxAirports)] {
+			rows = append(rows, row(a.Code, fmt.Sprintf("%d arr  %d dep", a.Arrivals, a.Departures)))
+		}
+	}
+
+	if m.receiverURL != "" {
+		rows = append(rows, "", titleStyle.Render("Receiver (last 1 min)"))
+		switch {
+		case m.receiver != nil:
+			r := m.receiver
+			rows = append(rows,
+				row("Messages", fmt.Sprint(r.Messages)),
+				row("Signal", fmt.Sprintf("%.1f dBFS", r.Signal)),
+				row("Noise", fmt.Sprintf("%.1f dBFS", r.Noise)),
+				row("Peak signal", fmt.Sprintf("%.1f dBFS", r.PeakSignal)),
+				row("Strong (>-3dB)", fmt.Sprint(r.StrongSignals)),
+				row("Tracks", fmt.Sprint(r.Tracks)),
+				row("Single-msg tracks", fmt.Sprint(r.SingleTracks)),
+				row("Decoder CPU", fmt.Sprintf("%.1f%%", r.CPU)),
+			)
+		case m.receiverErr == nil:
+			rows = append(rows, labelStyle.Render("Waiting for stats.json..."))
+		}
+		if m.receiverErr != nil {
+			rows = append(rows, errStyle.Width(innerWidth).Render(m.receiverErr.Error()))
+		}
+	}
+
+	return style.Render(strings.Join(rows, "\n"))
+}
@@ -0,0 +1,275 @@
+package textview
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"termtrack/aircraftdb"
+	"termtrack/geo"
+	"termtrack/icao"
+	"termtrack/sbs"
+)
+
+// refreshInterval is how often the list is rebuilt. Screen readers re-read
+// changed lines, so refreshing at the render frame rate would be unusable.
+const refreshInterval = 5 * time.Second
+
+// maxAlerts is how many alert lines we keep on screen
+const maxAlerts = 5
+
+// Model is a plain-text replacement for the map, for screen reader users
+type Model struct {
+	width  int
+	height int
+
+	lines       []string
+	count       int // Aircraft in lines, which can also hold headings
+	alerts      []string
+	known       map[string]bool // ICAOs we've announced
+	lastRefresh time.Time
+	at          time.Time      // The moment on screen, zero when live
+	timeouts    sbs.Timeouts   // How long before a quiet aircraft is announced as lost
+	db          *aircraftdb.DB // Registrations and types, nil for none
+
+	groupPrivate bool // List anonymous aircraft after the rest, under their own heading
+}
+
+// New creates a new text view model
+func New() Model {
+	return Model{
+		width:  80,
+		height: 23,
+		known:  make(map[string]bool),
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetTime sets the moment the aircraft are from when replaying history,
+// so quiet ones are judged against it. The zero time means live.
+func (m *Model) SetTime(t time.Time) {
+	m.at = t
+}
+
+// SetTimeouts sets how long aircraft from each source can go quiet before
+// they're announced as lost
+func (m *Model) SetTimeouts(t sbs.Timeouts) {
+	m.timeouts = t
+}
+
+// SetAircraftDB sets the database registrations and types are looked up
+// in, nil for none
+func (m *Model) SetAircraftDB(db *aircraftdb.DB) {
+	m.db = db
+}
+
+// SetGroupPrivate sets whether anonymous aircraft (those with blocked
+// callsigns) are listed separately, after the rest
+func (m *Model) SetGroupPrivate(on bool) {
+	m.groupPrivate = on
+}
+
+// row is one aircraft's line, with its distance for sorting
+type row struct {
+	dist    float64
+	text    string
+	private bool
+}
+
+// name is how we speak an aircraft: its callsign, or its hex if we have
+// none (or only a placeholder for a blocked one)
+func name(ac *sbs.Aircraft) string {
+	if ac.Callsign != "" && !icao.BlockedCallsign(ac.Callsign) {
+		return ac.Callsign
+	}
+	return "hex " + ac.ICAO
+}
+
+// about is what goes in brackets after an aircraft's name: where it's
+// registered, its registration and type, going by its ICAO address, and
+// whether it's anonymous
+func (m Model) about(ac *sbs.Aircraft) string {
+	var parts []string
+	if c, ok := icao.CountryOf(ac.ICAO); ok {
+		parts = append(parts, c.Name)
+	}
+	e, _ := m.db.Lookup(ac.ICAO)
+	if e.Registration != "" {
+		parts = append(parts, e.Registration)
+	} else if reg, ok := icao.Registration(ac.ICAO); ok {
+		parts = append(parts, reg)
+	}
+	if e.Type != "" {
+		parts = append(parts, e.Type)
+	}
+	if anonymous(ac) {
+		parts = append(parts, "private")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// spokenDuration says how long something's been going on, to the minute:
+// "under a minute", "12 minutes", "1 hour 5 minutes"
+func spokenDuration(d time.Duration) string {
+	mins := int(d / time.Minute)
+	if mins == 0 {
+		return "under a minute"
+	}
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return "1 " + unit
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+	if mins < 60 {
+		return plural(mins, "minute")
+	}
+	if mins%60 == 0 {
+		return plural(mins/60, "hour")
+	}
+	return plural(mins/60, "hour") + " " + plural(mins%60, "minute")
+}
+
+// anonymous reports whether an aircraft is hiding who it is
+func anonymous(ac *sbs.Aircraft) bool {
+	return icao.BlockedCallsign(ac.Callsign)
+}
+
+// UpdateAircraft rebuilds the list (at most every refreshInterval) relative
+// to the reference point, usually the receiver or the center of the map
+func (m *Model) UpdateAircraft(allAircraft map[string]*sbs.Aircraft, refLat, refLon float64) {
+	now := time.Now()
+	if now.Sub(m.lastRefresh) < refreshInterval {
+		return
+	}
+	m.lastRefresh = now
+	if !m.at.IsZero() {
+		now = m.at
+	}
+
+	var rows []row
+	for icao, ac := range allAircraft {
+		if ac.Lat == 0 && ac.Lon == 0 {
+			continue // No position yet
+		}
+
+		dist := geo.Distance(refLat, refLon, ac.Lat, ac.Lon)
+		dir := geo.CompassPoint(geo.Bearing(refLat, refLon, ac.Lat, ac.Lon))
+		where := fmt.Sprintf("%.0f nautical miles %s", dist, dir)
+
+		// --- Alerts ---
+		quiet := ac.Quiet(now, m.timeouts)
+		if !m.known[icao] && !quiet {
+			m.known[icao] = true
+			m.Alert(fmt.Sprintf("New aircraft: %s, %s.", name(ac), where))
+		} else if m.known[icao] && quiet {
+			delete(m.known, icao)
+			m.Alert(fmt.Sprintf("Lost contact: %s, last seen %s.", name(ac), where))
+		}
+		if quiet {
+			continue
+		}
+
+		text := fmt.Sprintf("%s, %s", name(ac), where)
+		if a := m.about(ac); a != "" {
+			text = fmt.Sprintf("%s (%s), %s", name(ac), a, where)
+		}
+		switch {
+		case ac.OnGround:
+			text += ", on the ground"
+		case ac.Has&sbs.HasAltitude != 0:
+			text += fmt.Sprintf(", at %d feet", ac.Altitude)
+		}
+		if ac.Speed != 0 {
+			text += fmt.Sprintf(", heading %s at %.0f knots", geo.CompassPoint(ac.SmoothTrack), ac.SmoothSpeed)
+		}
+		if ac.Has&sbs.HasSignal != 0 {
+			text += fmt.Sprintf(", signal %.0f dBFS", ac.Signal)
+		}
+		text += ". In view for " + spokenDuration(ac.VisibleFor())
+		if ac.Observed-ac.VisibleFor() >= time.Minute {
+			text += ", " + spokenDuration(ac.Observed) + " all told"
+		}
+		text += "."
+		if ac.Anomalies != 0 {
+			text += fmt.Sprintf(" Warning, suspect data: %s.", ac.Anomalies)
+		}
+		rows = append(rows, row{dist: dist, text: text, private: anonymous(ac)})
+	}
+
+	// Closest first, anonymous ones last if they're grouped
+	sort.Slice(rows, func(i, j int) bool {
+		if m.groupPrivate && rows[i].private != rows[j].private {
+			return !rows[i].private
+		}
+		return rows[i].dist < rows[j].dist
+	})
+
+	m.lines = m.lines[:0]
+	m.count = len(rows)
+	for i, r := range rows {
+		if m.groupPrivate && r.private && (i == 0 || !rows[i-1].private) {
+			m.lines = append(m.lines, "Private aircraft:")
+		}
+		m.lines = append(m.lines, r.text)
+	}
+}
+
+// Alert adds a spoken-friendly alert line, dropping the oldest
+func (m *Model) Alert(text string) {
+	m.alerts = append(m.alerts, time.Now().Format("15:04")+" "+text)
+	if len(m.alerts) > maxAlerts {
+		m.alerts = m.alerts[len(m.alerts)-maxAlerts:]
+	}
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	// Plain lines only: no borders or glyphs for the screen reader to trip over
+	out := []string{fmt.Sprintf("%d aircraft in view, updated %s.",
+		m.count, m.lastRefresh.Format("15:04:05"))}
+
+	// Leave room for the alerts section at the bottom
+	listRoom := m.height - 1 - (len(m.alerts) + 2)
+	for i, line := range m.lines {
+		if len(m.lines) > listRoom && i == listRoom-1 {
+			out = append(out, fmt.Sprintf("And %d more.", len(m.lines)-i))
+			break
+		}
+		out = append(out, line)
+	}
+
+	if len(m.alerts) > 0 {
+		out = append(out, "", "Alerts:")
+		out = append(out, m.alerts...)
+	}
+
+	// Pad to our height so the footer stays put
+	for len(out) < m.height {
+		out = append(out, "")
+	}
+	if len(out) > m.height {
+		out = out[:m.height]
+	}
+	// Cut long lines rather than letting the terminal wrap them
+	for i, line := range out {
+		if r := []rune(line); len(r) > m.width {
+			out[i] = string(r[:m.width])
+		}
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,201 @@
+// Package theme recolors finished frames for the dark: the panels all pick
+// their own colors, so rather than teach each of them a palette, the
+// colors in the escape codes are swapped for dimmer ones on the way out.
+package theme
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Theme is a way of coloring the UI
+type Theme int
+
+const (
+	Day   Theme = iota // The colors as the panels chose them
+	Night              // The same, dimmed
+	Red                // Dim reds only, which spoil dark-adapted eyes least
+)
+
+var names = []string{"day", "night", "red"}
+
+func (t Theme) String() string {
+	if t >= 0 && int(t) < len(names) {
+		return names[t]
+	}
+	return fmt.Sprintf("theme(%d)", int(t))
+}
+
+// Parse finds a theme by name
+func Parse(name string) (Theme, error) {
+	if i := slices.Index(names, name); i >= 0 {
+		return Theme(i), nil
+	}
+	return Day, fmt.Errorf("unknown theme %q (day, night or red)", name)
+}
+
+// rgb is a color to be recolored
+type rgb struct{ r, g, b float64 }
+
+// text is what plain text is taken to be drawn in: xterm's default
+// foreground, light grey
+var text = rgb{229, 229, 229}
+
+// recolor is the theme's version of a color
+func (t Theme) recolor(c rgb) rgb {
+	switch t {
+	case Night:
+		return rgb{c.r * 0.5, c.g * 0.5, c.b * 0.5}
+	case Red:
+		// Brightness becomes red, kept above black so nothing vanishes
+		l := 0.3*c.r + 0.59*c.g + 0.11*c.b
+		if l == 0 {
+			return c
+		}
+		return rgb{50 + 0.5*l, 0, 0}
+	}
+	return c
+}
+
+// Apply recolors a frame. Plain text gets a color too, and since the
+// renderer may redraw any line on its own, each line sets it afresh.
+func (t Theme) Apply(frame string) string {
+	if t == Day {
+		return frame
+	}
+	plain := fmt.Sprintf("38;5;%d", nearest(t.recolor(text)))
+	table := &tables[t]
+
+	var b strings.Builder
+	b.Grow(len(frame) + len(frame)/8)
+	for i, line := range strings.Split(frame, "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString("\x1b[" + plain + "m")
+		for {
+			start := strings.Index(line, "\x1b[")
+			if start < 0 {
+				break
+			}
+			end := strings.IndexFunc(line[start+2:], func(r rune) bool { return r >= 0x40 && r <= 0x7e })
+			if end < 0 {
+				break
+			}
+			end += start + 2
+			b.WriteString(line[:start])
+			if line[end] == 'm' {
+				b.WriteString("\x1b[" + t.sgr(line[start+2:end], plain, table) + "m")
+			} else {
+				b.WriteString(line[start : end+1]) // Not a color; leave it be
+			}
+			line = line[end+1:]
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// sgr recolors the parameters of one SGR ("set graphics rendition")
+// sequence, looking 256-color indexes up in table. Resets and the default
+// foreground go to plain.
+func (t Theme) sgr(params, plain string, table *[256]int) string {
+	ps := strings.Split(params, ";")
+	out := make([]string, 0, len(ps)+4)
+	for i := 0; i < len(ps); i++ {
+		p := ps[i]
+		n, err := strconv.Atoi(p)
+		switch {
+		case p == "" || n == 0 && err == nil:
+			out = append(out, "0", plain)
+		case err != nil:
+			out = append(out, p)
+		case n == 39:
+			out = append(out, plain)
+		case n >= 30 && n <= 37, n >= 90 && n <= 97:
+			out = append(out, fmt.Sprintf("38;5;%d", table[basic(n-30)]))
+		case n >= 40 && n <= 47, n >= 100 && n <= 107:
+			out = append(out, fmt.Sprintf("48;5;%d", table[basic(n-40)]))
+		case (n == 38 || n == 48) && i+2 < len(ps) && ps[i+1] == "5":
+			idx, _ := strconv.Atoi(ps[i+2])
+			out = append(out, fmt.Sprintf("%d;5;%d", n, table[min(max(idx, 0), 255)]))
+			i += 2
+		case (n == 38 || n == 48) && i+4 < len(ps) && ps[i+1] == "2":
+			var c [3]float64
+			for j := range c {
+				v, _ := strconv.Atoi(ps[i+2+j])
+				c[j] = float64(v)
+			}
+			r := t.recolor(rgb{c[0], c[1], c[2]})
+			out = append(out, fmt.Sprintf("%d;2;%d;%d;%d", n, int(r.r), int(r.g), int(r.b)))
+			i += 4
+		default:
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, ";")
+}
+
+// basic turns the offset of a 16-color code from 30 (or 40) into its
+// palette index: 0-7 are 30-37, and 60-67 the bright 90-97
+func basic(n int) int {
+	if n >= 60 {
+		return n - 60 + 8
+	}
+	return n
+}
+
+// standard is xterm's first 16 colors
+var standard = [16]rgb{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// levels are the steps of each channel in the 6x6x6 color cube
+var levels = [6]float64{0, 95, 135, 175, 215, 255}
+
+// tables are each theme's version of every 256-color index, worked out
+// once rather than per cell
+var tables = func() (tables [Red + 1][256]int) {
+	for t := range tables {
+		for i := range tables[t] {
+			tables[t][i] = nearest(Theme(t).recolor(palette(i)))
+		}
+	}
+	return tables
+}()
+
+// palette is the color of a 256-color index
+func palette(i int) rgb {
+	switch {
+	case i < 16:
+		return standard[i]
+	case i < 232:
+		i -= 16
+		return rgb{levels[i/36], levels[i/6%6], levels[i%6]}
+	}
+	v := float64(8 + 10*(i-232))
+	return rgb{v, v, v}
+}
+
+// nearest is the 256-color index closest to c, from the cube or the greys.
+// The first 16 are left out, since terminals change them.
+func nearest(c rgb) int {
+	best, bestDist := 0, math.Inf(1)
+	for i := 16; i < 256; i++ {
+		p := palette(i)
+		if d := sq(p.r-c.r) + sq(p.g-c.g) + sq(p.b-c.b); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best
+}
+
+func sq(x float64) float64 {
+	return x * x
+}
@@ -0,0 +1,151 @@
+package weather
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"termtrack/geo"
+	"termtrack/uat"
+	"termtrack/ui/glyphs"
+	mapview "termtrack/ui/map"
+)
+
+// Model is the panel of FIS-B text weather heard over UAT, nearest first
+type Model struct {
+	width  int
+	height int
+	border lipgloss.Border
+
+	addr    string // The dump978 we're reading, "" if none
+	reports []uat.Report
+	err     error // Why the UAT feed isn't running, if it isn't
+
+	airports       map[string]mapview.Airport // By ICAO and IATA code
+	refLat, refLon float64                    // Distances are from here
+}
+
+// New creates a new weather panel. addr is the dump978 being read, if any.
+func New(addr string) Model {
+	return Model{
+		width:  34,
+		height: 20,
+		border: glyphs.Unicode.Border,
+		addr:   addr,
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// SetGlyphs switches the glyph set used for the panel frame
+func (m *Model) SetGlyphs(g glyphs.Set) {
+	m.border = g.Border
+}
+
+// SetAirports sets the airports that reports are placed by
+func (m *Model) SetAirports(airports []mapview.Airport) {
+	m.airports = make(map[string]mapview.Airport, 2*len(airports))
+	for _, a := range airports {
+		if a.IATA != "" {
+			m.airports[strings.ToUpper(a.IATA)] = a
+		}
+		if a.Code != "" {
+			m.airports[strings.ToUpper(a.Code)] = a // ICAO wins a clash
+		}
+	}
+}
+
+// SetReports sets the latest reports, and why the feed stopped if it has
+func (m *Model) SetReports(reports []uat.Report, err error) {
+	m.reports, m.err = reports, err
+}
+
+// SetReference sets where distances to stations are measured from
+func (m *Model) SetReference(lat, lon float64) {
+	m.refLat, m.refLon = lat, lon
+}
+
+// airport finds a report's station. US stations sometimes go by their
+// three-letter FAA code, which is the ICAO code without the K.
+func (m Model) airport(station string) (mapview.Airport, bool) {
+	a, ok := m.airports[station]
+	if !ok && len(station) == 3 {
+		a, ok = m.airports["K"+station]
+	}
+	return a, ok
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+	}
+	return m, nil
+}
+
+func (m Model) View() string {
+	style := lipgloss.NewStyle().
+		Border(m.border).
+		BorderForeground(lipgloss.Color("63")).
+		Width(m.width - 2).
+		Height(m.height - 2).
+		MaxHeight(m.height)
+	titleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("63")).Bold(true)
+	labelStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	innerWidth := m.width - 2
+
+	rows := []string{titleStyle.Render("Weather (UAT)")}
+	switch {
+	case m.addr == "":
+		rows = append(rows, labelStyle.Width(innerWidth).Render("No UAT feed. Point -uat at dump978's raw output (usually port 30978)."))
+		return style.Render(strings.Join(rows, "\n"))
+	case m.err != nil:
+		rows = append(rows, errStyle.Width(innerWidth).Render(m.err.Error()))
+	case len(m.reports) == 0:
+		rows = append(rows, labelStyle.Render("Waiting for text reports..."))
+	}
+
+	// Nearest station first; ones we can't place go last, by name
+	type placed struct {
+		uat.Report
+		dist  float64
+		known bool
+		name  string
+	}
+	list := make([]placed, len(m.reports))
+	for i, r := range m.reports {
+		list[i] = placed{Report: r}
+		if a, ok := m.airport(r.Station); ok {
+			list[i].dist = geo.Distance(m.refLat, m.refLon, a.Lat, a.Lon)
+			list[i].known = true
+			list[i].name = a.Name
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].known != list[j].known {
+			return list[i].known
+		}
+		return list[i].dist < list[j].dist
+	})
+
+	for _, r := range list {
+		heading := fmt.Sprintf("%s %s %s", r.Station, r.Type, r.Time)
+		if r.known {
+			heading += fmt.Sprintf(" %.0fnm", r.dist)
+		}
+		rows = append(rows, "", titleStyle.Render(heading))
+		if r.name != "" {
+			rows = append(rows, labelStyle.Render(r.name))
+		}
+		rows = append(rows, lipgloss.NewStyle().Width(innerWidth).Render(r.Text))
+	}
+
+	return style.Render(strings.Join(rows, "\n"))
+}
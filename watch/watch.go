@@ -0,0 +1,43 @@
+// Package watch is what the checks that keep an eye on the aircraft
+// (announcements, fences, rules, traffic counts and so on) have in
+// common: running at most every Interval, however fast the render ticks
+// come, and knowing when an aircraft's gone quiet.
+package watch
+
+import (
+	"time"
+
+	"termtrack/sbs"
+)
+
+// Interval is how often a check runs. Aircraft don't do much in a second,
+// and the render ticks come far faster than that.
+const Interval = time.Second
+
+// Watch is embedded in a check to give it its pace and its timeouts. The
+// zero value is due straight away and uses sbs.DefaultTimeouts.
+type Watch struct {
+	last     time.Time
+	timeouts sbs.Timeouts
+}
+
+// SetTimeouts sets how long aircraft from each source can go unheard
+// before the check stops counting them
+func (w *Watch) SetTimeouts(t sbs.Timeouts) {
+	w.timeouts = t
+}
+
+// Due reports whether it's been an Interval since the check last ran,
+// and if so notes that it's running now
+func (w *Watch) Due(now time.Time) bool {
+	if now.Sub(w.last) < Interval {
+		return false
+	}
+	w.last = now
+	return true
+}
+
+// Quiet reports whether ac has gone unheard for its source's timeout
+func (w *Watch) Quiet(ac *sbs.Aircraft, now time.Time) bool {
+	return ac.Quiet(now, w.timeouts)
+}